@@ -25,6 +25,7 @@ type Command struct {
 	dumpBlocks bool
 	dumpAll    bool
 	filterKey  string
+	keyFile    string
 	path       string
 }
 
@@ -43,6 +44,7 @@ func (cmd *Command) Run(args ...string) error {
 	fs.BoolVar(&cmd.dumpBlocks, "blocks", false, "Dump raw block data")
 	fs.BoolVar(&cmd.dumpAll, "all", false, "Dump all data. Caution: This may print a lot of information")
 	fs.StringVar(&cmd.filterKey, "filter-key", "", "Only display index and block data match this key substring")
+	fs.StringVar(&cmd.keyFile, "keyfile", "", "Key file of an encrypted TSM file")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
@@ -75,9 +77,14 @@ func (cmd *Command) dump() error {
 	if err != nil {
 		return err
 	}
-	b := make([]byte, 8)
+	var keys *tsm1.KeyRing
+	if cmd.keyFile != "" {
+		if keys, err = tsm1.LoadKeyFile(cmd.keyFile); err != nil {
+			return err
+		}
+	}
 
-	r, err := tsm1.NewTSMReader(f)
+	r, err := tsm1.NewTSMReaderWithKeys(f, keys)
 	if err != nil {
 		return fmt.Errorf("Error opening TSM files: %s", err.Error())
 	}
@@ -147,14 +154,10 @@ func (cmd *Command) dump() error {
 	for j := 0; j < keyCount; j++ {
 		key, _ := r.KeyAt(j)
 		for _, e := range r.Entries(string(key)) {
-
-			f.Seek(int64(e.Offset), 0)
-			f.Read(b[:4])
-
-			chksum := binary.BigEndian.Uint32(b[:4])
-
-			buf := make([]byte, e.Size-4)
-			f.Read(buf)
+			chksum, buf, err := r.ReadBytes(&e, nil)
+			if err != nil {
+				return err
+			}
 
 			blockSize += int64(e.Size)
 
@@ -169,7 +172,7 @@ func (cmd *Command) dump() error {
 			encoded := buf[1:]
 
 			var v []tsm1.Value
-			v, err := tsm1.DecodeBlock(buf, v)
+			v, err = tsm1.DecodeBlock(buf, v)
 			if err != nil {
 				return err
 			}
@@ -275,6 +278,8 @@ Usage: influx_inspect dumptsm [flags] <path
             Dump all data. Caution: This may print a lot of information
     -filter-key <name>
             Only display index and block data match this key substring
+    -keyfile <path>
+            Key file of an encrypted TSM file
 `
 
 	fmt.Fprintf(cmd.Stdout, usage)
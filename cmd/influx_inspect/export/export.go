@@ -36,7 +36,9 @@ type Command struct {
 	startTime       int64
 	endTime         int64
 	compress        bool
+	keyFile         string
 
+	keys     *tsm1.KeyRing
 	manifest map[string]struct{}
 	tsmFiles map[string][]string
 	walFiles map[string][]string
@@ -66,6 +68,7 @@ func (cmd *Command) Run(args ...string) error {
 	fs.StringVar(&start, "start", "", "Optional: the start time to export (RFC3339 format)")
 	fs.StringVar(&end, "end", "", "Optional: the end time to export (RFC3339 format)")
 	fs.BoolVar(&cmd.compress, "compress", false, "Compress the output")
+	fs.StringVar(&cmd.keyFile, "keyfile", "", "Optional: the key file of encrypted TSM and WAL files")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = func() {
@@ -103,6 +106,14 @@ func (cmd *Command) Run(args ...string) error {
 		return err
 	}
 
+	if cmd.keyFile != "" {
+		keys, err := tsm1.LoadKeyFile(cmd.keyFile)
+		if err != nil {
+			return err
+		}
+		cmd.keys = keys
+	}
+
 	return cmd.export()
 }
 
@@ -265,7 +276,7 @@ func (cmd *Command) exportTSMFile(tsmFilePath string, w io.Writer) error {
 	}
 	defer f.Close()
 
-	r, err := tsm1.NewTSMReader(f)
+	r, err := tsm1.NewTSMReaderWithKeys(f, cmd.keys)
 	if err != nil {
 		fmt.Fprintf(cmd.Stderr, "unable to read %s, skipping: %s\n", tsmFilePath, err.Error())
 		return nil
@@ -329,7 +340,7 @@ func (cmd *Command) exportWALFile(walFilePath string, w io.Writer, warnDelete fu
 	}
 	defer f.Close()
 
-	r := tsm1.NewWALSegmentReader(f)
+	r := tsm1.NewWALSegmentReaderWithKeys(f, cmd.keys)
 	defer r.Close()
 
 	for r.Next() {
@@ -72,7 +72,7 @@ func Test_exportWALFile(t *testing.T) {
 		{corpus: basicCorpus, lines: basicCorpusExpLines},
 		{corpus: escapeStringCorpus, lines: escCorpusExpLines},
 	} {
-		walFile := writeCorpusToWALFile(c.corpus)
+		walFile := writeCorpusToWALFile(c.corpus, nil)
 		defer os.Remove(walFile.Name())
 
 		var out bytes.Buffer
@@ -105,7 +105,7 @@ func Test_exportTSMFile(t *testing.T) {
 		{corpus: basicCorpus, lines: basicCorpusExpLines},
 		{corpus: escapeStringCorpus, lines: escCorpusExpLines},
 	} {
-		tsmFile := writeCorpusToTSMFile(c.corpus)
+		tsmFile := writeCorpusToTSMFile(c.corpus, nil)
 		defer os.Remove(tsmFile.Name())
 
 		var out bytes.Buffer
@@ -130,13 +130,49 @@ func Test_exportTSMFile(t *testing.T) {
 	}
 }
 
+func Test_exportEncrypted(t *testing.T) {
+	keys := tsm1.NewKeyRing()
+	if err := keys.Add(1, bytes.Repeat([]byte{1}, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	walFile := writeCorpusToWALFile(basicCorpus, keys)
+	defer os.Remove(walFile.Name())
+	tsmFile := writeCorpusToTSMFile(basicCorpus, keys)
+	defer os.Remove(tsmFile.Name())
+
+	cmd := newCommand()
+	cmd.keys = keys
+
+	var out bytes.Buffer
+	if err := cmd.exportWALFile(walFile.Name(), &out, func() {}); err != nil {
+		t.Fatal(err)
+	} else if err := cmd.exportTSMFile(tsmFile.Name(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for _, exp := range basicCorpusExpLines {
+		n := 0
+		for _, l := range lines {
+			if exp == l {
+				n++
+			}
+		}
+
+		if n != 2 {
+			t.Fatalf("expected line %q to be exported from both files:\n%s", exp, out.String())
+		}
+	}
+}
+
 var sink interface{}
 
 func benchmarkExportTSM(c corpus, b *testing.B) {
 	// Garbage collection is relatively likely to happen during export, so track allocations.
 	b.ReportAllocs()
 
-	f := writeCorpusToTSMFile(c)
+	f := writeCorpusToTSMFile(c, nil)
 	defer os.Remove(f.Name())
 
 	cmd := newCommand()
@@ -173,7 +209,7 @@ func benchmarkExportWAL(c corpus, b *testing.B) {
 	// Garbage collection is relatively likely to happen during export, so track allocations.
 	b.ReportAllocs()
 
-	f := writeCorpusToWALFile(c)
+	f := writeCorpusToWALFile(c, nil)
 	defer os.Remove(f.Name())
 
 	cmd := newCommand()
@@ -272,8 +308,9 @@ func makeStringsCorpus(numSeries, numStringsPerSeries int) corpus {
 
 // writeCorpusToWALFile writes the given corpus as a WAL file, and returns a handle to that file.
 // It is the caller's responsibility to remove the returned temp file.
+// The file is encrypted if keyRing is set.
 // writeCorpusToWALFile will panic on any error that occurs.
-func writeCorpusToWALFile(c corpus) *os.File {
+func writeCorpusToWALFile(c corpus, keyRing *tsm1.KeyRing) *os.File {
 	walFile, err := ioutil.TempFile("", "export_test_corpus_wal")
 	if err != nil {
 		panic(err)
@@ -285,7 +322,7 @@ func writeCorpusToWALFile(c corpus) *os.File {
 		panic(err)
 	}
 
-	w := tsm1.NewWALSegmentWriter(walFile)
+	w := tsm1.NewWALSegmentWriterWithKeys(walFile, keyRing)
 	if err := w.Write(e.Type(), snappy.Encode(nil, b)); err != nil {
 		panic(err)
 	}
@@ -300,14 +337,15 @@ func writeCorpusToWALFile(c corpus) *os.File {
 
 // writeCorpusToTSMFile writes the given corpus as a TSM file, and returns a handle to that file.
 // It is the caller's responsibility to remove the returned temp file.
+// The file is encrypted if keyRing is set.
 // writeCorpusToTSMFile will panic on any error that occurs.
-func writeCorpusToTSMFile(c corpus) *os.File {
+func writeCorpusToTSMFile(c corpus, keyRing *tsm1.KeyRing) *os.File {
 	tsmFile, err := ioutil.TempFile("", "export_test_corpus_tsm")
 	if err != nil {
 		panic(err)
 	}
 
-	w, err := tsm1.NewTSMWriter(tsmFile)
+	w, err := tsm1.NewTSMWriterWithKeys(tsmFile, keyRing)
 	if err != nil {
 		panic(err)
 	}
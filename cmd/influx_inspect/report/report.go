@@ -25,6 +25,7 @@ type Command struct {
 	dir      string
 	pattern  string
 	detailed bool
+	keyFile  string
 }
 
 // NewCommand returns a new instance of Command.
@@ -40,6 +41,7 @@ func (cmd *Command) Run(args ...string) error {
 	fs := flag.NewFlagSet("report", flag.ExitOnError)
 	fs.StringVar(&cmd.pattern, "pattern", "", "Include only files matching a pattern")
 	fs.BoolVar(&cmd.detailed, "detailed", false, "Report detailed cardinality estimates")
+	fs.StringVar(&cmd.keyFile, "keyfile", "", "Key file of encrypted TSM files")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
@@ -49,6 +51,14 @@ func (cmd *Command) Run(args ...string) error {
 	}
 	cmd.dir = fs.Arg(0)
 
+	var keys *tsm1.KeyRing
+	if cmd.keyFile != "" {
+		var err error
+		if keys, err = tsm1.LoadKeyFile(cmd.keyFile); err != nil {
+			return err
+		}
+	}
+
 	start := time.Now()
 
 	files, err := filepath.Glob(filepath.Join(cmd.dir, fmt.Sprintf("*.%s", tsm1.TSMFileExtension)))
@@ -86,7 +96,7 @@ func (cmd *Command) Run(args ...string) error {
 		}
 
 		loadStart := time.Now()
-		reader, err := tsm1.NewTSMReader(file)
+		reader, err := tsm1.NewTSMReaderWithKeys(file, keys)
 		if err != nil {
 			fmt.Fprintf(cmd.Stderr, "error: %s: %v. Skipping.\n", file.Name(), err)
 			continue
@@ -174,6 +184,8 @@ Usage: influx_inspect report [flags]
     -detailed
             Report detailed cardinality estimates.
             Defaults to "false".
+    -keyfile <path>
+            Key file of encrypted TSM files.
 `
 
 	fmt.Fprintf(cmd.Stdout, usage)
@@ -30,9 +30,10 @@ func NewCommand() *Command {
 
 // Run executes the command.
 func (cmd *Command) Run(args ...string) error {
-	var path string
+	var path, keyFile string
 	fs := flag.NewFlagSet("verify", flag.ExitOnError)
 	fs.StringVar(&path, "dir", os.Getenv("HOME")+"/.influxdb", "Root storage path. [$HOME/.influxdb]")
+	fs.StringVar(&keyFile, "keyfile", "", "Key file of encrypted TSM files")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
@@ -41,6 +42,14 @@ func (cmd *Command) Run(args ...string) error {
 		return err
 	}
 
+	var keys *tsm1.KeyRing
+	if keyFile != "" {
+		var err error
+		if keys, err = tsm1.LoadKeyFile(keyFile); err != nil {
+			return err
+		}
+	}
+
 	start := time.Now()
 	dataPath := filepath.Join(path, "data")
 
@@ -74,7 +83,7 @@ func (cmd *Command) Run(args ...string) error {
 			return err
 		}
 
-		reader, err := tsm1.NewTSMReader(file)
+		reader, err := tsm1.NewTSMReaderWithKeys(file, keys)
 		if err != nil {
 			return err
 		}
@@ -114,6 +123,8 @@ Usage: influx_inspect verify [flags]
     -dir <path>
             Root storage path
             Defaults to "%[1]s/.influxdb".
+    -keyfile <path>
+            Key file of encrypted TSM files
  `, os.Getenv("HOME"))
 
 	fmt.Fprintf(cmd.Stdout, usage)
@@ -18,6 +18,7 @@ import (
 	"github.com/darshanman40/influxdb/coordinator"
 	"github.com/darshanman40/influxdb/monitor"
 	"github.com/darshanman40/influxdb/services/admin"
+	"github.com/darshanman40/influxdb/services/audit"
 	"github.com/darshanman40/influxdb/services/collectd"
 	"github.com/darshanman40/influxdb/services/continuous_querier"
 	"github.com/darshanman40/influxdb/services/graphite"
@@ -26,7 +27,9 @@ import (
 	"github.com/darshanman40/influxdb/services/opentsdb"
 	"github.com/darshanman40/influxdb/services/precreator"
 	"github.com/darshanman40/influxdb/services/retention"
+	"github.com/darshanman40/influxdb/services/statsd"
 	"github.com/darshanman40/influxdb/services/subscriber"
+	"github.com/darshanman40/influxdb/services/tiering"
 	"github.com/darshanman40/influxdb/services/udp"
 	"github.com/darshanman40/influxdb/tsdb"
 )
@@ -43,15 +46,18 @@ type Config struct {
 	Coordinator coordinator.Config `toml:"coordinator"`
 	Retention   retention.Config   `toml:"retention"`
 	Precreator  precreator.Config  `toml:"shard-precreation"`
+	Tiering     tiering.Config     `toml:"tiering"`
 
 	Admin          admin.Config      `toml:"admin"`
 	Monitor        monitor.Config    `toml:"monitor"`
 	Subscriber     subscriber.Config `toml:"subscriber"`
+	Audit          audit.Config      `toml:"audit"`
 	HTTPD          httpd.Config      `toml:"http"`
 	GraphiteInputs []graphite.Config `toml:"graphite"`
 	CollectdInputs []collectd.Config `toml:"collectd"`
 	OpenTSDBInputs []opentsdb.Config `toml:"opentsdb"`
 	UDPInputs      []udp.Config      `toml:"udp"`
+	StatsdInputs   []statsd.Config   `toml:"statsd"`
 
 	ContinuousQuery continuous_querier.Config `toml:"continuous_queries"`
 
@@ -73,15 +79,18 @@ func NewConfig() *Config {
 	c.Admin = admin.NewConfig()
 	c.Monitor = monitor.NewConfig()
 	c.Subscriber = subscriber.NewConfig()
+	c.Audit = audit.NewConfig()
 	c.HTTPD = httpd.NewConfig()
 
 	c.GraphiteInputs = []graphite.Config{graphite.NewConfig()}
 	c.CollectdInputs = []collectd.Config{collectd.NewConfig()}
 	c.OpenTSDBInputs = []opentsdb.Config{opentsdb.NewConfig()}
 	c.UDPInputs = []udp.Config{udp.NewConfig()}
+	c.StatsdInputs = []statsd.Config{statsd.NewConfig()}
 
 	c.ContinuousQuery = continuous_querier.NewConfig()
 	c.Retention = retention.NewConfig()
+	c.Tiering = tiering.NewConfig()
 	c.BindAddress = DefaultBindAddress
 
 	return c
@@ -151,6 +160,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Coordinator.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Monitor.Validate(); err != nil {
 		return err
 	}
@@ -167,10 +180,18 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Tiering.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Subscriber.Validate(); err != nil {
 		return err
 	}
 
+	if err := c.Audit.Validate(); err != nil {
+		return fmt.Errorf("invalid audit config: %v", err)
+	}
+
 	for _, graphite := range c.GraphiteInputs {
 		if err := graphite.Validate(); err != nil {
 			return fmt.Errorf("invalid graphite config: %v", err)
@@ -183,6 +204,18 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for _, udp := range c.UDPInputs {
+		if err := udp.Validate(); err != nil {
+			return fmt.Errorf("invalid udp config: %v", err)
+		}
+	}
+
+	for _, statsd := range c.StatsdInputs {
+		if err := statsd.Validate(); err != nil {
+			return fmt.Errorf("invalid statsd config: %v", err)
+		}
+	}
+
 	return nil
 }
 
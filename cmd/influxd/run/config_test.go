@@ -299,6 +299,8 @@ func TestConfig_InvalidSubsections(t *testing.T) {
 		{"subscriber", `http-timeout = "0s"`},
 		{"retention", `check-interval = "0s"`},
 		{"shard-precreation", `advance-period = "0s"`},
+		{"tiering", `enabled = true`},
+		{"audit", `enabled = true`},
 	} {
 		c, err := run.NewDemoConfig()
 		if err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/darshanman40/influxdb/models"
 	"github.com/darshanman40/influxdb/monitor"
 	"github.com/darshanman40/influxdb/services/admin"
+	"github.com/darshanman40/influxdb/services/audit"
 	"github.com/darshanman40/influxdb/services/collectd"
 	"github.com/darshanman40/influxdb/services/continuous_querier"
 	"github.com/darshanman40/influxdb/services/graphite"
@@ -25,10 +26,13 @@ import (
 	"github.com/darshanman40/influxdb/services/precreator"
 	"github.com/darshanman40/influxdb/services/retention"
 	"github.com/darshanman40/influxdb/services/snapshotter"
+	"github.com/darshanman40/influxdb/services/statsd"
 	"github.com/darshanman40/influxdb/services/subscriber"
+	"github.com/darshanman40/influxdb/services/tiering"
 	"github.com/darshanman40/influxdb/services/udp"
 	"github.com/darshanman40/influxdb/tcp"
 	"github.com/darshanman40/influxdb/tsdb"
+	"github.com/darshanman40/influxdb/tsdb/engine/tsm1"
 	client "github.com/influxdata/usage-client/v1"
 	"go.uber.org/zap"
 	// Initialize the engine packages
@@ -69,6 +73,7 @@ type Server struct {
 	QueryExecutor *influxql.QueryExecutor
 	PointsWriter  *coordinator.PointsWriter
 	Subscriber    *subscriber.Service
+	AuditLog      *audit.Service
 
 	Services []Service
 
@@ -166,6 +171,30 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	// Copy TSDB configuration.
 	s.TSDBStore.EngineOptions.EngineVersion = c.Data.Engine
 
+	// Shards already in the cold tier are served even if tiering is disabled.
+	s.TSDBStore.ColdPath = c.Tiering.Dir
+	if c.Tiering.S3Endpoint != "" {
+		s.TSDBStore.ObjectStore = tiering.NewS3Client(c.Tiering)
+	}
+
+	// The keys encrypting the shards also encrypt the manifests of the
+	// shards held by the object store.
+	if c.Data.EncryptionKeyFile != "" {
+		keys, err := tsm1.LoadKeyFile(c.Data.EncryptionKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.TSDBStore.ManifestKeys = keys
+	}
+
+	// Enforce the series quotas of databases.
+	s.TSDBStore.MaxSeriesN = func(database string) int64 {
+		if di := s.MetaClient.Database(database); di != nil {
+			return di.Quota.MaxSeriesN
+		}
+		return 0
+	}
+
 	// Create the Subscriber service
 	s.Subscriber = subscriber.NewService(c.Subscriber)
 
@@ -174,6 +203,17 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	s.PointsWriter.WriteTimeout = time.Duration(c.Coordinator.WriteTimeout)
 	s.PointsWriter.TSDBStore = s.TSDBStore
 	s.PointsWriter.Subscriber = s.Subscriber
+	if len(c.Coordinator.Transforms) > 0 {
+		if s.PointsWriter.Transformer, err = coordinator.NewTransformer(c.Coordinator.Transforms); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the audit log, if enabled.
+	if c.Audit.Enabled {
+		s.AuditLog = audit.NewService(c.Audit)
+		s.AuditLog.PointsWriter = (*monitorPointsWriter)(s.PointsWriter)
+	}
 
 	// Initialize query executor.
 	s.QueryExecutor = influxql.NewQueryExecutor()
@@ -191,9 +231,20 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 		MaxSelectSeriesN:  c.Coordinator.MaxSelectSeriesN,
 		MaxSelectBucketsN: c.Coordinator.MaxSelectBucketsN,
 	}
+	if s.AuditLog != nil {
+		s.QueryExecutor.StatementExecutor.(*coordinator.StatementExecutor).AuditLog = s.AuditLog
+	}
+	s.QueryExecutor.TaskManager.QueryQuota = s.QueryExecutor.StatementExecutor.(*coordinator.StatementExecutor).QueryQuota
 	s.QueryExecutor.TaskManager.QueryTimeout = time.Duration(c.Coordinator.QueryTimeout)
 	s.QueryExecutor.TaskManager.LogQueriesAfter = time.Duration(c.Coordinator.LogQueriesAfter)
 	s.QueryExecutor.TaskManager.MaxConcurrentQueries = c.Coordinator.MaxConcurrentQueries
+	s.QueryExecutor.TaskManager.MaxQueuedQueries = c.Coordinator.MaxQueuedQueries
+	s.QueryExecutor.TaskManager.Limiter = s.MetaClient
+	if c.Coordinator.ResultCacheMaxEntries > 0 {
+		s.QueryExecutor.ResultCache = influxql.NewResultCache(c.Coordinator.ResultCacheMaxEntries, time.Duration(c.Coordinator.ResultCacheHorizon))
+		s.QueryExecutor.ResultCache.MaxMemorySize = c.Coordinator.ResultCacheMaxMemorySize
+		s.PointsWriter.ResultCache = s.QueryExecutor.ResultCache
+	}
 
 	// Initialize the monitor
 	s.Monitor.Version = s.buildInfo.Version
@@ -256,6 +307,22 @@ func (s *Server) appendRetentionPolicyService(c retention.Config) {
 	srv := retention.NewService(c)
 	srv.MetaClient = s.MetaClient
 	srv.TSDBStore = s.TSDBStore
+	if s.QueryExecutor.ResultCache != nil {
+		srv.ResultCache = s.QueryExecutor.ResultCache
+	}
+	s.Services = append(s.Services, srv)
+}
+
+func (s *Server) appendTieringService(c tiering.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := tiering.NewService(c)
+	srv.MetaClient = s.MetaClient
+	srv.TSDBStore = s.TSDBStore
+	if s.QueryExecutor.ResultCache != nil {
+		srv.ResultCache = s.QueryExecutor.ResultCache
+	}
 	s.Services = append(s.Services, srv)
 }
 
@@ -280,6 +347,9 @@ func (s *Server) appendHTTPDService(c httpd.Config) {
 	srv.Handler.Monitor = s.Monitor
 	srv.Handler.PointsWriter = s.PointsWriter
 	srv.Handler.Version = s.buildInfo.Version
+	if s.AuditLog != nil {
+		srv.Handler.AuditLog = s.AuditLog
+	}
 
 	s.Services = append(s.Services, srv)
 }
@@ -304,6 +374,7 @@ func (s *Server) appendOpenTSDBService(c opentsdb.Config) error {
 	}
 	srv.PointsWriter = s.PointsWriter
 	srv.MetaClient = s.MetaClient
+	srv.QueryExecutor = s.QueryExecutor
 	s.Services = append(s.Services, srv)
 	return nil
 }
@@ -348,6 +419,20 @@ func (s *Server) appendUDPService(c udp.Config) {
 	s.Services = append(s.Services, srv)
 }
 
+func (s *Server) appendStatsdService(c statsd.Config) error {
+	if !c.Enabled {
+		return nil
+	}
+	srv, err := statsd.NewService(c)
+	if err != nil {
+		return err
+	}
+	srv.PointsWriter = s.PointsWriter
+	srv.MetaClient = s.MetaClient
+	s.Services = append(s.Services, srv)
+	return nil
+}
+
 func (s *Server) appendContinuousQueryService(c continuous_querier.Config) {
 	if !c.Enabled {
 		return
@@ -385,6 +470,7 @@ func (s *Server) Open() error {
 	s.appendContinuousQueryService(s.config.ContinuousQuery)
 	s.appendHTTPDService(s.config.HTTPD)
 	s.appendRetentionPolicyService(s.config.Retention)
+	s.appendTieringService(s.config.Tiering)
 	for _, i := range s.config.GraphiteInputs {
 		if err := s.appendGraphiteService(i); err != nil {
 			return err
@@ -401,11 +487,19 @@ func (s *Server) Open() error {
 	for _, i := range s.config.UDPInputs {
 		s.appendUDPService(i)
 	}
+	for _, i := range s.config.StatsdInputs {
+		if err := s.appendStatsdService(i); err != nil {
+			return err
+		}
+	}
 
 	s.Subscriber.MetaClient = s.MetaClient
 	s.Subscriber.MetaClient = s.MetaClient
 	s.PointsWriter.MetaClient = s.MetaClient
 	s.Monitor.MetaClient = s.MetaClient
+	if s.AuditLog != nil {
+		s.AuditLog.MetaClient = s.MetaClient
+	}
 
 	s.SnapshotterService.Listener = mux.Listen(snapshotter.MuxHeader)
 
@@ -419,6 +513,9 @@ func (s *Server) Open() error {
 	}
 	s.PointsWriter.WithLogger(s.Logger)
 	s.Subscriber.WithLogger(s.Logger)
+	if s.AuditLog != nil {
+		s.AuditLog.WithLogger(s.Logger)
+	}
 	for _, svc := range s.Services {
 		svc.WithLogger(s.Logger)
 	}
@@ -440,6 +537,13 @@ func (s *Server) Open() error {
 		return fmt.Errorf("open points writer: %s", err)
 	}
 
+	// Open the audit log before the services whose requests it records.
+	if s.AuditLog != nil {
+		if err := s.AuditLog.Open(); err != nil {
+			return fmt.Errorf("open audit log: %s", err)
+		}
+	}
+
 	for _, service := range s.Services {
 		if err := service.Open(); err != nil {
 			return fmt.Errorf("open service: %s", err)
@@ -469,6 +573,10 @@ func (s *Server) Close() error {
 		service.Close()
 	}
 
+	if s.AuditLog != nil {
+		s.AuditLog.Close()
+	}
+
 	if s.PointsWriter != nil {
 		s.PointsWriter.Close()
 	}
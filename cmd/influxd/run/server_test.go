@@ -1011,6 +1011,69 @@ func TestServer_Query_MaxSelectSeriesN(t *testing.T) {
 	}
 }
 
+// Ensure the server returns the same results with the result cache, and
+// drops cached results when points are written in their buckets.
+func TestServer_Query_ResultCache(t *testing.T) {
+	t.Parallel()
+	config := NewConfig()
+	config.Coordinator.ResultCacheMaxEntries = 10
+	s := OpenServer(config)
+	defer s.Close()
+
+	test := NewTest("db0", "rp0")
+	test.writes = Writes{
+		&Write{data: strings.Join([]string{
+			fmt.Sprintf(`cpu,host=a value=1,other=10i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z").UnixNano()),
+			fmt.Sprintf(`cpu,host=a value=2,other=20i %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:01:10Z").UnixNano()),
+			fmt.Sprintf(`cpu,host=a value=3 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:03:20Z").UnixNano()),
+			fmt.Sprintf(`cpu,host=b value=5 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:02:00Z").UnixNano()),
+		}, "\n")},
+	}
+
+	const command = `SELECT count(value), mean(value), sum(other) FROM cpu WHERE time >= '2000-01-01T00:00:30Z' AND time < '2000-01-01T00:05:00Z' GROUP BY time(1m), host`
+	test.addQueries([]*Query{
+		&Query{
+			name:    "compute results",
+			params:  url.Values{"db": []string{"db0"}},
+			command: command,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"a"},"columns":["time","count","mean","sum"],"values":[["2000-01-01T00:00:00Z",0,null,null],["2000-01-01T00:01:00Z",1,2,20],["2000-01-01T00:02:00Z",0,null,null],["2000-01-01T00:03:00Z",1,3,null],["2000-01-01T00:04:00Z",0,null,null]]},{"name":"cpu","tags":{"host":"b"},"columns":["time","count","mean","sum"],"values":[["2000-01-01T00:00:00Z",0,null,null],["2000-01-01T00:01:00Z",0,null,null],["2000-01-01T00:02:00Z",1,5,null],["2000-01-01T00:03:00Z",0,null,null],["2000-01-01T00:04:00Z",0,null,null]]}]}]}`,
+		},
+		&Query{
+			name:    "reuse cached results",
+			params:  url.Values{"db": []string{"db0"}},
+			command: command,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"a"},"columns":["time","count","mean","sum"],"values":[["2000-01-01T00:00:00Z",0,null,null],["2000-01-01T00:01:00Z",1,2,20],["2000-01-01T00:02:00Z",0,null,null],["2000-01-01T00:03:00Z",1,3,null],["2000-01-01T00:04:00Z",0,null,null]]},{"name":"cpu","tags":{"host":"b"},"columns":["time","count","mean","sum"],"values":[["2000-01-01T00:00:00Z",0,null,null],["2000-01-01T00:01:00Z",0,null,null],["2000-01-01T00:02:00Z",1,5,null],["2000-01-01T00:03:00Z",0,null,null],["2000-01-01T00:04:00Z",0,null,null]]}]}]}`,
+		},
+	}...)
+
+	if err := test.init(s); err != nil {
+		t.Fatalf("test init failed: %s", err)
+	}
+
+	for _, query := range test.queries {
+		if err := query.Execute(s); err != nil {
+			t.Error(query.Error(err))
+		} else if !query.success() {
+			t.Error(query.failureMessage())
+		}
+	}
+
+	// Writing into the cached buckets drops them.
+	s.MustWrite("db0", "rp0", fmt.Sprintf(`cpu,host=b value=7 %d`, mustParseTime(time.RFC3339Nano, "2000-01-01T00:04:10Z").UnixNano()), nil)
+
+	query := &Query{
+		name:    "compute results after write",
+		params:  url.Values{"db": []string{"db0"}},
+		command: command,
+		exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"a"},"columns":["time","count","mean","sum"],"values":[["2000-01-01T00:00:00Z",0,null,null],["2000-01-01T00:01:00Z",1,2,20],["2000-01-01T00:02:00Z",0,null,null],["2000-01-01T00:03:00Z",1,3,null],["2000-01-01T00:04:00Z",0,null,null]]},{"name":"cpu","tags":{"host":"b"},"columns":["time","count","mean","sum"],"values":[["2000-01-01T00:00:00Z",0,null,null],["2000-01-01T00:01:00Z",0,null,null],["2000-01-01T00:02:00Z",1,5,null],["2000-01-01T00:03:00Z",0,null,null],["2000-01-01T00:04:00Z",1,7,null]]}]}]}`,
+	}
+	if err := query.Execute(s); err != nil {
+		t.Error(query.Error(err))
+	} else if !query.success() {
+		t.Error(query.failureMessage())
+	}
+}
+
 // Ensure the server can query with Now().
 func TestServer_Query_Now(t *testing.T) {
 	t.Parallel()
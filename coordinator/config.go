@@ -3,6 +3,7 @@
 package coordinator
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/darshanman40/influxdb/influxql"
@@ -17,6 +18,11 @@ const (
 	// A value of zero will make the maximum query limit unlimited.
 	DefaultMaxConcurrentQueries = 0
 
+	// DefaultMaxQueuedQueries is the maximum number of queries waiting for
+	// the concurrency limits to allow them to run.
+	// A value of zero will refuse the queries over a limit instead of queuing them.
+	DefaultMaxQueuedQueries = 100
+
 	// DefaultMaxSelectPointN is the maximum number of points a SELECT can process.
 	// A value of zero will make the maximum point count unlimited.
 	DefaultMaxSelectPointN = 0
@@ -30,20 +36,50 @@ const (
 type Config struct {
 	WriteTimeout         toml.Duration `toml:"write-timeout"`
 	MaxConcurrentQueries int           `toml:"max-concurrent-queries"`
+	MaxQueuedQueries     int           `toml:"max-queued-queries"`
 	QueryTimeout         toml.Duration `toml:"query-timeout"`
 	LogQueriesAfter      toml.Duration `toml:"log-queries-after"`
 	MaxSelectPointN      int           `toml:"max-select-point"`
 	MaxSelectSeriesN     int           `toml:"max-select-series"`
 	MaxSelectBucketsN    int           `toml:"max-select-buckets"`
+
+	// Result cache of SELECT statements grouping by time.
+	ResultCacheMaxEntries    int           `toml:"result-cache-max-entries"`
+	ResultCacheMaxMemorySize uint64        `toml:"result-cache-max-memory-size"`
+	ResultCacheHorizon       toml.Duration `toml:"result-cache-horizon"`
+
+	// Transforms are the rules applied to points before they are written.
+	Transforms []TransformConfig `toml:"transform"`
 }
 
 // NewConfig returns an instance of Config with defaults.
 func NewConfig() Config {
 	return Config{
-		WriteTimeout:         toml.Duration(DefaultWriteTimeout),
-		QueryTimeout:         toml.Duration(influxql.DefaultQueryTimeout),
-		MaxConcurrentQueries: DefaultMaxConcurrentQueries,
-		MaxSelectPointN:      DefaultMaxSelectPointN,
-		MaxSelectSeriesN:     DefaultMaxSelectSeriesN,
+		WriteTimeout:             toml.Duration(DefaultWriteTimeout),
+		QueryTimeout:             toml.Duration(influxql.DefaultQueryTimeout),
+		MaxConcurrentQueries:     DefaultMaxConcurrentQueries,
+		MaxQueuedQueries:         DefaultMaxQueuedQueries,
+		MaxSelectPointN:          DefaultMaxSelectPointN,
+		MaxSelectSeriesN:         DefaultMaxSelectSeriesN,
+		ResultCacheMaxMemorySize: influxql.DefaultResultCacheMaxMemorySize,
+		ResultCacheHorizon:       toml.Duration(influxql.DefaultResultCacheHorizon),
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c Config) Validate() error {
+	if c.MaxQueuedQueries < 0 {
+		return fmt.Errorf("max-queued-queries must not be negative")
+	} else if c.ResultCacheMaxEntries < 0 {
+		return fmt.Errorf("result-cache-max-entries must not be negative")
+	} else if c.ResultCacheHorizon < 0 {
+		return fmt.Errorf("result-cache-horizon must not be negative")
+	}
+
+	for i, t := range c.Transforms {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("transform %d: %s", i+1, err)
+		}
 	}
+	return nil
 }
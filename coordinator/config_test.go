@@ -6,6 +6,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/darshanman40/influxdb/coordinator"
+	"github.com/darshanman40/influxdb/influxql"
 )
 
 func TestConfig_Parse(t *testing.T) {
@@ -22,3 +23,88 @@ write-timeout = "20s"
 		t.Fatalf("unexpected write timeout s: %s", c.WriteTimeout)
 	}
 }
+
+func TestConfig_Parse_Transforms(t *testing.T) {
+	var c coordinator.Config
+	if _, err := toml.Decode(`
+[[transform]]
+database = "db0"
+measurement = "legacy_(.*)"
+rename = "${1}"
+
+[[transform]]
+tag-exclude = ["pid"]
+field-drop = ["debug_.*"]
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if len(c.Transforms) != 2 {
+		t.Fatalf("unexpected transforms: %+v", c.Transforms)
+	} else if c.Transforms[0].Rename != "${1}" || c.Transforms[1].FieldDrop[0] != "debug_.*" {
+		t.Fatalf("unexpected transforms: %+v", c.Transforms)
+	}
+
+	c.Transforms[1].TagExclude = []string{"("}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestConfig_Parse_MaxQueuedQueries(t *testing.T) {
+	c := coordinator.NewConfig()
+	if c.MaxQueuedQueries != coordinator.DefaultMaxQueuedQueries {
+		t.Fatalf("unexpected default max queued queries: %d", c.MaxQueuedQueries)
+	}
+
+	if _, err := toml.Decode(`
+max-queued-queries = 10
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if c.MaxQueuedQueries != 10 {
+		t.Fatalf("unexpected max queued queries: %d", c.MaxQueuedQueries)
+	}
+
+	c.MaxQueuedQueries = -1
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestConfig_Parse_ResultCache(t *testing.T) {
+	c := coordinator.NewConfig()
+	if time.Duration(c.ResultCacheHorizon) != influxql.DefaultResultCacheHorizon {
+		t.Fatalf("unexpected result cache horizon: %s", time.Duration(c.ResultCacheHorizon))
+	} else if c.ResultCacheMaxMemorySize != influxql.DefaultResultCacheMaxMemorySize {
+		t.Fatalf("unexpected result cache max memory size: %d", c.ResultCacheMaxMemorySize)
+	}
+
+	if _, err := toml.Decode(`
+result-cache-max-entries = 100
+result-cache-max-memory-size = 1048576
+result-cache-horizon = "1m"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if c.ResultCacheMaxEntries != 100 {
+		t.Fatalf("unexpected result cache max entries: %d", c.ResultCacheMaxEntries)
+	} else if c.ResultCacheMaxMemorySize != 1048576 {
+		t.Fatalf("unexpected result cache max memory size: %d", c.ResultCacheMaxMemorySize)
+	} else if time.Duration(c.ResultCacheHorizon) != time.Minute {
+		t.Fatalf("unexpected result cache horizon: %s", time.Duration(c.ResultCacheHorizon))
+	}
+
+	c.ResultCacheMaxEntries = -1
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
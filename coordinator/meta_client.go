@@ -12,23 +12,37 @@ type MetaClient interface {
 	CreateContinuousQuery(database, name, query string) error
 	CreateDatabase(name string) (*meta.DatabaseInfo, error)
 	CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
+	CreateRole(name string) error
 	CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	CreateToken(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error)
 	CreateSubscription(database, rp, name, mode string, destinations []string) error
 	CreateUser(name, password string, admin bool) (*meta.UserInfo, error)
 	Database(name string) *meta.DatabaseInfo
 	Databases() []meta.DatabaseInfo
+	DropRole(name string) error
 	DropShard(id uint64) error
 	DropContinuousQuery(database, name string) error
 	DropDatabase(name string) error
 	DropRetentionPolicy(database, name string) error
 	DropSubscription(database, rp, name string) error
+	DropToken(name string) error
 	DropUser(name string) error
 	RetentionPolicy(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	Role(name string) (*meta.RoleInfo, error)
+	Roles() []meta.RoleInfo
 	SetAdminPrivilege(username string, admin bool) error
+	SetMeasurementPrivilege(username string, g meta.MeasurementGrant) error
 	SetPrivilege(username, database string, p influxql.Privilege) error
+	SetRolePrivilege(role, database string, p influxql.Privilege) error
+	SetUserRole(username, role string, member bool) error
 	ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	Tokens() []meta.TokenInfo
+	UpdateDatabaseQuota(name string, qu *meta.QuotaUpdate) error
+	UpdateDatabaseQueryLimits(name string, lu *meta.QueryLimitsUpdate) error
 	UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUser(name, password string) error
+	UpdateUserQueryLimits(name string, lu *meta.QueryLimitsUpdate) error
+	UserMeasurementGrants(username string) ([]meta.MeasurementGrant, error)
 	UserPrivilege(username, database string) (*influxql.Privilege, error)
 	UserPrivileges(username string) (map[string]influxql.Privilege, error)
 	Users() []meta.UserInfo
@@ -12,7 +12,9 @@ type MetaClient struct {
 	CreateContinuousQueryFn             func(database, name, query string) error
 	CreateDatabaseFn                    func(name string) (*meta.DatabaseInfo, error)
 	CreateDatabaseWithRetentionPolicyFn func(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
+	CreateRoleFn                        func(name string) error
 	CreateRetentionPolicyFn             func(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	CreateTokenFn                       func(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error)
 	CreateSubscriptionFn                func(database, rp, name, mode string, destinations []string) error
 	CreateUserFn                        func(name, password string, admin bool) (*meta.UserInfo, error)
 	DatabaseFn                          func(name string) *meta.DatabaseInfo
@@ -24,16 +26,28 @@ type MetaClient struct {
 	DropContinuousQueryFn               func(database, name string) error
 	DropDatabaseFn                      func(name string) error
 	DropRetentionPolicyFn               func(database, name string) error
+	DropTokenFn                         func(name string) error
 	DropSubscriptionFn                  func(database, rp, name string) error
+	DropRoleFn                          func(name string) error
 	DropShardFn                         func(id uint64) error
 	DropUserFn                          func(name string) error
 	MetaNodesFn                         func() ([]meta.NodeInfo, error)
 	RetentionPolicyFn                   func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	RoleFn                              func(name string) (*meta.RoleInfo, error)
+	RolesFn                             func() []meta.RoleInfo
 	SetAdminPrivilegeFn                 func(username string, admin bool) error
+	SetMeasurementPrivilegeFn           func(username string, g meta.MeasurementGrant) error
+	SetRolePrivilegeFn                  func(role, database string, p influxql.Privilege) error
+	SetUserRoleFn                       func(username, role string, member bool) error
 	SetPrivilegeFn                      func(username, database string, p influxql.Privilege) error
 	ShardGroupsByTimeRangeFn            func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	TokensFn                            func() []meta.TokenInfo
+	UpdateDatabaseQuotaFn               func(name string, qu *meta.QuotaUpdate) error
+	UpdateDatabaseQueryLimitsFn         func(name string, lu *meta.QueryLimitsUpdate) error
 	UpdateRetentionPolicyFn             func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUserFn                        func(name, password string) error
+	UpdateUserQueryLimitsFn             func(name string, lu *meta.QueryLimitsUpdate) error
+	UserMeasurementGrantsFn             func(username string) ([]meta.MeasurementGrant, error)
 	UserPrivilegeFn                     func(username, database string) (*influxql.Privilege, error)
 	UserPrivilegesFn                    func(username string) (map[string]influxql.Privilege, error)
 	UsersFn                             func() []meta.UserInfo
@@ -51,14 +65,26 @@ func (c *MetaClient) CreateDatabaseWithRetentionPolicy(name string, spec *meta.R
 	return c.CreateDatabaseWithRetentionPolicyFn(name, spec)
 }
 
+func (c *MetaClient) CreateRole(name string) error {
+	return c.CreateRoleFn(name)
+}
+
 func (c *MetaClient) CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error) {
 	return c.CreateRetentionPolicyFn(database, spec, makeDefault)
 }
 
+func (c *MetaClient) DropRole(name string) error {
+	return c.DropRoleFn(name)
+}
+
 func (c *MetaClient) DropShard(id uint64) error {
 	return c.DropShardFn(id)
 }
 
+func (c *MetaClient) CreateToken(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error) {
+	return c.CreateTokenFn(name, username, database, p, expires)
+}
+
 func (c *MetaClient) CreateSubscription(database, rp, name, mode string, destinations []string) error {
 	return c.CreateSubscriptionFn(database, rp, name, mode, destinations)
 }
@@ -103,6 +129,10 @@ func (c *MetaClient) DropRetentionPolicy(database, name string) error {
 	return c.DropRetentionPolicyFn(database, name)
 }
 
+func (c *MetaClient) DropToken(name string) error {
+	return c.DropTokenFn(name)
+}
+
 func (c *MetaClient) DropSubscription(database, rp, name string) error {
 	return c.DropSubscriptionFn(database, rp, name)
 }
@@ -119,10 +149,30 @@ func (c *MetaClient) RetentionPolicy(database, name string) (rpi *meta.Retention
 	return c.RetentionPolicyFn(database, name)
 }
 
+func (c *MetaClient) Role(name string) (*meta.RoleInfo, error) {
+	return c.RoleFn(name)
+}
+
+func (c *MetaClient) Roles() []meta.RoleInfo {
+	return c.RolesFn()
+}
+
 func (c *MetaClient) SetAdminPrivilege(username string, admin bool) error {
 	return c.SetAdminPrivilegeFn(username, admin)
 }
 
+func (c *MetaClient) SetMeasurementPrivilege(username string, g meta.MeasurementGrant) error {
+	return c.SetMeasurementPrivilegeFn(username, g)
+}
+
+func (c *MetaClient) SetRolePrivilege(role, database string, p influxql.Privilege) error {
+	return c.SetRolePrivilegeFn(role, database, p)
+}
+
+func (c *MetaClient) SetUserRole(username, role string, member bool) error {
+	return c.SetUserRoleFn(username, role, member)
+}
+
 func (c *MetaClient) SetPrivilege(username, database string, p influxql.Privilege) error {
 	return c.SetPrivilegeFn(username, database, p)
 }
@@ -131,6 +181,18 @@ func (c *MetaClient) ShardGroupsByTimeRange(database, policy string, min, max ti
 	return c.ShardGroupsByTimeRangeFn(database, policy, min, max)
 }
 
+func (c *MetaClient) Tokens() []meta.TokenInfo {
+	return c.TokensFn()
+}
+
+func (c *MetaClient) UpdateDatabaseQuota(name string, qu *meta.QuotaUpdate) error {
+	return c.UpdateDatabaseQuotaFn(name, qu)
+}
+
+func (c *MetaClient) UpdateDatabaseQueryLimits(name string, lu *meta.QueryLimitsUpdate) error {
+	return c.UpdateDatabaseQueryLimitsFn(name, lu)
+}
+
 func (c *MetaClient) UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error {
 	return c.UpdateRetentionPolicyFn(database, name, rpu, makeDefault)
 }
@@ -139,6 +201,14 @@ func (c *MetaClient) UpdateUser(name, password string) error {
 	return c.UpdateUserFn(name, password)
 }
 
+func (c *MetaClient) UpdateUserQueryLimits(name string, lu *meta.QueryLimitsUpdate) error {
+	return c.UpdateUserQueryLimitsFn(name, lu)
+}
+
+func (c *MetaClient) UserMeasurementGrants(username string) ([]meta.MeasurementGrant, error) {
+	return c.UserMeasurementGrantsFn(username)
+}
+
 func (c *MetaClient) UserPrivilege(username, database string) (*influxql.Privilege, error) {
 	return c.UserPrivilegeFn(username, database)
 }
@@ -154,7 +224,7 @@ func (c *MetaClient) Users() []meta.UserInfo {
 // DefaultMetaClientDatabaseFn returns a single database (db0) with a retention policy.
 func DefaultMetaClientDatabaseFn(name string) *meta.DatabaseInfo {
 	return &meta.DatabaseInfo{
-		Name: DefaultDatabase,
+		Name:                   DefaultDatabase,
 		DefaultRetentionPolicy: DefaultRetentionPolicy,
 	}
 }
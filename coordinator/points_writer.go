@@ -17,15 +17,17 @@ import (
 
 // The keys for statistics generated by the "write" module.
 const (
-	statWriteReq           = "req"
-	statPointWriteReq      = "pointReq"
-	statPointWriteReqLocal = "pointReqLocal"
-	statWriteOK            = "writeOk"
-	statWriteDrop          = "writeDrop"
-	statWriteTimeout       = "writeTimeout"
-	statWriteErr           = "writeError"
-	statSubWriteOK         = "subWriteOk"
-	statSubWriteDrop       = "subWriteDrop"
+	statWriteReq            = "req"
+	statPointWriteReq       = "pointReq"
+	statPointWriteReqLocal  = "pointReqLocal"
+	statWriteOK             = "writeOk"
+	statWriteDrop           = "writeDrop"
+	statWriteTimeout        = "writeTimeout"
+	statWriteErr            = "writeError"
+	statSubWriteOK          = "subWriteOk"
+	statSubWriteDrop        = "subWriteDrop"
+	statPointsTransformed   = "pointsTransformed"
+	statPointsTransformDrop = "pointsTransformDrop"
 )
 
 var (
@@ -59,6 +61,7 @@ type PointsWriter struct {
 	TSDBStore interface {
 		CreateShard(database, retentionPolicy string, shardID uint64, enabled bool) error
 		WriteToShard(shardID uint64, points []models.Point) error
+		DatabaseDiskBytes(database string) int64
 	}
 
 	ShardWriter interface {
@@ -70,6 +73,18 @@ type PointsWriter struct {
 	}
 	subPoints chan<- *WritePointsRequest
 
+	// ResultCache drops the cached query results changed by the points
+	// written.
+	ResultCache interface {
+		InvalidatePoints(database string, points []models.Point)
+	}
+
+	// Transformer rewrites points before they are mapped to shards.
+	Transformer *Transformer
+
+	// Limits the write rate of databases with a quota.
+	limiter writeLimiter
+
 	stats *WriteStatistics
 }
 
@@ -156,15 +171,17 @@ func (w *PointsWriter) WithLogger(log zap.Logger) {
 
 // WriteStatistics keeps statistics related to the PointsWriter.
 type WriteStatistics struct {
-	WriteReq           int64
-	PointWriteReq      int64
-	PointWriteReqLocal int64
-	WriteOK            int64
-	WriteDropped       int64
-	WriteTimeout       int64
-	WriteErr           int64
-	SubWriteOK         int64
-	SubWriteDrop       int64
+	WriteReq            int64
+	PointWriteReq       int64
+	PointWriteReqLocal  int64
+	WriteOK             int64
+	WriteDropped        int64
+	WriteTimeout        int64
+	WriteErr            int64
+	SubWriteOK          int64
+	SubWriteDrop        int64
+	PointsTransformed   int64
+	PointsTransformDrop int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -173,15 +190,17 @@ func (w *PointsWriter) Statistics(tags map[string]string) []models.Statistic {
 		Name: "write",
 		Tags: tags,
 		Values: map[string]interface{}{
-			statWriteReq:           atomic.LoadInt64(&w.stats.WriteReq),
-			statPointWriteReq:      atomic.LoadInt64(&w.stats.PointWriteReq),
-			statPointWriteReqLocal: atomic.LoadInt64(&w.stats.PointWriteReqLocal),
-			statWriteOK:            atomic.LoadInt64(&w.stats.WriteOK),
-			statWriteDrop:          atomic.LoadInt64(&w.stats.WriteDropped),
-			statWriteTimeout:       atomic.LoadInt64(&w.stats.WriteTimeout),
-			statWriteErr:           atomic.LoadInt64(&w.stats.WriteErr),
-			statSubWriteOK:         atomic.LoadInt64(&w.stats.SubWriteOK),
-			statSubWriteDrop:       atomic.LoadInt64(&w.stats.SubWriteDrop),
+			statWriteReq:            atomic.LoadInt64(&w.stats.WriteReq),
+			statPointWriteReq:       atomic.LoadInt64(&w.stats.PointWriteReq),
+			statPointWriteReqLocal:  atomic.LoadInt64(&w.stats.PointWriteReqLocal),
+			statWriteOK:             atomic.LoadInt64(&w.stats.WriteOK),
+			statWriteDrop:           atomic.LoadInt64(&w.stats.WriteDropped),
+			statWriteTimeout:        atomic.LoadInt64(&w.stats.WriteTimeout),
+			statWriteErr:            atomic.LoadInt64(&w.stats.WriteErr),
+			statSubWriteOK:          atomic.LoadInt64(&w.stats.SubWriteOK),
+			statSubWriteDrop:        atomic.LoadInt64(&w.stats.SubWriteDrop),
+			statPointsTransformed:   atomic.LoadInt64(&w.stats.PointsTransformed),
+			statPointsTransformDrop: atomic.LoadInt64(&w.stats.PointsTransformDrop),
 		},
 	}}
 }
@@ -288,24 +307,65 @@ func (w *PointsWriter) WritePoints(database, retentionPolicy string, consistency
 	atomic.AddInt64(&w.stats.WriteReq, 1)
 	atomic.AddInt64(&w.stats.PointWriteReq, int64(len(points)))
 
+	db := w.MetaClient.Database(database)
 	if retentionPolicy == "" {
-		db := w.MetaClient.Database(database)
 		if db == nil {
 			return influxdb.ErrDatabaseNotFound(database)
 		}
 		retentionPolicy = db.DefaultRetentionPolicy
 	}
 
+	// Points dropped by a transform are reported along with those dropped by
+	// the shards, as the points given at their index in the request.
+	request := points
+	var (
+		transformDropped []tsdb.DroppedPoint
+		requestIndex     []int // index in the request of each point, if some were dropped
+	)
+	if w.Transformer != nil {
+		transformed, ntransformed, ndropped := w.Transformer.Transform(database, points)
+		atomic.AddInt64(&w.stats.PointsTransformed, int64(ntransformed))
+		atomic.AddInt64(&w.stats.PointsTransformDrop, int64(ndropped))
+
+		if ndropped > 0 {
+			points = make([]models.Point, 0, len(transformed)-ndropped)
+			requestIndex = make([]int, 0, len(transformed)-ndropped)
+			for i, p := range transformed {
+				if p == nil {
+					transformDropped = append(transformDropped, tsdb.DroppedPoint{Point: request[i], Reason: errPointDropped.Error(), Index: i})
+					continue
+				}
+				points = append(points, p)
+				requestIndex = append(requestIndex, i)
+			}
+		} else {
+			points = transformed
+		}
+	}
+
+	if db != nil {
+		if err := w.checkQuota(db, len(points)); err != nil {
+			return err
+		}
+	}
+
 	shardMappings, err := w.MapShards(&WritePointsRequest{Database: database, RetentionPolicy: retentionPolicy, Points: points})
 	if err != nil {
 		return err
 	}
 
 	// Write each shard in it's own goroutine and return as soon as one fails.
-	ch := make(chan error, len(shardMappings.Points))
+	ch := make(chan shardWriteResult, len(shardMappings.Points))
 	for shardID, points := range shardMappings.Points {
 		go func(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) {
-			ch <- w.writeToShard(shard, database, retentionPolicy, points)
+			err := w.writeToShard(shard, database, retentionPolicy, points)
+			if w.ResultCache != nil {
+				// Only points written to the shard change query results.
+				if _, ok := err.(tsdb.PartialWriteError); ok || err == nil {
+					w.ResultCache.InvalidatePoints(database, points)
+				}
+			}
+			ch <- shardWriteResult{points: points, err: err}
 		}(shardMappings.Shards[shardID], database, retentionPolicy, points)
 	}
 
@@ -325,6 +385,12 @@ func (w *PointsWriter) WritePoints(database, retentionPolicy string, consistency
 		atomic.AddInt64(&w.stats.SubWriteDrop, 1)
 	}
 
+	// Partial writes of each shard are combined so that the caller learns
+	// about every dropped point. A shard refused by a quota drops all of its
+	// points, while the other shards may have been written.
+	var partial *tsdb.PartialWriteError
+	var quotaErr error
+
 	timeout := time.NewTimer(w.WriteTimeout)
 	defer timeout.Stop()
 	for range shardMappings.Points {
@@ -335,15 +401,75 @@ func (w *PointsWriter) WritePoints(database, retentionPolicy string, consistency
 			atomic.AddInt64(&w.stats.WriteTimeout, 1)
 			// return timeout error to caller
 			return ErrTimeout
-		case err := <-ch:
-			if err != nil {
+		case res := <-ch:
+			err := res.err
+			if qerr, ok := err.(tsdb.QuotaExceededError); ok {
+				if quotaErr == nil {
+					quotaErr = qerr
+				}
+				dropped := make([]tsdb.DroppedPoint, len(res.points))
+				for i, p := range res.points {
+					dropped[i] = tsdb.DroppedPoint{Point: p, Reason: qerr.Error()}
+				}
+				err = tsdb.PartialWriteError{Reason: qerr.Error(), Dropped: len(dropped), DroppedPoints: dropped}
+			}
+
+			if werr, ok := err.(tsdb.PartialWriteError); ok {
+				if partial == nil {
+					partial = &werr
+				} else {
+					partial.Dropped += werr.Dropped
+					partial.DroppedPoints = append(partial.DroppedPoints, werr.DroppedPoints...)
+				}
+			} else if err != nil {
 				return err
 			}
 		}
 	}
-	return nil
+
+	if partial == nil && len(transformDropped) == 0 {
+		return nil
+	} else if quotaErr != nil && partial.Dropped == len(points) {
+		// Nothing was written.
+		return quotaErr
+	} else if partial == nil {
+		partial = &tsdb.PartialWriteError{Reason: errPointDropped.Error()}
+	}
+
+	// Map the points dropped by the shards back to the points of the request.
+	index := make(map[models.Point]int, len(points))
+	for i, p := range points {
+		if requestIndex != nil {
+			i = requestIndex[i]
+		}
+		index[p] = i
+	}
+	for i, d := range partial.DroppedPoints {
+		if j, ok := index[d.Point]; ok {
+			partial.DroppedPoints[i].Index = j
+			partial.DroppedPoints[i].Point = request[j]
+		}
+	}
+
+	partial.Dropped += len(transformDropped)
+	partial.DroppedPoints = append(partial.DroppedPoints, transformDropped...)
+	sort.Sort(droppedPoints(partial.DroppedPoints))
+	return *partial
 }
 
+// shardWriteResult is the result of writing points to a shard.
+type shardWriteResult struct {
+	points []models.Point
+	err    error
+}
+
+// droppedPoints sorts dropped points by their index in the request.
+type droppedPoints []tsdb.DroppedPoint
+
+func (a droppedPoints) Len() int           { return len(a) }
+func (a droppedPoints) Less(i, j int) bool { return a[i].Index < a[j].Index }
+func (a droppedPoints) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
 // writeToShards writes points to a shard.
 func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) error {
 	atomic.AddInt64(&w.stats.PointWriteReqLocal, int64(len(points)))
@@ -12,6 +12,7 @@ import (
 	"github.com/darshanman40/influxdb/coordinator"
 	"github.com/darshanman40/influxdb/models"
 	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
 )
 
 // TODO(benbjohnson): Rewrite tests to use cluster_test.MetaClient.
@@ -373,6 +374,276 @@ func TestPointsWriter_WritePoints(t *testing.T) {
 	}
 }
 
+// Ensures the points writer reports the points dropped by the shards at their
+// index in the request.
+func TestPointsWriter_WritePoints_PartialWrite(t *testing.T) {
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			// The shard rejects the points of the conflict measurement, last
+			// first.
+			var dropped []tsdb.DroppedPoint
+			for i := len(points) - 1; i >= 0; i-- {
+				if points[i].Name() == "conflict" {
+					dropped = append(dropped, tsdb.DroppedPoint{Point: points[i], Reason: "field type conflict"})
+				}
+			}
+			return tsdb.PartialWriteError{Reason: "field type conflict", Dropped: len(dropped), DroppedPoints: dropped}
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = NewPointsWriterMetaClient()
+	c.TSDBStore = store
+	c.Open()
+	defer c.Close()
+
+	now := time.Now()
+	points := []models.Point{
+		models.MustNewPoint("conflict", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, now),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, now),
+		models.MustNewPoint("conflict", models.NewTags(map[string]string{"host": "b"}), models.Fields{"value": 1.0}, now),
+	}
+
+	err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points)
+	if werr, ok := err.(tsdb.PartialWriteError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(werr.DroppedPoints) != 2 {
+		t.Fatalf("unexpected dropped points: %v", werr.DroppedPoints)
+	} else if d := werr.DroppedPoints[0]; d.Index != 0 || d.Point != points[0] {
+		t.Fatalf("unexpected dropped point: %v", d)
+	} else if d := werr.DroppedPoints[1]; d.Index != 2 || d.Point != points[2] {
+		t.Fatalf("unexpected dropped point: %v", d)
+	}
+}
+
+// Ensures the points writer transforms points before writing them.
+func TestPointsWriter_WritePoints_Transform(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+
+	var (
+		mu      sync.Mutex
+		written []string
+	)
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			// The shard rejects the points of the conflict measurement.
+			var dropped []tsdb.DroppedPoint
+			for _, p := range points {
+				if p.Name() == "conflict" {
+					dropped = append(dropped, tsdb.DroppedPoint{Point: p, Reason: "field type conflict"})
+					continue
+				}
+				written = append(written, p.String())
+			}
+			if len(dropped) > 0 {
+				return tsdb.PartialWriteError{Reason: "field type conflict", Dropped: len(dropped), DroppedPoints: dropped}
+			}
+			return nil
+		},
+	}
+
+	tr, err := coordinator.NewTransformer([]coordinator.TransformConfig{
+		{Database: "mydb", TagExclude: []string{"pid"}, FieldDrop: []string{"debug"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Transformer = tr
+	c.Open()
+	defer c.Close()
+
+	now := time.Now()
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a", "pid": "1"}), models.Fields{"value": 1.0}, now),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"debug": 1.0}, now),
+		models.MustNewPoint("conflict", models.NewTags(map[string]string{"host": "a", "pid": "1"}), models.Fields{"value": 1.0}, now),
+	}
+
+	// Points dropped by the transform or by the shard, after being transformed,
+	// are reported as given.
+	err = c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points)
+	if werr, ok := err.(tsdb.PartialWriteError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if werr.Dropped != 2 {
+		t.Fatalf("unexpected dropped count: %d", werr.Dropped)
+	} else if len(werr.DroppedPoints) != 2 {
+		t.Fatalf("unexpected dropped points: %v", werr.DroppedPoints)
+	} else if d := werr.DroppedPoints[0]; d.Index != 1 || d.Point != points[1] {
+		t.Fatalf("unexpected dropped point: %v", d)
+	} else if d := werr.DroppedPoints[1]; d.Index != 2 || d.Point != points[2] || d.Reason != "field type conflict" {
+		t.Fatalf("unexpected dropped point: %v", d)
+	}
+
+	exp := models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, now)
+	if !reflect.DeepEqual(written, []string{exp.String()}) {
+		t.Fatalf("unexpected points written: %v", written)
+	}
+
+	values := c.Statistics(nil)[0].Values
+	if got := values["pointsTransformed"]; got != int64(2) {
+		t.Fatalf("got %v transformed points, expected 2", got)
+	} else if got := values["pointsTransformDrop"]; got != int64(1) {
+		t.Fatalf("got %v dropped points, expected 1", got)
+	}
+}
+
+// Ensures the points writer drops the cached query results changed by the
+// points once they are written.
+func TestPointsWriter_WritePoints_ResultCache(t *testing.T) {
+	var written bool
+	var writeErr error
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			written = writeErr == nil
+			return writeErr
+		},
+	}
+
+	var invalidated []models.Point
+	cache := &fakeResultCache{
+		InvalidatePointsFn: func(database string, points []models.Point) {
+			if database != "mydb" {
+				t.Errorf("unexpected database: %s", database)
+			} else if !written {
+				t.Error("points invalidated before being written")
+			}
+			invalidated = append(invalidated, points...)
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = NewPointsWriterMetaClient()
+	c.TSDBStore = store
+	c.ResultCache = cache
+	c.Open()
+	defer c.Close()
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, time.Now()),
+	}
+	if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(invalidated, points) {
+		t.Fatalf("unexpected points invalidated: %v", invalidated)
+	}
+
+	// Points failing to be written don't change the cached results.
+	invalidated, written, writeErr = nil, false, fmt.Errorf("write failed")
+	if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points); err == nil {
+		t.Fatal("expected error")
+	} else if len(invalidated) != 0 {
+		t.Fatalf("unexpected points invalidated: %v", invalidated)
+	}
+}
+
+// Ensures the points writer enforces the disk and write rate quotas of
+// databases.
+func TestPointsWriter_WritePoints_Quota(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	quota := meta.DatabaseQuota{}
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, Quota: quota}
+	}
+
+	var diskBytes int64
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error { return nil },
+		DatabaseDiskBytesFn: func(database string) int64 {
+			if database != "mydb" {
+				t.Fatalf("unexpected database: %s", database)
+			}
+			return diskBytes
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Open()
+	defer c.Close()
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, time.Now()),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), models.Fields{"value": 1.0}, time.Now()),
+	}
+
+	// The disk quota refuses writes once the database is full.
+	quota.MaxDiskBytes = 1000
+	diskBytes = 999
+	if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points); err != nil {
+		t.Fatal(err)
+	}
+	diskBytes = 1000
+	if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(tsdb.QuotaExceededError); !ok || e.Quota != tsdb.QuotaDisk || e.Temporary() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The write rate quota refuses writes once the bucket is empty.
+	quota = meta.DatabaseQuota{MaxWriteRate: 3}
+	for i := 0; i < 2; i++ {
+		if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points); err != nil {
+			t.Fatalf("write %d: %s", i, err)
+		}
+	}
+	if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(tsdb.QuotaExceededError); !ok || e.Quota != tsdb.QuotaWriteRate || !e.Temporary() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensures the points writer reports the points of a shard refused by a quota
+// as dropped when other shards were written.
+func TestPointsWriter_WritePoints_ShardQuota(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	rp, _ := ms.RetentionPolicy("mydb", "myrp")
+	full := rp.ShardGroups[1].Shards[0].ID
+
+	quotaErr := tsdb.QuotaExceededError{Database: "mydb", Quota: tsdb.QuotaSeries, Usage: 10, Limit: 10}
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			if shardID == full {
+				return quotaErr
+			}
+			return nil
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Open()
+	defer c.Close()
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, rp.ShardGroups[0].StartTime),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "b"}), models.Fields{"value": 1.0}, rp.ShardGroups[1].StartTime),
+	}
+
+	err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points)
+	if werr, ok := err.(tsdb.PartialWriteError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if werr.Dropped != 1 || len(werr.DroppedPoints) != 1 {
+		t.Fatalf("unexpected dropped points: %v", werr.DroppedPoints)
+	} else if d := werr.DroppedPoints[0]; d.Index != 1 || d.Reason != quotaErr.Error() {
+		t.Fatalf("unexpected dropped point: %v", d)
+	}
+
+	// The quota error is returned as is if nothing was written.
+	if err := c.WritePoints("mydb", "myrp", models.ConsistencyLevelOne, points[1:]); err != quotaErr {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 type fakePointsWriter struct {
 	WritePointsIntoFn func(*coordinator.IntoWriteRequest) error
 }
@@ -502,8 +773,9 @@ func (f *fakeShardWriter) WriteShard(shardID, nodeID uint64, points []models.Poi
 }
 
 type fakeStore struct {
-	WriteFn       func(shardID uint64, points []models.Point) error
-	CreateShardfn func(database, retentionPolicy string, shardID uint64, enabled bool) error
+	WriteFn             func(shardID uint64, points []models.Point) error
+	CreateShardfn       func(database, retentionPolicy string, shardID uint64, enabled bool) error
+	DatabaseDiskBytesFn func(database string) int64
 }
 
 func (f *fakeStore) WriteToShard(shardID uint64, points []models.Point) error {
@@ -514,6 +786,18 @@ func (f *fakeStore) CreateShard(database, retentionPolicy string, shardID uint64
 	return f.CreateShardfn(database, retentionPolicy, shardID, enabled)
 }
 
+func (f *fakeStore) DatabaseDiskBytes(database string) int64 {
+	return f.DatabaseDiskBytesFn(database)
+}
+
+type fakeResultCache struct {
+	InvalidatePointsFn func(database string, points []models.Point)
+}
+
+func (c *fakeResultCache) InvalidatePoints(database string, points []models.Point) {
+	c.InvalidatePointsFn(database, points)
+}
+
 func NewPointsWriterMetaClient() *PointsWriterMetaClient {
 	ms := &PointsWriterMetaClient{}
 	rp := NewRetentionPolicy("myp", time.Hour, 3)
@@ -532,6 +816,10 @@ func NewPointsWriterMetaClient() *PointsWriterMetaClient {
 		return rp, nil
 	}
 
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return nil
+	}
+
 	ms.CreateShardGroupIfNotExistsFn = func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
 		for i, sg := range rp.ShardGroups {
 			if timestamp.Equal(sg.StartTime) || timestamp.After(sg.StartTime) && timestamp.Before(sg.EndTime) {
@@ -0,0 +1,101 @@
+package coordinator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
+)
+
+// writeLimiter limits the rate of points written to each database with a
+// token bucket holding up to one second of points.
+type writeLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket holds the points that may be written to a database.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow returns true if n points may be written to the database at now
+// given its rate in points per second. A write is allowed as long as the
+// bucket isn't empty, so that writes larger than the rate eventually pass.
+func (l *writeLimiter) allow(database string, rate int64, n int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = make(map[string]*tokenBucket)
+	}
+
+	b := l.buckets[database]
+	if b == nil {
+		b = &tokenBucket{tokens: float64(rate), last: now}
+		l.buckets[database] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * float64(rate)
+	if b.tokens > float64(rate) {
+		b.tokens = float64(rate)
+	}
+	b.last = now
+
+	if b.tokens <= 0 {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// checkQuota returns an error if writing n points to the database would
+// exceed its disk or write rate quota.
+func (w *PointsWriter) checkQuota(di *meta.DatabaseInfo, n int) error {
+	if max := di.Quota.MaxDiskBytes; max > 0 {
+		if size := w.TSDBStore.DatabaseDiskBytes(di.Name); size >= max {
+			return tsdb.QuotaExceededError{Database: di.Name, Quota: tsdb.QuotaDisk, Usage: size, Limit: max}
+		}
+	}
+
+	if rate := di.Quota.MaxWriteRate; rate > 0 {
+		if !w.limiter.allow(di.Name, rate, n, time.Now()) {
+			return tsdb.QuotaExceededError{Database: di.Name, Quota: tsdb.QuotaWriteRate, Usage: int64(n), Limit: rate}
+		}
+	}
+	return nil
+}
+
+// QueryQuota returns an error if another query against the database would
+// exceed its query concurrency quota, given the number n of queries running
+// against it. It's set as the QueryQuota of the TaskManager.
+func (e *StatementExecutor) QueryQuota(database string, n int) error {
+	di := e.MetaClient.Database(database)
+	if di == nil {
+		return nil
+	}
+
+	if max := di.Quota.MaxConcurrentQueries; max > 0 && int64(n) >= max {
+		return tsdb.QuotaExceededError{Database: database, Quota: tsdb.QuotaQueries, Usage: int64(n), Limit: max}
+	}
+	return nil
+}
+
+// runningQueries returns the number of queries running against the database.
+func (e *StatementExecutor) runningQueries(database string) int64 {
+	tm, ok := e.TaskManager.(*influxql.TaskManager)
+	if !ok {
+		return 0
+	}
+
+	var n int64
+	for _, q := range tm.Queries() {
+		if q.Database == database {
+			n++
+		}
+	}
+	return n
+}
@@ -36,7 +36,8 @@ type LocalShardMapper struct {
 // MapShards maps the sources to the appropriate shards into an IteratorCreator.
 func (e *LocalShardMapper) MapShards(sources influxql.Sources, opt *influxql.SelectOptions) (IteratorCreator, error) {
 	a := &LocalShardMapping{
-		ShardMap: make(map[Source]tsdb.ShardGroup),
+		ShardMap:   make(map[Source]tsdb.ShardGroup),
+		Authorizer: opt.Authorizer,
 	}
 
 	if err := e.mapShards(a, sources, opt); err != nil {
@@ -88,6 +89,9 @@ func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sour
 // ShardMapper maps data sources to a list of shard information.
 type LocalShardMapping struct {
 	ShardMap map[Source]tsdb.ShardGroup
+
+	// Authorizer restricts the series that may be read, if set.
+	Authorizer influxql.ReadAuthorizer
 }
 
 func (a *LocalShardMapping) FieldDimensions(m *influxql.Measurement) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
@@ -110,6 +114,7 @@ func (a *LocalShardMapping) FieldDimensions(m *influxql.Measurement) (fields map
 	} else {
 		measurements = []string{m.Name}
 	}
+	measurements = a.authorizedMeasurements(m.Database, measurements)
 
 	f, d, err := sg.FieldDimensions(measurements)
 	if err != nil {
@@ -133,6 +138,8 @@ func (a *LocalShardMapping) MapType(m *influxql.Measurement, field string) influ
 	sg := a.ShardMap[source]
 	if sg == nil {
 		return influxql.Unknown
+	} else if len(a.authorizedMeasurements(m.Database, []string{m.Name})) == 0 {
+		return influxql.Unknown
 	}
 	return sg.MapType(m.Name, field)
 }
@@ -153,7 +160,11 @@ func (a *LocalShardMapping) CreateIterator(m *influxql.Measurement, opt influxql
 		inputs := make([]influxql.Iterator, 0, len(measurements))
 		if err := func() error {
 			for _, measurement := range measurements {
-				input, err := sg.CreateIterator(measurement, opt)
+				mopt, ok := a.authorizeIterator(m.Database, measurement, opt)
+				if !ok {
+					continue
+				}
+				input, err := sg.CreateIterator(measurement, mopt)
 				if err != nil {
 					return err
 				}
@@ -166,9 +177,69 @@ func (a *LocalShardMapping) CreateIterator(m *influxql.Measurement, opt influxql
 		}
 		return influxql.Iterators(inputs).Merge(opt)
 	}
+
+	opt, ok := a.authorizeIterator(m.Database, m.Name, opt)
+	if !ok {
+		return nil, nil
+	}
 	return sg.CreateIterator(m.Name, opt)
 }
 
+// authorizeIterator adds the condition that the series of a measurement must
+// meet to be read to the options of its iterator. System sources, which span
+// measurements, are restricted by the condition of the whole database. It
+// returns false if none of the series may be read.
+func (a *LocalShardMapping) authorizeIterator(database, measurement string, opt influxql.IteratorOptions) (influxql.IteratorOptions, bool) {
+	if a.Authorizer == nil {
+		return opt, true
+	}
+
+	var cond influxql.Expr
+	var ok bool
+	if influxql.IsSystemName(measurement) {
+		cond, ok = a.Authorizer.DatabaseCondition(database)
+	} else {
+		cond, ok = a.Authorizer.MeasurementCondition(database, measurement)
+	}
+	if !ok {
+		return opt, false
+	}
+	opt.Condition = andCondition(opt.Condition, cond)
+	return opt, true
+}
+
+// authorizedMeasurements returns the measurements with series that may be read.
+func (a *LocalShardMapping) authorizedMeasurements(database string, measurements []string) []string {
+	if a.Authorizer == nil {
+		return measurements
+	}
+
+	authorized := make([]string, 0, len(measurements))
+	for _, name := range measurements {
+		if influxql.IsSystemName(name) {
+			authorized = append(authorized, name)
+		} else if _, ok := a.Authorizer.MeasurementCondition(database, name); ok {
+			authorized = append(authorized, name)
+		}
+	}
+	return authorized
+}
+
+// andCondition returns the conjunction of two conditions, either of which may
+// be nil.
+func andCondition(lhs, rhs influxql.Expr) influxql.Expr {
+	if lhs == nil {
+		return rhs
+	} else if rhs == nil {
+		return lhs
+	}
+	return &influxql.BinaryExpr{
+		Op:  influxql.AND,
+		LHS: &influxql.ParenExpr{Expr: lhs},
+		RHS: &influxql.ParenExpr{Expr: rhs},
+	}
+}
+
 // Close does nothing for a LocalShardMapping.
 func (a *LocalShardMapping) Close() error {
 	return nil
@@ -13,6 +13,7 @@ import (
 	"github.com/darshanman40/influxdb/influxql"
 	"github.com/darshanman40/influxdb/models"
 	"github.com/darshanman40/influxdb/monitor"
+	"github.com/darshanman40/influxdb/services/audit"
 	"github.com/darshanman40/influxdb/services/meta"
 	"github.com/darshanman40/influxdb/tsdb"
 )
@@ -21,6 +22,10 @@ import (
 // when a database has not been provided.
 var ErrDatabaseNameRequired = errors.New("database name required")
 
+// ErrRoleMeasurementGrant is returned when granting or revoking privileges on
+// measurements to a role. Roles only hold privileges on whole databases.
+var ErrRoleMeasurementGrant = errors.New("measurement privileges can't be granted to roles")
+
 type pointsWriter interface {
 	WritePointsInto(*IntoWriteRequest) error
 }
@@ -48,10 +53,75 @@ type StatementExecutor struct {
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
 	MaxSelectBucketsN int
+
+	// Records administrative and data-modifying statements, if set.
+	AuditLog interface {
+		Log(e audit.Entry)
+	}
 }
 
 // ExecuteStatement executes the given statement with the given execution context.
 func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+	err := e.executeStatement(stmt, ctx)
+	if e.AuditLog != nil && isAuditedStatement(stmt) {
+		e.AuditLog.Log(audit.Entry{
+			Event:      audit.EventStatement,
+			User:       ctx.User,
+			RemoteAddr: ctx.RemoteAddr,
+			Database:   ctx.Database,
+			Statement:  stmt.String(),
+			Result:     audit.Result(err),
+		})
+	}
+	return err
+}
+
+// isAuditedStatement returns true if stmt is recorded in the audit log, which
+// is the case of statements modifying the schema, the data, the users or
+// their privileges.
+func isAuditedStatement(stmt influxql.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *influxql.AlterDatabaseStatement,
+		*influxql.AlterFieldStatement,
+		*influxql.AlterQueryLimitsStatement,
+		*influxql.AlterRetentionPolicyStatement,
+		*influxql.CreateContinuousQueryStatement,
+		*influxql.CreateDatabaseStatement,
+		*influxql.CreateRetentionPolicyStatement,
+		*influxql.CreateRoleStatement,
+		*influxql.CreateSubscriptionStatement,
+		*influxql.CreateTokenStatement,
+		*influxql.CreateUserStatement,
+		*influxql.DeleteSeriesStatement,
+		*influxql.DeleteStatement,
+		*influxql.DropContinuousQueryStatement,
+		*influxql.DropDatabaseStatement,
+		*influxql.DropFieldStatement,
+		*influxql.DropMeasurementStatement,
+		*influxql.DropRetentionPolicyStatement,
+		*influxql.DropRoleStatement,
+		*influxql.DropSeriesStatement,
+		*influxql.DropShardStatement,
+		*influxql.DropSubscriptionStatement,
+		*influxql.DropTokenStatement,
+		*influxql.DropUserStatement,
+		*influxql.GrantStatement,
+		*influxql.GrantAdminStatement,
+		*influxql.GrantRoleStatement,
+		*influxql.KillQueryStatement,
+		*influxql.RevokeStatement,
+		*influxql.RevokeAdminStatement,
+		*influxql.RevokeRoleStatement,
+		*influxql.SetPasswordUserStatement:
+		return true
+	case *influxql.SelectStatement:
+		// SELECT INTO writes points.
+		return stmt.Target != nil
+	}
+	return false
+}
+
+func (e *StatementExecutor) executeStatement(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
 	// Select statements are handled separately so that they can be streamed.
 	if stmt, ok := stmt.(*influxql.SelectStatement); ok {
 		return e.executeSelectStatement(stmt, &ctx)
@@ -61,6 +131,21 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 	var messages []*influxql.Message
 	var err error
 	switch stmt := stmt.(type) {
+	case *influxql.AlterDatabaseStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeAlterDatabaseStatement(stmt)
+	case *influxql.AlterFieldStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeAlterFieldStatement(stmt, ctx.Database)
+	case *influxql.AlterQueryLimitsStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeAlterQueryLimitsStatement(stmt)
 	case *influxql.AlterRetentionPolicyStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
@@ -86,11 +171,21 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeCreateSubscriptionStatement(stmt)
+	case *influxql.CreateTokenStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		rows, err = e.executeCreateTokenStatement(stmt)
 	case *influxql.CreateUserStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeCreateUserStatement(stmt)
+	case *influxql.CreateRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeCreateRoleStatement(stmt)
 	case *influxql.DeleteSeriesStatement:
 		err = e.executeDeleteSeriesStatement(stmt, ctx.Database)
 	case *influxql.DropContinuousQueryStatement:
@@ -103,6 +198,11 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeDropDatabaseStatement(stmt)
+	case *influxql.DropFieldStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeDropFieldStatement(stmt, ctx.Database)
 	case *influxql.DropMeasurementStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
@@ -118,6 +218,11 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeDropRetentionPolicyStatement(stmt)
+	case *influxql.DropRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeDropRoleStatement(stmt)
 	case *influxql.DropShardStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
@@ -128,6 +233,11 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeDropSubscriptionStatement(stmt)
+	case *influxql.DropTokenStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.MetaClient.DropToken(stmt.Name)
 	case *influxql.DropUserStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
@@ -143,6 +253,11 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeGrantAdminStatement(stmt)
+	case *influxql.GrantRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.MetaClient.SetUserRole(stmt.User, stmt.Role, true)
 	case *influxql.RevokeStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
@@ -153,6 +268,11 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeRevokeAdminStatement(stmt)
+	case *influxql.RevokeRoleStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, influxql.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.MetaClient.SetUserRole(stmt.User, stmt.Role, false)
 	case *influxql.ShowContinuousQueriesStatement:
 		rows, err = e.executeShowContinuousQueriesStatement(stmt)
 	case *influxql.ShowDatabasesStatement:
@@ -163,8 +283,14 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 		rows, err = e.executeShowGrantsForUserStatement(stmt)
 	case *influxql.ShowMeasurementsStatement:
 		return e.executeShowMeasurementsStatement(stmt, &ctx)
+	case *influxql.ShowQueryLimitsStatement:
+		rows, err = e.executeShowQueryLimitsStatement(stmt)
+	case *influxql.ShowQuotasStatement:
+		rows, err = e.executeShowQuotasStatement(stmt)
 	case *influxql.ShowRetentionPoliciesStatement:
 		rows, err = e.executeShowRetentionPoliciesStatement(stmt)
+	case *influxql.ShowRolesStatement:
+		rows, err = e.executeShowRolesStatement(stmt)
 	case *influxql.ShowShardsStatement:
 		rows, err = e.executeShowShardsStatement(stmt)
 	case *influxql.ShowShardGroupsStatement:
@@ -175,6 +301,8 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 		rows, err = e.executeShowSubscriptionsStatement(stmt)
 	case *influxql.ShowTagValuesStatement:
 		return e.executeShowTagValues(stmt, &ctx)
+	case *influxql.ShowTokensStatement:
+		rows, err = e.executeShowTokensStatement(stmt)
 	case *influxql.ShowUsersStatement:
 		rows, err = e.executeShowUsersStatement(stmt)
 	case *influxql.SetPasswordUserStatement:
@@ -200,6 +328,40 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx influx
 	})
 }
 
+func (e *StatementExecutor) executeAlterDatabaseStatement(stmt *influxql.AlterDatabaseStatement) error {
+	qu := &meta.QuotaUpdate{
+		MaxSeriesN:           stmt.MaxSeriesN,
+		MaxDiskBytes:         stmt.MaxDiskBytes,
+		MaxWriteRate:         stmt.MaxWriteRate,
+		MaxConcurrentQueries: stmt.MaxConcurrentQueries,
+	}
+	return e.MetaClient.UpdateDatabaseQuota(stmt.Name, qu)
+}
+
+func (e *StatementExecutor) executeAlterFieldStatement(stmt *influxql.AlterFieldStatement, database string) error {
+	if dbi := e.MetaClient.Database(database); dbi == nil {
+		return influxql.ErrDatabaseNotFound(database)
+	}
+
+	// Locally change the field type
+	return e.TSDBStore.AlterField(database, stmt.Measurement, stmt.Name, stmt.Type)
+}
+
+func (e *StatementExecutor) executeAlterQueryLimitsStatement(stmt *influxql.AlterQueryLimitsStatement) error {
+	lu := &meta.QueryLimitsUpdate{
+		MaxSelectPointN:      stmt.MaxSelectPointN,
+		MaxSelectSeriesN:     stmt.MaxSelectSeriesN,
+		MaxSelectBucketsN:    stmt.MaxSelectBucketsN,
+		QueryTimeout:         stmt.QueryTimeout,
+		MaxConcurrentQueries: stmt.MaxConcurrentQueries,
+		Priority:             stmt.Priority,
+	}
+	if stmt.User != "" {
+		return e.MetaClient.UpdateUserQueryLimits(stmt.User, lu)
+	}
+	return e.MetaClient.UpdateDatabaseQueryLimits(stmt.Database, lu)
+}
+
 func (e *StatementExecutor) executeAlterRetentionPolicyStatement(stmt *influxql.AlterRetentionPolicyStatement) error {
 	rpu := &meta.RetentionPolicyUpdate{
 		Duration:           stmt.Duration,
@@ -338,6 +500,15 @@ func (e *StatementExecutor) executeDropDatabaseStatement(stmt *influxql.DropData
 	return e.MetaClient.DropDatabase(stmt.Name)
 }
 
+func (e *StatementExecutor) executeDropFieldStatement(stmt *influxql.DropFieldStatement, database string) error {
+	if dbi := e.MetaClient.Database(database); dbi == nil {
+		return influxql.ErrDatabaseNotFound(database)
+	}
+
+	// Locally drop the field
+	return e.TSDBStore.DeleteField(database, stmt.Measurement, stmt.Name)
+}
+
 func (e *StatementExecutor) executeDropMeasurementStatement(stmt *influxql.DropMeasurementStatement, database string) error {
 	if dbi := e.MetaClient.Database(database); dbi == nil {
 		return influxql.ErrDatabaseNotFound(database)
@@ -398,6 +569,18 @@ func (e *StatementExecutor) executeDropUserStatement(q *influxql.DropUserStateme
 }
 
 func (e *StatementExecutor) executeGrantStatement(stmt *influxql.GrantStatement) error {
+	if stmt.Role != "" {
+		if stmt.Measurement != nil || stmt.Condition != nil {
+			return ErrRoleMeasurementGrant
+		}
+		return e.MetaClient.SetRolePrivilege(stmt.Role, stmt.On, stmt.Privilege)
+	}
+
+	if stmt.Measurement != nil || stmt.Condition != nil {
+		g := newMeasurementGrant(stmt.On, stmt.Measurement, stmt.Condition)
+		g.Privilege = stmt.Privilege
+		return e.MetaClient.SetMeasurementPrivilege(stmt.User, g)
+	}
 	return e.MetaClient.SetPrivilege(stmt.User, stmt.On, stmt.Privilege)
 }
 
@@ -406,21 +589,109 @@ func (e *StatementExecutor) executeGrantAdminStatement(stmt *influxql.GrantAdmin
 }
 
 func (e *StatementExecutor) executeRevokeStatement(stmt *influxql.RevokeStatement) error {
+	if stmt.Role != "" {
+		if stmt.Measurement != nil || stmt.Condition != nil {
+			return ErrRoleMeasurementGrant
+		}
+		return e.executeRevokeRoleStatement(stmt)
+	} else if stmt.Measurement != nil || stmt.Condition != nil {
+		return e.executeRevokeMeasurementStatement(stmt)
+	}
+
 	priv := influxql.NoPrivileges
 
 	// Revoking all privileges means there's no need to look at existing user privileges.
 	if stmt.Privilege != influxql.AllPrivileges {
-		p, err := e.MetaClient.UserPrivilege(stmt.User, stmt.On)
+		// Only the privilege granted to the user directly is changed, not
+		// the effective privilege the user has through its roles.
+		privs, err := e.MetaClient.UserPrivileges(stmt.User)
 		if err != nil {
 			return err
 		}
 		// Bit clear (AND NOT) the user's privilege with the revoked privilege.
-		priv = *p &^ stmt.Privilege
+		priv = privs[stmt.On] &^ stmt.Privilege
 	}
 
 	return e.MetaClient.SetPrivilege(stmt.User, stmt.On, priv)
 }
 
+func (e *StatementExecutor) executeRevokeRoleStatement(stmt *influxql.RevokeStatement) error {
+	priv := influxql.NoPrivileges
+
+	// Revoking all privileges means there's no need to look at existing role privileges.
+	if stmt.Privilege != influxql.AllPrivileges {
+		ri, err := e.MetaClient.Role(stmt.Role)
+		if err != nil {
+			return err
+		}
+		// Bit clear (AND NOT) the role's privilege with the revoked privilege.
+		priv = ri.Privileges[stmt.On] &^ stmt.Privilege
+	}
+
+	return e.MetaClient.SetRolePrivilege(stmt.Role, stmt.On, priv)
+}
+
+func (e *StatementExecutor) executeCreateTokenStatement(stmt *influxql.CreateTokenStatement) (models.Rows, error) {
+	token, err := e.MetaClient.CreateToken(stmt.Name, stmt.User, stmt.Database, stmt.Privilege, stmt.Expires)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is only returned here, it can't be retrieved afterwards.
+	return []*models.Row{{
+		Columns: []string{"name", "token"},
+		Values:  [][]interface{}{{stmt.Name, token}},
+	}}, nil
+}
+
+func (e *StatementExecutor) executeCreateRoleStatement(stmt *influxql.CreateRoleStatement) error {
+	return e.MetaClient.CreateRole(stmt.Name)
+}
+
+func (e *StatementExecutor) executeDropRoleStatement(stmt *influxql.DropRoleStatement) error {
+	return e.MetaClient.DropRole(stmt.Name)
+}
+
+func (e *StatementExecutor) executeRevokeMeasurementStatement(stmt *influxql.RevokeStatement) error {
+	g := newMeasurementGrant(stmt.On, stmt.Measurement, stmt.Condition)
+
+	grants, err := e.MetaClient.UserMeasurementGrants(stmt.User)
+	if err != nil {
+		return err
+	}
+
+	// Bit clear (AND NOT) the privilege of the matching grant, if any.
+	for _, other := range grants {
+		if other.Database == g.Database && other.Name == g.Name &&
+			other.Regex == g.Regex && other.Condition == g.Condition {
+			g.Privilege = other.Privilege &^ stmt.Privilege
+			break
+		}
+	}
+	if stmt.Privilege == influxql.AllPrivileges {
+		g.Privilege = influxql.NoPrivileges
+	}
+
+	return e.MetaClient.SetMeasurementPrivilege(stmt.User, g)
+}
+
+// newMeasurementGrant returns the grant on the measurements and series of a
+// GRANT or REVOKE statement, without its privilege.
+func newMeasurementGrant(database string, m *influxql.Measurement, cond influxql.Expr) meta.MeasurementGrant {
+	g := meta.MeasurementGrant{Database: database}
+	if m != nil {
+		if m.Regex != nil {
+			g.Regex = m.Regex.Val.String()
+		} else {
+			g.Name = m.Name
+		}
+	}
+	if cond != nil {
+		g.Condition = cond.String()
+	}
+	return g
+}
+
 func (e *StatementExecutor) executeRevokeAdminStatement(stmt *influxql.RevokeAdminStatement) error {
 	return e.MetaClient.SetAdminPrivilege(stmt.User, false)
 }
@@ -523,10 +794,16 @@ func (e *StatementExecutor) executeSelectStatement(stmt *influxql.SelectStatemen
 func (e *StatementExecutor) createIterators(stmt *influxql.SelectStatement, ctx *influxql.ExecutionContext) ([]influxql.Iterator, *influxql.SelectStatement, error) {
 	// It is important to "stamp" this time so that everywhere we evaluate `now()` in the statement is EXACTLY the same `now`
 	now := time.Now().UTC()
+
+	// The query limits of the user or of the database override the defaults.
+	maxPointN := limitOrDefault(ctx.Limits.MaxSelectPointN, e.MaxSelectPointN)
+	maxBucketsN := limitOrDefault(ctx.Limits.MaxSelectBucketsN, e.MaxSelectBucketsN)
+
 	opt := influxql.SelectOptions{
 		InterruptCh: ctx.InterruptCh,
 		NodeID:      ctx.ExecutionOptions.NodeID,
-		MaxSeriesN:  e.MaxSelectSeriesN,
+		MaxSeriesN:  limitOrDefault(ctx.Limits.MaxSelectSeriesN, e.MaxSelectSeriesN),
+		Authorizer:  ctx.Authorizer,
 	}
 
 	// Replace instances of "now()" with the current time, and check the resultant times.
@@ -574,7 +851,7 @@ func (e *StatementExecutor) createIterators(stmt *influxql.SelectStatement, ctx
 	}
 	stmt = tmp
 
-	if e.MaxSelectBucketsN > 0 && !stmt.IsRawQuery {
+	if maxBucketsN > 0 && !stmt.IsRawQuery {
 		interval, err := stmt.GroupByInterval()
 		if err != nil {
 			return nil, stmt, err
@@ -587,8 +864,8 @@ func (e *StatementExecutor) createIterators(stmt *influxql.SelectStatement, ctx
 
 			// Determine the number of buckets by finding the time span and dividing by the interval.
 			buckets := int64(max.Sub(min)) / int64(interval)
-			if int(buckets) > e.MaxSelectBucketsN {
-				return nil, stmt, fmt.Errorf("max-select-buckets limit exceeded: (%d/%d)", buckets, e.MaxSelectBucketsN)
+			if int(buckets) > maxBucketsN {
+				return nil, stmt, fmt.Errorf("max-select-buckets limit exceeded: (%d/%d)", buckets, maxBucketsN)
 			}
 		}
 	}
@@ -599,13 +876,21 @@ func (e *StatementExecutor) createIterators(stmt *influxql.SelectStatement, ctx
 		return nil, stmt, err
 	}
 
-	if e.MaxSelectPointN > 0 {
-		monitor := influxql.PointLimitMonitor(itrs, influxql.DefaultStatsInterval, e.MaxSelectPointN)
+	if maxPointN > 0 {
+		monitor := influxql.PointLimitMonitor(itrs, influxql.DefaultStatsInterval, maxPointN)
 		ctx.Query.Monitor(monitor)
 	}
 	return itrs, stmt, nil
 }
 
+// limitOrDefault returns limit if it is set, def otherwise.
+func limitOrDefault(limit, def int) int {
+	if limit > 0 {
+		return limit
+	}
+	return def
+}
+
 func (e *StatementExecutor) executeShowContinuousQueriesStatement(stmt *influxql.ShowContinuousQueriesStatement) (models.Rows, error) {
 	dis := e.MetaClient.Databases()
 
@@ -668,7 +953,35 @@ func (e *StatementExecutor) executeShowGrantsForUserStatement(q *influxql.ShowGr
 	for d, p := range priv {
 		row.Values = append(row.Values, []interface{}{d, p.String()})
 	}
-	return []*models.Row{row}, nil
+	rows := []*models.Row{row}
+
+	grants, err := e.MetaClient.UserMeasurementGrants(q.Name)
+	if err != nil {
+		return nil, err
+	} else if len(grants) > 0 {
+		row := &models.Row{Columns: []string{"database", "measurement", "condition", "privilege"}}
+		for _, g := range grants {
+			measurement := g.Name
+			if g.Regex != "" {
+				measurement = "/" + g.Regex + "/"
+			}
+			row.Values = append(row.Values, []interface{}{g.Database, measurement, g.Condition, g.Privilege.String()})
+		}
+		rows = append(rows, row)
+	}
+
+	for _, ui := range e.MetaClient.Users() {
+		if ui.Name != q.Name || len(ui.Roles) == 0 {
+			continue
+		}
+
+		row := &models.Row{Columns: []string{"role"}}
+		for _, role := range ui.Roles {
+			row.Values = append(row.Values, []interface{}{role})
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
 }
 
 func (e *StatementExecutor) executeShowMeasurementsStatement(q *influxql.ShowMeasurementsStatement, ctx *influxql.ExecutionContext) error {
@@ -676,7 +989,12 @@ func (e *StatementExecutor) executeShowMeasurementsStatement(q *influxql.ShowMea
 		return ErrDatabaseNameRequired
 	}
 
-	measurements, err := e.TSDBStore.Measurements(q.Database, q.Condition)
+	cond, ok := authorizedCondition(ctx, q.Database, q.Condition)
+	if !ok {
+		return ctx.Send(&influxql.Result{StatementID: ctx.StatementID})
+	}
+
+	measurements, err := e.TSDBStore.Measurements(q.Database, cond)
 	if err != nil || len(measurements) == 0 {
 		return ctx.Send(&influxql.Result{
 			StatementID: ctx.StatementID,
@@ -719,6 +1037,41 @@ func (e *StatementExecutor) executeShowMeasurementsStatement(q *influxql.ShowMea
 	})
 }
 
+func (e *StatementExecutor) executeShowQueryLimitsStatement(stmt *influxql.ShowQueryLimitsStatement) (models.Rows, error) {
+	columns := []string{"name", "max_select_point", "max_select_series", "max_select_buckets", "query_timeout", "max_concurrent_queries", "priority"}
+	values := func(name string, l influxql.QueryLimits) []interface{} {
+		return []interface{}{name, l.MaxSelectPointN, l.MaxSelectSeriesN, l.MaxSelectBucketsN, l.QueryTimeout.String(), l.MaxConcurrentQueries, l.Priority}
+	}
+
+	users := &models.Row{Name: "users", Columns: columns}
+	for _, ui := range e.MetaClient.Users() {
+		users.Values = append(users.Values, values(ui.Name, ui.QueryLimits))
+	}
+
+	databases := &models.Row{Name: "databases", Columns: columns}
+	for _, di := range e.MetaClient.Databases() {
+		databases.Values = append(databases.Values, values(di.Name, di.QueryLimits))
+	}
+	return []*models.Row{users, databases}, nil
+}
+
+func (e *StatementExecutor) executeShowQuotasStatement(stmt *influxql.ShowQuotasStatement) (models.Rows, error) {
+	row := &models.Row{Name: "quotas", Columns: []string{"database", "max_series", "series", "max_disk_bytes", "disk_bytes", "max_write_rate", "max_queries", "queries"}}
+	for _, di := range e.MetaClient.Databases() {
+		row.Values = append(row.Values, []interface{}{
+			di.Name,
+			di.Quota.MaxSeriesN,
+			e.TSDBStore.DatabaseSeriesN(di.Name),
+			di.Quota.MaxDiskBytes,
+			e.TSDBStore.DatabaseDiskBytes(di.Name),
+			di.Quota.MaxWriteRate,
+			di.Quota.MaxConcurrentQueries,
+			e.runningQueries(di.Name),
+		})
+	}
+	return []*models.Row{row}, nil
+}
+
 func (e *StatementExecutor) executeShowRetentionPoliciesStatement(q *influxql.ShowRetentionPoliciesStatement) (models.Rows, error) {
 	if q.Database == "" {
 		return nil, ErrDatabaseNameRequired
@@ -844,12 +1197,31 @@ func (e *StatementExecutor) executeShowSubscriptionsStatement(stmt *influxql.Sho
 	return rows, nil
 }
 
+// authorizedCondition restricts cond to the series of database the query of
+// ctx may read. It returns false if none of them may be read.
+func authorizedCondition(ctx *influxql.ExecutionContext, database string, cond influxql.Expr) (influxql.Expr, bool) {
+	if ctx.Authorizer == nil {
+		return cond, true
+	}
+
+	acond, ok := ctx.Authorizer.DatabaseCondition(database)
+	if !ok {
+		return nil, false
+	}
+	return andCondition(cond, acond), true
+}
+
 func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatement, ctx *influxql.ExecutionContext) error {
 	if q.Database == "" {
 		return ErrDatabaseNameRequired
 	}
 
-	tagValues, err := e.TSDBStore.TagValues(q.Database, q.Condition)
+	cond, ok := authorizedCondition(ctx, q.Database, q.Condition)
+	if !ok {
+		return ctx.Send(&influxql.Result{StatementID: ctx.StatementID})
+	}
+
+	tagValues, err := e.TSDBStore.TagValues(q.Database, cond)
 	if err != nil {
 		return ctx.Send(&influxql.Result{
 			StatementID: ctx.StatementID,
@@ -906,6 +1278,51 @@ func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatem
 	return nil
 }
 
+func (e *StatementExecutor) executeShowRolesStatement(q *influxql.ShowRolesStatement) (models.Rows, error) {
+	row := &models.Row{Columns: []string{"role", "database", "privilege"}}
+	for _, ri := range e.MetaClient.Roles() {
+		if len(ri.Privileges) == 0 {
+			row.Values = append(row.Values, []interface{}{ri.Name, "", influxql.NoPrivileges.String()})
+			continue
+		}
+
+		databases := make([]string, 0, len(ri.Privileges))
+		for db := range ri.Privileges {
+			databases = append(databases, db)
+		}
+		sort.Strings(databases)
+
+		for _, db := range databases {
+			row.Values = append(row.Values, []interface{}{ri.Name, db, ri.Privileges[db].String()})
+		}
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeShowTokensStatement(q *influxql.ShowTokensStatement) (models.Rows, error) {
+	row := &models.Row{Columns: []string{"name", "user", "database", "privilege", "created", "expires", "last_used"}}
+	for _, ti := range e.MetaClient.Tokens() {
+		row.Values = append(row.Values, []interface{}{
+			ti.Name,
+			ti.User,
+			ti.Database,
+			ti.Privilege.String(),
+			formatTime(ti.CreatedAt),
+			formatTime(ti.Expiration),
+			formatTime(ti.LastUsed),
+		})
+	}
+	return []*models.Row{row}, nil
+}
+
+// formatTime formats t for SHOW statements, or returns an empty string if t is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 func (e *StatementExecutor) executeShowUsersStatement(q *influxql.ShowUsersStatement) (models.Rows, error) {
 	row := &models.Row{Columns: []string{"user", "admin"}}
 	for _, ui := range e.MetaClient.Users() {
@@ -1149,13 +1566,19 @@ type TSDBStore interface {
 	BackupShard(id uint64, since time.Time, w io.Writer) error
 
 	DeleteDatabase(name string) error
+	DeleteField(database, measurement, name string) error
 	DeleteMeasurement(database, name string) error
 	DeleteRetentionPolicy(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	DeleteShard(id uint64) error
 
+	AlterField(database, measurement, name string, typ influxql.DataType) error
+
 	Measurements(database string, cond influxql.Expr) ([]string, error)
 	TagValues(database string, cond influxql.Expr) ([]tsdb.TagValues, error)
+
+	DatabaseSeriesN(database string) int64
+	DatabaseDiskBytes(database string) int64
 }
 
 var _ TSDBStore = LocalTSDBStore{}
@@ -3,6 +3,7 @@ package coordinator_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"reflect"
@@ -15,6 +16,7 @@ import (
 	"github.com/darshanman40/influxdb/influxql"
 	"github.com/darshanman40/influxdb/internal"
 	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/audit"
 	"github.com/darshanman40/influxdb/services/meta"
 	"github.com/darshanman40/influxdb/tsdb"
 	"go.uber.org/zap"
@@ -127,6 +129,407 @@ func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	}
 }
 
+// Ensure the query limits of a database override the maximum bucket selection count.
+func TestQueryExecutor_ExecuteQuery_QueryLimits_MaxSelectBucketsN(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.StatementExecutor.MaxSelectBucketsN = 10
+	e.QueryExecutor.TaskManager.Limiter = &QueryLimiter{
+		Databases: map[string]influxql.QueryLimits{"db0": {MaxSelectBucketsN: 3}},
+	}
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(m string, opt influxql.IteratorOptions) (influxql.Iterator, error) {
+			return &FloatIterator{}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`SELECT count(value) FROM cpu WHERE time >= '2000-01-01T00:00:05Z' AND time < '2000-01-01T00:00:35Z' GROUP BY time(10s)`, "db0", 0)); !reflect.DeepEqual(a, []*influxql.Result{
+		{
+			StatementID: 0,
+			Err:         errors.New("max-select-buckets limit exceeded: (4/3)"),
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+// Ensure query executor can alter and show the query limits of users and databases.
+func TestQueryExecutor_ExecuteQuery_AlterQueryLimits(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	updates := make(map[string]*meta.QueryLimitsUpdate)
+	e.MetaClient.UpdateUserQueryLimitsFn = func(name string, lu *meta.QueryLimitsUpdate) error {
+		updates["user "+name] = lu
+		return nil
+	}
+	e.MetaClient.UpdateDatabaseQueryLimitsFn = func(name string, lu *meta.QueryLimitsUpdate) error {
+		updates["database "+name] = lu
+		return nil
+	}
+	e.MetaClient.UsersFn = func() []meta.UserInfo {
+		return []meta.UserInfo{{Name: "bob", QueryLimits: influxql.QueryLimits{QueryTimeout: time.Minute, Priority: 2}}}
+	}
+	e.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{{Name: "db0", QueryLimits: influxql.QueryLimits{MaxSelectPointN: 1000, MaxConcurrentQueries: 4}}}
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`ALTER USER bob WITH QUERY LIMITS TIMEOUT 1m PRIORITY 2; ALTER DATABASE db0 WITH QUERY LIMITS POINTS 1000 QUERIES 4`, "", 0)); !reflect.DeepEqual(a, []*influxql.Result{{StatementID: 0}, {StatementID: 1}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	timeout, priority, pointN, queries := time.Minute, 2, 1000, 4
+	if exp := map[string]*meta.QueryLimitsUpdate{
+		"user bob":     {QueryTimeout: &timeout, Priority: &priority},
+		"database db0": {MaxSelectPointN: &pointN, MaxConcurrentQueries: &queries},
+	}; !reflect.DeepEqual(updates, exp) {
+		t.Fatalf("unexpected updates: %s", spew.Sdump(updates))
+	}
+
+	columns := []string{"name", "max_select_point", "max_select_series", "max_select_buckets", "query_timeout", "max_concurrent_queries", "priority"}
+	if a := ReadAllResults(e.ExecuteQuery(`SHOW QUERY LIMITS`, "", 0)); !reflect.DeepEqual(a, []*influxql.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{
+				{
+					Name:    "users",
+					Columns: columns,
+					Values:  [][]interface{}{{"bob", 0, 0, 0, "1m0s", 0, 2}},
+				},
+				{
+					Name:    "databases",
+					Columns: columns,
+					Values:  [][]interface{}{{"db0", 1000, 0, 0, "0s", 4, 0}},
+				},
+			},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+// Ensure query executor can grant and revoke privileges on measurements.
+func TestQueryExecutor_ExecuteQuery_GrantMeasurement(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	grant := meta.MeasurementGrant{Database: "db0", Regex: "^cpu", Condition: "host = 'serverA'"}
+	var grants []meta.MeasurementGrant
+	e.MetaClient.SetMeasurementPrivilegeFn = func(username string, g meta.MeasurementGrant) error {
+		if username != "bob" {
+			t.Errorf("unexpected user: %s", username)
+		}
+		grants = append(grants, g)
+		return nil
+	}
+	e.MetaClient.UserMeasurementGrantsFn = func(username string) ([]meta.MeasurementGrant, error) {
+		g := grant
+		g.Privilege = influxql.AllPrivileges
+		return []meta.MeasurementGrant{g}, nil
+	}
+
+	for _, q := range []string{
+		`GRANT READ ON db0./^cpu/ WHERE host = 'serverA' TO bob`,
+		`REVOKE WRITE ON db0./^cpu/ WHERE host = 'serverA' FROM bob`,
+		`REVOKE ALL ON db0./^cpu/ WHERE host = 'serverA' FROM bob`,
+	} {
+		if a := ReadAllResults(e.ExecuteQuery(q, "", 0)); len(a) != 1 || a[0].Err != nil {
+			t.Fatalf("%s: unexpected results: %s", q, spew.Sdump(a))
+		}
+	}
+
+	exp := []meta.MeasurementGrant{grant, grant, grant}
+	exp[0].Privilege = influxql.ReadPrivilege
+	exp[1].Privilege = influxql.ReadPrivilege
+	exp[2].Privilege = influxql.NoPrivileges
+	if !reflect.DeepEqual(grants, exp) {
+		t.Fatalf("unexpected grants:\ngot %v\nexp %v", grants, exp)
+	}
+}
+
+// Ensure query executor can manage roles and their privileges.
+func TestQueryExecutor_ExecuteQuery_Roles(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	var calls []string
+	e.MetaClient.CreateRoleFn = func(name string) error {
+		calls = append(calls, "create "+name)
+		return nil
+	}
+	e.MetaClient.DropRoleFn = func(name string) error {
+		calls = append(calls, "drop "+name)
+		return nil
+	}
+	e.MetaClient.RoleFn = func(name string) (*meta.RoleInfo, error) {
+		return &meta.RoleInfo{Name: name, Privileges: map[string]influxql.Privilege{"db0": influxql.AllPrivileges}}, nil
+	}
+	e.MetaClient.SetRolePrivilegeFn = func(role, database string, p influxql.Privilege) error {
+		calls = append(calls, fmt.Sprintf("set %s %s %s", role, database, p))
+		return nil
+	}
+	e.MetaClient.SetUserRoleFn = func(username, role string, member bool) error {
+		calls = append(calls, fmt.Sprintf("member %s %s %v", username, role, member))
+		return nil
+	}
+
+	for _, q := range []string{
+		`CREATE ROLE readers`,
+		`GRANT READ ON db0 TO ROLE readers`,
+		`REVOKE WRITE ON db0 FROM ROLE readers`,
+		`REVOKE ALL ON db0 FROM ROLE readers`,
+		`GRANT ROLE readers TO bob`,
+		`REVOKE ROLE readers FROM bob`,
+		`DROP ROLE readers`,
+	} {
+		if a := ReadAllResults(e.ExecuteQuery(q, "", 0)); len(a) != 1 || a[0].Err != nil {
+			t.Fatalf("%s: unexpected results: %s", q, spew.Sdump(a))
+		}
+	}
+
+	if exp := []string{
+		"create readers",
+		"set readers db0 READ",
+		"set readers db0 READ",
+		"set readers db0 NO PRIVILEGES",
+		"member bob readers true",
+		"member bob readers false",
+		"drop readers",
+	}; !reflect.DeepEqual(calls, exp) {
+		t.Fatalf("unexpected calls:\ngot %v\nexp %v", calls, exp)
+	}
+
+	// Roles can't be given measurement grants.
+	if a := ReadAllResults(e.ExecuteQuery(`GRANT READ ON db0.cpu TO ROLE readers`, "", 0)); len(a) != 1 || a[0].Err != coordinator.ErrRoleMeasurementGrant {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	e.MetaClient.RolesFn = func() []meta.RoleInfo {
+		return []meta.RoleInfo{
+			{Name: "empty"},
+			{Name: "readers", Privileges: map[string]influxql.Privilege{"db1": influxql.ReadPrivilege, "db0": influxql.AllPrivileges}},
+		}
+	}
+	if a := ReadAllResults(e.ExecuteQuery(`SHOW ROLES`, "", 0)); !reflect.DeepEqual(a, []*influxql.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"role", "database", "privilege"},
+			Values: [][]interface{}{
+				{"empty", "", "NO PRIVILEGES"},
+				{"readers", "db0", "ALL PRIVILEGES"},
+				{"readers", "db1", "READ"},
+			},
+		}},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+// Ensure query executor returns API tokens when they are created.
+func TestQueryExecutor_ExecuteQuery_CreateToken(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	e.MetaClient.CreateTokenFn = func(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error) {
+		if name != "telegraf" || username != "bob" || database != "db0" || p != influxql.WritePrivilege || expires != 90*24*time.Hour {
+			t.Errorf("unexpected token: %s %s %s %s %s", name, username, database, p, expires)
+		}
+		return "0123abcd", nil
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`CREATE TOKEN telegraf FOR bob WITH WRITE ON db0 EXPIRES 90d`, "", 0)); !reflect.DeepEqual(a, []*influxql.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"name", "token"},
+			Values:  [][]interface{}{{"telegraf", "0123abcd"}},
+		}},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+// Ensure administrative statements are recorded in the audit log with their
+// result, and that other statements aren't.
+func TestQueryExecutor_ExecuteQuery_AuditLog(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.TSDBStore.DeleteDatabaseFn = func(name string) error { return nil }
+	e.MetaClient.DropDatabaseFn = func(name string) error { return nil }
+	e.MetaClient.CreateUserFn = func(name, password string, admin bool) (*meta.UserInfo, error) {
+		return nil, meta.ErrUserExists
+	}
+	e.MetaClient.DatabasesFn = func() []meta.DatabaseInfo { return nil }
+
+	var entries []audit.Entry
+	e.StatementExecutor.AuditLog = AuditLogFunc(func(e audit.Entry) {
+		entries = append(entries, e)
+	})
+
+	results := e.QueryExecutor.ExecuteQuery(MustParseQuery(`SHOW DATABASES; DROP DATABASE db0; CREATE USER bob WITH PASSWORD 'secret'`), influxql.ExecutionOptions{
+		User:       "admin",
+		RemoteAddr: "127.0.0.1:1234",
+	}, make(chan struct{}))
+	ReadAllResults(results)
+
+	if !reflect.DeepEqual(entries, []audit.Entry{
+		{Event: audit.EventStatement, User: "admin", RemoteAddr: "127.0.0.1:1234", Statement: "DROP DATABASE db0", Result: "ok"},
+		{Event: audit.EventStatement, User: "admin", RemoteAddr: "127.0.0.1:1234", Statement: "CREATE USER bob WITH PASSWORD [REDACTED]", Result: "user already exists"},
+	}) {
+		t.Fatalf("unexpected entries: %s", spew.Sdump(entries))
+	}
+}
+
+// Ensure query executor can alter and show the quotas of databases.
+func TestQueryExecutor_ExecuteQuery_Quotas(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	var update *meta.QuotaUpdate
+	e.MetaClient.UpdateDatabaseQuotaFn = func(name string, qu *meta.QuotaUpdate) error {
+		if name != "db0" {
+			t.Fatalf("unexpected database: %s", name)
+		}
+		update = qu
+		return nil
+	}
+	e.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{{Name: "db0", Quota: meta.DatabaseQuota{MaxSeriesN: 1000, MaxWriteRate: 500}}}
+	}
+	e.TSDBStore.DatabaseSeriesNFn = func(database string) int64 { return 10 }
+	e.TSDBStore.DatabaseDiskBytesFn = func(database string) int64 { return 4096 }
+
+	if a := ReadAllResults(e.ExecuteQuery(`ALTER DATABASE db0 WITH QUOTA SERIES 1000 WRITE RATE 500`, "", 0)); !reflect.DeepEqual(a, []*influxql.Result{{StatementID: 0}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	seriesN, writeRate := int64(1000), int64(500)
+	if exp := (&meta.QuotaUpdate{MaxSeriesN: &seriesN, MaxWriteRate: &writeRate}); !reflect.DeepEqual(update, exp) {
+		t.Fatalf("unexpected update: %s", spew.Sdump(update))
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`SHOW QUOTAS`, "", 0)); !reflect.DeepEqual(a, []*influxql.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "quotas",
+				Columns: []string{"database", "max_series", "series", "max_disk_bytes", "disk_bytes", "max_write_rate", "max_queries", "queries"},
+				Values:  [][]interface{}{{"db0", int64(1000), int64(10), int64(0), int64(4096), int64(500), int64(0), int64(0)}},
+			}},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+// Ensure query executor enforces the query concurrency quota of databases.
+func TestQueryExecutor_ExecuteQuery_QueriesQuota(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		di := DefaultMetaClientDatabaseFn(name)
+		di.Quota.MaxConcurrentQueries = 1
+		return di
+	}
+	e.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{*e.MetaClient.DatabaseFn("db0")}
+	}
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	// A second query is run while the first one creates its iterators, and
+	// is refused since no query may wait in the queue.
+	var nested, quotas []*influxql.Result
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(m string, opt influxql.IteratorOptions) (influxql.Iterator, error) {
+			if nested == nil {
+				nested = ReadAllResults(e.ExecuteQuery(`SELECT value FROM cpu`, "db0", 0))
+				quotas = ReadAllResults(e.ExecuteQuery(`SHOW QUOTAS`, "", 0))
+			}
+			return &FloatIterator{}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`SELECT value FROM cpu`, "db0", 0)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	} else if len(nested) != 1 || nested[0].Err == nil || nested[0].Err.Error() != "queries quota exceeded: db=db0 (1/1)" {
+		t.Fatalf("unexpected nested results: %s", spew.Sdump(nested))
+	} else if len(quotas) != 1 || len(quotas[0].Series) != 1 || quotas[0].Series[0].Values[0][7] != int64(1) {
+		t.Fatalf("unexpected quotas: %s", spew.Sdump(quotas))
+	}
+
+	// The query no longer counts once it's done.
+	nested = nil
+	if a := ReadAllResults(e.ExecuteQuery(`SELECT value FROM cpu`, "db0", 0)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	} else if len(nested) != 1 || nested[0].Err == nil {
+		t.Fatalf("unexpected nested results: %s", spew.Sdump(nested))
+	}
+}
+
+// AuditLogFunc records the entries of the audit log by calling a function.
+type AuditLogFunc func(e audit.Entry)
+
+func (fn AuditLogFunc) Log(e audit.Entry) { fn(e) }
+
+// Ensure the series read by a SELECT statement are restricted by the
+// authorizer of the query.
+func TestQueryExecutor_ExecuteQuery_Authorizer(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(m string, opt influxql.IteratorOptions) (influxql.Iterator, error) {
+			if m != "cpu" {
+				t.Errorf("unexpected measurement: %s", m)
+			} else if got, exp := opt.Condition.String(), `(value > 1) AND (host::tag = 'serverA')`; got != exp {
+				t.Errorf("unexpected condition: got %s, exp %s", got, exp)
+			}
+			return &FloatIterator{}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			if len(measurements) > 0 && !reflect.DeepEqual(measurements, []string{"cpu"}) {
+				t.Errorf("unexpected measurements: %#v", measurements)
+			}
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	ui := &meta.UserInfo{
+		Name: "bob",
+		Grants: []meta.MeasurementGrant{
+			{Database: "db0", Name: "cpu", Condition: "host = 'serverA'", Privilege: influxql.ReadPrivilege},
+		},
+	}
+	results := e.QueryExecutor.ExecuteQuery(MustParseQuery(`SELECT value FROM cpu, mem WHERE value > 1`), influxql.ExecutionOptions{
+		Database:   "db0",
+		Authorizer: ui,
+	}, make(chan struct{}))
+	if a := ReadAllResults(results); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
 func TestStatementExecutor_NormalizeDropSeries(t *testing.T) {
 	q, err := influxql.ParseQuery("DROP SERIES FROM cpu")
 	if err != nil {
@@ -193,6 +596,20 @@ func TestStatementExecutor_NormalizeDeleteSeries(t *testing.T) {
 	}
 }
 
+// QueryLimiter is a mockable implementation of influxql.QueryLimiter.
+type QueryLimiter struct {
+	Users     map[string]influxql.QueryLimits
+	Databases map[string]influxql.QueryLimits
+}
+
+func (l *QueryLimiter) UserQueryLimits(name string) influxql.QueryLimits {
+	return l.Users[name]
+}
+
+func (l *QueryLimiter) DatabaseQueryLimits(name string) influxql.QueryLimits {
+	return l.Databases[name]
+}
+
 // QueryExecutor is a test wrapper for coordinator.QueryExecutor.
 type QueryExecutor struct {
 	*influxql.QueryExecutor
@@ -210,14 +627,16 @@ func NewQueryExecutor() *QueryExecutor {
 		QueryExecutor: influxql.NewQueryExecutor(),
 	}
 	e.StatementExecutor = &coordinator.StatementExecutor{
-		MetaClient: &e.MetaClient,
-		TSDBStore:  &e.TSDBStore,
+		MetaClient:  &e.MetaClient,
+		TaskManager: e.QueryExecutor.TaskManager,
+		TSDBStore:   &e.TSDBStore,
 		ShardMapper: &coordinator.LocalShardMapper{
 			MetaClient: &e.MetaClient,
 			TSDBStore:  &e.TSDBStore,
 		},
 	}
 	e.QueryExecutor.StatementExecutor = e.StatementExecutor
+	e.QueryExecutor.TaskManager.QueryQuota = e.StatementExecutor.QueryQuota
 
 	var z *zap.Logger
 	var err error
@@ -261,12 +680,16 @@ type TSDBStore struct {
 	BackupShardFn  func(id uint64, since time.Time, w io.Writer) error
 
 	DeleteDatabaseFn        func(name string) error
+	DeleteFieldFn           func(database, measurement, name string) error
 	DeleteMeasurementFn     func(database, name string) error
 	DeleteRetentionPolicyFn func(database, name string) error
 	DeleteShardFn           func(id uint64) error
 	DeleteSeriesFn          func(database string, sources []influxql.Source, condition influxql.Expr) error
 	DatabaseIndexFn         func(name string) *tsdb.DatabaseIndex
 	ShardGroupFn            func(ids []uint64) tsdb.ShardGroup
+	AlterFieldFn            func(database, measurement, name string, typ influxql.DataType) error
+	DatabaseSeriesNFn       func(database string) int64
+	DatabaseDiskBytesFn     func(database string) int64
 }
 
 func (s *TSDBStore) CreateShard(database, policy string, shardID uint64, enabled bool) error {
@@ -292,6 +715,14 @@ func (s *TSDBStore) DeleteDatabase(name string) error {
 	return s.DeleteDatabaseFn(name)
 }
 
+func (s *TSDBStore) DeleteField(database, measurement, name string) error {
+	return s.DeleteFieldFn(database, measurement, name)
+}
+
+func (s *TSDBStore) AlterField(database, measurement, name string, typ influxql.DataType) error {
+	return s.AlterFieldFn(database, measurement, name, typ)
+}
+
 func (s *TSDBStore) DeleteMeasurement(database, name string) error {
 	return s.DeleteMeasurementFn(database, name)
 }
@@ -324,6 +755,20 @@ func (s *TSDBStore) TagValues(database string, cond influxql.Expr) ([]tsdb.TagVa
 	return nil, nil
 }
 
+func (s *TSDBStore) DatabaseSeriesN(database string) int64 {
+	if s.DatabaseSeriesNFn == nil {
+		return 0
+	}
+	return s.DatabaseSeriesNFn(database)
+}
+
+func (s *TSDBStore) DatabaseDiskBytes(database string) int64 {
+	if s.DatabaseDiskBytesFn == nil {
+		return 0
+	}
+	return s.DatabaseDiskBytesFn(database)
+}
+
 type MockShard struct {
 	Measurements      []string
 	FieldDimensionsFn func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error)
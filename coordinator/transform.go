@@ -0,0 +1,239 @@
+package coordinator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/darshanman40/influxdb/models"
+)
+
+// TransformConfig represents a rule rewriting the points written to a
+// database. The steps of a rule are applied in the order: measurement rename,
+// tag to field conversion, tag include, tag exclude and field drop.
+//
+// Measurement, tag and field patterns are regular expressions that must match
+// the whole name, so plain names match only themselves.
+type TransformConfig struct {
+	// Database is the database the rule applies to. The rule applies to all
+	// databases if empty.
+	Database string `toml:"database"`
+
+	// Measurement restricts the rule to points of matching measurements.
+	Measurement string `toml:"measurement"`
+
+	// Rename replaces the measurement name using the Measurement pattern. It
+	// may refer to submatches of the pattern, as in "${1}".
+	Rename string `toml:"rename"`
+
+	// TagsToFields are patterns of tags converted to string fields. A tag is
+	// dropped without replacing a field of the same name.
+	TagsToFields []string `toml:"tags-to-fields"`
+
+	// TagInclude are patterns of the tags to keep. All tags are kept if empty.
+	TagInclude []string `toml:"tag-include"`
+
+	// TagExclude are patterns of the tags to drop.
+	TagExclude []string `toml:"tag-exclude"`
+
+	// FieldDrop are patterns of the fields to drop. Points left without
+	// fields are dropped.
+	FieldDrop []string `toml:"field-drop"`
+}
+
+// Validate returns an error if the rule is invalid.
+func (c TransformConfig) Validate() error {
+	_, err := newTransformRule(c)
+	return err
+}
+
+// Transformer rewrites points with a chain of rules before they are written.
+type Transformer struct {
+	rules []*transformRule
+}
+
+// NewTransformer returns a Transformer applying the rules of configs in order.
+func NewTransformer(configs []TransformConfig) (*Transformer, error) {
+	t := &Transformer{}
+	for i, c := range configs {
+		r, err := newTransformRule(c)
+		if err != nil {
+			return nil, fmt.Errorf("transform %d: %s", i+1, err)
+		}
+		t.rules = append(t.rules, r)
+	}
+	return t, nil
+}
+
+// Transform applies the rules of database to points. It returns the
+// resulting points, at the index of the points they replace and nil for the
+// points that were dropped, along with the number of points that were changed
+// and the number of points that were dropped. The points slice is not
+// modified.
+func (t *Transformer) Transform(database string, points []models.Point) ([]models.Point, int, int) {
+	var rules []*transformRule
+	for _, r := range t.rules {
+		if r.database == "" || r.database == database {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == 0 {
+		return points, 0, 0
+	}
+
+	var transformed, dropped int
+	out := make([]models.Point, len(points))
+	for i, p := range points {
+		pt, changed, err := transformPoint(p, rules)
+		if err != nil {
+			dropped++
+			continue
+		} else if changed {
+			transformed++
+		}
+		out[i] = pt
+	}
+	return out, transformed, dropped
+}
+
+// errPointDropped is returned by transformPoint when a rule removes all the
+// fields of a point or renames its measurement to an empty name.
+var errPointDropped = errors.New("point dropped by transform")
+
+// transformPoint applies rules to p, returning a new point if any rule
+// changed it.
+func transformPoint(p models.Point, rules []*transformRule) (models.Point, bool, error) {
+	name := p.Name()
+	tags := p.Tags().Map()
+	fields, err := p.Fields()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var changed bool
+	for _, r := range rules {
+		if r.apply(&name, tags, fields) {
+			changed = true
+		}
+	}
+	if !changed {
+		return p, false, nil
+	}
+
+	if name == "" || len(fields) == 0 {
+		return nil, false, errPointDropped
+	}
+
+	pt, err := models.NewPoint(name, models.NewTags(tags), fields, p.Time())
+	if err != nil {
+		return nil, false, err
+	}
+	return pt, true, nil
+}
+
+// transformRule is a compiled TransformConfig.
+type transformRule struct {
+	database     string
+	measurement  *regexp.Regexp
+	rename       string
+	tagsToFields []*regexp.Regexp
+	tagInclude   []*regexp.Regexp
+	tagExclude   []*regexp.Regexp
+	fieldDrop    []*regexp.Regexp
+}
+
+func newTransformRule(c TransformConfig) (*transformRule, error) {
+	r := &transformRule{database: c.Database, rename: c.Rename}
+
+	if c.Measurement != "" {
+		re, err := compileNamePattern(c.Measurement)
+		if err != nil {
+			return nil, fmt.Errorf("invalid measurement pattern: %s", err)
+		}
+		r.measurement = re
+	} else if c.Rename != "" {
+		return nil, errors.New("rename requires a measurement pattern")
+	}
+
+	var err error
+	if r.tagsToFields, err = compileNamePatterns(c.TagsToFields); err != nil {
+		return nil, fmt.Errorf("invalid tags-to-fields pattern: %s", err)
+	}
+	if r.tagInclude, err = compileNamePatterns(c.TagInclude); err != nil {
+		return nil, fmt.Errorf("invalid tag-include pattern: %s", err)
+	}
+	if r.tagExclude, err = compileNamePatterns(c.TagExclude); err != nil {
+		return nil, fmt.Errorf("invalid tag-exclude pattern: %s", err)
+	}
+	if r.fieldDrop, err = compileNamePatterns(c.FieldDrop); err != nil {
+		return nil, fmt.Errorf("invalid field-drop pattern: %s", err)
+	}
+	return r, nil
+}
+
+// apply applies the rule to the parts of a point in place and returns true if
+// any of them changed.
+func (r *transformRule) apply(name *string, tags map[string]string, fields models.Fields) bool {
+	if r.measurement != nil && !r.measurement.MatchString(*name) {
+		return false
+	}
+
+	var changed bool
+	if r.rename != "" {
+		if s := r.measurement.ReplaceAllString(*name, r.rename); s != *name {
+			*name, changed = s, true
+		}
+	}
+
+	for k, v := range tags {
+		if !matchAny(r.tagsToFields, k) {
+			continue
+		}
+		if _, ok := fields[k]; !ok {
+			fields[k] = v
+		}
+		delete(tags, k)
+		changed = true
+	}
+
+	for k := range tags {
+		if (len(r.tagInclude) > 0 && !matchAny(r.tagInclude, k)) || matchAny(r.tagExclude, k) {
+			delete(tags, k)
+			changed = true
+		}
+	}
+
+	for k := range fields {
+		if matchAny(r.fieldDrop, k) {
+			delete(fields, k)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// compileNamePattern compiles a pattern that must match a whole name.
+func compileNamePattern(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+func compileNamePatterns(exprs []string) ([]*regexp.Regexp, error) {
+	a := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := compileNamePattern(expr)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, re)
+	}
+	return a, nil
+}
+
+func matchAny(a []*regexp.Regexp, s string) bool {
+	for _, re := range a {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
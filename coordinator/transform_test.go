@@ -0,0 +1,98 @@
+package coordinator_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/darshanman40/influxdb/coordinator"
+	"github.com/darshanman40/influxdb/models"
+)
+
+// Ensure the transformer applies the rules of a database in order.
+func TestTransformer_Transform(t *testing.T) {
+	tr, err := coordinator.NewTransformer([]coordinator.TransformConfig{
+		{
+			Database:    "db0",
+			Measurement: "legacy_(.*)",
+			Rename:      "${1}",
+		},
+		{
+			Database:     "db0",
+			TagsToFields: []string{"status"},
+			TagExclude:   []string{"pid", "tmp_.*"},
+			FieldDrop:    []string{"debug_.*"},
+		},
+		{
+			Database:   "db1",
+			TagInclude: []string{"host"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := models.ParsePointsString(`legacy_cpu,host=a,pid=1,tmp_x=y value=1 1
+mem,host=a,status=ok value=2i,status="old" 2
+mem,host=a value=3i 3
+disk,host=a debug_inodes=1 4
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, transformed, dropped := tr.Transform("db0", points)
+	if transformed != 2 || dropped != 1 {
+		t.Fatalf("got %d transformed and %d dropped points, expected 2 and 1", transformed, dropped)
+	}
+	if exp := []string{
+		`cpu,host=a value=1 1`,
+		`mem,host=a status="old",value=2i 2`,
+		`mem,host=a value=3i 3`,
+		``,
+	}; !reflect.DeepEqual(pointStrings(got), exp) {
+		t.Fatalf("unexpected points:\n\tgot = %v\n\texp = %v", pointStrings(got), exp)
+	}
+
+	// Unchanged points are returned as is.
+	if got[2] != points[2] {
+		t.Fatal("expected unchanged point to be returned as is")
+	}
+
+	got, transformed, dropped = tr.Transform("db1", points[:1])
+	if transformed != 1 || dropped != 0 {
+		t.Fatalf("got %d transformed and %d dropped points, expected 1 and 0", transformed, dropped)
+	} else if exp := []string{`legacy_cpu,host=a value=1 1`}; !reflect.DeepEqual(pointStrings(got), exp) {
+		t.Fatalf("unexpected points:\n\tgot = %v\n\texp = %v", pointStrings(got), exp)
+	}
+
+	// Databases without rules are left alone.
+	if got, _, _ = tr.Transform("db2", points); !reflect.DeepEqual(got, points) {
+		t.Fatal("expected points of db2 to be unchanged")
+	}
+}
+
+// Ensure invalid rules are rejected.
+func TestTransformConfig_Validate(t *testing.T) {
+	for _, c := range []coordinator.TransformConfig{
+		{Rename: "cpu"},
+		{Measurement: "("},
+		{TagInclude: []string{"["}},
+		{TagExclude: []string{"["}},
+		{TagsToFields: []string{"["}},
+		{FieldDrop: []string{"["}},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
+
+func pointStrings(points []models.Point) []string {
+	a := make([]string, len(points))
+	for i, p := range points {
+		if p != nil {
+			a[i] = p.String()
+		}
+	}
+	return a
+}
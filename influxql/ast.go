@@ -108,28 +108,38 @@ type Node interface {
 func (*Query) node()     {}
 func (Statements) node() {}
 
+func (*AlterDatabaseStatement) node()         {}
+func (*AlterFieldStatement) node()            {}
+func (*AlterQueryLimitsStatement) node()      {}
 func (*AlterRetentionPolicyStatement) node()  {}
 func (*CreateContinuousQueryStatement) node() {}
 func (*CreateDatabaseStatement) node()        {}
 func (*CreateRetentionPolicyStatement) node() {}
+func (*CreateRoleStatement) node()            {}
 func (*CreateSubscriptionStatement) node()    {}
+func (*CreateTokenStatement) node()           {}
 func (*CreateUserStatement) node()            {}
 func (*Distinct) node()                       {}
 func (*DeleteSeriesStatement) node()          {}
 func (*DeleteStatement) node()                {}
 func (*DropContinuousQueryStatement) node()   {}
 func (*DropDatabaseStatement) node()          {}
+func (*DropFieldStatement) node()             {}
 func (*DropMeasurementStatement) node()       {}
 func (*DropRetentionPolicyStatement) node()   {}
+func (*DropRoleStatement) node()              {}
 func (*DropSeriesStatement) node()            {}
 func (*DropShardStatement) node()             {}
 func (*DropSubscriptionStatement) node()      {}
+func (*DropTokenStatement) node()             {}
 func (*DropUserStatement) node()              {}
 func (*GrantStatement) node()                 {}
 func (*GrantAdminStatement) node()            {}
+func (*GrantRoleStatement) node()             {}
 func (*KillQueryStatement) node()             {}
 func (*RevokeStatement) node()                {}
 func (*RevokeAdminStatement) node()           {}
+func (*RevokeRoleStatement) node()            {}
 func (*SelectStatement) node()                {}
 func (*SetPasswordUserStatement) node()       {}
 func (*ShowContinuousQueriesStatement) node() {}
@@ -139,8 +149,12 @@ func (*ShowFieldKeysStatement) node()         {}
 func (*ShowRetentionPoliciesStatement) node() {}
 func (*ShowMeasurementsStatement) node()      {}
 func (*ShowQueriesStatement) node()           {}
+func (*ShowQueryLimitsStatement) node()       {}
+func (*ShowQuotasStatement) node()            {}
+func (*ShowRolesStatement) node()             {}
 func (*ShowSeriesStatement) node()            {}
 func (*ShowShardGroupsStatement) node()       {}
+func (*ShowTokensStatement) node()            {}
 func (*ShowShardsStatement) node()            {}
 func (*ShowStatsStatement) node()             {}
 func (*ShowSubscriptionsStatement) node()     {}
@@ -229,23 +243,32 @@ type ExecutionPrivilege struct {
 // ExecutionPrivileges is a list of privileges required to execute a statement.
 type ExecutionPrivileges []ExecutionPrivilege
 
+func (*AlterDatabaseStatement) stmt()         {}
+func (*AlterFieldStatement) stmt()            {}
+func (*AlterQueryLimitsStatement) stmt()      {}
 func (*AlterRetentionPolicyStatement) stmt()  {}
 func (*CreateContinuousQueryStatement) stmt() {}
 func (*CreateDatabaseStatement) stmt()        {}
 func (*CreateRetentionPolicyStatement) stmt() {}
+func (*CreateRoleStatement) stmt()            {}
 func (*CreateSubscriptionStatement) stmt()    {}
+func (*CreateTokenStatement) stmt()           {}
 func (*CreateUserStatement) stmt()            {}
 func (*DeleteSeriesStatement) stmt()          {}
 func (*DeleteStatement) stmt()                {}
 func (*DropContinuousQueryStatement) stmt()   {}
 func (*DropDatabaseStatement) stmt()          {}
+func (*DropFieldStatement) stmt()             {}
 func (*DropMeasurementStatement) stmt()       {}
 func (*DropRetentionPolicyStatement) stmt()   {}
+func (*DropRoleStatement) stmt()              {}
 func (*DropSeriesStatement) stmt()            {}
 func (*DropSubscriptionStatement) stmt()      {}
+func (*DropTokenStatement) stmt()             {}
 func (*DropUserStatement) stmt()              {}
 func (*GrantStatement) stmt()                 {}
 func (*GrantAdminStatement) stmt()            {}
+func (*GrantRoleStatement) stmt()             {}
 func (*KillQueryStatement) stmt()             {}
 func (*ShowContinuousQueriesStatement) stmt() {}
 func (*ShowGrantsForUserStatement) stmt()     {}
@@ -253,10 +276,14 @@ func (*ShowDatabasesStatement) stmt()         {}
 func (*ShowFieldKeysStatement) stmt()         {}
 func (*ShowMeasurementsStatement) stmt()      {}
 func (*ShowQueriesStatement) stmt()           {}
+func (*ShowQueryLimitsStatement) stmt()       {}
+func (*ShowQuotasStatement) stmt()            {}
 func (*ShowRetentionPoliciesStatement) stmt() {}
+func (*ShowRolesStatement) stmt()             {}
 func (*ShowSeriesStatement) stmt()            {}
 func (*ShowShardGroupsStatement) stmt()       {}
 func (*ShowShardsStatement) stmt()            {}
+func (*ShowTokensStatement) stmt()            {}
 func (*ShowStatsStatement) stmt()             {}
 func (*DropShardStatement) stmt()             {}
 func (*ShowSubscriptionsStatement) stmt()     {}
@@ -266,6 +293,7 @@ func (*ShowTagValuesStatement) stmt()         {}
 func (*ShowUsersStatement) stmt()             {}
 func (*RevokeStatement) stmt()                {}
 func (*RevokeAdminStatement) stmt()           {}
+func (*RevokeRoleStatement) stmt()            {}
 func (*SelectStatement) stmt()                {}
 func (*SetPasswordUserStatement) stmt()       {}
 
@@ -672,8 +700,18 @@ type GrantStatement struct {
 	// Database to grant the privilege to.
 	On string
 
+	// Measurements of the database to grant the privilege to, by name or
+	// regex. The privilege applies to the whole database if nil.
+	Measurement *Measurement
+
+	// Condition restricting the privilege to the series whose tags match.
+	Condition Expr
+
 	// Who to grant the privilege to.
 	User string
+
+	// Role to grant the privilege to, instead of a user.
+	Role string
 }
 
 // String returns a string representation of the grant statement.
@@ -682,12 +720,41 @@ func (s *GrantStatement) String() string {
 	_, _ = buf.WriteString("GRANT ")
 	_, _ = buf.WriteString(s.Privilege.String())
 	_, _ = buf.WriteString(" ON ")
-	_, _ = buf.WriteString(QuoteIdent(s.On))
+	writeGrantTarget(&buf, s.On, s.Measurement, s.Condition)
 	_, _ = buf.WriteString(" TO ")
-	_, _ = buf.WriteString(QuoteIdent(s.User))
+	writeGrantee(&buf, s.User, s.Role)
 	return buf.String()
 }
 
+// writeGrantTarget writes the database, measurement and condition of a grant
+// or revoke statement.
+func writeGrantTarget(buf *bytes.Buffer, database string, m *Measurement, cond Expr) {
+	_, _ = buf.WriteString(QuoteIdent(database))
+	if m != nil {
+		_, _ = buf.WriteString(".")
+		if m.Regex != nil {
+			_, _ = buf.WriteString(m.Regex.String())
+		} else {
+			_, _ = buf.WriteString(QuoteIdent(m.Name))
+		}
+	}
+	if cond != nil {
+		_, _ = buf.WriteString(" WHERE ")
+		_, _ = buf.WriteString(cond.String())
+	}
+}
+
+// writeGrantee writes the user, or the role if set, a privilege is granted to
+// or revoked from.
+func writeGrantee(buf *bytes.Buffer, user, role string) {
+	if role != "" {
+		_, _ = buf.WriteString("ROLE ")
+		_, _ = buf.WriteString(QuoteIdent(role))
+		return
+	}
+	_, _ = buf.WriteString(QuoteIdent(user))
+}
+
 // RequiredPrivileges returns the privilege required to execute a GrantStatement.
 func (s *GrantStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
@@ -712,6 +779,150 @@ func (s *GrantAdminStatement) RequiredPrivileges() (ExecutionPrivileges, error)
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
 }
 
+// CreateRoleStatement represents a command for creating a new role.
+type CreateRoleStatement struct {
+	// Name of the role to be created.
+	Name string
+}
+
+// String returns a string representation of the create role statement.
+func (s *CreateRoleStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("CREATE ROLE ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a CreateRoleStatement.
+func (s *CreateRoleStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// DropRoleStatement represents a command for dropping a role.
+type DropRoleStatement struct {
+	// Name of the role to drop.
+	Name string
+}
+
+// String returns a string representation of the drop role statement.
+func (s *DropRoleStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("DROP ROLE ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a DropRoleStatement.
+func (s *DropRoleStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// CreateTokenStatement represents a command for creating an API token.
+type CreateTokenStatement struct {
+	// Name of the token to be created.
+	Name string
+
+	// User the token authenticates as.
+	User string
+
+	// Privilege and database the token is restricted to.
+	Privilege Privilege
+	Database  string
+
+	// Duration after which the token expires. Zero means it never expires.
+	Expires time.Duration
+}
+
+// String returns a string representation of the create token statement.
+func (s *CreateTokenStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("CREATE TOKEN ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" FOR ")
+	_, _ = buf.WriteString(QuoteIdent(s.User))
+	_, _ = buf.WriteString(" WITH ")
+	_, _ = buf.WriteString(s.Privilege.String())
+	_, _ = buf.WriteString(" ON ")
+	_, _ = buf.WriteString(QuoteIdent(s.Database))
+	if s.Expires > 0 {
+		_, _ = buf.WriteString(" EXPIRES ")
+		_, _ = buf.WriteString(FormatDuration(s.Expires))
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a CreateTokenStatement.
+func (s *CreateTokenStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// DropTokenStatement represents a command for revoking an API token.
+type DropTokenStatement struct {
+	// Name of the token to drop.
+	Name string
+}
+
+// String returns a string representation of the drop token statement.
+func (s *DropTokenStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("DROP TOKEN ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a DropTokenStatement.
+func (s *DropTokenStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// GrantRoleStatement represents a command for making a user a member of a role.
+type GrantRoleStatement struct {
+	// Role to grant.
+	Role string
+
+	// Who to grant the role to.
+	User string
+}
+
+// String returns a string representation of the grant role statement.
+func (s *GrantRoleStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("GRANT ROLE ")
+	_, _ = buf.WriteString(QuoteIdent(s.Role))
+	_, _ = buf.WriteString(" TO ")
+	_, _ = buf.WriteString(QuoteIdent(s.User))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a GrantRoleStatement.
+func (s *GrantRoleStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// RevokeRoleStatement represents a command for removing a user from a role.
+type RevokeRoleStatement struct {
+	// Role to revoke.
+	Role string
+
+	// Who to revoke the role from.
+	User string
+}
+
+// String returns a string representation of the revoke role statement.
+func (s *RevokeRoleStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("REVOKE ROLE ")
+	_, _ = buf.WriteString(QuoteIdent(s.Role))
+	_, _ = buf.WriteString(" FROM ")
+	_, _ = buf.WriteString(QuoteIdent(s.User))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a RevokeRoleStatement.
+func (s *RevokeRoleStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
 // KillQueryStatement represents a command for killing a query.
 type KillQueryStatement struct {
 	// The query to kill.
@@ -770,8 +981,18 @@ type RevokeStatement struct {
 	// Database to revoke the privilege from.
 	On string
 
+	// Measurements the privilege was granted on, if it was not granted on
+	// the whole database.
+	Measurement *Measurement
+
+	// Condition the privilege was granted with.
+	Condition Expr
+
 	// Who to revoke privilege from.
 	User string
+
+	// Role to revoke the privilege from, instead of a user.
+	Role string
 }
 
 // String returns a string representation of the revoke statement.
@@ -780,9 +1001,9 @@ func (s *RevokeStatement) String() string {
 	_, _ = buf.WriteString("REVOKE ")
 	_, _ = buf.WriteString(s.Privilege.String())
 	_, _ = buf.WriteString(" ON ")
-	_, _ = buf.WriteString(QuoteIdent(s.On))
+	writeGrantTarget(&buf, s.On, s.Measurement, s.Condition)
 	_, _ = buf.WriteString(" FROM ")
-	_, _ = buf.WriteString(QuoteIdent(s.User))
+	writeGrantee(&buf, s.User, s.Role)
 	return buf.String()
 }
 
@@ -857,6 +1078,120 @@ func (s *CreateRetentionPolicyStatement) RequiredPrivileges() (ExecutionPrivileg
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
 }
 
+// AlterDatabaseStatement represents a command to alter the quota of an
+// existing database.
+type AlterDatabaseStatement struct {
+	// Name of the database to alter.
+	Name string
+
+	// Limits of the quota to update. Zero removes a limit.
+	MaxSeriesN           *int64
+	MaxDiskBytes         *int64
+	MaxWriteRate         *int64
+	MaxConcurrentQueries *int64
+}
+
+// String returns a string representation of the alter database statement.
+func (s *AlterDatabaseStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("ALTER DATABASE ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" WITH QUOTA")
+
+	if s.MaxSeriesN != nil {
+		_, _ = buf.WriteString(" SERIES ")
+		_, _ = buf.WriteString(strconv.FormatInt(*s.MaxSeriesN, 10))
+	}
+
+	if s.MaxDiskBytes != nil {
+		_, _ = buf.WriteString(" DISK ")
+		_, _ = buf.WriteString(strconv.FormatInt(*s.MaxDiskBytes, 10))
+	}
+
+	if s.MaxWriteRate != nil {
+		_, _ = buf.WriteString(" WRITE RATE ")
+		_, _ = buf.WriteString(strconv.FormatInt(*s.MaxWriteRate, 10))
+	}
+
+	if s.MaxConcurrentQueries != nil {
+		_, _ = buf.WriteString(" QUERIES ")
+		_, _ = buf.WriteString(strconv.FormatInt(*s.MaxConcurrentQueries, 10))
+	}
+
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute an AlterDatabaseStatement.
+func (s *AlterDatabaseStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// AlterQueryLimitsStatement represents a command to alter the query limits
+// of a user or of a database.
+type AlterQueryLimitsStatement struct {
+	// Name of the user or of the database to alter. Only one of them is set.
+	User     string
+	Database string
+
+	// Limits to update. Zero removes a limit.
+	MaxSelectPointN      *int
+	MaxSelectSeriesN     *int
+	MaxSelectBucketsN    *int
+	QueryTimeout         *time.Duration
+	MaxConcurrentQueries *int
+	Priority             *int
+}
+
+// String returns a string representation of the alter query limits statement.
+func (s *AlterQueryLimitsStatement) String() string {
+	var buf bytes.Buffer
+	if s.User != "" {
+		_, _ = buf.WriteString("ALTER USER ")
+		_, _ = buf.WriteString(QuoteIdent(s.User))
+	} else {
+		_, _ = buf.WriteString("ALTER DATABASE ")
+		_, _ = buf.WriteString(QuoteIdent(s.Database))
+	}
+	_, _ = buf.WriteString(" WITH QUERY LIMITS")
+
+	if s.MaxSelectPointN != nil {
+		_, _ = buf.WriteString(" POINTS ")
+		_, _ = buf.WriteString(strconv.Itoa(*s.MaxSelectPointN))
+	}
+
+	if s.MaxSelectSeriesN != nil {
+		_, _ = buf.WriteString(" SERIES ")
+		_, _ = buf.WriteString(strconv.Itoa(*s.MaxSelectSeriesN))
+	}
+
+	if s.MaxSelectBucketsN != nil {
+		_, _ = buf.WriteString(" BUCKETS ")
+		_, _ = buf.WriteString(strconv.Itoa(*s.MaxSelectBucketsN))
+	}
+
+	if s.QueryTimeout != nil {
+		_, _ = buf.WriteString(" TIMEOUT ")
+		_, _ = buf.WriteString(FormatDuration(*s.QueryTimeout))
+	}
+
+	if s.MaxConcurrentQueries != nil {
+		_, _ = buf.WriteString(" QUERIES ")
+		_, _ = buf.WriteString(strconv.Itoa(*s.MaxConcurrentQueries))
+	}
+
+	if s.Priority != nil {
+		_, _ = buf.WriteString(" PRIORITY ")
+		_, _ = buf.WriteString(strconv.Itoa(*s.Priority))
+	}
+
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute an AlterQueryLimitsStatement.
+func (s *AlterQueryLimitsStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
 // AlterRetentionPolicyStatement represents a command to alter an existing retention policy.
 type AlterRetentionPolicyStatement struct {
 	// Name of policy to alter.
@@ -2886,6 +3221,60 @@ func (s *DropMeasurementStatement) RequiredPrivileges() (ExecutionPrivileges, er
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
 }
 
+// DropFieldStatement represents a command to drop a field from a measurement.
+type DropFieldStatement struct {
+	// Name of the field to be dropped.
+	Name string
+
+	// Name of the measurement the field belongs to.
+	Measurement string
+}
+
+// String returns a string representation of the drop field statement.
+func (s *DropFieldStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("DROP FIELD ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" FROM ")
+	_, _ = buf.WriteString(QuoteIdent(s.Measurement))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a DropFieldStatement.
+func (s *DropFieldStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// AlterFieldStatement represents a command to change the type of a field.
+// Values already written with the previous type are removed.
+type AlterFieldStatement struct {
+	// Name of the field to be altered.
+	Name string
+
+	// Name of the measurement the field belongs to.
+	Measurement string
+
+	// The new type of the field.
+	Type DataType
+}
+
+// String returns a string representation of the alter field statement.
+func (s *AlterFieldStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("ALTER FIELD ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" FROM ")
+	_, _ = buf.WriteString(QuoteIdent(s.Measurement))
+	_, _ = buf.WriteString(" TYPE ")
+	_, _ = buf.WriteString(s.Type.String())
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute an AlterFieldStatement.
+func (s *AlterFieldStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
 // ShowQueriesStatement represents a command for listing all running queries.
 type ShowQueriesStatement struct{}
 
@@ -3213,6 +3602,60 @@ func (s *ShowUsersStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
 }
 
+// ShowRolesStatement represents a command for listing roles.
+type ShowRolesStatement struct{}
+
+// String returns a string representation of the ShowRolesStatement.
+func (s *ShowRolesStatement) String() string {
+	return "SHOW ROLES"
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a ShowRolesStatement.
+func (s *ShowRolesStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// ShowTokensStatement represents a command for listing API tokens.
+type ShowTokensStatement struct{}
+
+// String returns a string representation of the ShowTokensStatement.
+func (s *ShowTokensStatement) String() string {
+	return "SHOW TOKENS"
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a ShowTokensStatement.
+func (s *ShowTokensStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// ShowQueryLimitsStatement represents a command for listing the query limits
+// of users and databases.
+type ShowQueryLimitsStatement struct{}
+
+// String returns a string representation of the ShowQueryLimitsStatement.
+func (s *ShowQueryLimitsStatement) String() string {
+	return "SHOW QUERY LIMITS"
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a ShowQueryLimitsStatement.
+func (s *ShowQueryLimitsStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
+// ShowQuotasStatement represents a command for listing the quotas of
+// databases and their usage.
+type ShowQuotasStatement struct{}
+
+// String returns a string representation of the ShowQuotasStatement.
+func (s *ShowQuotasStatement) String() string {
+	return "SHOW QUOTAS"
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a ShowQuotasStatement.
+func (s *ShowQuotasStatement) RequiredPrivileges() (ExecutionPrivileges, error) {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}, nil
+}
+
 // ShowFieldKeysStatement represents a command for listing field keys.
 type ShowFieldKeysStatement struct {
 	// Database to query. If blank, use the default database.
@@ -987,9 +987,63 @@ func TestParseString(t *testing.T) {
 		{
 			stmt: `GRANT ALL PRIVILEGES TO "user with spaces"`,
 		},
+		{
+			stmt: `GRANT READ ON db0."cpu load" WHERE team = 'a' TO bob`,
+		},
+		{
+			stmt: `REVOKE WRITE ON db0./^cpu/ FROM bob`,
+		},
 		{
 			stmt: `SHOW GRANTS FOR "user with spaces"`,
 		},
+		{
+			stmt: `CREATE ROLE "role with spaces"`,
+		},
+		{
+			stmt: `DROP ROLE "role with spaces"`,
+		},
+		{
+			stmt: `GRANT READ ON db0 TO ROLE "role with spaces"`,
+		},
+		{
+			stmt: `REVOKE ALL PRIVILEGES ON db0 FROM ROLE readers`,
+		},
+		{
+			stmt: `GRANT ROLE "role with spaces" TO "user with spaces"`,
+		},
+		{
+			stmt: `REVOKE ROLE readers FROM bob`,
+		},
+		{
+			stmt: `SHOW ROLES`,
+		},
+		{
+			stmt: `CREATE TOKEN "token with spaces" FOR "user with spaces" WITH WRITE ON "db with spaces" EXPIRES 90d`,
+		},
+		{
+			stmt: `CREATE TOKEN t0 FOR bob WITH ALL PRIVILEGES ON db0`,
+		},
+		{
+			stmt: `DROP TOKEN "token with spaces"`,
+		},
+		{
+			stmt: `SHOW TOKENS`,
+		},
+		{
+			stmt: `ALTER DATABASE "db with spaces" WITH QUOTA SERIES 1000 DISK 0 WRITE RATE 100 QUERIES 2`,
+		},
+		{
+			stmt: `SHOW QUOTAS`,
+		},
+		{
+			stmt: `ALTER USER "user with spaces" WITH QUERY LIMITS POINTS 1000 SERIES 10 BUCKETS 0 TIMEOUT 30s QUERIES 2 PRIORITY 5`,
+		},
+		{
+			stmt: `ALTER DATABASE "db with spaces" WITH QUERY LIMITS QUERIES 4`,
+		},
+		{
+			stmt: `SHOW QUERY LIMITS`,
+		},
 		{
 			stmt: `REVOKE ALL PRIVILEGES ON "db with spaces" FROM "user with spaces"`,
 		},
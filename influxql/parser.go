@@ -142,6 +142,12 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		return p.parseShowMeasurementsStatement()
 	case QUERIES:
 		return p.parseShowQueriesStatement()
+	case QUERY:
+		tok, pos, lit := p.scanIgnoreWhitespace()
+		if isIdentKeyword(tok, lit, "LIMITS") {
+			return &ShowQueryLimitsStatement{}, nil
+		}
+		return nil, newParseError(tokstr(tok, lit), []string{"LIMITS"}, pos)
 	case RETENTION:
 		tok, pos, lit := p.scanIgnoreWhitespace()
 		if tok == POLICIES {
@@ -174,6 +180,16 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		return p.parseShowUsersStatement()
 	case SUBSCRIPTIONS:
 		return p.parseShowSubscriptionsStatement()
+	case IDENT:
+		// ROLES, TOKENS and QUOTAS are not keywords so that they can still be used
+		// as identifiers.
+		if isIdentKeyword(tok, lit, "ROLES") {
+			return &ShowRolesStatement{}, nil
+		} else if isIdentKeyword(tok, lit, "TOKENS") {
+			return &ShowTokensStatement{}, nil
+		} else if isIdentKeyword(tok, lit, "QUOTAS") {
+			return &ShowQuotasStatement{}, nil
+		}
 	}
 
 	showQueryKeywords := []string{
@@ -183,7 +199,10 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		"GRANTS",
 		"MEASUREMENTS",
 		"QUERIES",
+		"QUERY",
+		"QUOTAS",
 		"RETENTION",
+		"ROLES",
 		"SERIES",
 		"TAG",
 		"USERS",
@@ -192,6 +211,7 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		"SHARD",
 		"SHARDS",
 		"SUBSCRIPTIONS",
+		"TOKENS",
 	}
 	sort.Strings(showQueryKeywords)
 
@@ -216,9 +236,13 @@ func (p *Parser) parseCreateStatement() (Statement, error) {
 		return p.parseCreateRetentionPolicyStatement()
 	} else if tok == SUBSCRIPTION {
 		return p.parseCreateSubscriptionStatement()
+	} else if isIdentKeyword(tok, lit, "ROLE") {
+		return p.parseCreateRoleStatement()
+	} else if isIdentKeyword(tok, lit, "TOKEN") {
+		return p.parseCreateTokenStatement()
 	}
 
-	return nil, newParseError(tokstr(tok, lit), []string{"CONTINUOUS", "DATABASE", "USER", "RETENTION", "SUBSCRIPTION"}, pos)
+	return nil, newParseError(tokstr(tok, lit), []string{"CONTINUOUS", "DATABASE", "USER", "RETENTION", "ROLE", "SUBSCRIPTION", "TOKEN"}, pos)
 }
 
 // parseDropStatement parses a string and returns a drop statement.
@@ -230,6 +254,8 @@ func (p *Parser) parseDropStatement() (Statement, error) {
 		return p.parseDropContinuousQueryStatement()
 	case DATABASE:
 		return p.parseDropDatabaseStatement()
+	case FIELD:
+		return p.parseDropFieldStatement()
 	case MEASUREMENT:
 		return p.parseDropMeasurementStatement()
 	case RETENTION:
@@ -246,7 +272,12 @@ func (p *Parser) parseDropStatement() (Statement, error) {
 	case USER:
 		return p.parseDropUserStatement()
 	default:
-		return nil, newParseError(tokstr(tok, lit), []string{"CONTINUOUS", "MEASUREMENT", "RETENTION", "SERIES", "SHARD", "SUBSCRIPTION", "USER"}, pos)
+		if isIdentKeyword(tok, lit, "ROLE") {
+			return p.parseDropRoleStatement()
+		} else if isIdentKeyword(tok, lit, "TOKEN") {
+			return p.parseDropTokenStatement()
+		}
+		return nil, newParseError(tokstr(tok, lit), []string{"CONTINUOUS", "FIELD", "MEASUREMENT", "RETENTION", "ROLE", "SERIES", "SHARD", "SUBSCRIPTION", "TOKEN", "USER"}, pos)
 	}
 }
 
@@ -259,9 +290,162 @@ func (p *Parser) parseAlterStatement() (Statement, error) {
 			return nil, newParseError(tokstr(tok, lit), []string{"POLICY"}, pos)
 		}
 		return p.parseAlterRetentionPolicyStatement()
+	} else if tok == FIELD {
+		return p.parseAlterFieldStatement()
+	} else if tok == DATABASE {
+		return p.parseAlterDatabaseStatement()
+	} else if tok == USER {
+		return p.parseAlterUserStatement()
+	}
+
+	return nil, newParseError(tokstr(tok, lit), []string{"DATABASE", "FIELD", "RETENTION", "USER"}, pos)
+}
+
+// parseAlterDatabaseStatement parses a string and returns an alter database
+// or alter query limits statement.
+// This function assumes the ALTER DATABASE tokens have already been consumed.
+func (p *Parser) parseAlterDatabaseStatement() (Statement, error) {
+	// Parse the database name.
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	// Consume the required WITH token followed by QUOTA or QUERY LIMITS.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != WITH {
+		return nil, newParseError(tokstr(tok, lit), []string{"WITH"}, pos)
+	}
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	if tok == QUERY {
+		return p.parseQueryLimits(&AlterQueryLimitsStatement{Database: ident})
+	} else if !isIdentKeyword(tok, lit, "QUOTA") {
+		return nil, newParseError(tokstr(tok, lit), []string{"QUERY", "QUOTA"}, pos)
 	}
+	stmt := &AlterDatabaseStatement{Name: ident}
+
+	// Loop through the limits (SERIES, DISK, WRITE RATE and QUERIES).
+	found := make(map[string]struct{})
+Loop:
+	for {
+		tok, pos, lit := p.scanIgnoreWhitespace()
+
+		var option string
+		var limit **int64
+		switch {
+		case tok == SERIES:
+			option, limit = "SERIES", &stmt.MaxSeriesN
+		case isIdentKeyword(tok, lit, "DISK"):
+			option, limit = "DISK", &stmt.MaxDiskBytes
+		case tok == WRITE:
+			if tok, pos, lit := p.scanIgnoreWhitespace(); !isIdentKeyword(tok, lit, "RATE") {
+				return nil, newParseError(tokstr(tok, lit), []string{"RATE"}, pos)
+			}
+			option, limit = "WRITE RATE", &stmt.MaxWriteRate
+		case tok == QUERIES:
+			option, limit = "QUERIES", &stmt.MaxConcurrentQueries
+		default:
+			if len(found) == 0 {
+				return nil, newParseError(tokstr(tok, lit), []string{"SERIES", "DISK", "WRITE", "QUERIES"}, pos)
+			}
+			p.unscan()
+			break Loop
+		}
+
+		if _, ok := found[option]; ok {
+			return nil, &ParseError{
+				Message: fmt.Sprintf("found duplicate %s option", option),
+				Pos:     pos,
+			}
+		}
+		found[option] = struct{}{}
+
+		n, err := p.parseInt64()
+		if err != nil {
+			return nil, err
+		}
+		*limit = &n
+	}
+
+	return stmt, nil
+}
+
+// parseAlterUserStatement parses a string and returns an alter query limits
+// statement.
+// This function assumes the ALTER USER tokens have already been consumed.
+func (p *Parser) parseAlterUserStatement() (*AlterQueryLimitsStatement, error) {
+	// Parse the user name.
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	// Consume the required WITH QUERY tokens.
+	if err := p.parseTokens([]Token{WITH, QUERY}); err != nil {
+		return nil, err
+	}
+	return p.parseQueryLimits(&AlterQueryLimitsStatement{User: ident})
+}
+
+// parseQueryLimits parses the query limits of an alter query limits
+// statement.
+// This function assumes the QUERY token has already been consumed.
+func (p *Parser) parseQueryLimits(stmt *AlterQueryLimitsStatement) (*AlterQueryLimitsStatement, error) {
+	if tok, pos, lit := p.scanIgnoreWhitespace(); !isIdentKeyword(tok, lit, "LIMITS") {
+		return nil, newParseError(tokstr(tok, lit), []string{"LIMITS"}, pos)
+	}
+
+	// Loop through the limits (POINTS, SERIES, BUCKETS, TIMEOUT, QUERIES and
+	// PRIORITY).
+	found := make(map[string]struct{})
+	for {
+		tok, pos, lit := p.scanIgnoreWhitespace()
+
+		var option string
+		var limit **int
+		switch {
+		case isIdentKeyword(tok, lit, "POINTS"):
+			option, limit = "POINTS", &stmt.MaxSelectPointN
+		case tok == SERIES:
+			option, limit = "SERIES", &stmt.MaxSelectSeriesN
+		case isIdentKeyword(tok, lit, "BUCKETS"):
+			option, limit = "BUCKETS", &stmt.MaxSelectBucketsN
+		case isIdentKeyword(tok, lit, "TIMEOUT"):
+			option = "TIMEOUT"
+		case tok == QUERIES:
+			option, limit = "QUERIES", &stmt.MaxConcurrentQueries
+		case isIdentKeyword(tok, lit, "PRIORITY"):
+			option, limit = "PRIORITY", &stmt.Priority
+		default:
+			if len(found) == 0 {
+				return nil, newParseError(tokstr(tok, lit), []string{"POINTS", "SERIES", "BUCKETS", "TIMEOUT", "QUERIES", "PRIORITY"}, pos)
+			}
+			p.unscan()
+			return stmt, nil
+		}
+
+		if _, ok := found[option]; ok {
+			return nil, &ParseError{
+				Message: fmt.Sprintf("found duplicate %s option", option),
+				Pos:     pos,
+			}
+		}
+		found[option] = struct{}{}
 
-	return nil, newParseError(tokstr(tok, lit), []string{"RETENTION"}, pos)
+		if limit == nil {
+			d, err := p.parseDuration()
+			if err != nil {
+				return nil, err
+			}
+			stmt.QueryTimeout = &d
+			continue
+		}
+
+		n, err := p.parseInt(0, math.MaxInt32)
+		if err != nil {
+			return nil, err
+		}
+		*limit = &n
+	}
 }
 
 // parseSetPasswordUserStatement parses a string and returns a set statement.
@@ -584,6 +768,22 @@ func (p *Parser) parseUInt32() (uint32, error) {
 	return uint32(n), nil
 }
 
+// parseInt64 parses a string and returns a non-negative 64-bit integer literal.
+func (p *Parser) parseInt64() (int64, error) {
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	if tok != INTEGER {
+		return 0, newParseError(tokstr(tok, lit), []string{"integer"}, pos)
+	}
+
+	// Convert string to 64-bit integer
+	n, err := strconv.ParseInt(lit, 10, 64)
+	if err != nil {
+		return 0, &ParseError{Message: err.Error(), Pos: pos}
+	}
+
+	return n, nil
+}
+
 // parseUInt64 parses a string and returns a 64-bit unsigned integer literal.
 func (p *Parser) parseUInt64() (uint64, error) {
 	tok, pos, lit := p.scanIgnoreWhitespace()
@@ -734,6 +934,12 @@ func (p *Parser) parseStringList() ([]string, error) {
 // parseRevokeStatement parses a string and returns a revoke statement.
 // This function assumes the REVOKE token has already been consumed.
 func (p *Parser) parseRevokeStatement() (Statement, error) {
+	// Check for the revocation of a role.
+	if tok, _, lit := p.scanIgnoreWhitespace(); isIdentKeyword(tok, lit, "ROLE") {
+		return p.parseRevokeRoleStatement()
+	}
+	p.unscan()
+
 	// Parse the privilege to be revoked.
 	priv, err := p.parsePrivilege()
 	if err != nil {
@@ -769,12 +975,11 @@ func (p *Parser) parseRevokeStatement() (Statement, error) {
 func (p *Parser) parseRevokeOnStatement() (*RevokeStatement, error) {
 	stmt := &RevokeStatement{}
 
-	// Parse the name of the database.
-	lit, err := p.parseIdent()
-	if err != nil {
+	// Parse the name of the database and the optional measurement and condition.
+	var err error
+	if stmt.On, stmt.Measurement, stmt.Condition, err = p.parseGrantTarget(); err != nil {
 		return nil, err
 	}
-	stmt.On = lit
 
 	// Parse FROM clause.
 	tok, pos, lit := p.scanIgnoreWhitespace()
@@ -784,12 +989,10 @@ func (p *Parser) parseRevokeOnStatement() (*RevokeStatement, error) {
 		return nil, newParseError(tokstr(tok, lit), []string{"FROM"}, pos)
 	}
 
-	// Parse the name of the user.
-	lit, err = p.parseIdent()
-	if err != nil {
+	// Parse the name of the user or role.
+	if stmt.User, stmt.Role, err = p.parseGrantee(); err != nil {
 		return nil, err
 	}
-	stmt.User = lit
 
 	return stmt, nil
 }
@@ -813,6 +1016,12 @@ func (p *Parser) parseRevokeAdminStatement() (*RevokeAdminStatement, error) {
 // parseGrantStatement parses a string and returns a grant statement.
 // This function assumes the GRANT token has already been consumed.
 func (p *Parser) parseGrantStatement() (Statement, error) {
+	// Check for the grant of a role.
+	if tok, _, lit := p.scanIgnoreWhitespace(); isIdentKeyword(tok, lit, "ROLE") {
+		return p.parseGrantRoleStatement()
+	}
+	p.unscan()
+
 	// Parse the privilege to be granted.
 	priv, err := p.parsePrivilege()
 	if err != nil {
@@ -848,12 +1057,11 @@ func (p *Parser) parseGrantStatement() (Statement, error) {
 func (p *Parser) parseGrantOnStatement() (*GrantStatement, error) {
 	stmt := &GrantStatement{}
 
-	// Parse the name of the database.
-	lit, err := p.parseIdent()
-	if err != nil {
+	// Parse the name of the database and the optional measurement and condition.
+	var err error
+	if stmt.On, stmt.Measurement, stmt.Condition, err = p.parseGrantTarget(); err != nil {
 		return nil, err
 	}
-	stmt.On = lit
 
 	// Parse TO clause.
 	tok, pos, lit := p.scanIgnoreWhitespace()
@@ -863,16 +1071,122 @@ func (p *Parser) parseGrantOnStatement() (*GrantStatement, error) {
 		return nil, newParseError(tokstr(tok, lit), []string{"TO"}, pos)
 	}
 
+	// Parse the name of the user or role.
+	if stmt.User, stmt.Role, err = p.parseGrantee(); err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+// parseGrantee parses the user, or the role as in ROLE name, a privilege is
+// granted to or revoked from. A user named role is parsed as a user when no
+// other name follows.
+func (p *Parser) parseGrantee() (user, role string, err error) {
+	lit, err := p.parseIdent()
+	if err != nil {
+		return "", "", err
+	} else if strings.ToUpper(lit) != "ROLE" {
+		return lit, "", nil
+	}
+
+	if tok, _, _ := p.scanIgnoreWhitespace(); tok != IDENT {
+		p.unscan()
+		return lit, "", nil
+	}
+	p.unscan()
+
+	if role, err = p.parseIdent(); err != nil {
+		return "", "", err
+	}
+	return "", role, nil
+}
+
+// parseGrantRoleStatement parses a string and returns a grant role statement.
+// This function assumes the GRANT ROLE tokens have already been consumed.
+func (p *Parser) parseGrantRoleStatement() (*GrantRoleStatement, error) {
+	stmt := &GrantRoleStatement{}
+
+	// Parse the name of the role.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Role = lit
+
+	// Parse TO clause.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != TO {
+		return nil, newParseError(tokstr(tok, lit), []string{"TO"}, pos)
+	}
+
 	// Parse the name of the user.
-	lit, err = p.parseIdent()
+	if stmt.User, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseRevokeRoleStatement parses a string and returns a revoke role statement.
+// This function assumes the REVOKE ROLE tokens have already been consumed.
+func (p *Parser) parseRevokeRoleStatement() (*RevokeRoleStatement, error) {
+	stmt := &RevokeRoleStatement{}
+
+	// Parse the name of the role.
+	lit, err := p.parseIdent()
 	if err != nil {
 		return nil, err
 	}
-	stmt.User = lit
+	stmt.Role = lit
 
+	// Parse FROM clause.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FROM {
+		return nil, newParseError(tokstr(tok, lit), []string{"FROM"}, pos)
+	}
+
+	// Parse the name of the user.
+	if stmt.User, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
 	return stmt, nil
 }
 
+// isIdentKeyword returns true if the token is the identifier keyword, such as
+// ROLE or TOKEN. These are not keywords so that they can still be used as
+// identifiers.
+func isIdentKeyword(tok Token, lit, keyword string) bool {
+	return tok == IDENT && strings.ToUpper(lit) == keyword
+}
+
+// parseGrantTarget parses the target of a grant or revoke statement: a
+// database, optionally followed by a measurement name or regex and a WHERE
+// condition on tags, such as db.cpu WHERE team = 'a'.
+func (p *Parser) parseGrantTarget() (string, *Measurement, Expr, error) {
+	database, err := p.parseIdent()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var m *Measurement
+	if tok, _, _ := p.scan(); tok == DOT {
+		m = &Measurement{}
+		if m.Regex, err = p.parseRegex(); err != nil {
+			return "", nil, nil, err
+		} else if m.Regex == nil {
+			if m.Name, err = p.parseIdent(); err != nil {
+				return "", nil, nil, err
+			}
+		}
+	} else {
+		p.unscan()
+	}
+
+	cond, err := p.parseCondition()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return database, m, cond, nil
+}
+
 // parseGrantAdminStatement parses a string and returns a grant admin statement.
 // This function assumes the ALL [PRVILEGES] TO tokens have already been consumed.
 func (p *Parser) parseGrantAdminStatement() (*GrantAdminStatement, error) {
@@ -1461,6 +1775,75 @@ func (p *Parser) parseDropMeasurementStatement() (*DropMeasurementStatement, err
 	return stmt, nil
 }
 
+// parseDropFieldStatement parses a string and returns a DropFieldStatement.
+// This function assumes the "DROP FIELD" tokens have already been consumed.
+func (p *Parser) parseDropFieldStatement() (*DropFieldStatement, error) {
+	stmt := &DropFieldStatement{}
+
+	// Parse the name of the field to be dropped.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	// Parse the name of the measurement the field belongs to.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FROM {
+		return nil, newParseError(tokstr(tok, lit), []string{"FROM"}, pos)
+	}
+	if stmt.Measurement, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+// parseAlterFieldStatement parses a string and returns an AlterFieldStatement.
+// This function assumes the "ALTER FIELD" tokens have already been consumed.
+func (p *Parser) parseAlterFieldStatement() (*AlterFieldStatement, error) {
+	stmt := &AlterFieldStatement{}
+
+	// Parse the name of the field to be altered.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	// Parse the name of the measurement the field belongs to.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FROM {
+		return nil, newParseError(tokstr(tok, lit), []string{"FROM"}, pos)
+	}
+	if stmt.Measurement, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+
+	// TYPE is not a keyword so that it can still be used as an identifier.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != IDENT || strings.ToUpper(lit) != "TYPE" {
+		return nil, newParseError(tokstr(tok, lit), []string{"TYPE"}, pos)
+	}
+
+	// Parse the new field type.
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	if tok == IDENT {
+		switch strings.ToLower(lit) {
+		case "float":
+			stmt.Type = Float
+		case "integer":
+			stmt.Type = Integer
+		case "string":
+			stmt.Type = String
+		case "boolean":
+			stmt.Type = Boolean
+		}
+	}
+	if stmt.Type == Unknown {
+		return nil, newParseError(tokstr(tok, lit), []string{"float", "integer", "string", "boolean"}, pos)
+	}
+
+	return stmt, nil
+}
+
 // parseDropSeriesStatement parses a string and returns a DropSeriesStatement.
 // This function assumes the "DROP SERIES" tokens have already been consumed.
 func (p *Parser) parseDropSeriesStatement() (*DropSeriesStatement, error) {
@@ -1830,6 +2213,97 @@ func (p *Parser) parseCreateUserStatement() (*CreateUserStatement, error) {
 	return stmt, nil
 }
 
+// parseCreateRoleStatement parses a string and returns a CreateRoleStatement.
+// This function assumes the "CREATE ROLE" tokens have already been consumed.
+func (p *Parser) parseCreateRoleStatement() (*CreateRoleStatement, error) {
+	stmt := &CreateRoleStatement{}
+
+	// Parse name of the role to be created.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	return stmt, nil
+}
+
+// parseDropRoleStatement parses a string and returns a DropRoleStatement.
+// This function assumes the "DROP ROLE" tokens have already been consumed.
+func (p *Parser) parseDropRoleStatement() (*DropRoleStatement, error) {
+	stmt := &DropRoleStatement{}
+
+	// Parse the name of the role to be dropped.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	return stmt, nil
+}
+
+// parseCreateTokenStatement parses a string and returns a CreateTokenStatement.
+// This function assumes the "CREATE TOKEN" tokens have already been consumed.
+func (p *Parser) parseCreateTokenStatement() (*CreateTokenStatement, error) {
+	stmt := &CreateTokenStatement{}
+
+	// Parse name of the token to be created.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	// Parse the user the token authenticates as.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FOR {
+		return nil, newParseError(tokstr(tok, lit), []string{"FOR"}, pos)
+	}
+	if stmt.User, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+
+	// Parse the privilege and database the token is restricted to.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != WITH {
+		return nil, newParseError(tokstr(tok, lit), []string{"WITH"}, pos)
+	}
+	if stmt.Privilege, err = p.parsePrivilege(); err != nil {
+		return nil, err
+	}
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != ON {
+		return nil, newParseError(tokstr(tok, lit), []string{"ON"}, pos)
+	}
+	if stmt.Database, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+
+	// Parse optional EXPIRES clause.
+	if tok, _, lit := p.scanIgnoreWhitespace(); isIdentKeyword(tok, lit, "EXPIRES") {
+		if stmt.Expires, err = p.parseDuration(); err != nil {
+			return nil, err
+		}
+	} else {
+		p.unscan()
+	}
+
+	return stmt, nil
+}
+
+// parseDropTokenStatement parses a string and returns a DropTokenStatement.
+// This function assumes the "DROP TOKEN" tokens have already been consumed.
+func (p *Parser) parseDropTokenStatement() (*DropTokenStatement, error) {
+	stmt := &DropTokenStatement{}
+
+	// Parse the name of the token to be dropped.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	return stmt, nil
+}
+
 // parseDropUserStatement parses a string and returns a DropUserStatement.
 // This function assumes the DROP USER tokens have already been consumed.
 func (p *Parser) parseDropUserStatement() (*DropUserStatement, error) {
@@ -2088,6 +2088,24 @@ func TestParser_ParseStatement(t *testing.T) {
 			stmt: &influxql.DropMeasurementStatement{Name: "cpu"},
 		},
 
+		// DROP FIELD statement
+		{
+			s:    `DROP FIELD value FROM cpu`,
+			stmt: &influxql.DropFieldStatement{Name: "value", Measurement: "cpu"},
+		},
+
+		// ALTER FIELD statement
+		{
+			s:    `ALTER FIELD "value" FROM "cpu" TYPE integer`,
+			stmt: &influxql.AlterFieldStatement{Name: "value", Measurement: "cpu", Type: influxql.Integer},
+		},
+
+		// ALTER FIELD statement with a lowercase type keyword
+		{
+			s:    `alter field value from cpu type Boolean`,
+			stmt: &influxql.AlterFieldStatement{Name: "value", Measurement: "cpu", Type: influxql.Boolean},
+		},
+
 		// DROP RETENTION POLICY
 		{
 			s: `DROP RETENTION POLICY "1h.cpu" ON mydb`,
@@ -2143,6 +2161,33 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		// GRANT READ on a measurement with a condition
+		{
+			s: `GRANT READ ON testdb.cpu WHERE team = 'a' TO jdoe`,
+			stmt: &influxql.GrantStatement{
+				Privilege:   influxql.ReadPrivilege,
+				On:          "testdb",
+				Measurement: &influxql.Measurement{Name: "cpu"},
+				Condition: &influxql.BinaryExpr{
+					Op:  influxql.EQ,
+					LHS: &influxql.VarRef{Val: "team"},
+					RHS: &influxql.StringLiteral{Val: "a"},
+				},
+				User: "jdoe",
+			},
+		},
+
+		// GRANT WRITE on a measurement regex
+		{
+			s: `GRANT WRITE ON "testdb"./^cpu/ TO jdoe`,
+			stmt: &influxql.GrantStatement{
+				Privilege:   influxql.WritePrivilege,
+				On:          "testdb",
+				Measurement: &influxql.Measurement{Regex: &influxql.RegexLiteral{Val: regexp.MustCompile(`^cpu`)}},
+				User:        "jdoe",
+			},
+		},
+
 		// GRANT ALL admin privilege
 		{
 			s: `GRANT ALL TO jdoe`,
@@ -2199,6 +2244,22 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		// REVOKE READ on a measurement with a condition
+		{
+			s: `REVOKE READ ON testdb.cpu WHERE team = 'a' FROM jdoe`,
+			stmt: &influxql.RevokeStatement{
+				Privilege:   influxql.ReadPrivilege,
+				On:          "testdb",
+				Measurement: &influxql.Measurement{Name: "cpu"},
+				Condition: &influxql.BinaryExpr{
+					Op:  influxql.EQ,
+					LHS: &influxql.VarRef{Val: "team"},
+					RHS: &influxql.StringLiteral{Val: "a"},
+				},
+				User: "jdoe",
+			},
+		},
+
 		// REVOKE ALL admin privilege
 		{
 			s: `REVOKE ALL FROM jdoe`,
@@ -2215,6 +2276,174 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		// CREATE ROLE statement
+		{
+			s:    `CREATE ROLE readers`,
+			stmt: &influxql.CreateRoleStatement{Name: "readers"},
+		},
+
+		// DROP ROLE statement
+		{
+			s:    `drop role readers`,
+			stmt: &influxql.DropRoleStatement{Name: "readers"},
+		},
+
+		// SHOW ROLES statement
+		{
+			s:    `SHOW ROLES`,
+			stmt: &influxql.ShowRolesStatement{},
+		},
+
+		// GRANT privilege TO ROLE
+		{
+			s: `GRANT READ ON testdb TO ROLE readers`,
+			stmt: &influxql.GrantStatement{
+				Privilege: influxql.ReadPrivilege,
+				On:        "testdb",
+				Role:      "readers",
+			},
+		},
+
+		// GRANT privilege to a user named role
+		{
+			s: `GRANT WRITE ON testdb TO role`,
+			stmt: &influxql.GrantStatement{
+				Privilege: influxql.WritePrivilege,
+				On:        "testdb",
+				User:      "role",
+			},
+		},
+
+		// REVOKE privilege FROM ROLE
+		{
+			s: `REVOKE ALL PRIVILEGES ON testdb FROM ROLE readers`,
+			stmt: &influxql.RevokeStatement{
+				Privilege: influxql.AllPrivileges,
+				On:        "testdb",
+				Role:      "readers",
+			},
+		},
+
+		// GRANT ROLE
+		{
+			s:    `GRANT ROLE readers TO jdoe`,
+			stmt: &influxql.GrantRoleStatement{Role: "readers", User: "jdoe"},
+		},
+
+		// REVOKE ROLE
+		{
+			s:    `REVOKE ROLE readers FROM jdoe`,
+			stmt: &influxql.RevokeRoleStatement{Role: "readers", User: "jdoe"},
+		},
+
+		// ROLE is still an identifier
+		{
+			s: `SELECT value FROM cpu WHERE role = 'db'`,
+			stmt: &influxql.SelectStatement{
+				IsRawQuery: true,
+				Fields:     []*influxql.Field{{Expr: &influxql.VarRef{Val: "value"}}},
+				Sources:    []influxql.Source{&influxql.Measurement{Name: "cpu"}},
+				Condition: &influxql.BinaryExpr{
+					Op:  influxql.EQ,
+					LHS: &influxql.VarRef{Val: "role"},
+					RHS: &influxql.StringLiteral{Val: "db"},
+				},
+			},
+		},
+
+		// CREATE TOKEN statement
+		{
+			s: `CREATE TOKEN telegraf FOR jdoe WITH WRITE ON testdb EXPIRES 90d`,
+			stmt: &influxql.CreateTokenStatement{
+				Name:      "telegraf",
+				User:      "jdoe",
+				Privilege: influxql.WritePrivilege,
+				Database:  "testdb",
+				Expires:   90 * 24 * time.Hour,
+			},
+		},
+
+		// CREATE TOKEN statement without expiration
+		{
+			s: `create token grafana for jdoe with all on testdb`,
+			stmt: &influxql.CreateTokenStatement{
+				Name:      "grafana",
+				User:      "jdoe",
+				Privilege: influxql.AllPrivileges,
+				Database:  "testdb",
+			},
+		},
+
+		// DROP TOKEN statement
+		{
+			s:    `DROP TOKEN telegraf`,
+			stmt: &influxql.DropTokenStatement{Name: "telegraf"},
+		},
+
+		// SHOW TOKENS statement
+		{
+			s:    `SHOW TOKENS`,
+			stmt: &influxql.ShowTokensStatement{},
+		},
+
+		// SHOW QUOTAS statement
+		{
+			s:    `SHOW QUOTAS`,
+			stmt: &influxql.ShowQuotasStatement{},
+		},
+
+		// ALTER DATABASE statement
+		{
+			s: `ALTER DATABASE db0 WITH QUOTA SERIES 100000 DISK 1073741824 WRITE RATE 5000 QUERIES 4`,
+			stmt: &influxql.AlterDatabaseStatement{
+				Name:                 "db0",
+				MaxSeriesN:           int64Ptr(100000),
+				MaxDiskBytes:         int64Ptr(1073741824),
+				MaxWriteRate:         int64Ptr(5000),
+				MaxConcurrentQueries: int64Ptr(4),
+			},
+		},
+
+		// ALTER DATABASE statement removing a limit
+		{
+			s: `ALTER DATABASE db0 WITH QUOTA queries 2 series 0`,
+			stmt: &influxql.AlterDatabaseStatement{
+				Name:                 "db0",
+				MaxSeriesN:           int64Ptr(0),
+				MaxConcurrentQueries: int64Ptr(2),
+			},
+		},
+
+		// ALTER USER statement setting query limits
+		{
+			s: `ALTER USER bob WITH QUERY LIMITS POINTS 1000000 SERIES 100 BUCKETS 500 TIMEOUT 1m QUERIES 2 PRIORITY 10`,
+			stmt: &influxql.AlterQueryLimitsStatement{
+				User:                 "bob",
+				MaxSelectPointN:      intPtr(1000000),
+				MaxSelectSeriesN:     intPtr(100),
+				MaxSelectBucketsN:    intPtr(500),
+				QueryTimeout:         durationPtr(time.Minute),
+				MaxConcurrentQueries: intPtr(2),
+				Priority:             intPtr(10),
+			},
+		},
+
+		// ALTER DATABASE statement setting query limits
+		{
+			s: `ALTER DATABASE db0 WITH QUERY LIMITS priority 1 timeout 0s`,
+			stmt: &influxql.AlterQueryLimitsStatement{
+				Database:     "db0",
+				QueryTimeout: durationPtr(0),
+				Priority:     intPtr(1),
+			},
+		},
+
+		// SHOW QUERY LIMITS
+		{
+			s:    `SHOW QUERY LIMITS`,
+			stmt: &influxql.ShowQueryLimitsStatement{},
+		},
+
 		// CREATE RETENTION POLICY
 		{
 			s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 2`,
@@ -2555,6 +2784,9 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `DELETE FROM "foo".myseries`, err: `retention policy not supported at line 1, char 1`},
 		{s: `DELETE FROM foo..myseries`, err: `database not supported at line 1, char 1`},
 		{s: `DROP MEASUREMENT`, err: `found EOF, expected identifier at line 1, char 18`},
+		{s: `DROP FIELD`, err: `found EOF, expected identifier at line 1, char 12`},
+		{s: `DROP FIELD value`, err: `found EOF, expected FROM at line 1, char 18`},
+		{s: `DROP FIELD value FROM`, err: `found EOF, expected identifier at line 1, char 23`},
 		{s: `DROP SERIES`, err: `found EOF, expected FROM, WHERE at line 1, char 13`},
 		{s: `DROP SERIES FROM`, err: `found EOF, expected identifier at line 1, char 18`},
 		{s: `DROP SERIES FROM src WHERE`, err: `found EOF, expected identifier, string, number, bool at line 1, char 28`},
@@ -2565,7 +2797,7 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `SHOW RETENTION ON`, err: `found ON, expected POLICIES at line 1, char 16`},
 		{s: `SHOW RETENTION POLICIES ON`, err: `found EOF, expected identifier at line 1, char 28`},
 		{s: `SHOW SHARD`, err: `found EOF, expected GROUPS at line 1, char 12`},
-		{s: `SHOW FOO`, err: `found FOO, expected CONTINUOUS, DATABASES, DIAGNOSTICS, FIELD, GRANTS, MEASUREMENTS, QUERIES, RETENTION, SERIES, SHARD, SHARDS, STATS, SUBSCRIPTIONS, TAG, USERS at line 1, char 6`},
+		{s: `SHOW FOO`, err: `found FOO, expected CONTINUOUS, DATABASES, DIAGNOSTICS, FIELD, GRANTS, MEASUREMENTS, QUERIES, QUERY, QUOTAS, RETENTION, ROLES, SERIES, SHARD, SHARDS, STATS, SUBSCRIPTIONS, TAG, TOKENS, USERS at line 1, char 6`},
 		{s: `SHOW STATS FOR`, err: `found EOF, expected string at line 1, char 16`},
 		{s: `SHOW DIAGNOSTICS FOR`, err: `found EOF, expected string at line 1, char 22`},
 		{s: `SHOW GRANTS`, err: `found EOF, expected FOR at line 1, char 13`},
@@ -2578,8 +2810,16 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `CREATE CONTINUOUS QUERY`, err: `found EOF, expected identifier at line 1, char 25`},
 		{s: `CREATE CONTINUOUS QUERY cq ON db RESAMPLE FOR 5s BEGIN SELECT mean(value) INTO cpu_mean FROM cpu GROUP BY time(10s) END`, err: `FOR duration must be >= GROUP BY time duration: must be a minimum of 10s, got 5s`},
 		{s: `CREATE CONTINUOUS QUERY cq ON db RESAMPLE EVERY 10s FOR 5s BEGIN SELECT mean(value) INTO cpu_mean FROM cpu GROUP BY time(5s) END`, err: `FOR duration must be >= GROUP BY time duration: must be a minimum of 10s, got 5s`},
-		{s: `DROP FOO`, err: `found FOO, expected CONTINUOUS, MEASUREMENT, RETENTION, SERIES, SHARD, SUBSCRIPTION, USER at line 1, char 6`},
-		{s: `CREATE FOO`, err: `found FOO, expected CONTINUOUS, DATABASE, USER, RETENTION, SUBSCRIPTION at line 1, char 8`},
+		{s: `DROP FOO`, err: `found FOO, expected CONTINUOUS, FIELD, MEASUREMENT, RETENTION, ROLE, SERIES, SHARD, SUBSCRIPTION, TOKEN, USER at line 1, char 6`},
+		{s: `CREATE ROLE`, err: `found EOF, expected identifier at line 1, char 13`},
+		{s: `GRANT ROLE readers`, err: `found EOF, expected TO at line 1, char 20`},
+		{s: `REVOKE ROLE readers TO jdoe`, err: `found TO, expected FROM at line 1, char 21`},
+		{s: `CREATE FOO`, err: `found FOO, expected CONTINUOUS, DATABASE, USER, RETENTION, ROLE, SUBSCRIPTION, TOKEN at line 1, char 8`},
+		{s: `CREATE TOKEN t0`, err: `found EOF, expected FOR at line 1, char 17`},
+		{s: `CREATE TOKEN t0 FOR jdoe`, err: `found EOF, expected WITH at line 1, char 26`},
+		{s: `CREATE TOKEN t0 FOR jdoe WITH WRITE testdb`, err: `found testdb, expected ON at line 1, char 37`},
+		{s: `CREATE TOKEN t0 FOR jdoe WITH WRITE ON testdb EXPIRES`, err: `found EOF, expected duration at line 1, char 55`},
+		{s: `DROP TOKEN`, err: `found EOF, expected identifier at line 1, char 12`},
 		{s: `CREATE DATABASE`, err: `found EOF, expected identifier at line 1, char 17`},
 		{s: `CREATE DATABASE "testdb" WITH`, err: `found EOF, expected DURATION, NAME, REPLICATION, SHARD at line 1, char 31`},
 		{s: `CREATE DATABASE "testdb" WITH DURATION`, err: `found EOF, expected duration at line 1, char 40`},
@@ -2618,6 +2858,8 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `GRANT READ ON TO`, err: `found TO, expected identifier at line 1, char 15`},
 		{s: `GRANT READ ON testdb`, err: `found EOF, expected TO at line 1, char 22`},
 		{s: `GRANT READ ON testdb TO`, err: `found EOF, expected identifier at line 1, char 25`},
+		{s: `GRANT READ ON testdb. TO jdoe`, err: `found TO, expected identifier at line 1, char 23`},
+		{s: `GRANT READ ON testdb.cpu WHERE TO jdoe`, err: `found TO, expected identifier, string, number, bool at line 1, char 32`},
 		{s: `GRANT READ TO`, err: `found TO, expected ON at line 1, char 12`},
 		{s: `GRANT WRITE`, err: `found EOF, expected ON at line 1, char 13`},
 		{s: `GRANT WRITE FROM`, err: `found FROM, expected ON at line 1, char 13`},
@@ -2690,7 +2932,26 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 0`, err: `invalid value 0: must be 1 <= n <= 2147483647 at line 1, char 67`},
 		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION bad`, err: `found bad, expected integer at line 1, char 67`},
 		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 2 SHARD DURATION INF`, err: `invalid duration INF for shard duration at line 1, char 84`},
-		{s: `ALTER`, err: `found EOF, expected RETENTION at line 1, char 7`},
+		{s: `ALTER`, err: `found EOF, expected DATABASE, FIELD, RETENTION, USER at line 1, char 7`},
+		{s: `ALTER DATABASE`, err: `found EOF, expected identifier at line 1, char 16`},
+		{s: `ALTER DATABASE db0`, err: `found EOF, expected WITH at line 1, char 20`},
+		{s: `ALTER DATABASE db0 WITH`, err: `found EOF, expected QUERY, QUOTA at line 1, char 25`},
+		{s: `ALTER DATABASE db0 WITH QUOTA`, err: `found EOF, expected SERIES, DISK, WRITE, QUERIES at line 1, char 31`},
+		{s: `ALTER DATABASE db0 WITH QUOTA SERIES`, err: `found EOF, expected integer at line 1, char 38`},
+		{s: `ALTER DATABASE db0 WITH QUOTA WRITE 10`, err: `found 10, expected RATE at line 1, char 37`},
+		{s: `ALTER DATABASE db0 WITH QUOTA SERIES 10 SERIES 20`, err: `found duplicate SERIES option at line 1, char 41`},
+		{s: `ALTER DATABASE db0 WITH QUERY`, err: `found EOF, expected LIMITS at line 1, char 31`},
+		{s: `ALTER DATABASE db0 WITH QUERY LIMITS`, err: `found EOF, expected POINTS, SERIES, BUCKETS, TIMEOUT, QUERIES, PRIORITY at line 1, char 38`},
+		{s: `ALTER USER`, err: `found EOF, expected identifier at line 1, char 12`},
+		{s: `ALTER USER bob WITH`, err: `found EOF, expected QUERY at line 1, char 21`},
+		{s: `ALTER USER bob WITH QUERY LIMITS TIMEOUT 10`, err: `found 10, expected duration at line 1, char 42`},
+		{s: `ALTER USER bob WITH QUERY LIMITS PRIORITY -1`, err: `invalid value -1: must be 0 <= n <= 2147483647 at line 1, char 43`},
+		{s: `ALTER USER bob WITH QUERY LIMITS QUERIES 1 QUERIES 2`, err: `found duplicate QUERIES option at line 1, char 44`},
+		{s: `ALTER FIELD`, err: `found EOF, expected identifier at line 1, char 13`},
+		{s: `ALTER FIELD value`, err: `found EOF, expected FROM at line 1, char 19`},
+		{s: `ALTER FIELD value FROM cpu`, err: `found EOF, expected TYPE at line 1, char 28`},
+		{s: `ALTER FIELD value FROM cpu TYPE`, err: `found EOF, expected float, integer, string, boolean at line 1, char 33`},
+		{s: `ALTER FIELD value FROM cpu TYPE time`, err: `found time, expected float, integer, string, boolean at line 1, char 33`},
 		{s: `ALTER RETENTION`, err: `found EOF, expected POLICY at line 1, char 17`},
 		{s: `ALTER RETENTION POLICY`, err: `found EOF, expected identifier at line 1, char 24`},
 		{s: `ALTER RETENTION POLICY policy1`, err: `found EOF, expected ON at line 1, char 32`}, {s: `ALTER RETENTION POLICY policy1 ON`, err: `found EOF, expected identifier at line 1, char 35`},
@@ -3223,6 +3484,21 @@ func newAlterRetentionPolicyStatement(name string, DB string, d, sd time.Duratio
 	return stmt
 }
 
+// int64Ptr returns a pointer to n.
+func int64Ptr(n int64) *int64 {
+	return &n
+}
+
+// intPtr returns a pointer to n.
+func intPtr(n int) *int {
+	return &n
+}
+
+// durationPtr returns a pointer to d.
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
 // mustMarshalJSON encodes a value to JSON.
 func mustMarshalJSON(v interface{}) []byte {
 	b, err := json.MarshalIndent(v, "", "  ")
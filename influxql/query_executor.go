@@ -36,10 +36,13 @@ var (
 
 // Statistics for the QueryExecutor
 const (
-	statQueriesActive          = "queriesActive"   // Number of queries currently being executed
-	statQueriesExecuted        = "queriesExecuted" // Number of queries that have been executed (started).
-	statQueriesFinished        = "queriesFinished" // Number of queries that have finished.
-	statQueryExecutionDuration = "queryDurationNs" // Total (wall) time spent executing queries
+	statQueriesActive          = "queriesActive"     // Number of queries currently being executed
+	statQueriesExecuted        = "queriesExecuted"   // Number of queries that have been executed (started).
+	statQueriesFinished        = "queriesFinished"   // Number of queries that have finished.
+	statQueryExecutionDuration = "queryDurationNs"   // Total (wall) time spent executing queries
+	statQueriesQueued          = "queriesQueued"     // Number of queries waiting for the concurrency limits
+	statResultCacheHits        = "resultCacheHits"   // Number of statements reusing cached results
+	statResultCacheMisses      = "resultCacheMisses" // Number of cacheable statements without cached results
 )
 
 // ErrDatabaseNotFound returns a database not found error for the given database name.
@@ -78,6 +81,28 @@ type ExecutionOptions struct {
 
 	// AbortCh is a channel that signals when results are no longer desired by the caller.
 	AbortCh <-chan struct{}
+
+	// Authorizer restricts the series the query may read. All series may be
+	// read if it is nil.
+	Authorizer ReadAuthorizer
+
+	// The user running the query and the address of their client, recorded
+	// in the audit log.
+	User       string
+	RemoteAddr string
+}
+
+// ReadAuthorizer restricts the series of a database a query may read.
+type ReadAuthorizer interface {
+	// MeasurementCondition returns the condition on tags the series of a
+	// measurement must meet to be read, or nil if all of them may be read.
+	// It returns false if none of them may be read.
+	MeasurementCondition(database, measurement string) (Expr, bool)
+
+	// DatabaseCondition returns the condition the series of a database must
+	// meet to be read, referring to measurement names as _name, or nil if
+	// all of them may be read. It returns false if none of them may be read.
+	DatabaseCondition(database string) (Expr, bool)
 }
 
 // ExecutionContext contains state that the query is currently executing with.
@@ -100,6 +125,10 @@ type ExecutionContext struct {
 	// A channel that is closed when the query is interrupted.
 	InterruptCh <-chan struct{}
 
+	// The limits of the query overriding the defaults of the statement
+	// executor, if set.
+	Limits QueryLimits
+
 	// Options used to start this query.
 	ExecutionOptions
 }
@@ -150,6 +179,9 @@ type QueryExecutor struct {
 	// Used for tracking running queries.
 	TaskManager *TaskManager
 
+	// Caches the results of SELECT statements grouping by time, if set.
+	ResultCache *ResultCache
+
 	// Logger to use for all logging.
 	// Defaults to discarding all log output.
 	Logger zap.Logger
@@ -177,15 +209,22 @@ type QueryStatistics struct {
 
 // Statistics returns statistics for periodic monitoring.
 func (e *QueryExecutor) Statistics(tags map[string]string) []models.Statistic {
+	values := map[string]interface{}{
+		statQueriesActive:          atomic.LoadInt64(&e.stats.ActiveQueries),
+		statQueriesExecuted:        atomic.LoadInt64(&e.stats.ExecutedQueries),
+		statQueriesFinished:        atomic.LoadInt64(&e.stats.FinishedQueries),
+		statQueryExecutionDuration: atomic.LoadInt64(&e.stats.QueryExecutionDuration),
+		statQueriesQueued:          int64(e.TaskManager.queuedN()),
+	}
+	if e.ResultCache != nil {
+		values[statResultCacheHits] = atomic.LoadInt64(&e.ResultCache.stats.Hits)
+		values[statResultCacheMisses] = atomic.LoadInt64(&e.ResultCache.stats.Misses)
+	}
+
 	return []models.Statistic{{
-		Name: "queryExecutor",
-		Tags: tags,
-		Values: map[string]interface{}{
-			statQueriesActive:          atomic.LoadInt64(&e.stats.ActiveQueries),
-			statQueriesExecuted:        atomic.LoadInt64(&e.stats.ExecutedQueries),
-			statQueriesFinished:        atomic.LoadInt64(&e.stats.FinishedQueries),
-			statQueryExecutionDuration: atomic.LoadInt64(&e.stats.QueryExecutionDuration),
-		},
+		Name:   "queryExecutor",
+		Tags:   tags,
+		Values: values,
 	}}
 }
 
@@ -220,7 +259,7 @@ func (e *QueryExecutor) executeQuery(query *Query, opt ExecutionOptions, closing
 		atomic.AddInt64(&e.stats.QueryExecutionDuration, time.Since(start).Nanoseconds())
 	}(time.Now())
 
-	qid, task, err := e.TaskManager.AttachQuery(query, opt.Database, closing)
+	qid, task, err := e.TaskManager.AttachQuery(query, opt, closing)
 	if err != nil {
 		select {
 		case results <- &Result{Err: err}:
@@ -237,6 +276,7 @@ func (e *QueryExecutor) executeQuery(query *Query, opt ExecutionOptions, closing
 		Results:          results,
 		Log:              e.Logger,
 		InterruptCh:      task.closing,
+		Limits:           task.limits,
 		ExecutionOptions: opt,
 	}
 
@@ -308,8 +348,19 @@ LOOP:
 			e.Logger.Info(stmt.String())
 		}
 
-		// Send any other statements to the underlying statement executor.
-		err = e.StatementExecutor.ExecuteStatement(stmt, ctx)
+		// Send any other statements to the underlying statement executor,
+		// through the result cache if possible.
+		if s, ok := stmt.(*SelectStatement); ok && e.ResultCache != nil && e.ResultCache.cacheable(s) {
+			err = e.ResultCache.execute(e.StatementExecutor, s, ctx)
+		} else {
+			err = e.StatementExecutor.ExecuteStatement(stmt, ctx)
+		}
+
+		// Drop the cached results when data or privileges may have changed.
+		if e.ResultCache != nil && modifiesResults(stmt) {
+			e.ResultCache.Purge()
+		}
+
 		if err == ErrQueryInterrupted {
 			// Query was interrupted so retrieve the real interrupt error from
 			// the query task if there is one.
@@ -357,6 +408,37 @@ LOOP:
 	}
 }
 
+// modifiesResults returns true if stmt may change the results of SELECT
+// statements other than by writing points, by changing data, the schema or
+// the series users may read.
+func modifiesResults(stmt Statement) bool {
+	switch stmt.(type) {
+	case *AlterDatabaseStatement,
+		*AlterFieldStatement,
+		*AlterRetentionPolicyStatement,
+		*CreateDatabaseStatement,
+		*DeleteSeriesStatement,
+		*DeleteStatement,
+		*DropDatabaseStatement,
+		*DropFieldStatement,
+		*DropMeasurementStatement,
+		*DropRetentionPolicyStatement,
+		*DropRoleStatement,
+		*DropSeriesStatement,
+		*DropShardStatement,
+		*DropUserStatement,
+		*GrantAdminStatement,
+		*GrantRoleStatement,
+		*GrantStatement,
+		*RevokeAdminStatement,
+		*RevokeRoleStatement,
+		*RevokeStatement:
+		return true
+	default:
+		return false
+	}
+}
+
 func (e *QueryExecutor) recover(query *Query, results chan *Result) {
 	if err := recover(); err != nil {
 		e.Logger.Error(fmt.Sprintf("%s [panic:%s] %s", query.String(), err, debug.Stack()))
@@ -377,6 +459,8 @@ type QueryMonitorFunc func(<-chan struct{}) error
 type QueryTask struct {
 	query     string
 	database  string
+	user      string
+	limits    QueryLimits
 	startTime time.Time
 	closing   chan struct{}
 	monitorCh chan error
@@ -237,6 +237,112 @@ func TestQueryExecutor_Limit_ConcurrentQueries(t *testing.T) {
 	}
 }
 
+// Ensure queries beyond the concurrency limit wait in a queue by priority.
+func TestQueryExecutor_Limit_Queue(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan string)
+	release := make(chan struct{})
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+			started <- ctx.User
+			<-release
+			return nil
+		},
+	}
+	e.TaskManager.MaxConcurrentQueries = 1
+	e.TaskManager.MaxQueuedQueries = 2
+	e.TaskManager.Limiter = &QueryLimiter{
+		Users: map[string]influxql.QueryLimits{"high": {Priority: 10}},
+	}
+	defer e.Close()
+
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{User: "first"}, nil))
+	if user := <-started; user != "first" {
+		t.Fatalf("unexpected query started: %s", user)
+	}
+
+	// Queue a query with a low and one with a high priority.
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{User: "low"}, nil))
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{User: "high"}, nil))
+	waitQueued(t, e, 2)
+
+	// Queries are refused once the queue is full.
+	result := <-e.ExecuteQuery(q, influxql.ExecutionOptions{User: "other"}, nil)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "max-concurrent-queries") {
+		t.Errorf("unexpected error: %s", result.Err)
+	}
+
+	// The queued queries run by priority as the running ones finish.
+	for _, exp := range []string{"high", "low"} {
+		release <- struct{}{}
+		if user := <-started; user != exp {
+			t.Fatalf("unexpected query started: exp=%s got=%s", exp, user)
+		}
+	}
+	release <- struct{}{}
+}
+
+// Ensure the stricter of the limits of users and databases override the
+// defaults of the query engine.
+func TestQueryExecutor_Limit_UserAndDatabase(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan influxql.QueryLimits)
+	release := make(chan struct{})
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+			started <- ctx.Limits
+			<-release
+			return nil
+		},
+	}
+	e.TaskManager.MaxQueuedQueries = 1
+	e.TaskManager.Limiter = &QueryLimiter{
+		Users: map[string]influxql.QueryLimits{
+			"bob": {MaxSelectPointN: 10, MaxSelectSeriesN: 50, MaxConcurrentQueries: 1},
+		},
+		Databases: map[string]influxql.QueryLimits{
+			"db0": {MaxSelectPointN: 100, MaxSelectSeriesN: 5, Priority: 1},
+		},
+	}
+	defer e.Close()
+
+	// The stricter of the limits of the user and of the database apply.
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{User: "bob", Database: "db0"}, nil))
+	if limits, exp := <-started, (influxql.QueryLimits{MaxSelectPointN: 10, MaxSelectSeriesN: 5, MaxConcurrentQueries: 1, Priority: 1}); limits != exp {
+		t.Fatalf("unexpected limits: exp=%+v got=%+v", exp, limits)
+	}
+
+	// Another query of the user waits until it's interrupted.
+	interrupt := make(chan struct{})
+	results := e.ExecuteQuery(q, influxql.ExecutionOptions{User: "bob", Database: "db0"}, interrupt)
+	waitQueued(t, e, 1)
+	close(interrupt)
+	if result := <-results; result.Err != influxql.ErrQueryInterrupted {
+		t.Errorf("unexpected error: %s", result.Err)
+	}
+	waitQueued(t, e, 0)
+
+	// The queries of other users still run.
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{User: "alice", Database: "db0"}, nil))
+	if limits, exp := <-started, (influxql.QueryLimits{MaxSelectPointN: 100, MaxSelectSeriesN: 5, Priority: 1}); limits != exp {
+		t.Fatalf("unexpected limits: exp=%+v got=%+v", exp, limits)
+	}
+	release <- struct{}{}
+	release <- struct{}{}
+}
+
 func TestQueryExecutor_Close(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
@@ -360,6 +466,88 @@ func TestQueryExecutor_InvalidSource(t *testing.T) {
 	}
 }
 
+// QueryLimiter returns the query limits of users and databases from maps.
+// Ensure queries beyond the quota of their database wait in the queue, and
+// are refused with the quota's error once it's full.
+func TestQueryExecutor_Limit_Quota(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan string)
+	release := make(chan struct{})
+
+	errQuota := errors.New("quota exceeded")
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+			started <- ctx.Database
+			<-release
+			return nil
+		},
+	}
+	e.TaskManager.MaxQueuedQueries = 1
+	e.TaskManager.QueryQuota = func(database string, n int) error {
+		if database == "db0" && n >= 1 {
+			return errQuota
+		}
+		return nil
+	}
+	defer e.Close()
+
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{Database: "db0"}, nil))
+	<-started
+	if err := e.TaskManager.CheckQuery(influxql.ExecutionOptions{Database: "db0"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// A second query waits in the queue, and a third one is refused.
+	go discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{Database: "db0"}, nil))
+	waitQueued(t, e, 1)
+	if err := e.TaskManager.CheckQuery(influxql.ExecutionOptions{Database: "db0"}); err != errQuota {
+		t.Fatalf("unexpected error: %v", err)
+	} else if result := <-e.ExecuteQuery(q, influxql.ExecutionOptions{Database: "db0"}, nil); result.Err != errQuota {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+
+	// The queries against other databases aren't refused.
+	if err := e.TaskManager.CheckQuery(influxql.ExecutionOptions{Database: "db1"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The queued query runs once the first one finishes.
+	release <- struct{}{}
+	if database := <-started; database != "db0" {
+		t.Fatalf("unexpected query started: %s", database)
+	}
+	release <- struct{}{}
+}
+
+type QueryLimiter struct {
+	Users     map[string]influxql.QueryLimits
+	Databases map[string]influxql.QueryLimits
+}
+
+func (l *QueryLimiter) UserQueryLimits(name string) influxql.QueryLimits {
+	return l.Users[name]
+}
+
+func (l *QueryLimiter) DatabaseQueryLimits(name string) influxql.QueryLimits {
+	return l.Databases[name]
+}
+
+// waitQueued waits for n queries to be queued by the query executor.
+func waitQueued(t *testing.T, e *influxql.QueryExecutor, n int64) {
+	for i := 0; i < 100; i++ {
+		if e.Statistics(nil)[0].Values["queriesQueued"] == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d queued queries", n)
+}
+
 func discardOutput(results <-chan *influxql.Result) {
 	for range results {
 		// Read all results and discard.
@@ -0,0 +1,76 @@
+package influxql
+
+import "time"
+
+// QueryLimits represents the limits of the queries of a user or against a
+// database, overriding the defaults of the query engine. Zero limits are
+// unset.
+type QueryLimits struct {
+	// Maximum number of points, series and GROUP BY time() buckets a SELECT
+	// statement can process.
+	MaxSelectPointN   int
+	MaxSelectSeriesN  int
+	MaxSelectBucketsN int
+
+	// Query execution timeout.
+	QueryTimeout time.Duration
+
+	// Maximum number of queries of the user or against the database running
+	// at the same time.
+	MaxConcurrentQueries int
+
+	// Priority of the queries waiting for the concurrency limits, higher
+	// priorities running first.
+	Priority int
+}
+
+// merge returns the stricter of the limits and other. A limit unset on one
+// side is taken from the other. The lower priority is the stricter one.
+func (l QueryLimits) merge(other QueryLimits) QueryLimits {
+	l.MaxSelectPointN = minLimit(l.MaxSelectPointN, other.MaxSelectPointN)
+	l.MaxSelectSeriesN = minLimit(l.MaxSelectSeriesN, other.MaxSelectSeriesN)
+	l.MaxSelectBucketsN = minLimit(l.MaxSelectBucketsN, other.MaxSelectBucketsN)
+	if l.QueryTimeout == 0 || (other.QueryTimeout != 0 && other.QueryTimeout < l.QueryTimeout) {
+		l.QueryTimeout = other.QueryTimeout
+	}
+	l.MaxConcurrentQueries = minLimit(l.MaxConcurrentQueries, other.MaxConcurrentQueries)
+	l.Priority = minLimit(l.Priority, other.Priority)
+	return l
+}
+
+// minLimit returns the lower of two limits, ignoring an unset (zero) one.
+func minLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// QueryLimiter returns the query limits of users and databases.
+type QueryLimiter interface {
+	UserQueryLimits(name string) QueryLimits
+	DatabaseQueryLimits(name string) QueryLimits
+}
+
+// queuedQuery represents a query waiting for the concurrency limits to allow
+// it to run.
+type queuedQuery struct {
+	query     *Query
+	user      string
+	database  string
+	interrupt <-chan struct{}
+
+	// Limits of the query, the stricter of those of the user and of the
+	// database.
+	limits QueryLimits
+
+	// Concurrency limits of the user and of the database.
+	maxUserQueries     int
+	maxDatabaseQueries int
+
+	// Closed once the query is attached or refused.
+	ready chan struct{}
+	qid   uint64
+	task  *QueryTask
+	err   error
+}
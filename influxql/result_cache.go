@@ -0,0 +1,659 @@
+package influxql
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+)
+
+// DefaultResultCacheHorizon is the default write-safe horizon of the result
+// cache.
+const DefaultResultCacheHorizon = 5 * time.Minute
+
+// DefaultResultCacheMaxMemorySize is the default maximum size of the results
+// held by the result cache.
+const DefaultResultCacheMaxMemorySize = 64 * 1024 * 1024 // 64MB
+
+// ResultCache caches the results of SELECT statements grouping by time, so
+// that statements executed repeatedly, such as those of dashboards, only
+// compute their newest buckets.
+//
+// Only the buckets ending before the write-safe horizon are cached, since
+// points are rarely written that far in the past. Writing such points drops
+// the cached results of their measurements, as does deleting or moving the
+// shards of their time range.
+//
+// The buckets are cached as computed with fill(none) and fill(null) is
+// applied to the merged result, so that series are filled the same way
+// whether their buckets come from the cache or not.
+type ResultCache struct {
+	// Maximum number of cached statements.
+	MaxEntries int
+
+	// Maximum estimated size in bytes of the cached results. Zero makes the
+	// size unlimited.
+	MaxMemorySize uint64
+
+	// Buckets ending more than Horizon before now are cached.
+	Horizon time.Duration
+
+	// Returns the current time. Defaults to time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*resultCacheEntry
+	size    uint64
+	clock   uint64
+
+	// Incremented when the results of a measurement, or of a database if
+	// keyed by its name followed by a null byte, are invalidated, so that a
+	// statement executed concurrently doesn't cache the results changed.
+	// The generation is incremented when all results are.
+	epochs     map[string]uint64
+	generation uint64
+
+	stats *ResultCacheStatistics
+}
+
+// ResultCacheStatistics keeps statistics related to the ResultCache.
+type ResultCacheStatistics struct {
+	Hits   int64
+	Misses int64
+}
+
+// resultCacheEntry holds the cached buckets of a statement.
+type resultCacheEntry struct {
+	// Databases and names of the measurements of the statement, separated
+	// by a null byte.
+	measurements map[string]struct{}
+
+	// Bounds of the cached buckets, as [start, end).
+	start, end int64
+
+	// Rows of the cached buckets, one per series, in emission order.
+	rows []*models.Row
+
+	// Estimated size of the rows in bytes.
+	size uint64
+
+	// Value of the cache clock when the entry was last used.
+	used uint64
+}
+
+// NewResultCache returns a new ResultCache holding up to maxEntries
+// statements.
+func NewResultCache(maxEntries int, horizon time.Duration) *ResultCache {
+	return &ResultCache{
+		MaxEntries:    maxEntries,
+		MaxMemorySize: DefaultResultCacheMaxMemorySize,
+		Horizon:       horizon,
+		Now:           time.Now,
+		entries:       make(map[string]*resultCacheEntry),
+		epochs:        make(map[string]uint64),
+		stats:         &ResultCacheStatistics{},
+	}
+}
+
+// Len returns the number of cached statements.
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Size returns the estimated size in bytes of the cached results.
+func (c *ResultCache) Size() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Purge drops all cached results.
+func (c *ResultCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]*resultCacheEntry)
+	c.size = 0
+}
+
+// InvalidatePoints drops the cached results changed by points written to a
+// database.
+func (c *ResultCache) InvalidatePoints(database string, points []models.Point) {
+	horizon := c.Now().Add(-c.Horizon).UnixNano()
+
+	var late []models.Point
+	for _, p := range points {
+		if p.Time().UnixNano() < horizon {
+			late = append(late, p)
+		}
+	}
+	if len(late) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range late {
+		c.epochs[database+"\x00"+p.Name()]++
+	}
+	for key, entry := range c.entries {
+		for _, p := range late {
+			if _, ok := entry.measurements[database+"\x00"+p.Name()]; !ok {
+				continue
+			} else if t := p.Time().UnixNano(); t >= entry.start && t < entry.end {
+				c.remove(key)
+				break
+			}
+		}
+	}
+}
+
+// InvalidateRange drops the cached results of a database within a time
+// range, such as that of a shard group deleted or moved to the cold tier.
+func (c *ResultCache) InvalidateRange(database string, start, end time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epochs[database+"\x00"]++
+	for key, entry := range c.entries {
+		if entry.start >= end.UnixNano() || entry.end <= start.UnixNano() {
+			continue
+		}
+		for m := range entry.measurements {
+			if strings.HasPrefix(m, database+"\x00") {
+				c.remove(key)
+				break
+			}
+		}
+	}
+}
+
+// remove drops a cached entry. The cache must be locked.
+func (c *ResultCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= entry.size
+		delete(c.entries, key)
+	}
+}
+
+// epoch returns the sum of the epochs of the measurements and databases of a
+// statement, which changes whenever one of them is invalidated. The cache
+// must be locked.
+func (c *ResultCache) epoch(measurements map[string]struct{}) uint64 {
+	epoch := c.generation
+	for m := range measurements {
+		epoch += c.epochs[m]
+		epoch += c.epochs[m[:strings.IndexByte(m, 0)+1]]
+	}
+	return epoch
+}
+
+// cacheableFunctions are the functions computing each bucket from its own
+// points only. Those requiring ordered input, such as median(), are left out
+// since their series aren't always emitted in order.
+var cacheableFunctions = map[string]struct{}{
+	"count":  {},
+	"first":  {},
+	"last":   {},
+	"max":    {},
+	"mean":   {},
+	"min":    {},
+	"mode":   {},
+	"spread": {},
+	"stddev": {},
+	"sum":    {},
+}
+
+// cacheable returns true if the results of stmt can be cached.
+func (c *ResultCache) cacheable(stmt *SelectStatement) bool {
+	if stmt.Target != nil || stmt.IsRawQuery || !stmt.TimeAscending() {
+		return false
+	} else if stmt.Limit != 0 || stmt.Offset != 0 || stmt.SLimit != 0 || stmt.SOffset != 0 {
+		return false
+	} else if stmt.Fill != NullFill && stmt.Fill != NoFill {
+		return false
+	}
+
+	if interval, err := stmt.GroupByInterval(); err != nil || interval <= 0 {
+		return false
+	} else if _, err := stmt.GroupByOffset(); err != nil {
+		return false
+	}
+
+	// The series of several measurements aren't always emitted in order.
+	if len(stmt.Sources) != 1 {
+		return false
+	} else if m, ok := stmt.Sources[0].(*Measurement); !ok || m.Regex != nil || m.Name == "" {
+		return false
+	}
+
+	for _, f := range stmt.Fields {
+		call, ok := f.Expr.(*Call)
+		if !ok {
+			return false
+		} else if _, ok := cacheableFunctions[call.Name]; !ok {
+			return false
+		}
+
+		cacheable := true
+		WalkFunc(call, func(n Node) {
+			switch n.(type) {
+			case *Wildcard, *RegexLiteral:
+				cacheable = false
+			}
+		})
+		if !cacheable {
+			return false
+		}
+	}
+	return true
+}
+
+// execute executes a cacheable statement, computing only the buckets missing
+// from the cache.
+func (c *ResultCache) execute(e StatementExecutor, stmt *SelectStatement, ctx ExecutionContext) error {
+	now := c.Now().UTC()
+
+	// Determine the time range the same way the statement executor does.
+	tmin, tmax, err := TimeRange(stmt.Reduce(&NowValuer{Now: now}).Condition)
+	if err != nil || tmin.IsZero() {
+		return e.ExecuteStatement(stmt, ctx)
+	} else if tmax.IsZero() {
+		tmax = now
+	}
+	start, end := tmin.UnixNano(), tmax.UnixNano()+1
+
+	interval, _ := stmt.GroupByInterval()
+	offset, _ := stmt.GroupByOffset()
+	opt := IteratorOptions{
+		StartTime: start,
+		EndTime:   end - 1,
+		Interval:  Interval{Duration: interval, Offset: offset},
+		Ascending: true,
+	}
+
+	// Only the whole buckets ending before the horizon are cached.
+	first, _ := opt.Window(start)
+	if first < start {
+		first += int64(interval)
+	}
+	last := now.Add(-c.Horizon).UnixNano()
+	if end < last {
+		last = end
+	}
+	last, _ = opt.Window(last)
+	if last <= first {
+		return e.ExecuteStatement(stmt, ctx)
+	}
+
+	key := ctx.User + "\x00" + ctx.Database + "\x00" + stmt.String()
+	measurements := statementMeasurements(stmt)
+
+	c.mu.Lock()
+	epoch := c.epoch(measurements)
+	entry := c.entries[key]
+	if entry != nil {
+		c.clock++
+		entry.used = c.clock
+	}
+	c.mu.Unlock()
+
+	// Reuse the cached buckets within the range and compute the others.
+	reuseStart, reuseEnd := end, end
+	if entry != nil {
+		reuseStart, reuseEnd = maxInt64(first, entry.start), minInt64(last, entry.end)
+		if reuseStart >= reuseEnd {
+			reuseStart, reuseEnd = end, end
+		}
+	}
+	if reuseStart < reuseEnd {
+		atomic.AddInt64(&c.stats.Hits, 1)
+	} else {
+		atomic.AddInt64(&c.stats.Misses, 1)
+	}
+
+	var segments [][]*models.Row
+	for _, r := range [][2]int64{{start, reuseStart}, {reuseStart, reuseEnd}, {reuseEnd, end}} {
+		if r[0] >= r[1] {
+			continue
+		} else if r[0] == reuseStart && r[1] == reuseEnd {
+			rows, _ := selectRows(entry.rows, r[0], r[1])
+			segments = append(segments, rows)
+			continue
+		}
+
+		other := stmt.Clone()
+		other.Fill = NoFill
+		if err := other.SetTimeRange(time.Unix(0, r[0]), time.Unix(0, r[1])); err != nil {
+			return err
+		}
+
+		rows, err := collectRows(e, other, ctx)
+		if err != nil {
+			return err
+		}
+		segments = append(segments, rows)
+	}
+	rows := mergeRows(segments)
+
+	// Cache the whole buckets unless points were written in them meanwhile.
+	cached, ok := selectRows(rows, first, last)
+	if !ok {
+		return e.ExecuteStatement(stmt, ctx)
+	}
+	c.store(key, epoch, &resultCacheEntry{
+		measurements: measurements,
+		start:        first,
+		end:          last,
+		rows:         cached,
+		size:         rowsSize(cached),
+	})
+
+	if stmt.Fill == NullFill {
+		fillRows(rows, stmt, opt)
+	}
+	return emitRows(rows, ctx)
+}
+
+// store caches an entry, unless its measurements were invalidated since
+// epoch.
+func (c *ResultCache) store(key string, epoch uint64, entry *resultCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.epoch(entry.measurements) != epoch || c.MaxEntries <= 0 {
+		return
+	} else if c.MaxMemorySize > 0 && entry.size > c.MaxMemorySize {
+		return
+	}
+	c.remove(key)
+
+	// Evict the least recently used entries until the entry fits.
+	for len(c.entries) >= c.MaxEntries || (c.MaxMemorySize > 0 && c.size+entry.size > c.MaxMemorySize) {
+		var evict string
+		var used uint64
+		for k, e := range c.entries {
+			if evict == "" || e.used < used {
+				evict, used = k, e.used
+			}
+		}
+		c.remove(evict)
+	}
+
+	c.clock++
+	entry.used = c.clock
+	c.entries[key] = entry
+	c.size += entry.size
+}
+
+// rowsSize returns an estimate of the size in bytes of rows.
+func rowsSize(rows []*models.Row) uint64 {
+	var n int
+	for _, row := range rows {
+		n += len(row.Name)
+		for k, v := range row.Tags {
+			n += len(k) + len(v)
+		}
+		for _, c := range row.Columns {
+			n += len(c)
+		}
+		for _, v := range row.Values {
+			// Each value is a slice of interfaces.
+			n += 24 + 16*len(v)
+			for _, vv := range v {
+				switch vv := vv.(type) {
+				case string:
+					n += len(vv)
+				case time.Time:
+					n += 24
+				case float64, int64:
+					n += 8
+				}
+			}
+		}
+	}
+	return uint64(n)
+}
+
+// statementMeasurements returns the databases and names of the measurements
+// of a statement, separated by a null byte.
+func statementMeasurements(stmt *SelectStatement) map[string]struct{} {
+	m := make(map[string]struct{})
+	for _, mm := range stmt.Sources.Measurements() {
+		m[mm.Database+"\x00"+mm.Name] = struct{}{}
+	}
+	return m
+}
+
+// collectRows executes stmt and returns the rows it emits, merging those of
+// the same series.
+func collectRows(e StatementExecutor, stmt *SelectStatement, ctx ExecutionContext) ([]*models.Row, error) {
+	results := make(chan *Result)
+	ctx.Results = results
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.ExecuteStatement(stmt, ctx)
+		close(results)
+	}()
+
+	var rows []*models.Row
+	var rerr error
+	for result := range results {
+		if result.Err != nil && rerr == nil {
+			rerr = result.Err
+		}
+		for _, row := range result.Series {
+			if n := len(rows); n > 0 && seriesKey(rows[n-1]) == seriesKey(row) {
+				rows[n-1].Values = append(rows[n-1].Values, row.Values...)
+				continue
+			}
+			rows = append(rows, &models.Row{
+				Name:    row.Name,
+				Tags:    row.Tags,
+				Columns: row.Columns,
+				Values:  row.Values,
+			})
+		}
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return rows, rerr
+}
+
+// seriesKey returns the key sorting the rows of a series the way they are
+// emitted.
+func seriesKey(row *models.Row) string {
+	return row.Name + "\x00" + NewTags(row.Tags).ID()
+}
+
+// mergeRows merges the rows of consecutive time ranges.
+func mergeRows(segments [][]*models.Row) []*models.Row {
+	var rows []*models.Row
+	series := make(map[string]*models.Row)
+	for _, segment := range segments {
+		for _, row := range segment {
+			if other, ok := series[seriesKey(row)]; ok {
+				other.Values = append(other.Values, row.Values...)
+				continue
+			}
+
+			row = &models.Row{
+				Name:    row.Name,
+				Tags:    row.Tags,
+				Columns: row.Columns,
+				Values:  append([][]interface{}{}, row.Values...),
+			}
+			series[seriesKey(row)] = row
+			rows = append(rows, row)
+		}
+	}
+	sort.Sort(rowsBySeries(rows))
+	return rows
+}
+
+// rowsBySeries sorts rows the way series are emitted.
+type rowsBySeries []*models.Row
+
+func (a rowsBySeries) Len() int           { return len(a) }
+func (a rowsBySeries) Less(i, j int) bool { return seriesKey(a[i]) < seriesKey(a[j]) }
+func (a rowsBySeries) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// selectRows returns copies of the rows holding only the values within
+// [start, end). It returns false if a value has no time.
+func selectRows(rows []*models.Row, start, end int64) ([]*models.Row, bool) {
+	other := make([]*models.Row, 0, len(rows))
+	for _, row := range rows {
+		var values [][]interface{}
+		for _, v := range row.Values {
+			t, ok := rowTime(v)
+			if !ok {
+				return nil, false
+			} else if t >= start && t < end {
+				values = append(values, append([]interface{}{}, v...))
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		other = append(other, &models.Row{
+			Name:    row.Name,
+			Tags:    row.Tags,
+			Columns: row.Columns,
+			Values:  values,
+		})
+	}
+	return other, true
+}
+
+// rowTime returns the time of a row value.
+func rowTime(v []interface{}) (int64, bool) {
+	if len(v) == 0 {
+		return 0, false
+	}
+	t, ok := v[0].(time.Time)
+	if !ok {
+		return 0, false
+	}
+	return t.UnixNano(), true
+}
+
+// fillRows adds the values fill(null) adds to rows computed with fill(none).
+// Like the fill iterators, it fills the buckets of each column with points
+// in the series, with zero for count() and null otherwise.
+func fillRows(rows []*models.Row, stmt *SelectStatement, opt IteratorOptions) {
+	startTime, _ := opt.Window(opt.StartTime)
+	endTime, _ := opt.Window(opt.EndTime)
+
+	for _, row := range rows {
+		if len(row.Columns) != len(stmt.Fields)+1 {
+			continue
+		}
+
+		// Determine the fill value of each column with points.
+		fill := make([]interface{}, len(row.Columns))
+		filled := make([]bool, len(row.Columns))
+		for _, v := range row.Values {
+			for i := 1; i < len(v) && i < len(filled); i++ {
+				if v[i] != nil {
+					filled[i] = true
+				}
+			}
+		}
+		for i := 1; i < len(filled); i++ {
+			if call, ok := stmt.Fields[i-1].Expr.(*Call); ok && call.Name == "count" && filled[i] {
+				fill[i] = int64(0)
+			}
+		}
+
+		values := make([][]interface{}, 0, len(row.Values))
+		j := 0
+		for t := startTime; t <= endTime; t += int64(opt.Interval.Duration) {
+			n := len(values)
+			for ; j < len(row.Values); j++ {
+				if vt, _ := rowTime(row.Values[j]); vt != t {
+					break
+				}
+				values = append(values, row.Values[j])
+			}
+
+			if len(values) == n {
+				v := make([]interface{}, len(row.Columns))
+				v[0] = time.Unix(0, t).UTC()
+				copy(v[1:], fill[1:])
+				values = append(values, v)
+				continue
+			}
+
+			for _, v := range values[n:] {
+				for i := 1; i < len(v) && i < len(fill); i++ {
+					if v[i] == nil {
+						v[i] = fill[i]
+					}
+				}
+			}
+		}
+		row.Values = append(values, row.Values[j:]...)
+	}
+}
+
+// emitRows sends rows the way the statement executor emits them.
+func emitRows(rows []*models.Row, ctx ExecutionContext) error {
+	if len(rows) == 0 {
+		return ctx.Send(&Result{
+			StatementID: ctx.StatementID,
+			Series:      make([]*models.Row, 0),
+		})
+	}
+
+	for i, row := range rows {
+		values := row.Values
+		for len(values) > 0 {
+			n := len(values)
+			if ctx.ChunkSize > 0 && n > ctx.ChunkSize {
+				n = ctx.ChunkSize
+			}
+
+			// Copy the values since the caller may modify them.
+			other := &models.Row{
+				Name:    row.Name,
+				Tags:    row.Tags,
+				Columns: row.Columns,
+				Partial: n < len(values),
+			}
+			for _, v := range values[:n] {
+				other.Values = append(other.Values, append([]interface{}{}, v...))
+			}
+			values = values[n:]
+
+			if err := ctx.Send(&Result{
+				StatementID: ctx.StatementID,
+				Series:      []*models.Row{other},
+				Partial:     len(values) > 0 || i < len(rows)-1,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
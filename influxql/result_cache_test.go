@@ -0,0 +1,324 @@
+package influxql_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/models"
+)
+
+// ResultCacheData is a statement executor summing the values of points of
+// cpu by minute and host.
+type ResultCacheData struct {
+	Points []models.Point
+
+	// Time ranges of the executed statements.
+	Ranges [][2]time.Time
+}
+
+func (d *ResultCacheData) ExecuteStatement(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+	switch stmt := stmt.(type) {
+	case *influxql.SelectStatement:
+		tmin, tmax, err := influxql.TimeRange(stmt.Condition)
+		if err != nil {
+			return err
+		}
+		d.Ranges = append(d.Ranges, [2]time.Time{tmin, tmax.Add(time.Nanosecond)})
+
+		sums := make(map[string]map[int64]float64)
+		for _, p := range d.Points {
+			if p.Time().Before(tmin) || p.Time().After(tmax) {
+				continue
+			}
+
+			host := p.Tags().GetString("host")
+			if sums[host] == nil {
+				sums[host] = make(map[int64]float64)
+			}
+			fields, _ := p.Fields()
+			sums[host][p.Time().Truncate(time.Minute).UnixNano()] += fields["value"].(float64)
+		}
+
+		var hosts []string
+		for host := range sums {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+
+		var rows []*models.Row
+		for _, host := range hosts {
+			row := &models.Row{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": host},
+				Columns: []string{"time", "sum"},
+			}
+			for t := tmin.Truncate(time.Minute); !t.After(tmax); t = t.Add(time.Minute) {
+				if sum, ok := sums[host][t.UnixNano()]; ok {
+					row.Values = append(row.Values, []interface{}{t.UTC(), sum})
+				} else if stmt.Fill == influxql.NullFill {
+					row.Values = append(row.Values, []interface{}{t.UTC(), nil})
+				}
+			}
+			rows = append(rows, row)
+		}
+		return ctx.Send(&influxql.Result{StatementID: ctx.StatementID, Series: rows})
+	default:
+		return nil
+	}
+}
+
+// Write adds a point to the data.
+func (d *ResultCacheData) Write(host string, t time.Time, value float64) models.Point {
+	p := models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": host}), models.Fields{"value": value}, t)
+	d.Points = append(d.Points, p)
+	return p
+}
+
+// Ensure the result cache only computes the buckets it doesn't cache, and
+// that its results match those computed without it.
+func TestResultCache_Execute(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT sum(value) FROM cpu WHERE time >= '2000-01-01T00:00:30Z' AND time < '2000-01-01T00:10:00Z' GROUP BY time(1m), host fill(null)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2000, 1, 1, 0, 10, 0, 0, time.UTC)
+	data := &ResultCacheData{}
+	for i := 0; i < 10; i++ {
+		data.Write("a", now.Add(time.Duration(i-10)*time.Minute), float64(i))
+		data.Write("a", now.Add(time.Duration(i-10)*time.Minute+45*time.Second), float64(i))
+	}
+	data.Write("b", now.Add(-8*time.Minute), 100)
+	data.Write("b", now.Add(-30*time.Second), 200)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = data
+	exp := readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil))
+	data.Ranges = nil
+
+	e.ResultCache = influxql.NewResultCache(10, 2*time.Minute)
+	e.ResultCache.Now = func() time.Time { return now }
+
+	// The first execution computes and caches the complete buckets.
+	if got := readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil)); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected results:\n\ngot=%#v\n\nexp=%#v", got, exp)
+	} else if len(data.Ranges) != 1 {
+		t.Fatalf("unexpected ranges: %v", data.Ranges)
+	} else if e.ResultCache.Len() != 1 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	}
+
+	// The second execution only computes the first partial bucket and the
+	// buckets within the horizon.
+	data.Ranges = nil
+	if got := readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil)); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected results:\n\ngot=%#v\n\nexp=%#v", got, exp)
+	} else if exp := [][2]time.Time{
+		{now.Add(-570 * time.Second), now.Add(-9 * time.Minute)},
+		{now.Add(-2 * time.Minute), now},
+	}; !reflect.DeepEqual(data.Ranges, exp) {
+		t.Fatalf("unexpected ranges: got=%v exp=%v", data.Ranges, exp)
+	}
+
+	// Writing into the horizon keeps the cached buckets.
+	e.ResultCache.InvalidatePoints("", []models.Point{data.Write("b", now.Add(-time.Minute), 1)})
+	if e.ResultCache.Len() != 1 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	}
+
+	// Writing into the cached buckets drops them.
+	e.ResultCache.InvalidatePoints("", []models.Point{data.Write("c", now.Add(-5*time.Minute), 1)})
+	if e.ResultCache.Len() != 0 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	}
+
+	e.ResultCache = nil
+	exp = readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil))
+
+	e.ResultCache = influxql.NewResultCache(10, 2*time.Minute)
+	e.ResultCache.Now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		if got := readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil)); !reflect.DeepEqual(got, exp) {
+			t.Fatalf("%d. unexpected results:\n\ngot=%#v\n\nexp=%#v", i, got, exp)
+		}
+	}
+
+	// Statements changing data drop the cached results.
+	dq, err := influxql.ParseQuery(`DROP MEASUREMENT cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	discardOutput(e.ExecuteQuery(dq, influxql.ExecutionOptions{}, nil))
+	if e.ResultCache.Len() != 0 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	}
+}
+
+// Ensure the result cache evicts the least recently used statement.
+func TestResultCache_Evict(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 10, 0, 0, time.UTC)
+	data := &ResultCacheData{}
+	data.Write("a", now.Add(-5*time.Minute), 1)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = data
+	e.ResultCache = influxql.NewResultCache(2, time.Minute)
+	e.ResultCache.Now = func() time.Time { return now }
+
+	execute := func(s string) {
+		q, err := influxql.ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil))
+	}
+
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 9m GROUP BY time(1m)`)
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 8m GROUP BY time(1m)`)
+	if e.ResultCache.Len() != 2 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	}
+
+	// The statement over 10m is still cached.
+	data.Ranges = nil
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	if exp := [][2]time.Time{{now.Add(-time.Minute), now.Add(time.Nanosecond)}}; !reflect.DeepEqual(data.Ranges, exp) {
+		t.Fatalf("unexpected ranges: got=%v exp=%v", data.Ranges, exp)
+	}
+}
+
+// Ensure the result cache evicts the least recently used statements to stay
+// under its maximum size.
+func TestResultCache_MaxMemorySize(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 10, 0, 0, time.UTC)
+	data := &ResultCacheData{}
+	for i := 0; i < 10; i++ {
+		data.Write("a", now.Add(time.Duration(i-10)*time.Minute), 1)
+	}
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = data
+	e.ResultCache = influxql.NewResultCache(10, time.Minute)
+	e.ResultCache.Now = func() time.Time { return now }
+
+	execute := func(s string) {
+		q, err := influxql.ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil))
+	}
+
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	size := e.ResultCache.Size()
+	if size == 0 {
+		t.Fatal("expected cached results")
+	}
+
+	// Only one statement fits.
+	e.ResultCache.MaxMemorySize = size + size/2
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 9m GROUP BY time(1m)`)
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	if e.ResultCache.Len() != 1 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	} else if got := e.ResultCache.Size(); got != size {
+		t.Fatalf("unexpected size: got=%d exp=%d", got, size)
+	}
+
+	// Statements larger than the maximum size aren't cached.
+	e.ResultCache.Purge()
+	e.ResultCache.MaxMemorySize = 1
+	execute(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	if e.ResultCache.Len() != 0 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	} else if got := e.ResultCache.Size(); got != 0 {
+		t.Fatalf("unexpected size: %d", got)
+	}
+}
+
+// Ensure the result cache drops the results of a database within a time
+// range, such as that of a deleted shard group.
+func TestResultCache_InvalidateRange(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 10, 0, 0, time.UTC)
+	data := &ResultCacheData{}
+	data.Write("a", now.Add(-5*time.Minute), 1)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = data
+	e.ResultCache = influxql.NewResultCache(10, time.Minute)
+	e.ResultCache.Now = func() time.Time { return now }
+
+	q, err := influxql.ParseQuery(`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSeries(t, e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil))
+
+	// Other databases and time ranges keep the cached results.
+	e.ResultCache.InvalidateRange("db1", now.Add(-time.Hour), now)
+	e.ResultCache.InvalidateRange("", now.Add(-time.Hour), now.Add(-10*time.Minute))
+	if e.ResultCache.Len() != 1 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	}
+
+	e.ResultCache.InvalidateRange("", now.Add(-time.Hour), now.Add(-5*time.Minute))
+	if e.ResultCache.Len() != 0 {
+		t.Fatalf("unexpected cached statements: %d", e.ResultCache.Len())
+	} else if got := e.ResultCache.Size(); got != 0 {
+		t.Fatalf("unexpected size: %d", got)
+	}
+}
+
+// Ensure statements which can't reuse buckets are not cached.
+func TestResultCache_NotCacheable(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 10, 0, 0, time.UTC)
+	e := NewQueryExecutor()
+	e.StatementExecutor = &ResultCacheData{}
+	e.ResultCache = influxql.NewResultCache(10, time.Minute)
+	e.ResultCache.Now = func() time.Time { return now }
+
+	for _, s := range []string{
+		`SELECT value FROM cpu WHERE time >= now() - 10m`,
+		`SELECT sum(value) FROM cpu WHERE time < now() GROUP BY time(1m)`,
+		`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m) fill(previous)`,
+		`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m) LIMIT 2`,
+		`SELECT sum(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m) ORDER BY time DESC`,
+		`SELECT derivative(sum(value)) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`,
+		`SELECT sum(*) FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`,
+		`SELECT sum(value) FROM /cpu/ WHERE time >= now() - 10m GROUP BY time(1m)`,
+		`SELECT sum(value) INTO cpu_1m FROM cpu WHERE time >= now() - 10m GROUP BY time(1m)`,
+	} {
+		q, err := influxql.ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		discardOutput(e.ExecuteQuery(q, influxql.ExecutionOptions{}, nil))
+		if e.ResultCache.Len() != 0 {
+			t.Fatalf("%s: unexpected cached statements: %d", s, e.ResultCache.Len())
+		}
+	}
+}
+
+// readSeries returns the series of results, merging partial rows.
+func readSeries(t *testing.T, results <-chan *influxql.Result) []*models.Row {
+	var rows []*models.Row
+	for result := range results {
+		if result.Err != nil {
+			t.Fatalf("unexpected error: %s", result.Err)
+		}
+		for _, row := range result.Series {
+			if n := len(rows); n > 0 && rows[n-1].Partial {
+				rows[n-1].Values = append(rows[n-1].Values, row.Values...)
+				rows[n-1].Partial = row.Partial
+				continue
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
@@ -25,6 +25,9 @@ type SelectOptions struct {
 
 	// Maximum number of concurrent series.
 	MaxSeriesN int
+
+	// Authorizer restricts the series that may be read, if set.
+	Authorizer ReadAuthorizer
 }
 
 // Select executes stmt against ic and returns a list of iterators to stream from.
@@ -27,12 +27,27 @@ type TaskManager struct {
 	// Maximum number of concurrent queries.
 	MaxConcurrentQueries int
 
+	// Maximum number of queries waiting for the concurrency limits to allow
+	// them to run. Queries are refused when the queue is full.
+	MaxQueuedQueries int
+
+	// Returns the limits of users and databases overriding the defaults,
+	// if set.
+	Limiter QueryLimiter
+
+	// Returns an error if another query against a database would exceed its
+	// quota, given the number n of queries running against it, if set.
+	// Queries exceeding the quota wait in the queue like those exceeding a
+	// concurrency limit, and are refused with this error if it's full.
+	QueryQuota func(database string, n int) error
+
 	// Logger to use for all logging.
 	// Defaults to discarding all log output.
 	Logger zap.Logger
 
 	// Used for managing and tracking running queries.
 	queries  map[uint64]*QueryTask
+	queue    []*queuedQuery // Ordered by priority.
 	nextID   uint64
 	mu       sync.RWMutex
 	shutdown bool
@@ -125,30 +140,148 @@ func (t *TaskManager) query(qid uint64) (*QueryTask, bool) {
 // This function also returns a channel that will be closed when this
 // query finishes running.
 //
+// If a concurrency limit is reached, the query waits in a queue for other
+// queries to finish, unless the queue is full.
+//
 // After a query finishes running, the system is free to reuse a query id.
-func (t *TaskManager) AttachQuery(q *Query, database string, interrupt <-chan struct{}) (uint64, *QueryTask, error) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
+func (t *TaskManager) AttachQuery(q *Query, opt ExecutionOptions, interrupt <-chan struct{}) (uint64, *QueryTask, error) {
+	qq := t.newQueuedQuery(q, opt, interrupt)
 
+	t.mu.Lock()
 	if t.shutdown {
+		t.mu.Unlock()
 		return 0, nil, ErrQueryEngineShutdown
 	}
 
+	err := t.checkConcurrency(qq)
+	if err == nil {
+		t.attach(qq)
+		t.mu.Unlock()
+		return qq.qid, qq.task, nil
+	} else if len(t.queue) >= t.MaxQueuedQueries {
+		t.mu.Unlock()
+		return 0, nil, err
+	}
+
+	qq.ready = make(chan struct{})
+	t.enqueue(qq)
+	t.mu.Unlock()
+
+	select {
+	case <-qq.ready:
+	case <-interrupt:
+		err = ErrQueryInterrupted
+	case <-opt.AbortCh:
+		err = ErrQueryAborted
+	}
+
+	if err != nil {
+		t.mu.Lock()
+		queued := t.dequeue(qq)
+		t.mu.Unlock()
+		if queued {
+			return 0, nil, err
+		}
+
+		// The query was attached or refused in the meantime.
+		<-qq.ready
+	}
+	return qq.qid, qq.task, qq.err
+}
+
+// CheckQuery returns the error AttachQuery would refuse a query with right
+// away, because a concurrency limit or quota is reached and the queue is
+// full. It allows refusing a query before executing it.
+func (t *TaskManager) CheckQuery(opt ExecutionOptions) error {
+	qq := t.newQueuedQuery(nil, opt, nil)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.shutdown {
+		return ErrQueryEngineShutdown
+	} else if err := t.checkConcurrency(qq); err != nil && len(t.queue) >= t.MaxQueuedQueries {
+		return err
+	}
+	return nil
+}
+
+// newQueuedQuery returns the query of the options with the limits of its
+// user and database.
+func (t *TaskManager) newQueuedQuery(q *Query, opt ExecutionOptions, interrupt <-chan struct{}) *queuedQuery {
+	var userLimits, databaseLimits QueryLimits
+	if t.Limiter != nil {
+		if opt.User != "" {
+			userLimits = t.Limiter.UserQueryLimits(opt.User)
+		}
+		if opt.Database != "" {
+			databaseLimits = t.Limiter.DatabaseQueryLimits(opt.Database)
+		}
+	}
+
+	return &queuedQuery{
+		query:              q,
+		user:               opt.User,
+		database:           opt.Database,
+		interrupt:          interrupt,
+		limits:             userLimits.merge(databaseLimits),
+		maxUserQueries:     userLimits.MaxConcurrentQueries,
+		maxDatabaseQueries: databaseLimits.MaxConcurrentQueries,
+	}
+}
+
+// checkConcurrency returns an error if running the query would exceed a
+// concurrency limit or the quota of its database.
+func (t *TaskManager) checkConcurrency(qq *queuedQuery) error {
 	if t.MaxConcurrentQueries > 0 && len(t.queries) >= t.MaxConcurrentQueries {
-		return 0, nil, ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
+		return ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
+	}
+
+	quota := t.QueryQuota != nil && qq.database != ""
+	if qq.maxUserQueries == 0 && qq.maxDatabaseQueries == 0 && !quota {
+		return nil
 	}
 
+	var userN, databaseN int
+	for _, query := range t.queries {
+		if query.user == qq.user {
+			userN++
+		}
+		if query.database == qq.database {
+			databaseN++
+		}
+	}
+
+	if qq.maxUserQueries > 0 && userN >= qq.maxUserQueries {
+		return ErrMaxConcurrentQueriesLimitExceeded(userN, qq.maxUserQueries)
+	} else if qq.maxDatabaseQueries > 0 && databaseN >= qq.maxDatabaseQueries {
+		return ErrMaxConcurrentQueriesLimitExceeded(databaseN, qq.maxDatabaseQueries)
+	} else if quota {
+		return t.QueryQuota(qq.database, databaseN)
+	}
+	return nil
+}
+
+// attach starts managing the query, setting its id and task.
+func (t *TaskManager) attach(qq *queuedQuery) {
 	qid := t.nextID
 	query := &QueryTask{
-		query:     q.String(),
-		database:  database,
+		query:     qq.query.String(),
+		database:  qq.database,
+		user:      qq.user,
+		limits:    qq.limits,
 		startTime: time.Now(),
 		closing:   make(chan struct{}),
 		monitorCh: make(chan error),
 	}
 	t.queries[qid] = query
 
-	go t.waitForQuery(qid, query.closing, interrupt, query.monitorCh)
+	timeout := t.QueryTimeout
+	if qq.limits.QueryTimeout != 0 {
+		timeout = qq.limits.QueryTimeout
+	}
+
+	go t.waitForQuery(qid, timeout, query.closing, qq.interrupt, query.monitorCh)
 	if t.LogQueriesAfter != 0 {
 		go query.monitor(func(closing <-chan struct{}) error {
 			timer := time.NewTimer(t.LogQueriesAfter)
@@ -164,7 +297,57 @@ func (t *TaskManager) AttachQuery(q *Query, database string, interrupt <-chan st
 		})
 	}
 	t.nextID++
-	return qid, query, nil
+
+	qq.qid, qq.task = qid, query
+}
+
+// enqueue adds the query to the queue, after the queries with the same or a
+// higher priority.
+func (t *TaskManager) enqueue(qq *queuedQuery) {
+	i := len(t.queue)
+	for i > 0 && t.queue[i-1].limits.Priority < qq.limits.Priority {
+		i--
+	}
+	t.queue = append(t.queue, nil)
+	copy(t.queue[i+1:], t.queue[i:])
+	t.queue[i] = qq
+}
+
+// dequeue removes the query from the queue. It returns false if the query
+// wasn't queued anymore.
+func (t *TaskManager) dequeue(qq *queuedQuery) bool {
+	for i, other := range t.queue {
+		if other == qq {
+			t.queue = append(t.queue[:i], t.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// queuedN returns the number of queued queries.
+func (t *TaskManager) queuedN() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.queue)
+}
+
+// dispatch attaches the queued queries the concurrency limits allow to run,
+// in order of priority.
+func (t *TaskManager) dispatch() {
+	queue := t.queue[:0]
+	for _, qq := range t.queue {
+		if t.checkConcurrency(qq) != nil {
+			queue = append(queue, qq)
+			continue
+		}
+		t.attach(qq)
+		close(qq.ready)
+	}
+	for i := len(queue); i < len(t.queue); i++ {
+		t.queue[i] = nil
+	}
+	t.queue = queue
 }
 
 // KillQuery stops and removes a query from the TaskManager.
@@ -180,6 +363,7 @@ func (t *TaskManager) KillQuery(qid uint64) error {
 
 	close(query.closing)
 	delete(t.queries, qid)
+	t.dispatch()
 	return nil
 }
 
@@ -209,10 +393,10 @@ func (t *TaskManager) Queries() []QueryInfo {
 	return queries
 }
 
-func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closing <-chan struct{}, monitorCh <-chan error) {
+func (t *TaskManager) waitForQuery(qid uint64, timeout time.Duration, interrupt <-chan struct{}, closing <-chan struct{}, monitorCh <-chan error) {
 	var timerCh <-chan time.Time
-	if t.QueryTimeout != 0 {
-		timer := time.NewTimer(t.QueryTimeout)
+	if timeout != 0 {
+		timer := time.NewTimer(timeout)
 		timerCh = timer.C
 		defer timer.Stop()
 	}
@@ -258,5 +442,11 @@ func (t *TaskManager) Close() error {
 		close(query.closing)
 	}
 	t.queries = nil
+
+	for _, qq := range t.queue {
+		qq.err = ErrQueryEngineShutdown
+		close(qq.ready)
+	}
+	t.queue = nil
 	return nil
 }
@@ -9,12 +9,15 @@ import (
 
 // MetaClientMock is a mockable implementation of meta.MetaClient.
 type MetaClientMock struct {
+	AuthenticateFn                      func(username, password string) (*meta.UserInfo, error)
 	CloseFn                             func() error
 	CreateContinuousQueryFn             func(database, name, query string) error
 	CreateDatabaseFn                    func(name string) (*meta.DatabaseInfo, error)
 	CreateDatabaseWithRetentionPolicyFn func(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
+	CreateRoleFn                        func(name string) error
 	CreateRetentionPolicyFn             func(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
 	CreateShardGroupFn                  func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
+	CreateTokenFn                       func(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error)
 	CreateSubscriptionFn                func(database, rp, name, mode string, destinations []string) error
 	CreateUserFn                        func(name, password string, admin bool) (*meta.UserInfo, error)
 
@@ -26,7 +29,9 @@ type MetaClientMock struct {
 	DropContinuousQueryFn func(database, name string) error
 	DropDatabaseFn        func(name string) error
 	DropRetentionPolicyFn func(database, name string) error
+	DropTokenFn           func(name string) error
 	DropSubscriptionFn    func(database, rp, name string) error
+	DropRoleFn            func(name string) error
 	DropShardFn           func(id uint64) error
 	DropUserFn            func(name string) error
 
@@ -34,16 +39,30 @@ type MetaClientMock struct {
 
 	RetentionPolicyFn func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
 
-	SetAdminPrivilegeFn      func(username string, admin bool) error
-	SetDataFn                func(*meta.Data) error
-	SetPrivilegeFn           func(username, database string, p influxql.Privilege) error
-	ShardGroupsByTimeRangeFn func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
-	ShardOwnerFn             func(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
-	UpdateRetentionPolicyFn  func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
-	UpdateUserFn             func(name, password string) error
-	UserPrivilegeFn          func(username, database string) (*influxql.Privilege, error)
-	UserPrivilegesFn         func(username string) (map[string]influxql.Privilege, error)
-	UsersFn                  func() []meta.UserInfo
+	RoleFn                      func(name string) (*meta.RoleInfo, error)
+	RolesFn                     func() []meta.RoleInfo
+	SetAdminPrivilegeFn         func(username string, admin bool) error
+	SetDataFn                   func(*meta.Data) error
+	SetMeasurementPrivilegeFn   func(username string, g meta.MeasurementGrant) error
+	SetRolePrivilegeFn          func(role, database string, p influxql.Privilege) error
+	SetUserRoleFn               func(username, role string, member bool) error
+	SetPrivilegeFn              func(username, database string, p influxql.Privilege) error
+	ShardGroupsByTimeRangeFn    func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	ShardOwnerFn                func(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
+	TokensFn                    func() []meta.TokenInfo
+	UpdateDatabaseQuotaFn       func(name string, qu *meta.QuotaUpdate) error
+	UpdateDatabaseQueryLimitsFn func(name string, lu *meta.QueryLimitsUpdate) error
+	UpdateRetentionPolicyFn     func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
+	UpdateUserFn                func(name, password string) error
+	UpdateUserQueryLimitsFn     func(name string, lu *meta.QueryLimitsUpdate) error
+	UserMeasurementGrantsFn     func(username string) ([]meta.MeasurementGrant, error)
+	UserPrivilegeFn             func(username, database string) (*influxql.Privilege, error)
+	UserPrivilegesFn            func(username string) (map[string]influxql.Privilege, error)
+	UsersFn                     func() []meta.UserInfo
+}
+
+func (c *MetaClientMock) Authenticate(username, password string) (*meta.UserInfo, error) {
+	return c.AuthenticateFn(username, password)
 }
 
 func (c *MetaClientMock) Close() error {
@@ -62,6 +81,10 @@ func (c *MetaClientMock) CreateDatabaseWithRetentionPolicy(name string, spec *me
 	return c.CreateDatabaseWithRetentionPolicyFn(name, spec)
 }
 
+func (c *MetaClientMock) CreateRole(name string) error {
+	return c.CreateRoleFn(name)
+}
+
 func (c *MetaClientMock) CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error) {
 	return c.CreateRetentionPolicyFn(database, spec, makeDefault)
 }
@@ -70,6 +93,10 @@ func (c *MetaClientMock) CreateShardGroup(database, policy string, timestamp tim
 	return c.CreateShardGroupFn(database, policy, timestamp)
 }
 
+func (c *MetaClientMock) CreateToken(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error) {
+	return c.CreateTokenFn(name, username, database, p, expires)
+}
+
 func (c *MetaClientMock) CreateSubscription(database, rp, name, mode string, destinations []string) error {
 	return c.CreateSubscriptionFn(database, rp, name, mode, destinations)
 }
@@ -102,10 +129,18 @@ func (c *MetaClientMock) DropRetentionPolicy(database, name string) error {
 	return c.DropRetentionPolicyFn(database, name)
 }
 
+func (c *MetaClientMock) DropRole(name string) error {
+	return c.DropRoleFn(name)
+}
+
 func (c *MetaClientMock) DropShard(id uint64) error {
 	return c.DropShardFn(id)
 }
 
+func (c *MetaClientMock) DropToken(name string) error {
+	return c.DropTokenFn(name)
+}
+
 func (c *MetaClientMock) DropSubscription(database, rp, name string) error {
 	return c.DropSubscriptionFn(database, rp, name)
 }
@@ -118,10 +153,30 @@ func (c *MetaClientMock) RetentionPolicy(database, name string) (rpi *meta.Reten
 	return c.RetentionPolicyFn(database, name)
 }
 
+func (c *MetaClientMock) Role(name string) (*meta.RoleInfo, error) {
+	return c.RoleFn(name)
+}
+
+func (c *MetaClientMock) Roles() []meta.RoleInfo {
+	return c.RolesFn()
+}
+
 func (c *MetaClientMock) SetAdminPrivilege(username string, admin bool) error {
 	return c.SetAdminPrivilegeFn(username, admin)
 }
 
+func (c *MetaClientMock) SetMeasurementPrivilege(username string, g meta.MeasurementGrant) error {
+	return c.SetMeasurementPrivilegeFn(username, g)
+}
+
+func (c *MetaClientMock) SetRolePrivilege(role, database string, p influxql.Privilege) error {
+	return c.SetRolePrivilegeFn(role, database, p)
+}
+
+func (c *MetaClientMock) SetUserRole(username, role string, member bool) error {
+	return c.SetUserRoleFn(username, role, member)
+}
+
 func (c *MetaClientMock) SetPrivilege(username, database string, p influxql.Privilege) error {
 	return c.SetPrivilegeFn(username, database, p)
 }
@@ -134,6 +189,18 @@ func (c *MetaClientMock) ShardOwner(shardID uint64) (database, policy string, sg
 	return c.ShardOwnerFn(shardID)
 }
 
+func (c *MetaClientMock) Tokens() []meta.TokenInfo {
+	return c.TokensFn()
+}
+
+func (c *MetaClientMock) UpdateDatabaseQuota(name string, qu *meta.QuotaUpdate) error {
+	return c.UpdateDatabaseQuotaFn(name, qu)
+}
+
+func (c *MetaClientMock) UpdateDatabaseQueryLimits(name string, lu *meta.QueryLimitsUpdate) error {
+	return c.UpdateDatabaseQueryLimitsFn(name, lu)
+}
+
 func (c *MetaClientMock) UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error {
 	return c.UpdateRetentionPolicyFn(database, name, rpu, makeDefault)
 }
@@ -142,6 +209,14 @@ func (c *MetaClientMock) UpdateUser(name, password string) error {
 	return c.UpdateUserFn(name, password)
 }
 
+func (c *MetaClientMock) UpdateUserQueryLimits(name string, lu *meta.QueryLimitsUpdate) error {
+	return c.UpdateUserQueryLimitsFn(name, lu)
+}
+
+func (c *MetaClientMock) UserMeasurementGrants(username string) ([]meta.MeasurementGrant, error) {
+	return c.UserMeasurementGrantsFn(username)
+}
+
 func (c *MetaClientMock) UserPrivilege(username, database string) (*influxql.Privilege, error) {
 	return c.UserPrivilegeFn(username, database)
 }
@@ -273,30 +273,14 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 		pos, block = scanLine(buf, pos)
 		pos++
 
+		block = trimLine(block)
 		if len(block) == 0 {
 			continue
 		}
 
-		// lines which start with '#' are comments
-		start := skipWhitespace(block, 0)
-
-		// If line is all whitespace, just skip it
-		if start >= len(block) {
-			continue
-		}
-
-		if block[start] == '#' {
-			continue
-		}
-
-		// strip the newline if one is present
-		if block[len(block)-1] == '\n' {
-			block = block[:len(block)-1]
-		}
-
-		pt, err := parsePoint(block[start:], defaultTime, precision)
+		pt, err := parsePoint(block, defaultTime, precision)
 		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
+			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block), err))
 		} else {
 			points = append(points, pt)
 		}
@@ -309,6 +293,33 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 
 }
 
+// trimLine returns the point held by a line of line protocol without leading
+// whitespace or a trailing newline. It returns nil for blank lines and
+// comments, which start with '#'.
+func trimLine(block []byte) []byte {
+	if len(block) == 0 {
+		return nil
+	}
+
+	// lines which start with '#' are comments
+	start := skipWhitespace(block, 0)
+
+	// If line is all whitespace, just skip it
+	if start >= len(block) {
+		return nil
+	}
+
+	if block[start] == '#' {
+		return nil
+	}
+
+	// strip the newline if one is present
+	if block[len(block)-1] == '\n' {
+		block = block[:len(block)-1]
+	}
+	return block[start:]
+}
+
 func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
 	// scan the first block which is measurement[,tag1=value1,tag2=value=2...]
 	pos, key, err := scanKey(buf, 0)
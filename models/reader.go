@@ -0,0 +1,180 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// DefaultReadSize is the number of bytes a PointReader reads from its
+	// underlying reader at once.
+	DefaultReadSize = 64 * 1024
+
+	// DefaultMaxLineSize is the default maximum size of a line read by a
+	// PointReader.
+	DefaultMaxLineSize = 16 * 1024 * 1024
+)
+
+// LineError describes a line of line protocol that failed to parse.
+type LineError struct {
+	// Line is the number of the line within the input, starting at 1.
+	Line int
+
+	// Text is the content of the line.
+	Text string
+
+	// Err is the reason the line was rejected.
+	Err error
+}
+
+// Error returns a string representation of the error.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("unable to parse '%s' (line %d): %v", e.Text, e.Line, e.Err)
+}
+
+// ErrLineTooLong is returned when a line exceeds the maximum line size of a
+// PointReader.
+var ErrLineTooLong = errors.New("line too long")
+
+// PointReader parses points incrementally from line protocol read from an
+// io.Reader. Unlike ParsePointsWithPrecision, it only holds the lines of the
+// points being returned in memory, so it can be used on inputs of any size.
+type PointReader struct {
+	r           io.Reader
+	defaultTime time.Time
+	precision   string
+
+	// MaxLineSize is the maximum size of a single line. Reading a longer
+	// line fails with ErrLineTooLong.
+	MaxLineSize int
+
+	buf   []byte // unparsed input
+	line  int    // number of the line at the start of buf
+	eof   bool   // set once the underlying reader is exhausted
+	lines []int  // line numbers of the points last returned
+}
+
+// NewPointReader returns a reader of the points in r. Points without a
+// timestamp are given defaultTime, or the time they are read at if
+// defaultTime is zero, and timestamps are read in precision.
+func NewPointReader(r io.Reader, defaultTime time.Time, precision string) *PointReader {
+	return &PointReader{
+		r:           r,
+		defaultTime: defaultTime,
+		precision:   precision,
+		MaxLineSize: DefaultMaxLineSize,
+		line:        1,
+	}
+}
+
+// ReadPoints reads up to n points. Lines that fail to parse are skipped and
+// returned as errors alongside the points; they don't count toward n. Once
+// the input is exhausted, ReadPoints returns io.EOF along with the last points
+// and line errors, if any. Other errors of the underlying reader are returned
+// as is.
+//
+// The returned points don't refer to the reader's buffer and remain valid
+// after subsequent reads.
+func (r *PointReader) ReadPoints(n int) ([]Point, []*LineError, error) {
+	var (
+		points []Point
+		failed []*LineError
+	)
+	r.lines = r.lines[:0]
+	for len(points) < n {
+		block, line, err := r.readLine()
+		if err != nil {
+			return points, failed, err
+		}
+
+		block = trimLine(block)
+		if len(block) == 0 {
+			continue
+		}
+
+		// Points refer to the bytes they were parsed from, so copy the line
+		// before the buffer is reused.
+		block = append([]byte(nil), block...)
+
+		defaultTime := r.defaultTime
+		if defaultTime.IsZero() {
+			defaultTime = time.Now().UTC()
+		}
+
+		pt, err := parsePoint(block, defaultTime, r.precision)
+		if err != nil {
+			failed = append(failed, &LineError{Line: line, Text: string(block), Err: err})
+			continue
+		}
+		points = append(points, pt)
+		r.lines = append(r.lines, line)
+	}
+	return points, failed, nil
+}
+
+// Lines returns the line numbers of the points returned by the last call to
+// ReadPoints, in the same order.
+func (r *PointReader) Lines() []int {
+	return r.lines
+}
+
+// readLine returns the next line of input and its line number. The line is
+// only valid until the next call.
+func (r *PointReader) readLine() ([]byte, int, error) {
+	for {
+		i, block := scanLine(r.buf, 0)
+
+		// The line is complete once an unquoted newline is found. Lines at
+		// the end of the input are complete once there is nothing left to read.
+		if i < len(r.buf) || (r.eof && len(r.buf) > 0) {
+			if i > len(r.buf) {
+				i, block = len(r.buf), r.buf
+			}
+			if len(block) > r.MaxLineSize {
+				return nil, 0, ErrLineTooLong
+			}
+			line := r.line
+			r.line += bytes.Count(block, []byte{'\n'})
+			if i < len(r.buf) {
+				r.line++
+				i++
+			}
+			r.buf = r.buf[i:]
+			return block, line, nil
+		} else if r.eof {
+			return nil, 0, io.EOF
+		}
+
+		if len(r.buf) > r.MaxLineSize {
+			return nil, 0, ErrLineTooLong
+		}
+
+		if err := r.fill(); err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// fill reads more input into the buffer, moving the unparsed input to a new
+// buffer first if there is not enough room left.
+func (r *PointReader) fill() error {
+	if cap(r.buf)-len(r.buf) < DefaultReadSize {
+		buf := make([]byte, len(r.buf), 2*len(r.buf)+DefaultReadSize)
+		copy(buf, r.buf)
+		r.buf = buf
+	}
+
+	// Leave a spare byte at the end of the buffer: scanLine steps past the
+	// end of its input when the input ends with a backslash.
+	n, err := r.r.Read(r.buf[len(r.buf) : cap(r.buf)-1])
+	r.buf = r.buf[:len(r.buf)+n]
+	if err == io.EOF {
+		r.eof = true
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
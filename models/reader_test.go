@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+)
+
+// Ensure the point reader returns points in batches and reports the lines that
+// failed to parse.
+func TestPointReader_ReadPoints(t *testing.T) {
+	input := strings.Join([]string{
+		"# comment",
+		"cpu value=1 1000000000",
+		"",
+		"cpu value= 2000000000",
+		`log msg="a` + "\n" + `b" 3000000000`,
+		"   ",
+		"mem value=4i 4000000000",
+		"mem value=5i 5000000000",
+		`mem\`,
+	}, "\n")
+
+	// Read a byte at a time to exercise lines spanning reads.
+	r := models.NewPointReader(iotest.OneByteReader(strings.NewReader(input)), time.Unix(0, 0), "n")
+
+	var (
+		batches [][]string
+		written []int
+		lines   []int
+	)
+	for {
+		points, failed, err := r.ReadPoints(2)
+		var batch []string
+		for _, p := range points {
+			batch = append(batch, p.String())
+		}
+		batches = append(batches, batch)
+		written = append(written, r.Lines()...)
+		for _, e := range failed {
+			lines = append(lines, e.Line)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if exp := [][]string{
+		{"cpu value=1 1000000000", `log msg="a` + "\n" + `b" 3000000000`},
+		{"mem value=4i 4000000000", "mem value=5i 5000000000"},
+		nil,
+	}; !reflect.DeepEqual(batches, exp) {
+		t.Fatalf("unexpected points:\n\nexp=%q\n\ngot=%q", exp, batches)
+	}
+	if exp := []int{2, 5, 8, 9}; !reflect.DeepEqual(written, exp) {
+		t.Fatalf("unexpected point lines: exp=%v, got=%v", exp, written)
+	}
+	if exp := []int{4, 10}; !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("unexpected rejected lines: exp=%v, got=%v", exp, lines)
+	}
+}
+
+// Ensure the point reader rejects lines longer than its maximum line size.
+func TestPointReader_MaxLineSize(t *testing.T) {
+	r := models.NewPointReader(strings.NewReader("cpu value=1\ncpu,host="+strings.Repeat("a", 1000)+" value=2\n"), time.Unix(0, 0), "n")
+	r.MaxLineSize = 100
+
+	if points, _, err := r.ReadPoints(1); err != nil {
+		t.Fatal(err)
+	} else if len(points) != 1 {
+		t.Fatalf("unexpected points: %v", points)
+	}
+
+	if _, _, err := r.ReadPoints(1); err != models.ErrLineTooLong {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure points without a timestamp are given the time they are read at when
+// the default time is zero.
+func TestPointReader_ZeroDefaultTime(t *testing.T) {
+	before := time.Now()
+	r := models.NewPointReader(strings.NewReader("cpu value=1\n"), time.Time{}, "n")
+	points, _, err := r.ReadPoints(1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(points) != 1 {
+		t.Fatalf("got %d points, expected 1", len(points))
+	}
+
+	if ts := points[0].Time(); ts.Before(before) || ts.After(time.Now()) {
+		t.Fatalf("unexpected time: %s", ts)
+	}
+}
@@ -0,0 +1,262 @@
+// Package prometheus converts between Prometheus remote storage requests and
+// InfluxDB points and queries.
+package prometheus // import "github.com/darshanman40/influxdb/prometheus"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/prometheus/remote"
+)
+
+const (
+	// MetricNameLabel is the label holding the name of a Prometheus metric.
+	// It is stored as the measurement name of the metric's points.
+	MetricNameLabel = "__name__"
+
+	// FieldName is the field that Prometheus sample values are written to.
+	FieldName = "value"
+
+	// reservedLabelPrefix starts the names of the labels reserved for
+	// Prometheus' internal use.
+	reservedLabelPrefix = "__"
+)
+
+// ErrNaNDropped is returned by WriteRequestToPoints when samples were
+// dropped because their value was NaN or infinite.
+var ErrNaNDropped = errors.New("dropped NaN or infinite sample values")
+
+// WriteRequestToPoints converts a Prometheus remote write request to points.
+// The metric name becomes the measurement, the other labels become tags and
+// each sample value is written to FieldName. Samples with NaN or infinite
+// values can't be stored and are dropped, in which case the converted points
+// are returned along with ErrNaNDropped.
+//
+// Series repeating a label, labels reserved by Prometheus other than the
+// metric name, and series sent more than once in the request are rejected.
+func WriteRequestToPoints(req *remote.WriteRequest) ([]models.Point, error) {
+	var maxPoints int
+	for _, ts := range req.Timeseries {
+		maxPoints += len(ts.Samples)
+	}
+	points := make([]models.Point, 0, maxPoints)
+
+	var dropped bool
+	series := make(map[string]struct{}, len(req.Timeseries))
+	for _, ts := range req.Timeseries {
+		var name string
+		tags := make(map[string]string, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == MetricNameLabel {
+				if name != "" {
+					return nil, fmt.Errorf("time series has more than one %s label", MetricNameLabel)
+				}
+				name = l.Value
+				continue
+			} else if strings.HasPrefix(l.Name, reservedLabelPrefix) {
+				return nil, fmt.Errorf("time series has reserved label %s", l.Name)
+			} else if _, ok := tags[l.Name]; ok {
+				return nil, fmt.Errorf("time series has more than one %s label", l.Name)
+			}
+			tags[l.Name] = l.Value
+		}
+		if name == "" {
+			return nil, fmt.Errorf("time series is missing the %s label", MetricNameLabel)
+		}
+
+		key := string(models.MakeKey([]byte(name), models.NewTags(tags)))
+		if _, ok := series[key]; ok {
+			return nil, fmt.Errorf("time series %s is repeated", key)
+		}
+		series[key] = struct{}{}
+
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				dropped = true
+				continue
+			}
+
+			p, err := models.NewPoint(name, models.NewTags(tags), map[string]interface{}{FieldName: s.Value}, time.Unix(0, s.TimestampMs*int64(time.Millisecond)))
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, p)
+		}
+	}
+
+	if dropped {
+		return points, ErrNaNDropped
+	}
+	return points, nil
+}
+
+// ReadRequestToInfluxQLQuery converts a Prometheus remote read request to a
+// query with one SELECT statement for each of the request's queries. Results
+// should be converted with RowsToTimeSeries along with the request query of
+// the same index.
+func ReadRequestToInfluxQLQuery(req *remote.ReadRequest, db, rp string) (*influxql.Query, error) {
+	q := &influxql.Query{}
+	for _, rq := range req.Queries {
+		stmt, err := queryToSelectStatement(rq, db, rp)
+		if err != nil {
+			return nil, err
+		}
+		q.Statements = append(q.Statements, stmt)
+	}
+	return q, nil
+}
+
+// queryToSelectStatement returns a statement selecting the raw values of the
+// series matched by q. Matchers on the metric name select the measurements;
+// the others are converted to a condition on tags.
+func queryToSelectStatement(q *remote.Query, db, rp string) (*influxql.SelectStatement, error) {
+	mm := &influxql.Measurement{Database: db, RetentionPolicy: rp, Regex: &influxql.RegexLiteral{Val: regexp.MustCompile(`.+`)}}
+
+	var cond influxql.Expr = &influxql.BinaryExpr{
+		Op:  influxql.AND,
+		LHS: &influxql.BinaryExpr{Op: influxql.GTE, LHS: &influxql.VarRef{Val: "time"}, RHS: &influxql.TimeLiteral{Val: msToTime(q.StartTimestampMs)}},
+		RHS: &influxql.BinaryExpr{Op: influxql.LTE, LHS: &influxql.VarRef{Val: "time"}, RHS: &influxql.TimeLiteral{Val: msToTime(q.EndTimestampMs)}},
+	}
+
+	for _, m := range q.Matchers {
+		if m.Name == MetricNameLabel {
+			// Negated name matchers can't be expressed as a source and are
+			// applied to the results by RowsToTimeSeries instead.
+			switch m.Type {
+			case remote.MatchType_EQUAL:
+				mm.Name, mm.Regex = m.Value, nil
+			case remote.MatchType_REGEX_MATCH:
+				re, err := anchoredRegex(m.Value)
+				if err != nil {
+					return nil, err
+				}
+				mm.Regex = &influxql.RegexLiteral{Val: re}
+			}
+			continue
+		}
+
+		expr, err := matcherToExpr(m)
+		if err != nil {
+			return nil, err
+		}
+		cond = &influxql.BinaryExpr{Op: influxql.AND, LHS: cond, RHS: expr}
+	}
+
+	return &influxql.SelectStatement{
+		Fields:     []*influxql.Field{{Expr: &influxql.VarRef{Val: FieldName}}},
+		Sources:    []influxql.Source{mm},
+		Dimensions: []*influxql.Dimension{{Expr: &influxql.Wildcard{}}},
+		Condition:  cond,
+		IsRawQuery: true,
+	}, nil
+}
+
+// matcherToExpr converts a label matcher to a condition on the label's tag.
+func matcherToExpr(m *remote.LabelMatcher) (influxql.Expr, error) {
+	tag := &influxql.VarRef{Val: m.Name}
+	switch m.Type {
+	case remote.MatchType_EQUAL:
+		return &influxql.BinaryExpr{Op: influxql.EQ, LHS: tag, RHS: &influxql.StringLiteral{Val: m.Value}}, nil
+	case remote.MatchType_NOT_EQUAL:
+		return &influxql.BinaryExpr{Op: influxql.NEQ, LHS: tag, RHS: &influxql.StringLiteral{Val: m.Value}}, nil
+	case remote.MatchType_REGEX_MATCH, remote.MatchType_REGEX_NO_MATCH:
+		re, err := anchoredRegex(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		op := influxql.EQREGEX
+		if m.Type == remote.MatchType_REGEX_NO_MATCH {
+			op = influxql.NEQREGEX
+		}
+		return &influxql.BinaryExpr{Op: op, LHS: tag, RHS: &influxql.RegexLiteral{Val: re}}, nil
+	default:
+		return nil, fmt.Errorf("unknown match type %v", m.Type)
+	}
+}
+
+// RowsToTimeSeries converts the rows returned for the SELECT statement of a
+// request query to Prometheus time series. Rows of measurements excluded by
+// negated matchers on the metric name are skipped.
+func RowsToTimeSeries(q *remote.Query, rows models.Rows) ([]*remote.TimeSeries, error) {
+	var a []*remote.TimeSeries
+	for _, row := range rows {
+		if ok, err := matchName(q, row.Name); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		ts := &remote.TimeSeries{
+			Labels: []*remote.LabelPair{{Name: MetricNameLabel, Value: row.Name}},
+		}
+		for k, v := range row.Tags {
+			if v == "" {
+				continue
+			}
+			ts.Labels = append(ts.Labels, &remote.LabelPair{Name: k, Value: v})
+		}
+
+		for _, v := range row.Values {
+			t, ok := v[0].(time.Time)
+			if !ok {
+				continue
+			}
+
+			var value float64
+			switch x := v[1].(type) {
+			case float64:
+				value = x
+			case int64:
+				value = float64(x)
+			default:
+				continue
+			}
+			ts.Samples = append(ts.Samples, &remote.Sample{
+				TimestampMs: t.UnixNano() / int64(time.Millisecond),
+				Value:       value,
+			})
+		}
+		a = append(a, ts)
+	}
+	return a, nil
+}
+
+// matchName returns true if name satisfies the negated metric name matchers of q.
+func matchName(q *remote.Query, name string) (bool, error) {
+	for _, m := range q.Matchers {
+		if m.Name != MetricNameLabel {
+			continue
+		}
+
+		switch m.Type {
+		case remote.MatchType_NOT_EQUAL:
+			if name == m.Value {
+				return false, nil
+			}
+		case remote.MatchType_REGEX_NO_MATCH:
+			re, err := anchoredRegex(m.Value)
+			if err != nil {
+				return false, err
+			} else if re.MatchString(name) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// anchoredRegex compiles a label matcher regular expression, which must match
+// the whole label value.
+func anchoredRegex(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+func msToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
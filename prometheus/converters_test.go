@@ -0,0 +1,139 @@
+package prometheus_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/prometheus"
+	"github.com/darshanman40/influxdb/prometheus/remote"
+)
+
+func TestWriteRequestToPoints(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{{
+			Labels: []*remote.LabelPair{
+				{Name: "__name__", Value: "http_requests_total"},
+				{Name: "job", Value: "api"},
+			},
+			Samples: []*remote.Sample{
+				{TimestampMs: 1000, Value: 1},
+				{TimestampMs: 2000, Value: 2},
+			},
+		}},
+	}
+
+	points, err := prometheus.WriteRequestToPoints(req)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(points) != 2 {
+		t.Fatalf("unexpected point count: %d", len(points))
+	} else if exp := "http_requests_total,job=api value=1 1000000000"; points[0].String() != exp {
+		t.Fatalf("unexpected point:\n\texp=%s\n\tgot=%s", exp, points[0].String())
+	} else if exp := "http_requests_total,job=api value=2 2000000000"; points[1].String() != exp {
+		t.Fatalf("unexpected point:\n\texp=%s\n\tgot=%s", exp, points[1].String())
+	}
+}
+
+func TestWriteRequestToPoints_NaN(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{{
+			Labels: []*remote.LabelPair{{Name: "__name__", Value: "up"}},
+			Samples: []*remote.Sample{
+				{TimestampMs: 1000, Value: 1},
+				{TimestampMs: 2000, Value: math.NaN()},
+			},
+		}},
+	}
+
+	points, err := prometheus.WriteRequestToPoints(req)
+	if err != prometheus.ErrNaNDropped {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(points) != 1 {
+		t.Fatalf("unexpected point count: %d", len(points))
+	}
+}
+
+func TestWriteRequestToPoints_InvalidLabels(t *testing.T) {
+	for i, labels := range [][][]*remote.LabelPair{
+		{{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}, {Name: "job", Value: "db"}}},
+		{{{Name: "__name__", Value: "up"}, {Name: "__name__", Value: "down"}}},
+		{{{Name: "__name__", Value: "up"}, {Name: "__scheme__", Value: "http"}}},
+		{
+			{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}},
+			{{Name: "job", Value: "api"}, {Name: "__name__", Value: "up"}},
+		},
+	} {
+		req := &remote.WriteRequest{}
+		for _, l := range labels {
+			req.Timeseries = append(req.Timeseries, &remote.TimeSeries{
+				Labels:  l,
+				Samples: []*remote.Sample{{TimestampMs: 1000, Value: 1}},
+			})
+		}
+
+		if _, err := prometheus.WriteRequestToPoints(req); err == nil {
+			t.Fatalf("%d. expected error", i)
+		}
+	}
+}
+
+func TestReadRequestToInfluxQLQuery(t *testing.T) {
+	req := &remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000,
+			EndTimestampMs:   2000,
+			Matchers: []*remote.LabelMatcher{
+				{Name: "__name__", Value: "http_requests_total"},
+				{Name: "job", Value: "api"},
+				{Type: remote.MatchType_NOT_EQUAL, Name: "env", Value: "dev"},
+				{Type: remote.MatchType_REGEX_MATCH, Name: "host", Value: "server.*"},
+				{Type: remote.MatchType_REGEX_NO_MATCH, Name: "dc", Value: "us"},
+			},
+		}},
+	}
+
+	q, err := prometheus.ReadRequestToInfluxQLQuery(req, "db0", "rp0")
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := `SELECT value FROM db0.rp0.http_requests_total WHERE time >= '1970-01-01T00:00:01Z' AND time <= '1970-01-01T00:00:02Z' AND job = 'api' AND env != 'dev' AND host =~ /^(?:server.*)$/ AND dc !~ /^(?:us)$/ GROUP BY *`; q.String() != exp {
+		t.Fatalf("unexpected query:\n\texp=%s\n\tgot=%s", exp, q.String())
+	}
+
+	// Name regexes select measurements by regex.
+	req.Queries[0].Matchers = []*remote.LabelMatcher{{Type: remote.MatchType_REGEX_MATCH, Name: "__name__", Value: "http_.*"}}
+	if q, err := prometheus.ReadRequestToInfluxQLQuery(req, "db0", "rp0"); err != nil {
+		t.Fatal(err)
+	} else if exp := `SELECT value FROM db0.rp0./^(?:http_.*)$/ WHERE time >= '1970-01-01T00:00:01Z' AND time <= '1970-01-01T00:00:02Z' GROUP BY *`; q.String() != exp {
+		t.Fatalf("unexpected query:\n\texp=%s\n\tgot=%s", exp, q.String())
+	}
+}
+
+func TestRowsToTimeSeries(t *testing.T) {
+	q := &remote.Query{
+		Matchers: []*remote.LabelMatcher{{Type: remote.MatchType_NOT_EQUAL, Name: "__name__", Value: "up"}},
+	}
+	rows := models.Rows{
+		{
+			Name:    "http_requests_total",
+			Tags:    map[string]string{"job": "api", "env": ""},
+			Columns: []string{"time", "value"},
+			Values:  [][]interface{}{{time.Unix(1, 0).UTC(), float64(1)}},
+		},
+		{
+			Name:    "up",
+			Columns: []string{"time", "value"},
+			Values:  [][]interface{}{{time.Unix(1, 0).UTC(), float64(1)}},
+		},
+	}
+
+	ts, err := prometheus.RowsToTimeSeries(q, rows)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(ts) != 1 {
+		t.Fatalf("unexpected time series count: %d", len(ts))
+	} else if s := ts[0].String(); s != `labels:<name:"__name__" value:"http_requests_total" > labels:<name:"job" value:"api" > samples:<value:1 timestamp_ms:1000 > ` {
+		t.Fatalf("unexpected time series: %s", s)
+	}
+}
@@ -0,0 +1,3 @@
+package remote
+
+//go:generate protoc --gogo_out=. remote.proto
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/darshanman40/influxdb/toml"
+)
+
+// Destinations of the audit log.
+const (
+	// DestinationFile writes the audit log to a rotating file.
+	DestinationFile = "file"
+
+	// DestinationDatabase writes the audit log to a database.
+	DestinationDatabase = "database"
+)
+
+const (
+	// DefaultDestination is the default destination of the audit log.
+	DefaultDestination = DestinationFile
+
+	// DefaultMaxSize is the default size of the audit log file before it is
+	// rotated.
+	DefaultMaxSize = 100 * 1024 * 1024
+
+	// DefaultMaxBackups is the default number of rotated audit log files kept.
+	DefaultMaxBackups = 5
+
+	// DefaultDatabase is the default database the audit log is written to.
+	DefaultDatabase = "_audit"
+
+	// DefaultFailureBufferSize is the default number of authentication
+	// failures buffered to be written to the audit log. Failures beyond it
+	// are counted rather than recorded.
+	DefaultFailureBufferSize = 1000
+)
+
+// Config represents the configuration for the audit log.
+type Config struct {
+	Enabled     bool   `toml:"enabled"`
+	Destination string `toml:"destination"`
+
+	// HashKey is the secret key of the HMAC chaining the entries of the log.
+	HashKey string `toml:"hash-key"`
+
+	// FailureBufferSize is the number of authentication failures buffered
+	// to be written to the log.
+	FailureBufferSize int `toml:"failure-buffer-size"`
+
+	// Path, MaxSize and MaxBackups configure the file destination. Rotated
+	// files are renamed with a numbered suffix, path.1 being the newest.
+	Path       string    `toml:"path"`
+	MaxSize    toml.Size `toml:"max-size"`
+	MaxBackups int       `toml:"max-backups"`
+
+	// Database configures the database destination.
+	Database string `toml:"database"`
+}
+
+// NewConfig returns an instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Destination: DefaultDestination,
+		MaxSize:     DefaultMaxSize,
+		MaxBackups:  DefaultMaxBackups,
+		Database:    DefaultDatabase,
+
+		FailureBufferSize: DefaultFailureBufferSize,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.HashKey == "" {
+		return errors.New("hash-key must be specified")
+	} else if c.FailureBufferSize <= 0 {
+		return errors.New("failure-buffer-size must be positive")
+	}
+
+	switch c.Destination {
+	case DestinationFile:
+		if c.Path == "" {
+			return errors.New("path must be specified")
+		} else if c.MaxSize <= 0 {
+			return errors.New("max-size must be positive")
+		} else if c.MaxBackups < 0 {
+			return errors.New("max-backups must not be negative")
+		}
+	case DestinationDatabase:
+		if c.Database == "" {
+			return errors.New("database must be specified")
+		}
+	default:
+		return fmt.Errorf("invalid destination %q, expected %q or %q", c.Destination, DestinationFile, DestinationDatabase)
+	}
+
+	return nil
+}
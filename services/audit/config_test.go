@@ -0,0 +1,73 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/darshanman40/influxdb/services/audit"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	c := audit.NewConfig()
+	if _, err := toml.Decode(`
+enabled = true
+destination = "file"
+hash-key = "secret"
+path = "/var/log/influxdb/audit.log"
+max-size = "10m"
+max-backups = 3
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled: %v", c.Enabled)
+	} else if c.Destination != audit.DestinationFile {
+		t.Fatalf("unexpected destination: %s", c.Destination)
+	} else if c.Path != "/var/log/influxdb/audit.log" {
+		t.Fatalf("unexpected path: %s", c.Path)
+	} else if c.MaxSize != 10*1024*1024 {
+		t.Fatalf("unexpected max size: %d", c.MaxSize)
+	} else if c.MaxBackups != 3 {
+		t.Fatalf("unexpected max backups: %d", c.MaxBackups)
+	} else if c.Database != audit.DefaultDatabase {
+		t.Fatalf("unexpected database: %s", c.Database)
+	} else if c.HashKey != "secret" {
+		t.Fatalf("unexpected hash key: %s", c.HashKey)
+	} else if c.FailureBufferSize != audit.DefaultFailureBufferSize {
+		t.Fatalf("unexpected failure buffer size: %d", c.FailureBufferSize)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		err string
+	}{
+		{s: `enabled = false`},
+		{s: "enabled = true\nhash-key = \"k\"\npath = \"audit.log\""},
+		{s: "enabled = true\nhash-key = \"k\"\ndestination = \"database\""},
+		{s: "enabled = true\npath = \"audit.log\"", err: "hash-key must be specified"},
+		{s: "enabled = true\nhash-key = \"k\"\npath = \"audit.log\"\nfailure-buffer-size = 0", err: "failure-buffer-size must be positive"},
+		{s: "enabled = true\nhash-key = \"k\"", err: "path must be specified"},
+		{s: "enabled = true\nhash-key = \"k\"\npath = \"audit.log\"\nmax-backups = -1", err: "max-backups must not be negative"},
+		{s: "enabled = true\nhash-key = \"k\"\ndestination = \"database\"\ndatabase = \"\"", err: "database must be specified"},
+		{s: "enabled = true\nhash-key = \"k\"\ndestination = \"syslog\"", err: `invalid destination "syslog", expected "file" or "database"`},
+	} {
+		c := audit.NewConfig()
+		if _, err := toml.Decode(tt.s, &c); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.Validate(); tt.err == "" && err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.s, err)
+		} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%q: unexpected error: got %v, exp %s", tt.s, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+// Package audit provides the audit log of administrative and data-modifying
+// statements and of authentication failures.
+package audit // import "github.com/darshanman40/influxdb/services/audit"
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+	"go.uber.org/zap"
+)
+
+// Events recorded in the audit log.
+const (
+	// EventStatement is the execution of a statement.
+	EventStatement = "statement"
+
+	// EventAuthentication is a failed authentication.
+	EventAuthentication = "authentication"
+
+	// EventAuthorization is a request refused for lack of privileges.
+	EventAuthorization = "authorization"
+)
+
+// ResultOK is the result of successful events.
+const ResultOK = "ok"
+
+// Entry is a record of the audit log.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	User       string    `json:"user,omitempty"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	Database   string    `json:"database,omitempty"`
+	Statement  string    `json:"statement,omitempty"`
+	Result     string    `json:"result"`
+
+	// Hash chains the entries of the log to make it tamper-evident. It is
+	// the hex encoded HMAC-SHA256, keyed with the configured hash key, of the
+	// hash of the previous entry followed by the JSON encoding of the entry
+	// without its hash.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Result returns the result of an event failing with err, if any.
+func Result(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ResultOK
+}
+
+// chain sets the hash of e, keyed with key, following the entry with hash
+// prev, and returns the JSON encoding of e.
+func (e *Entry) chain(key []byte, prev string) ([]byte, error) {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(prev))
+	h.Write(b)
+	e.Hash = hex.EncodeToString(h.Sum(nil))
+
+	return json.Marshal(e)
+}
+
+// Verify checks the hash chain, keyed with key, of the JSON lines audit log
+// read from r, whose first entry follows the entry with hash prev. It returns
+// the hash of the last entry, or an error for the first entry that was
+// modified, removed or inserted.
+func Verify(r io.Reader, key []byte, prev string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return "", fmt.Errorf("line %d: %s", n, err)
+		}
+
+		hash := e.Hash
+		if _, err := e.chain(key, prev); err != nil {
+			return "", fmt.Errorf("line %d: %s", n, err)
+		} else if !hmac.Equal([]byte(e.Hash), []byte(hash)) {
+			return "", fmt.Errorf("line %d: hash mismatch", n)
+		}
+		prev = hash
+	}
+	return prev, scanner.Err()
+}
+
+// Service writes the audit log to a rotating file or to a database.
+type Service struct {
+	MetaClient interface {
+		CreateDatabase(name string) (*meta.DatabaseInfo, error)
+	}
+
+	PointsWriter interface {
+		WritePoints(database, retentionPolicy string, points models.Points) error
+	}
+
+	mu       sync.Mutex
+	config   Config
+	file     *os.File
+	size     int64
+	prev     string // Hash of the last entry.
+	dbExists bool
+
+	// Authentication failures are written asynchronously, so that clients
+	// failing to authenticate don't wait on the log, and are counted in
+	// dropped when the buffer is full.
+	failures chan Entry
+	dropped  int64
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	Logger zap.Logger
+}
+
+// NewService returns a configured audit log service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		Logger: *zap.NewNop(),
+	}
+}
+
+// Open opens the audit log file, continuing the hash chain of its entries.
+func (s *Service) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Logger.Info(fmt.Sprintf("Starting audit log to %s", s.config.Destination))
+	if s.config.Destination == DestinationFile {
+		if err := s.openDestinationFile(); err != nil {
+			return err
+		}
+	}
+
+	size := s.config.FailureBufferSize
+	if size <= 0 {
+		size = DefaultFailureBufferSize
+	}
+	s.failures = make(chan Entry, size)
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go s.processFailures()
+	return nil
+}
+
+// openDestinationFile opens the audit log file destination, continuing the
+// hash chain of its entries.
+func (s *Service) openDestinationFile() error {
+	if err := os.MkdirAll(filepath.Dir(s.config.Path), 0700); err != nil {
+		return err
+	}
+
+	prev, err := lastHash(s.config.Path)
+	if err != nil {
+		return err
+	}
+	s.prev = prev
+
+	return s.openFile()
+}
+
+// openFile opens the audit log file for appending.
+func (s *Service) openFile() error {
+	f, err := os.OpenFile(s.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	s.file, s.size = f, fi.Size()
+	return nil
+}
+
+// lastHash returns the hash of the last entry of the audit log file at path.
+func lastHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := scanner.Err(); err != nil || last == nil {
+		return "", err
+	}
+
+	var e Entry
+	if err := json.Unmarshal(last, &e); err != nil {
+		return "", fmt.Errorf("invalid audit log %s: %s", path, err)
+	}
+	return e.Hash, nil
+}
+
+// Close writes the buffered authentication failures and closes the audit
+// log file.
+func (s *Service) Close() error {
+	if s.done != nil {
+		close(s.done)
+		s.wg.Wait()
+		s.done = nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.Logger = *log.With(zap.String("service", "audit"))
+}
+
+// Log records an entry. Errors are logged since they must not fail the
+// audited operation. Authentication failures are buffered and written
+// asynchronously, and only counted if the buffer is full.
+func (s *Service) Log(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	if e.Event == EventAuthentication {
+		select {
+		case s.failures <- e:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+		return
+	}
+	s.log(e)
+}
+
+// processFailures writes the buffered authentication failures until the
+// service closes, along with the number of failures that were dropped.
+func (s *Service) processFailures() {
+	defer s.wg.Done()
+	for {
+		select {
+		case e := <-s.failures:
+			s.logFailure(e)
+		case <-s.done:
+			for {
+				select {
+				case e := <-s.failures:
+					s.logFailure(e)
+				default:
+					s.logDropped()
+					return
+				}
+			}
+		}
+	}
+}
+
+// logFailure writes an authentication failure, preceded by the number of
+// failures dropped before it, if any.
+func (s *Service) logFailure(e Entry) {
+	s.logDropped()
+	s.log(e)
+}
+
+// logDropped records the number of authentication failures dropped since it
+// was last recorded.
+func (s *Service) logDropped() {
+	if n := atomic.SwapInt64(&s.dropped, 0); n > 0 {
+		s.log(Entry{
+			Time:   time.Now().UTC(),
+			Event:  EventAuthentication,
+			Result: fmt.Sprintf("%d authentication failures dropped", n),
+		})
+	}
+}
+
+// log writes an entry to the destination of the log.
+func (s *Service) log(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := e.chain([]byte(s.config.HashKey), s.prev)
+	if err != nil {
+		s.Logger.Info(fmt.Sprintf("failed to encode audit log entry: %s", err))
+		return
+	}
+
+	switch s.config.Destination {
+	case DestinationFile:
+		err = s.writeFile(append(b, '\n'))
+	case DestinationDatabase:
+		err = s.writePoint(&e)
+	}
+	if err != nil {
+		s.Logger.Info(fmt.Sprintf("failed to write audit log entry: %s", err))
+		return
+	}
+	s.prev = e.Hash
+}
+
+// writeFile appends line to the audit log file, rotating it first if it
+// would exceed its maximum size.
+func (s *Service) writeFile(line []byte) error {
+	if s.file == nil {
+		return fmt.Errorf("audit log closed")
+	}
+
+	if s.size > 0 && s.size+int64(len(line)) > int64(s.config.MaxSize) {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotate renames the audit log file to path.1, shifting older files and
+// removing the ones beyond the maximum number of backups, and opens a new
+// file.
+func (s *Service) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	path := s.config.Path
+	if s.config.MaxBackups == 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return s.openFile()
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", path, s.config.MaxBackups))
+	for i := s.config.MaxBackups - 1; i > 0; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return err
+	}
+	return s.openFile()
+}
+
+// writePoint writes the entry as a point of the audit database, creating
+// the database first if needed.
+func (s *Service) writePoint(e *Entry) error {
+	if !s.dbExists {
+		if _, err := s.MetaClient.CreateDatabase(s.config.Database); err != nil {
+			return err
+		}
+		s.dbExists = true
+	}
+
+	tags := map[string]string{"event": e.Event}
+	if e.User != "" {
+		tags["user"] = e.User
+	}
+	fields := map[string]interface{}{
+		"remote_addr": e.RemoteAddr,
+		"database":    e.Database,
+		"statement":   e.Statement,
+		"result":      e.Result,
+		"hash":        e.Hash,
+	}
+
+	pt, err := models.NewPoint("audit", models.NewTags(tags), fields, e.Time)
+	if err != nil {
+		return err
+	}
+
+	if err := s.PointsWriter.WritePoints(s.config.Database, "", models.Points{pt}); err != nil {
+		// The database may have been dropped, so create it again next time.
+		s.dbExists = false
+		return err
+	}
+	return nil
+}
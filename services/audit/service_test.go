@@ -0,0 +1,239 @@
+package audit_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/audit"
+	"github.com/darshanman40/influxdb/services/meta"
+)
+
+// Ensure entries are written to the file as a hash chain which continues
+// across rotations and restarts.
+func TestService_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxdb-audit-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := audit.NewConfig()
+	c.Enabled = true
+	c.Path = filepath.Join(dir, "audit.log")
+	c.HashKey = "secret"
+	c.MaxSize = 500
+	c.MaxBackups = 1
+
+	s := audit.NewService(c)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		s.Log(audit.Entry{
+			Time:       time.Unix(0, 0).UTC(),
+			Event:      audit.EventStatement,
+			User:       "admin",
+			RemoteAddr: "127.0.0.1:1234",
+			Statement:  "DROP DATABASE db0",
+			Result:     audit.Result(nil),
+		})
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the log to ensure the chain is continued.
+	s = audit.NewService(c)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	s.Log(audit.Entry{Event: audit.EventAuthentication, User: "bob", Result: "authorization failed"})
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries were rotated to a single backup.
+	if _, err := os.Stat(c.Path + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("unexpected second backup: %v", err)
+	}
+	backup := MustReadFile(c.Path + ".1")
+	current := MustReadFile(c.Path)
+
+	key := []byte(c.HashKey)
+	prev, err := audit.Verify(bytes.NewReader(backup), key, "")
+	if err != nil {
+		t.Fatalf("unexpected error verifying backup: %s", err)
+	}
+	if _, err := audit.Verify(bytes.NewReader(current), key, prev); err != nil {
+		t.Fatalf("unexpected error verifying log: %s", err)
+	}
+	if !bytes.Contains(current, []byte(`"event":"authentication","user":"bob"`)) {
+		t.Fatalf("unexpected log: %s", current)
+	}
+
+	// Modifying an entry breaks the chain.
+	tampered := bytes.Replace(current, []byte("bob"), []byte("eve"), 1)
+	if _, err := audit.Verify(bytes.NewReader(tampered), key, prev); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The chain can't be verified, or forged, without the key.
+	if _, err := audit.Verify(bytes.NewReader(backup), []byte("other"), ""); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure entries are written as points to the audit database.
+func TestService_Database(t *testing.T) {
+	c := audit.NewConfig()
+	c.Enabled = true
+	c.Destination = audit.DestinationDatabase
+	c.HashKey = "secret"
+
+	var created int
+	var points models.Points
+	s := audit.NewService(c)
+	s.MetaClient = &MetaClient{
+		CreateDatabaseFn: func(name string) (*meta.DatabaseInfo, error) {
+			if name != audit.DefaultDatabase {
+				t.Fatalf("unexpected database: %s", name)
+			}
+			created++
+			return &meta.DatabaseInfo{Name: name}, nil
+		},
+	}
+	writeErr := errors.New("database not found")
+	s.PointsWriter = &PointsWriter{
+		WritePointsFn: func(database, retentionPolicy string, pts models.Points) error {
+			if database != audit.DefaultDatabase {
+				t.Fatalf("unexpected database: %s", database)
+			}
+			if writeErr != nil {
+				err := writeErr
+				writeErr = nil
+				return err
+			}
+			points = append(points, pts...)
+			return nil
+		},
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The first write fails so the database is created again.
+	s.Log(audit.Entry{Event: audit.EventStatement, User: "admin", Statement: "DROP DATABASE db0", Result: audit.ResultOK})
+	s.Log(audit.Entry{Event: audit.EventStatement, User: "admin", Statement: "DROP DATABASE db1", Result: audit.ResultOK})
+
+	if created != 2 {
+		t.Fatalf("unexpected database creations: %d", created)
+	} else if len(points) != 1 {
+		t.Fatalf("unexpected points: %v", points)
+	}
+
+	pt := points[0]
+	if pt.Name() != "audit" {
+		t.Fatalf("unexpected measurement: %s", pt.Name())
+	} else if v := pt.Tags().GetString("user"); v != "admin" {
+		t.Fatalf("unexpected user: %s", v)
+	} else if v := pt.Tags().GetString("event"); v != audit.EventStatement {
+		t.Fatalf("unexpected event: %s", v)
+	}
+	fields, err := pt.Fields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fields["statement"]; v != "DROP DATABASE db1" {
+		t.Fatalf("unexpected statement: %v", v)
+	} else if v, _ := fields["hash"].(string); len(v) != 64 {
+		t.Fatalf("unexpected hash: %v", v)
+	}
+}
+
+// Ensure authentication failures are written asynchronously, and counted
+// when the buffer is full.
+func TestService_AuthenticationFailures(t *testing.T) {
+	c := audit.NewConfig()
+	c.Enabled = true
+	c.Destination = audit.DestinationDatabase
+	c.HashKey = "secret"
+	c.FailureBufferSize = 1
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		results []string
+	)
+	s := audit.NewService(c)
+	s.MetaClient = &MetaClient{
+		CreateDatabaseFn: func(name string) (*meta.DatabaseInfo, error) {
+			return &meta.DatabaseInfo{Name: name}, nil
+		},
+	}
+	s.PointsWriter = &PointsWriter{
+		WritePointsFn: func(database, retentionPolicy string, pts models.Points) error {
+			// Block the first write until the buffer is full.
+			if len(results) == 0 {
+				close(started)
+				<-release
+			}
+			fields, _ := pts[0].Fields()
+			results = append(results, fields["result"].(string))
+			return nil
+		},
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Log(audit.Entry{Event: audit.EventAuthentication, User: "a", Result: "1"})
+	<-started
+	for i := 2; i <= 4; i++ {
+		s.Log(audit.Entry{Event: audit.EventAuthentication, User: "a", Result: strconv.Itoa(i)})
+	}
+	close(release)
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"1", "2 authentication failures dropped", "2"}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected entries: got %q, exp %q", results, exp)
+	}
+}
+
+// MetaClient is a mock of the meta client used by the service.
+type MetaClient struct {
+	CreateDatabaseFn func(name string) (*meta.DatabaseInfo, error)
+}
+
+func (c *MetaClient) CreateDatabase(name string) (*meta.DatabaseInfo, error) {
+	return c.CreateDatabaseFn(name)
+}
+
+// PointsWriter is a mock of the points writer used by the service.
+type PointsWriter struct {
+	WritePointsFn func(database, retentionPolicy string, points models.Points) error
+}
+
+func (w *PointsWriter) WritePoints(database, retentionPolicy string, points models.Points) error {
+	return w.WritePointsFn(database, retentionPolicy, points)
+}
+
+// MustReadFile returns the contents of a file or panics on error.
+func MustReadFile(path string) []byte {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -47,6 +47,14 @@ const (
 
 	// DefaultAuthFile is the default location of the user/password file.
 	DefaultAuthFile = "/etc/collectd/auth_file"
+
+	// DefaultParseMultiValuePlugin is the default way of parsing the values of
+	// multi-value types: each value into a point of its own.
+	DefaultParseMultiValuePlugin = "split"
+
+	// DefaultTypesDBReloadInterval is the default interval at which the types
+	// db files are checked for changes.
+	DefaultTypesDBReloadInterval = toml.Duration(10 * time.Second)
 )
 
 // Config represents a configuration for the collectd service.
@@ -62,6 +70,15 @@ type Config struct {
 	TypesDB         string        `toml:"typesdb"`
 	SecurityLevel   string        `toml:"security-level"`
 	AuthFile        string        `toml:"auth-file"`
+
+	// ParseMultiValuePlugin is either "split", to write each value of a
+	// multi-value type as a measurement of its own, or "join", to write the
+	// values as fields of one point.
+	ParseMultiValuePlugin string `toml:"parse-multivalue-plugin"`
+
+	// TypesDBReloadInterval is the interval at which the types db files are
+	// checked for changes and reloaded. Zero disables reloading.
+	TypesDBReloadInterval toml.Duration `toml:"typesdb-reload-interval"`
 }
 
 // NewConfig returns a new instance of Config with defaults.
@@ -77,6 +94,9 @@ func NewConfig() Config {
 		TypesDB:         DefaultTypesDB,
 		SecurityLevel:   DefaultSecurityLevel,
 		AuthFile:        DefaultAuthFile,
+
+		ParseMultiValuePlugin: DefaultParseMultiValuePlugin,
+		TypesDBReloadInterval: DefaultTypesDBReloadInterval,
 	}
 }
 
@@ -114,6 +134,9 @@ func (c *Config) WithDefaults() *Config {
 	if d.AuthFile == "" {
 		d.AuthFile = DefaultAuthFile
 	}
+	if d.ParseMultiValuePlugin == "" {
+		d.ParseMultiValuePlugin = DefaultParseMultiValuePlugin
+	}
 
 	return &d
 }
@@ -126,5 +149,11 @@ func (c *Config) Validate() error {
 		return errors.New("Invalid security level")
 	}
 
+	switch c.ParseMultiValuePlugin {
+	case "split", "join":
+	default:
+		return errors.New(`Invalid value for parse-multivalue-plugin. Valid options are "split" and "join"`)
+	}
+
 	return nil
 }
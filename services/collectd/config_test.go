@@ -30,3 +30,20 @@ typesdb = "yyy"
 		t.Fatalf("unexpected types db: %s", c.TypesDB)
 	}
 }
+
+func TestConfig_Validate_ParseMultiValuePlugin(t *testing.T) {
+	c := collectd.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.ParseMultiValuePlugin = "join"
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.ParseMultiValuePlugin = "merge"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -2,6 +2,7 @@
 package collectd // import "github.com/darshanman40/influxdb/services/collectd"
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -30,6 +31,8 @@ const (
 	statPointsTransmitted    = "pointsTx"
 	statBatchesTransmitFail  = "batchesTxFail"
 	statDroppedPointsInvalid = "droppedPointsInvalid"
+	statTypesDBReloads       = "typesDBReloads"
+	statTypesDBReloadFail    = "typesDBReloadFail"
 )
 
 // pointsWriter is an internal interface to make testing easier.
@@ -47,6 +50,7 @@ func TypesDBFile(path string) (typesdb *api.TypesDB, err error) {
 	var reader *os.File
 	reader, err = os.Open(path)
 	if err == nil {
+		defer reader.Close()
 		typesdb, err = api.NewTypesDB(reader)
 	}
 	return
@@ -63,9 +67,12 @@ type Service struct {
 	wg      sync.WaitGroup
 	conn    *net.UDPConn
 	batcher *tsdb.PointBatcher
-	popts   network.ParseOpts
 	addr    net.Addr
 
+	typesMu    sync.RWMutex
+	popts      network.ParseOpts
+	typesDBSig string // Sizes and modification times of the types db files.
+
 	mu    sync.RWMutex
 	ready bool          // Has the required database been created?
 	done  chan struct{} // Is the service closing or closed?
@@ -109,47 +116,17 @@ func (s *Service) Open() error {
 		return fmt.Errorf("PointsWriter is nil")
 	}
 
+	// Open collectd types, unless they were set, and reload them as they
+	// change.
+	var watch bool
 	if s.popts.TypesDB == nil {
-		// Open collectd types.
-		if stat, err := os.Stat(s.Config.TypesDB); err != nil {
-			return fmt.Errorf("Stat(): %s", err)
-		} else if stat.IsDir() {
-			alltypesdb := new(api.TypesDB)
-			var readdir func(path string)
-			readdir = func(path string) {
-				files, err := ioutil.ReadDir(path)
-				if err != nil {
-					s.Logger.Info(fmt.Sprintf("Unable to read directory %s: %s\n", path, err))
-					return
-				}
-
-				for _, f := range files {
-					fullpath := filepath.Join(path, f.Name())
-					if f.IsDir() {
-						readdir(fullpath)
-						continue
-					}
-
-					s.Logger.Info(fmt.Sprintf("Loading %s\n", fullpath))
-					types, err := TypesDBFile(fullpath)
-					if err != nil {
-						s.Logger.Info(fmt.Sprintf("Unable to parse collectd types file: %s\n", f.Name()))
-						continue
-					}
-
-					alltypesdb.Merge(types)
-				}
-			}
-			readdir(s.Config.TypesDB)
-			s.popts.TypesDB = alltypesdb
-		} else {
-			s.Logger.Info(fmt.Sprintf("Loading %s\n", s.Config.TypesDB))
-			types, err := TypesDBFile(s.Config.TypesDB)
-			if err != nil {
-				return fmt.Errorf("Open(): %s", err)
-			}
-			s.popts.TypesDB = types
+		types, sig, err := s.loadTypesDB()
+		if err != nil {
+			return err
 		}
+		s.popts.TypesDB = types
+		s.typesDBSig = sig
+		watch = s.Config.TypesDBReloadInterval > 0
 	}
 
 	// Sets the security level according to the config.
@@ -201,6 +178,10 @@ func (s *Service) Open() error {
 	s.wg.Add(2)
 	go func() { defer s.wg.Done(); s.serve() }()
 	go func() { defer s.wg.Done(); s.writePoints() }()
+	if watch {
+		s.wg.Add(1)
+		go func() { defer s.wg.Done(); s.watchTypesDB(time.Duration(s.Config.TypesDBReloadInterval)) }()
+	}
 
 	return nil
 }
@@ -277,6 +258,8 @@ type Statistics struct {
 	PointsTransmitted    int64
 	BatchesTransmitFail  int64
 	InvalidDroppedPoints int64
+	TypesDBReloads       int64
+	TypesDBReloadFail    int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -293,6 +276,8 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statPointsTransmitted:    atomic.LoadInt64(&s.stats.PointsTransmitted),
 			statBatchesTransmitFail:  atomic.LoadInt64(&s.stats.BatchesTransmitFail),
 			statDroppedPointsInvalid: atomic.LoadInt64(&s.stats.InvalidDroppedPoints),
+			statTypesDBReloads:       atomic.LoadInt64(&s.stats.TypesDBReloads),
+			statTypesDBReloadFail:    atomic.LoadInt64(&s.stats.TypesDBReloadFail),
 		},
 	}}
 }
@@ -300,8 +285,140 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 // SetTypes sets collectd types db.
 func (s *Service) SetTypes(types string) (err error) {
 	reader := strings.NewReader(types)
-	s.popts.TypesDB, err = api.NewTypesDB(reader)
-	return
+	typesdb, err := api.NewTypesDB(reader)
+	if err != nil {
+		return err
+	}
+
+	s.typesMu.Lock()
+	s.popts.TypesDB = typesdb
+	s.typesMu.Unlock()
+	return nil
+}
+
+// loadTypesDB loads the types db file, or every file of the types db
+// directory, of the config. It also returns the signature of the files.
+func (s *Service) loadTypesDB() (*api.TypesDB, string, error) {
+	stat, err := os.Stat(s.Config.TypesDB)
+	if err != nil {
+		return nil, "", fmt.Errorf("Stat(): %s", err)
+	}
+
+	files, err := typesDBFiles(s.Config.TypesDB)
+	if err != nil {
+		return nil, "", err
+	}
+	sig := typesDBSignature(files)
+
+	if !stat.IsDir() {
+		s.Logger.Info(fmt.Sprintf("Loading %s\n", s.Config.TypesDB))
+		types, err := TypesDBFile(s.Config.TypesDB)
+		if err != nil {
+			return nil, "", fmt.Errorf("Open(): %s", err)
+		}
+		return types, sig, nil
+	}
+
+	alltypesdb, err := api.NewTypesDB(strings.NewReader(""))
+	if err != nil {
+		return nil, "", err
+	}
+	for _, path := range files {
+		s.Logger.Info(fmt.Sprintf("Loading %s\n", path))
+		types, err := TypesDBFile(path)
+		if err != nil {
+			s.Logger.Info(fmt.Sprintf("Unable to parse collectd types file: %s\n", filepath.Base(path)))
+			continue
+		}
+
+		alltypesdb.Merge(types)
+	}
+	return alltypesdb, sig, nil
+}
+
+// watchTypesDB reloads the types db whenever its files change, checking
+// them at every interval. Packets keep being parsed with the previous types
+// until the new ones are loaded.
+func (s *Service) watchTypesDB(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		files, err := typesDBFiles(s.Config.TypesDB)
+		if err != nil {
+			continue
+		} else if typesDBSignature(files) == s.typesDBSig {
+			continue
+		}
+
+		types, sig, err := s.loadTypesDB()
+		if err != nil {
+			atomic.AddInt64(&s.stats.TypesDBReloadFail, 1)
+			s.Logger.Info(fmt.Sprintf("Unable to reload collectd types db: %s", err))
+			continue
+		}
+		s.typesDBSig = sig
+
+		s.typesMu.Lock()
+		s.popts.TypesDB = types
+		s.typesMu.Unlock()
+
+		atomic.AddInt64(&s.stats.TypesDBReloads, 1)
+		s.Logger.Info(fmt.Sprintf("Reloaded collectd types db %s", s.Config.TypesDB))
+	}
+}
+
+// typesDBFiles returns the path of the types db file, or the paths of the
+// files of the types db directory and its subdirectories. Subdirectories that
+// can't be read are skipped.
+func typesDBFiles(path string) ([]string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	} else if !stat.IsDir() {
+		return []string{path}, nil
+	}
+
+	var paths []string
+	var readdir func(path string) error
+	readdir = func(path string) error {
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			fullpath := filepath.Join(path, f.Name())
+			if f.IsDir() {
+				readdir(fullpath)
+				continue
+			}
+			paths = append(paths, fullpath)
+		}
+		return nil
+	}
+	if err := readdir(path); err != nil {
+		return nil, fmt.Errorf("Unable to read directory %s: %s", path, err)
+	}
+	return paths, nil
+}
+
+// typesDBSignature returns the paths, sizes and modification times of the
+// types db files, which change whenever a file is added, removed or modified.
+func typesDBSignature(paths []string) string {
+	var buf bytes.Buffer
+	for _, path := range paths {
+		if stat, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&buf, "%s:%d:%d\n", path, stat.Size(), stat.ModTime().UnixNano())
+		}
+	}
+	return buf.String()
 }
 
 // Addr returns the listener's address. It returns nil if listener is closed.
@@ -343,7 +460,11 @@ func (s *Service) serve() {
 }
 
 func (s *Service) handleMessage(buffer []byte) {
-	valueLists, err := network.Parse(buffer, s.popts)
+	s.typesMu.RLock()
+	popts := s.popts
+	s.typesMu.RUnlock()
+
+	valueLists, err := network.Parse(buffer, popts)
 	if err != nil {
 		atomic.AddInt64(&s.stats.PointsParseFail, 1)
 		s.Logger.Info(fmt.Sprintf("Collectd parse error: %s", err))
@@ -381,49 +502,84 @@ func (s *Service) writePoints() {
 	}
 }
 
-// UnmarshalValueList translates a ValueList into InfluxDB data points.
+// UnmarshalValueList translates a ValueList into InfluxDB data points. The
+// values of multi-value types are either split into a measurement each or
+// joined into the fields of one point, depending on the config.
 func (s *Service) UnmarshalValueList(vl *api.ValueList) []models.Point {
 	timestamp := vl.Time.UTC()
+	tags := valueListTags(vl)
+
+	if s.Config.ParseMultiValuePlugin == "join" {
+		fields := make(map[string]interface{}, len(vl.Values))
+		for i := range vl.Values {
+			if v, ok := floatValue(vl.Values[i]); ok {
+				fields[vl.DSName(i)] = v
+			}
+		}
+
+		if p := s.newPoint(vl.Identifier.Plugin, tags, fields, timestamp); p != nil {
+			return []models.Point{p}
+		}
+		return nil
+	}
 
 	var points []models.Point
 	for i := range vl.Values {
 		var name string
 		name = fmt.Sprintf("%s_%s", vl.Identifier.Plugin, vl.DSName(i))
-		tags := make(map[string]string)
 		fields := make(map[string]interface{})
 
 		// Convert interface back to actual type, then to float64
-		switch value := vl.Values[i].(type) {
-		case api.Gauge:
-			fields["value"] = float64(value)
-		case api.Derive:
-			fields["value"] = float64(value)
-		case api.Counter:
-			fields["value"] = float64(value)
+		if v, ok := floatValue(vl.Values[i]); ok {
+			fields["value"] = v
 		}
 
-		if vl.Identifier.Host != "" {
-			tags["host"] = vl.Identifier.Host
-		}
-		if vl.Identifier.PluginInstance != "" {
-			tags["instance"] = vl.Identifier.PluginInstance
-		}
-		if vl.Identifier.Type != "" {
-			tags["type"] = vl.Identifier.Type
-		}
-		if vl.Identifier.TypeInstance != "" {
-			tags["type_instance"] = vl.Identifier.TypeInstance
+		if p := s.newPoint(name, tags, fields, timestamp); p != nil {
+			points = append(points, p)
 		}
+	}
+	return points
+}
 
-		// Drop invalid points
-		p, err := models.NewPoint(name, models.NewTags(tags), fields, timestamp)
-		if err != nil {
-			s.Logger.Info(fmt.Sprintf("Dropping point %v: %v", name, err))
-			atomic.AddInt64(&s.stats.InvalidDroppedPoints, 1)
-			continue
-		}
+// newPoint returns a new point, or nil if the point is invalid.
+func (s *Service) newPoint(name string, tags map[string]string, fields map[string]interface{}, t time.Time) models.Point {
+	// Drop invalid points
+	p, err := models.NewPoint(name, models.NewTags(tags), fields, t)
+	if err != nil {
+		s.Logger.Info(fmt.Sprintf("Dropping point %v: %v", name, err))
+		atomic.AddInt64(&s.stats.InvalidDroppedPoints, 1)
+		return nil
+	}
+	return p
+}
 
-		points = append(points, p)
+// valueListTags returns the tags of the points of a ValueList.
+func valueListTags(vl *api.ValueList) map[string]string {
+	tags := make(map[string]string)
+	if vl.Identifier.Host != "" {
+		tags["host"] = vl.Identifier.Host
 	}
-	return points
+	if vl.Identifier.PluginInstance != "" {
+		tags["instance"] = vl.Identifier.PluginInstance
+	}
+	if vl.Identifier.Type != "" {
+		tags["type"] = vl.Identifier.Type
+	}
+	if vl.Identifier.TypeInstance != "" {
+		tags["type_instance"] = vl.Identifier.TypeInstance
+	}
+	return tags
+}
+
+// floatValue returns a collectd value as a float64.
+func floatValue(v api.Value) (float64, bool) {
+	switch v := v.(type) {
+	case api.Gauge:
+		return float64(v), true
+	case api.Derive:
+		return float64(v), true
+	case api.Counter:
+		return float64(v), true
+	}
+	return 0, false
 }
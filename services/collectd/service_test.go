@@ -3,8 +3,12 @@ package collectd
 import (
 	"encoding/hex"
 	"errors"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -265,6 +269,100 @@ Loop:
 	}
 }
 
+// Test that the values of multi-value types are joined into the fields of one
+// point in join mode.
+func TestService_ParseMultiValuePlugin_Join(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService(1, time.Second)
+	s.Service.Config.ParseMultiValuePlugin = "join"
+
+	pointCh := make(chan models.Point, 1000)
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		for _, p := range points {
+			pointCh <- p
+		}
+		return nil
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn, err := net.Dial("udp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(testData); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, exp := range expJoinedPoints {
+		select {
+		case p := <-pointCh:
+			if got := p.String(); got != exp {
+				t.Fatalf("point %d:\n\texp = %s\n\tgot = %s\n", i, exp, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for point %d", i)
+		}
+	}
+}
+
+// Test that the types db directory is reloaded when a file is added.
+func TestService_TypesDBReload(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "collectd-typesdb-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "types.db"), []byte("foo value:GAUGE:0:U\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewTestService(1, time.Second)
+	s.Service.popts.TypesDB = nil
+	s.Service.Config.TypesDB = dir
+	s.Service.Config.TypesDBReloadInterval = toml.Duration(10 * time.Millisecond)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	hasType := func(typ string) bool {
+		s.Service.typesMu.RLock()
+		defer s.Service.typesMu.RUnlock()
+		_, ok := s.Service.popts.TypesDB.DataSet(typ)
+		return ok
+	}
+	if !hasType("foo") {
+		t.Fatal("type foo not loaded")
+	} else if hasType("bar") {
+		t.Fatal("unexpected type bar")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "custom.db"), []byte("bar value:GAUGE:0:U\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for atomic.LoadInt64(&s.Service.stats.TypesDBReloads) == 0 {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for the types db to be reloaded")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if !hasType("foo") || !hasType("bar") {
+		t.Fatal("types not reloaded")
+	}
+}
+
 type TestService struct {
 	Service       *Service
 	Config        Config
@@ -403,6 +501,28 @@ var expPoints = []string{
 	"cpu_value,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=interrupt value=306 1414080767000000000",
 }
 
+var expJoinedPoints = []string{
+	"entropy,host=pf1-62-210-94-173,type=entropy value=288 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=1,type=cpu,type_instance=idle value=10908770 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=1,type=cpu,type_instance=wait value=0 1414080767000000000",
+	"df,host=pf1-62-210-94-173,type=df,type_instance=live-cow free=50287988736,used=378576896 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=1,type=cpu,type_instance=interrupt value=254 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=1,type=cpu,type_instance=softirq value=0 1414080767000000000",
+	"df,host=pf1-62-210-94-173,type=df,type_instance=live free=50666565632,used=0 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=1,type=cpu,type_instance=steal value=0 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=user value=24374 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=nice value=2776 1414080767000000000",
+	"interface,host=pf1-62-210-94-173,type=if_octets,type_instance=dummy0 rx=0,tx=1050 1414080767000000000",
+	"df,host=pf1-62-210-94-173,type=df,type_instance=tmp free=50666491904,used=73728 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=system value=17875 1414080767000000000",
+	"interface,host=pf1-62-210-94-173,type=if_packets,type_instance=dummy0 rx=0,tx=15 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=idle value=10904704 1414080767000000000",
+	"df,host=pf1-62-210-94-173,type=df,type_instance=run-lock free=5242880,used=0 1414080767000000000",
+	"interface,host=pf1-62-210-94-173,type=if_errors,type_instance=dummy0 rx=0,tx=0 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=wait value=0 1414080767000000000",
+	"cpu,host=pf1-62-210-94-173,instance=2,type=cpu,type_instance=interrupt value=306 1414080767000000000",
+}
+
 // Taken from /usr/share/collectd/types.db on a Ubuntu system
 var typesDBText = `
 absolute		value:ABSOLUTE:0:U
@@ -0,0 +1,267 @@
+package graphite
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// aggregationRuleRegex matches a Carbon aggregation rule:
+//
+//	output_template (frequency) = method input_pattern
+var aggregationRuleRegex = regexp.MustCompile(`^(\S+)\s+\((\d+)\)\s*=\s*(\S+)\s+(\S+)$`)
+
+// aggregationFieldRegex matches the <field> and <<field>> references of an
+// output template.
+var aggregationFieldRegex = regexp.MustCompile(`<<([^<>]+)>>|<([^<>]+)>`)
+
+// aggregationRule aggregates the values of the metrics matching an input
+// pattern into a metric named after an output template.
+type aggregationRule struct {
+	output    string
+	frequency time.Duration
+	method    string
+	input     *regexp.Regexp
+}
+
+// parseAggregationRule parses a rule in the format of Carbon's
+// aggregation-rules.conf, such as:
+//
+//	<env>.requests.all (60) = sum <env>.requests.<host>
+//
+// Within the input pattern, <field> matches one path node, <<field>> matches
+// one or more nodes and * matches any part of a node. The fields captured are
+// substituted into the output template.
+func parseAggregationRule(rule string) (*aggregationRule, error) {
+	m := aggregationRuleRegex.FindStringSubmatch(strings.TrimSpace(rule))
+	if m == nil {
+		return nil, fmt.Errorf("invalid aggregation rule: '%s'", rule)
+	}
+
+	frequency, err := strconv.Atoi(m[2])
+	if err != nil || frequency <= 0 {
+		return nil, fmt.Errorf("invalid aggregation frequency in rule: '%s'", rule)
+	}
+
+	switch m[3] {
+	case "sum", "avg", "min", "max", "count", "last":
+	default:
+		return nil, fmt.Errorf("unknown aggregation method '%s' in rule: '%s'", m[3], rule)
+	}
+
+	input, err := compileAggregationPattern(m[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid aggregation input pattern in rule '%s': %s", rule, err)
+	}
+
+	for _, f := range aggregationFieldRegex.FindAllString(m[1], -1) {
+		if subexpIndex(input, strings.Trim(f, "<>")) < 0 {
+			return nil, fmt.Errorf("aggregation output field %s is not in the input pattern of rule: '%s'", f, rule)
+		}
+	}
+
+	return &aggregationRule{
+		output:    m[1],
+		frequency: time.Duration(frequency) * time.Second,
+		method:    m[3],
+		input:     input,
+	}, nil
+}
+
+// compileAggregationPattern returns a regular expression matching the metric
+// paths of an input pattern.
+func compileAggregationPattern(pattern string) (*regexp.Regexp, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('^')
+	for i := 0; i < len(pattern); {
+		switch {
+		case strings.HasPrefix(pattern[i:], "<<"):
+			end := strings.Index(pattern[i:], ">>")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated field at position %d", i)
+			}
+			fmt.Fprintf(&buf, "(?P<%s>.+?)", pattern[i+2:i+end])
+			i += end + 2
+		case pattern[i] == '<':
+			end := strings.IndexByte(pattern[i:], '>')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated field at position %d", i)
+			}
+			fmt.Fprintf(&buf, "(?P<%s>[^.]+)", pattern[i+1:i+end])
+			i += end + 1
+		case pattern[i] == '*':
+			buf.WriteString("[^.]*")
+			i++
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			i++
+		}
+	}
+	buf.WriteByte('$')
+	return regexp.Compile(buf.String())
+}
+
+// match returns the output path of the metric path if it matches the rule.
+func (r *aggregationRule) match(path string) (string, bool) {
+	m := r.input.FindStringSubmatch(path)
+	if m == nil {
+		return "", false
+	}
+	return aggregationFieldRegex.ReplaceAllStringFunc(r.output, func(s string) string {
+		return m[subexpIndex(r.input, strings.Trim(s, "<>"))]
+	}), true
+}
+
+// subexpIndex returns the index of the named group of re, or -1.
+func subexpIndex(re *regexp.Regexp, name string) int {
+	for i, n := range re.SubexpNames() {
+		if i > 0 && n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// aggregationKey identifies the values aggregated into one output point.
+type aggregationKey struct {
+	rule  *aggregationRule
+	path  string
+	start int64
+}
+
+// aggregationBucket accumulates the values of an aggregation interval.
+type aggregationBucket struct {
+	sum, min, max, last float64
+	count               int
+}
+
+func (b *aggregationBucket) add(v float64) {
+	if b.count == 0 {
+		b.min, b.max = v, v
+	}
+	b.sum += v
+	b.min = math.Min(b.min, v)
+	b.max = math.Max(b.max, v)
+	b.last = v
+	b.count++
+}
+
+func (b *aggregationBucket) value(method string) float64 {
+	switch method {
+	case "avg":
+		return b.sum / float64(b.count)
+	case "min":
+		return b.min
+	case "max":
+		return b.max
+	case "count":
+		return float64(b.count)
+	case "last":
+		return b.last
+	default:
+		return b.sum
+	}
+}
+
+// aggregator pre-aggregates metric values according to a set of rules.
+type aggregator struct {
+	rules []*aggregationRule
+
+	// maxBuckets is the maximum number of intervals held until they are
+	// flushed. Values that would need more are not aggregated.
+	maxBuckets int
+
+	mu      sync.Mutex
+	buckets map[aggregationKey]*aggregationBucket
+
+	// Now returns the current time. Values timestamped more than one
+	// interval after it are not aggregated, as they would not be flushed
+	// until then.
+	Now func() time.Time
+}
+
+// newAggregator returns an aggregator for the given Carbon aggregation rules,
+// holding at most maxBuckets intervals.
+func newAggregator(rules []string, maxBuckets int) (*aggregator, error) {
+	a := &aggregator{
+		maxBuckets: maxBuckets,
+		buckets:    make(map[aggregationKey]*aggregationBucket),
+		Now:        time.Now,
+	}
+	for _, rule := range rules {
+		r, err := parseAggregationRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		a.rules = append(a.rules, r)
+	}
+	return a, nil
+}
+
+// Add adds a metric value to the interval of every rule that the metric path
+// matches. It returns false if the value was not added to any interval,
+// because no rule matched, the value is timestamped too far in the future or
+// the aggregator holds too many intervals.
+func (a *aggregator) Add(path string, value float64, t time.Time) bool {
+	now := a.Now()
+
+	var added bool
+	for _, r := range a.rules {
+		output, ok := r.match(path)
+		if !ok || t.After(now.Add(r.frequency)) {
+			continue
+		}
+
+		key := aggregationKey{rule: r, path: output, start: t.Truncate(r.frequency).UnixNano()}
+		a.mu.Lock()
+		b := a.buckets[key]
+		if b == nil {
+			if a.maxBuckets > 0 && len(a.buckets) >= a.maxBuckets {
+				a.mu.Unlock()
+				continue
+			}
+			b = &aggregationBucket{}
+			a.buckets[key] = b
+		}
+		b.add(value)
+		a.mu.Unlock()
+		added = true
+	}
+	return added
+}
+
+// Flush returns the aggregates of the intervals that ended at least one
+// interval before now, as lines of the plaintext protocol. Waiting for an
+// extra interval leaves time for late values to arrive.
+func (a *aggregator) Flush(now time.Time) []string {
+	return a.flush(func(start time.Time, frequency time.Duration) bool {
+		return !now.Before(start.Add(2 * frequency))
+	})
+}
+
+// FlushAll returns the aggregates of all intervals, ended or not.
+func (a *aggregator) FlushAll() []string {
+	return a.flush(func(time.Time, time.Duration) bool { return true })
+}
+
+// flush returns and removes the aggregates of the intervals fn returns true for.
+func (a *aggregator) flush(fn func(start time.Time, frequency time.Duration) bool) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var lines []string
+	for key, b := range a.buckets {
+		start := time.Unix(0, key.start)
+		if !fn(start, key.rule.frequency) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %d", key.path, strconv.FormatFloat(b.value(key.rule.method), 'f', -1, 64), start.Unix()))
+		delete(a.buckets, key)
+	}
+	return lines
+}
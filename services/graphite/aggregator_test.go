@@ -0,0 +1,118 @@
+package graphite
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_aggregator(t *testing.T) {
+	a, err := newAggregator([]string{
+		"<env>.requests.all (60) = sum <env>.requests.<host>",
+		"<env>.latency.max (60) = max <env>.latency.*",
+		"<<prefix>>.count (60) = count <<prefix>>.hits",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Unix(1500000000, 0).Truncate(time.Minute)
+	a.Now = func() time.Time { return start.Add(time.Minute) }
+	for _, m := range []struct {
+		path  string
+		value float64
+		t     time.Time
+	}{
+		{"prod.requests.a", 1, start},
+		{"prod.requests.b", 2, start.Add(10 * time.Second)},
+		{"prod.requests.a", 4, start.Add(70 * time.Second)},
+		{"prod.latency.a", 5, start},
+		{"prod.latency.b", 3, start},
+		{"web.prod.hits", 1, start},
+		{"web.prod.hits", 1, start},
+	} {
+		if !a.Add(m.path, m.value, m.t) {
+			t.Fatalf("expected %s to match", m.path)
+		}
+	}
+
+	if a.Add("prod.other.a", 1, start) {
+		t.Fatal("unexpected match")
+	}
+
+	// Intervals are only flushed one interval after they end.
+	if lines := a.Flush(start.Add(time.Minute)); len(lines) != 0 {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+
+	lines := a.Flush(start.Add(2 * time.Minute))
+	sort.Strings(lines)
+	if exp := []string{
+		"prod.latency.max 5 1500000000",
+		"prod.requests.all 3 1500000000",
+		"web.prod.count 2 1500000000",
+	}; !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("unexpected lines:\n\nexp=%q\n\ngot=%q", exp, lines)
+	}
+
+	if exp, got := []string{"prod.requests.all 4 1500000060"}, a.Flush(start.Add(3*time.Minute)); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected lines:\n\nexp=%q\n\ngot=%q", exp, got)
+	}
+}
+
+func Test_parseAggregationRule_Invalid(t *testing.T) {
+	for _, rule := range []string{
+		"<env>.all = sum <env>.*",
+		"<env>.all (0) = sum <env>.*",
+		"<env>.all (60) = median <env>.*",
+		"<env>.all (60) = sum <host>.*",
+		"<env>.all (60) = sum <env.*",
+	} {
+		if _, err := parseAggregationRule(rule); err == nil {
+			t.Fatalf("expected error for rule: %s", rule)
+		}
+	}
+}
+
+// Ensure the aggregator does not aggregate values it could not flush in time.
+func Test_aggregator_Limits(t *testing.T) {
+	a, err := newAggregator([]string{"<env>.requests.all (60) = sum <env>.requests.<host>"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1500000000, 0).Truncate(time.Minute)
+	a.Now = func() time.Time { return now }
+
+	// Values timestamped more than one interval in the future are not
+	// aggregated.
+	if !a.Add("prod.requests.a", 1, now.Add(time.Minute)) {
+		t.Fatal("expected value to be aggregated")
+	} else if a.Add("prod.requests.a", 1, now.Add(2*time.Minute+time.Second)) {
+		t.Fatal("expected future value not to be aggregated")
+	}
+
+	// Values needing more intervals than the aggregator holds are not
+	// aggregated, unlike values of the intervals it holds.
+	if !a.Add("prod.requests.a", 1, now) {
+		t.Fatal("expected value to be aggregated")
+	} else if a.Add("prod.requests.a", 1, now.Add(-time.Minute)) {
+		t.Fatal("expected value not to be aggregated")
+	} else if !a.Add("prod.requests.b", 2, now) {
+		t.Fatal("expected value to be aggregated")
+	}
+
+	// Flushing all intervals flushes those that have not ended.
+	lines := a.FlushAll()
+	sort.Strings(lines)
+	if exp := []string{
+		"prod.requests.all 1 1500000060",
+		"prod.requests.all 3 1500000000",
+	}; !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("unexpected lines:\n\nexp=%q\n\ngot=%q", exp, lines)
+	}
+	if lines := a.FlushAll(); len(lines) != 0 {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+}
@@ -19,6 +19,10 @@ const (
 	// DefaultProtocol is the default IP protocol used by the Graphite input.
 	DefaultProtocol = "tcp"
 
+	// PickleProtocol is the protocol of a Graphite input receiving the
+	// pickle protocol of Carbon relays over TCP.
+	PickleProtocol = "pickle"
+
 	// DefaultConsistencyLevel is the default write consistency for the Graphite input.
 	DefaultConsistencyLevel = "one"
 
@@ -47,36 +51,44 @@ const (
 	//     Linux:      sudo sysctl -w net.core.rmem_max=<read-buffer>
 	//     BSD/Darwin: sudo sysctl -w kern.ipc.maxsockbuf=<read-buffer>
 	DefaultUDPReadBuffer = 0
+
+	// DefaultMaxAggregationBuckets is the default maximum number of intervals
+	// of aggregated metrics held in memory until they are written.
+	DefaultMaxAggregationBuckets = 100000
 )
 
 // Config represents the configuration for Graphite endpoints.
 type Config struct {
-	Enabled          bool          `toml:"enabled"`
-	BindAddress      string        `toml:"bind-address"`
-	Database         string        `toml:"database"`
-	RetentionPolicy  string        `toml:"retention-policy"`
-	Protocol         string        `toml:"protocol"`
-	BatchSize        int           `toml:"batch-size"`
-	BatchPending     int           `toml:"batch-pending"`
-	BatchTimeout     toml.Duration `toml:"batch-timeout"`
-	ConsistencyLevel string        `toml:"consistency-level"`
-	Templates        []string      `toml:"templates"`
-	Tags             []string      `toml:"tags"`
-	Separator        string        `toml:"separator"`
-	UDPReadBuffer    int           `toml:"udp-read-buffer"`
+	Enabled               bool          `toml:"enabled"`
+	BindAddress           string        `toml:"bind-address"`
+	Database              string        `toml:"database"`
+	RetentionPolicy       string        `toml:"retention-policy"`
+	Protocol              string        `toml:"protocol"`
+	BatchSize             int           `toml:"batch-size"`
+	BatchPending          int           `toml:"batch-pending"`
+	BatchTimeout          toml.Duration `toml:"batch-timeout"`
+	ConsistencyLevel      string        `toml:"consistency-level"`
+	Templates             []string      `toml:"templates"`
+	Tags                  []string      `toml:"tags"`
+	Separator             string        `toml:"separator"`
+	UDPReadBuffer         int           `toml:"udp-read-buffer"`
+	Aggregations          []string      `toml:"aggregations"`
+	DropAggregated        bool          `toml:"drop-aggregated"`
+	MaxAggregationBuckets int           `toml:"max-aggregation-buckets"`
 }
 
 // NewConfig returns a new instance of Config with defaults.
 func NewConfig() Config {
 	return Config{
-		BindAddress:      DefaultBindAddress,
-		Database:         DefaultDatabase,
-		Protocol:         DefaultProtocol,
-		BatchSize:        DefaultBatchSize,
-		BatchPending:     DefaultBatchPending,
-		BatchTimeout:     toml.Duration(DefaultBatchTimeout),
-		ConsistencyLevel: DefaultConsistencyLevel,
-		Separator:        DefaultSeparator,
+		BindAddress:           DefaultBindAddress,
+		Database:              DefaultDatabase,
+		Protocol:              DefaultProtocol,
+		BatchSize:             DefaultBatchSize,
+		BatchPending:          DefaultBatchPending,
+		BatchTimeout:          toml.Duration(DefaultBatchTimeout),
+		ConsistencyLevel:      DefaultConsistencyLevel,
+		Separator:             DefaultSeparator,
+		MaxAggregationBuckets: DefaultMaxAggregationBuckets,
 	}
 }
 
@@ -111,6 +123,9 @@ func (c *Config) WithDefaults() *Config {
 	if d.UDPReadBuffer == 0 {
 		d.UDPReadBuffer = DefaultUDPReadBuffer
 	}
+	if d.MaxAggregationBuckets == 0 {
+		d.MaxAggregationBuckets = DefaultMaxAggregationBuckets
+	}
 	return &d
 }
 
@@ -134,6 +149,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.validateAggregations(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -213,6 +232,15 @@ func (c *Config) validateTags() error {
 	return nil
 }
 
+func (c *Config) validateAggregations() error {
+	for _, rule := range c.Aggregations {
+		if _, err := parseAggregationRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Config) validateTemplate(template string) error {
 	hasMeasurement := false
 	for _, p := range strings.Split(template, ".") {
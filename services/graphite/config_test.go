@@ -168,3 +168,21 @@ func TestConfigValidateFilterDuplicates(t *testing.T) {
 	}
 
 }
+
+func TestConfigValidateAggregations(t *testing.T) {
+	c := &graphite.Config{}
+	c.Aggregations = []string{"<env>.requests.all (60) = sum <env>.requests.<host>"}
+	if err := c.Validate(); err != nil {
+		t.Errorf("config validate expected success, got %v", err)
+	}
+
+	c.Aggregations = []string{"<env>.requests.all (60) = median <env>.requests.<host>"}
+	if err := c.Validate(); err == nil {
+		t.Errorf("config validate expected error. got nil")
+	}
+
+	c.Aggregations = []string{"<env>.requests.<dc> (60) = sum <env>.requests.<host>"}
+	if err := c.Validate(); err == nil {
+		t.Errorf("config validate expected error. got nil")
+	}
+}
@@ -0,0 +1,662 @@
+package graphite
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// maxPickleSize is the largest pickle message accepted, matching the limit of
+// the Carbon pickle receiver.
+const maxPickleSize = 1 << 20
+
+// Pickle opcodes understood by unpickle. Opcodes that import, build or call
+// objects are deliberately left out.
+const (
+	opMark           = '('
+	opStop           = '.'
+	opPop            = '0'
+	opPopMark        = '1'
+	opDup            = '2'
+	opFloat          = 'F'
+	opInt            = 'I'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opLong           = 'L'
+	opBinInt2        = 'M'
+	opNone           = 'N'
+	opString         = 'S'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opUnicode        = 'V'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opAppends        = 'e'
+	opGet            = 'g'
+	opBinGet         = 'h'
+	opLongBinGet     = 'j'
+	opList           = 'l'
+	opEmptyList      = ']'
+	opPut            = 'p'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opTuple          = 't'
+	opEmptyTuple     = ')'
+	opBinFloat       = 'G'
+	opBinBytes       = 'B'
+	opShortBinBytes  = 'C'
+	opProto          = 0x80
+	opTuple1         = 0x85
+	opTuple2         = 0x86
+	opTuple3         = 0x87
+	opNewTrue        = 0x88
+	opNewFalse       = 0x89
+	opLong1          = 0x8a
+	opShortBinUni    = 0x8c
+	opBinUnicode8    = 0x8d
+	opMemoize        = 0x94
+	opFrame          = 0x95
+)
+
+// errPickleTruncated is returned when a pickle ends before its STOP opcode.
+var errPickleTruncated = errors.New("pickle: unexpected end of data")
+
+// mark is pushed on the unpickler stack by the MARK opcode.
+type mark struct{}
+
+// unpickle decodes a pickle of protocol 0 to 4 made of lists, tuples,
+// strings, numbers, booleans and None. Lists and tuples are both returned as
+// []interface{}. Any other opcode is rejected, so decoding a pickle can never
+// import modules or run code.
+func unpickle(data []byte) (interface{}, error) {
+	r := bytes.NewReader(data)
+	var (
+		stack []interface{}
+		memo  = make(map[int]interface{})
+	)
+
+	pop := func() (interface{}, error) {
+		if len(stack) == 0 {
+			return nil, errors.New("pickle: stack underflow")
+		}
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v, nil
+	}
+
+	// popMark pops the values pushed since the last mark.
+	popMark := func() ([]interface{}, error) {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if _, ok := stack[i].(mark); ok {
+				items := append([]interface{}{}, stack[i+1:]...)
+				stack = stack[:i]
+				return items, nil
+			}
+		}
+		return nil, errors.New("pickle: mark not found")
+	}
+
+	top := func() (interface{}, error) {
+		if len(stack) == 0 {
+			return nil, errors.New("pickle: stack underflow")
+		}
+		return stack[len(stack)-1], nil
+	}
+
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return nil, errPickleTruncated
+		}
+
+		switch op {
+		case opProto:
+			if _, err := r.ReadByte(); err != nil {
+				return nil, errPickleTruncated
+			}
+		case opFrame:
+			if _, err := readN(r, 8); err != nil {
+				return nil, err
+			}
+		case opStop:
+			v, err := pop()
+			if err != nil {
+				return nil, err
+			}
+			var n int
+			return unwrapLists(v, 0, &n)
+		case opMark:
+			stack = append(stack, mark{})
+		case opPop:
+			if _, err := pop(); err != nil {
+				return nil, err
+			}
+		case opPopMark:
+			if _, err := popMark(); err != nil {
+				return nil, err
+			}
+		case opDup:
+			v, err := top()
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+
+		case opNone:
+			stack = append(stack, nil)
+		case opNewTrue:
+			stack = append(stack, true)
+		case opNewFalse:
+			stack = append(stack, false)
+		case opInt:
+			line, err := readLine(r)
+			if err != nil {
+				return nil, err
+			}
+			switch line {
+			case "00":
+				stack = append(stack, false)
+			case "01":
+				stack = append(stack, true)
+			default:
+				v, err := strconv.ParseInt(line, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("pickle: invalid int: %s", err)
+				}
+				stack = append(stack, v)
+			}
+		case opLong:
+			line, err := readLine(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseInt(strings.TrimSuffix(line, "L"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("pickle: invalid long: %s", err)
+			}
+			stack = append(stack, v)
+		case opBinInt:
+			b, err := readN(r, 4)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, int64(int32(binary.LittleEndian.Uint32(b))))
+		case opBinInt1:
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, errPickleTruncated
+			}
+			stack = append(stack, int64(b))
+		case opBinInt2:
+			b, err := readN(r, 2)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, int64(binary.LittleEndian.Uint16(b)))
+		case opLong1:
+			n, err := r.ReadByte()
+			if err != nil {
+				return nil, errPickleTruncated
+			} else if n > 8 {
+				return nil, errors.New("pickle: long out of range")
+			}
+			b, err := readN(r, int(n))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, decodeLong(b))
+		case opFloat:
+			line, err := readLine(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseFloat(line, 64)
+			if err != nil {
+				return nil, fmt.Errorf("pickle: invalid float: %s", err)
+			}
+			stack = append(stack, v)
+		case opBinFloat:
+			b, err := readN(r, 8)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, math.Float64frombits(binary.BigEndian.Uint64(b)))
+
+		case opString:
+			line, err := readLine(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := unquotePickleString(line)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opUnicode:
+			line, err := readLine(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := unescapeRawUnicode(line)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opShortBinString, opShortBinBytes, opShortBinUni:
+			n, err := r.ReadByte()
+			if err != nil {
+				return nil, errPickleTruncated
+			}
+			b, err := readN(r, int(n))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, string(b))
+		case opBinString, opBinBytes, opBinUnicode:
+			b, err := readN(r, 4)
+			if err != nil {
+				return nil, err
+			}
+			if b, err = readN(r, int(binary.LittleEndian.Uint32(b))); err != nil {
+				return nil, err
+			}
+			stack = append(stack, string(b))
+		case opBinUnicode8:
+			b, err := readN(r, 8)
+			if err != nil {
+				return nil, err
+			}
+			n := binary.LittleEndian.Uint64(b)
+			if n > uint64(r.Len()) {
+				return nil, errPickleTruncated
+			}
+			if b, err = readN(r, int(n)); err != nil {
+				return nil, err
+			}
+			stack = append(stack, string(b))
+
+		case opEmptyList:
+			stack = append(stack, &[]interface{}{})
+		case opEmptyTuple:
+			stack = append(stack, []interface{}{})
+		case opList:
+			items, err := popMark()
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &items)
+		case opTuple:
+			items, err := popMark()
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, items)
+		case opTuple1, opTuple2, opTuple3:
+			n := int(op-opTuple1) + 1
+			if len(stack) < n {
+				return nil, errors.New("pickle: stack underflow")
+			}
+			items := append([]interface{}{}, stack[len(stack)-n:]...)
+			stack = append(stack[:len(stack)-n], items)
+		case opAppend:
+			v, err := pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := appendToList(stack, v); err != nil {
+				return nil, err
+			}
+		case opAppends:
+			items, err := popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := appendToList(stack, items...); err != nil {
+				return nil, err
+			}
+
+		case opPut:
+			line, err := readLine(r)
+			if err != nil {
+				return nil, err
+			}
+			i, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("pickle: invalid memo key: %s", err)
+			}
+			if memo[i], err = top(); err != nil {
+				return nil, err
+			}
+		case opBinPut:
+			i, err := r.ReadByte()
+			if err != nil {
+				return nil, errPickleTruncated
+			}
+			if memo[int(i)], err = top(); err != nil {
+				return nil, err
+			}
+		case opLongBinPut:
+			b, err := readN(r, 4)
+			if err != nil {
+				return nil, err
+			}
+			if memo[int(binary.LittleEndian.Uint32(b))], err = top(); err != nil {
+				return nil, err
+			}
+		case opMemoize:
+			v, err := top()
+			if err != nil {
+				return nil, err
+			}
+			memo[len(memo)] = v
+		case opGet, opBinGet, opLongBinGet:
+			var i int
+			switch op {
+			case opGet:
+				line, err := readLine(r)
+				if err != nil {
+					return nil, err
+				}
+				if i, err = strconv.Atoi(line); err != nil {
+					return nil, fmt.Errorf("pickle: invalid memo key: %s", err)
+				}
+			case opBinGet:
+				b, err := r.ReadByte()
+				if err != nil {
+					return nil, errPickleTruncated
+				}
+				i = int(b)
+			default:
+				b, err := readN(r, 4)
+				if err != nil {
+					return nil, err
+				}
+				i = int(binary.LittleEndian.Uint32(b))
+			}
+			v, ok := memo[i]
+			if !ok {
+				return nil, fmt.Errorf("pickle: memo key %d not found", i)
+			}
+			stack = append(stack, v)
+
+		default:
+			return nil, fmt.Errorf("pickle: unsupported opcode %q", op)
+		}
+	}
+}
+
+// appendToList appends values to the list on top of the stack. Lists are held
+// by pointer while decoding so that memoized references see the appends.
+func appendToList(stack []interface{}, values ...interface{}) error {
+	if len(stack) == 0 {
+		return errors.New("pickle: stack underflow")
+	}
+	list, ok := stack[len(stack)-1].(*[]interface{})
+	if !ok {
+		return errors.New("pickle: append to non-list")
+	}
+	*list = append(*list, values...)
+	return nil
+}
+
+// Limits on decoded values. They reject lists that contain themselves and
+// memoized lists referenced over and over to expand into huge values.
+const (
+	maxPickleDepth = 32
+	maxPickleItems = 1 << 20
+)
+
+// unwrapLists replaces the list pointers within a decoded value with slices.
+// n counts the items visited so far.
+func unwrapLists(v interface{}, depth int, n *int) (interface{}, error) {
+	if depth > maxPickleDepth {
+		return nil, errors.New("pickle: nested too deeply")
+	}
+
+	var items []interface{}
+	switch v := v.(type) {
+	case *[]interface{}:
+		items = *v
+	case []interface{}:
+		items = v
+	default:
+		return v, nil
+	}
+
+	if *n += len(items); *n > maxPickleItems {
+		return nil, errors.New("pickle: too many items")
+	}
+
+	a := make([]interface{}, len(items))
+	for i, item := range items {
+		var err error
+		if a[i], err = unwrapLists(item, depth+1, n); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// readN reads exactly n bytes from r.
+func readN(r *bytes.Reader, n int) ([]byte, error) {
+	if n > r.Len() {
+		return nil, errPickleTruncated
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errPickleTruncated
+	}
+	return b, nil
+}
+
+// readLine reads up to the next newline from r, which is not included.
+func readLine(r *bytes.Reader) (string, error) {
+	var buf bytes.Buffer
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", errPickleTruncated
+		} else if b == '\n' {
+			return buf.String(), nil
+		}
+		buf.WriteByte(b)
+	}
+}
+
+// decodeLong decodes a little-endian two's complement integer.
+func decodeLong(b []byte) int64 {
+	if len(b) == 0 {
+		return 0
+	}
+	var v uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	// Sign extend.
+	if shift := uint(64 - 8*len(b)); b[len(b)-1]&0x80 != 0 && shift > 0 {
+		return int64(v<<shift) >> shift
+	}
+	return int64(v)
+}
+
+// unquotePickleString decodes the quoted, escaped argument of a STRING opcode.
+func unquotePickleString(s string) (string, error) {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '\'' && s[0] != '"') {
+		return "", errors.New("pickle: invalid quoted string")
+	}
+	s = s[1 : len(s)-1]
+
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			buf.WriteByte(s[i])
+			continue
+		}
+		if i++; i >= len(s) {
+			return "", errors.New("pickle: invalid escape")
+		}
+		switch s[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'x':
+			if i+3 > len(s) {
+				return "", errors.New("pickle: invalid escape")
+			}
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", errors.New("pickle: invalid escape")
+			}
+			buf.WriteByte(byte(v))
+			i += 2
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+	return buf.String(), nil
+}
+
+// unescapeRawUnicode decodes the raw-unicode-escape argument of a UNICODE opcode.
+func unescapeRawUnicode(s string) (string, error) {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == 'u' || s[i+1] == 'U') {
+			n := 4
+			if s[i+1] == 'U' {
+				n = 8
+			}
+			if i+2+n > len(s) {
+				return "", errors.New("pickle: invalid escape")
+			}
+			v, err := strconv.ParseUint(s[i+2:i+2+n], 16, 32)
+			if err != nil {
+				return "", errors.New("pickle: invalid escape")
+			}
+			buf.WriteRune(rune(v))
+			i += 1 + n
+			continue
+		}
+		// Other characters are latin-1.
+		buf.WriteRune(rune(s[i]))
+	}
+	if !utf8.Valid(buf.Bytes()) {
+		return "", errors.New("pickle: invalid unicode")
+	}
+	return buf.String(), nil
+}
+
+// parsePickle decodes a Carbon pickle message, a list of
+// (path, (timestamp, value)) tuples, into lines of the plaintext protocol.
+func parsePickle(data []byte) ([]string, error) {
+	v, err := unpickle(data)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("pickle: expected a list of metrics")
+	}
+
+	lines := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		tuple, ok := m.([]interface{})
+		if !ok || len(tuple) != 2 {
+			return nil, errors.New("pickle: expected a (path, (timestamp, value)) tuple")
+		}
+		path, ok := tuple[0].(string)
+		if !ok {
+			return nil, errors.New("pickle: metric path is not a string")
+		}
+		datapoint, ok := tuple[1].([]interface{})
+		if !ok || len(datapoint) != 2 {
+			return nil, fmt.Errorf("pickle: %s: expected a (timestamp, value) tuple", path)
+		}
+
+		timestamp, ok := pickleNumber(datapoint[0])
+		if !ok {
+			return nil, fmt.Errorf("pickle: %s: timestamp is not a number", path)
+		}
+		value, ok := pickleNumber(datapoint[1])
+		if !ok {
+			return nil, fmt.Errorf("pickle: %s: value is not a number", path)
+		}
+		lines = append(lines, path+" "+value+" "+timestamp)
+	}
+	return lines, nil
+}
+
+// pickleNumber formats a decoded numeric value for the plaintext protocol.
+// Strings are accepted as Carbon converts them with float().
+func pickleNumber(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case string:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", false
+		}
+		return v, true
+	default:
+		return "", false
+	}
+}
+
+// handlePickleConnection services an individual TCP connection sending
+// length-prefixed pickle messages, as Carbon relays do.
+func (s *Service) handlePickleConnection(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+	defer atomic.AddInt64(&s.stats.ActiveConnections, -1)
+	defer s.untrackConnection(conn)
+	atomic.AddInt64(&s.stats.ActiveConnections, 1)
+	atomic.AddInt64(&s.stats.HandledConnections, 1)
+	s.trackConnection(conn)
+
+	reader := bufio.NewReader(conn)
+
+	var hdr [4]byte
+	for {
+		if _, err := io.ReadFull(reader, hdr[:]); err != nil {
+			return
+		}
+
+		n := binary.BigEndian.Uint32(hdr[:])
+		if n > maxPickleSize {
+			s.logger.Info(fmt.Sprintf("pickle message of %d bytes from %s exceeds the maximum of %d bytes", n, conn.RemoteAddr(), maxPickleSize))
+			return
+		}
+
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return
+		}
+		atomic.AddInt64(&s.stats.BytesReceived, int64(len(hdr)+len(buf)))
+
+		lines, err := parsePickle(buf)
+		if err != nil {
+			s.logger.Info(fmt.Sprintf("unable to parse pickle message: %s", err))
+			atomic.AddInt64(&s.stats.PointsParseFail, 1)
+			continue
+		}
+
+		atomic.AddInt64(&s.stats.PointsReceived, int64(len(lines)))
+		for _, line := range lines {
+			s.handleLine(line)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package graphite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parsePickle(t *testing.T) {
+	exp := []string{
+		"servers.a.cpu 1.5 1500000000",
+		"servers.b.cpu 2 1500000001",
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "protocol 0",
+			data: "(lp0\n(Vservers.a.cpu\np1\n(I1500000000\nF1.5\ntp2\ntp3\na(S'servers.b.cpu'\np4\n(I1500000001\nI2\ntp5\ntp6\na.",
+		},
+		{
+			name: "protocol 2",
+			data: "\x80\x02]q\x00(X\r\x00\x00\x00servers.a.cpuq\x01J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\r\x00\x00\x00servers.b.cpuq\x04J\x01/hYK\x02\x86q\x05\x86q\x06e.",
+		},
+		{
+			name: "protocol 4",
+			data: "\x80\x04\x95B\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\rservers.a.cpu\x94J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86\x94\x86\x94\x8c\rservers.b.cpu\x94J\x01/hYK\x02\x86\x94\x86\x94e.",
+		},
+	}
+
+	for _, test := range tests {
+		lines, err := parsePickle([]byte(test.data))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.name, err)
+		} else if !reflect.DeepEqual(lines, exp) {
+			t.Fatalf("%s: unexpected lines:\n\nexp=%q\n\ngot=%q", test.name, exp, lines)
+		}
+	}
+}
+
+func Test_parsePickle_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			// [os.system("echo pwned")]
+			name: "reduce",
+			data: "\x80\x02]q\x00cposix\nsystem\nq\x01X\n\x00\x00\x00echo pwnedq\x02\x85q\x03Rq\x04a.",
+			err:  `pickle: unsupported opcode 'c'`,
+		},
+		{
+			name: "truncated",
+			data: "\x80\x02]q\x00(X\r\x00\x00\x00servers",
+			err:  `pickle: unexpected end of data`,
+		},
+		{
+			name: "not a list",
+			data: "\x80\x02K\x01.",
+			err:  `pickle: expected a list of metrics`,
+		},
+		{
+			name: "missing value",
+			data: "\x80\x02]q\x00X\x01\x00\x00\x00a\x85a.",
+			err:  `pickle: expected a (path, (timestamp, value)) tuple`,
+		},
+		{
+			// A list appended to itself.
+			name: "recursive",
+			data: "\x80\x02]q\x00h\x00a.",
+			err:  `pickle: nested too deeply`,
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := parsePickle([]byte(test.data)); err == nil || err.Error() != test.err {
+			t.Fatalf("%s: unexpected error:\n\nexp=%s\n\ngot=%v", test.name, test.err, err)
+		}
+	}
+}
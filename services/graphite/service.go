@@ -31,6 +31,7 @@ const (
 	statBatchesTransmitFail = "batchesTxFail"
 	statConnectionsActive   = "connsActive"
 	statConnectionsHandled  = "connsHandled"
+	statPointsAggregated    = "pointsAggregated"
 )
 
 type tcpConnection struct {
@@ -56,6 +57,9 @@ type Service struct {
 	batcher *tsdb.PointBatcher
 	parser  *Parser
 
+	aggregator     *aggregator
+	dropAggregated bool
+
 	logger      zap.Logger
 	stats       *Statistics
 	defaultTags models.StatisticTags
@@ -121,6 +125,13 @@ func NewService(c Config) (*Service, error) {
 	}
 	s.parser = parser
 
+	if len(d.Aggregations) > 0 {
+		if s.aggregator, err = newAggregator(d.Aggregations, d.MaxAggregationBuckets); err != nil {
+			return nil, err
+		}
+		s.dropAggregated = d.DropAggregated
+	}
+
 	return &s, nil
 }
 
@@ -148,9 +159,16 @@ func (s *Service) Open() error {
 	s.wg.Add(1)
 	go s.processBatches(s.batcher)
 
+	if s.aggregator != nil {
+		s.wg.Add(1)
+		go s.processAggregates()
+	}
+
 	var err error
 	if strings.ToLower(s.protocol) == "tcp" {
-		s.addr, err = s.openTCPServer()
+		s.addr, err = s.openTCPServer(s.handleTCPConnection)
+	} else if strings.ToLower(s.protocol) == PickleProtocol {
+		s.addr, err = s.openTCPServer(s.handlePickleConnection)
 	} else if strings.ToLower(s.protocol) == "udp" {
 		s.addr, err = s.openUDPServer()
 	} else {
@@ -230,6 +248,20 @@ func (s *Service) createInternalStorage() error {
 		return nil
 	}
 
+	if err := s.createStorage(); err != nil {
+		return err
+	}
+
+	// The service is now ready.
+	s.mu.Lock()
+	s.ready = true
+	s.mu.Unlock()
+	return nil
+}
+
+// createStorage creates the required database and retention policy if they
+// do not exist.
+func (s *Service) createStorage() error {
 	if db := s.MetaClient.Database(s.database); db != nil {
 		if rp, _ := s.MetaClient.RetentionPolicy(s.database, s.retentionPolicy); rp == nil {
 			spec := meta.RetentionPolicySpec{Name: s.retentionPolicy}
@@ -243,11 +275,6 @@ func (s *Service) createInternalStorage() error {
 			return err
 		}
 	}
-
-	// The service is now ready.
-	s.mu.Lock()
-	s.ready = true
-	s.mu.Unlock()
 	return nil
 }
 
@@ -270,6 +297,7 @@ type Statistics struct {
 	BatchesTransmitFail int64
 	ActiveConnections   int64
 	HandledConnections  int64
+	PointsAggregated    int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -287,6 +315,7 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
 			statConnectionsActive:   atomic.LoadInt64(&s.stats.ActiveConnections),
 			statConnectionsHandled:  atomic.LoadInt64(&s.stats.HandledConnections),
+			statPointsAggregated:    atomic.LoadInt64(&s.stats.PointsAggregated),
 		},
 	}}
 }
@@ -296,8 +325,9 @@ func (s *Service) Addr() net.Addr {
 	return s.addr
 }
 
-// openTCPServer opens the Graphite input in TCP mode and starts processing data.
-func (s *Service) openTCPServer() (net.Addr, error) {
+// openTCPServer opens the Graphite input in TCP mode and starts processing
+// data, handling each connection with handle.
+func (s *Service) openTCPServer(handle func(conn net.Conn)) (net.Addr, error) {
 	ln, err := net.Listen("tcp", s.bindAddress)
 	if err != nil {
 		return nil, err
@@ -319,7 +349,7 @@ func (s *Service) openTCPServer() (net.Addr, error) {
 			}
 
 			s.wg.Add(1)
-			go s.handleTCPConnection(conn)
+			go handle(conn)
 		}
 	}()
 	return ln.Addr(), nil
@@ -430,9 +460,87 @@ func (s *Service) handleLine(line string) {
 		return
 	}
 
+	// Add the value to the matching aggregation rules. The line parsed, so
+	// it has a path and the point has its value.
+	if s.aggregator != nil {
+		fields, err := point.Fields()
+		if v, ok := fields["value"].(float64); err == nil && ok {
+			if s.aggregator.Add(strings.Fields(line)[0], v, point.Time()) && s.dropAggregated {
+				return
+			}
+		}
+	}
+
 	s.batcher.In() <- point
 }
 
+// processAggregates periodically writes the aggregates of the intervals that
+// have ended, and the aggregates of all intervals when the service closes.
+func (s *Service) processAggregates() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			points := s.parseAggregates(s.aggregator.Flush(now))
+			for i, point := range points {
+				select {
+				case s.batcher.In() <- point:
+				case <-s.done:
+					s.writeAggregates(points[i:])
+					return
+				}
+			}
+
+		case <-s.done:
+			s.writeAggregates(nil)
+			return
+		}
+	}
+}
+
+// writeAggregates writes points, and the aggregates of all intervals, directly
+// as the batcher is stopping. Close holds the service lock until it returns,
+// so the database is checked without it.
+func (s *Service) writeAggregates(points []models.Point) {
+	points = append(points, s.parseAggregates(s.aggregator.FlushAll())...)
+	if len(points) == 0 {
+		return
+	}
+
+	if !s.ready {
+		if err := s.createStorage(); err != nil {
+			s.logger.Info(fmt.Sprintf("Required database or retention policy do not yet exist: %s", err.Error()))
+			return
+		}
+	}
+	if err := s.PointsWriter.WritePoints(s.database, s.retentionPolicy, models.ConsistencyLevelAny, points); err != nil {
+		s.logger.Info(fmt.Sprintf("failed to write aggregates to database %q: %s", s.database, err))
+		atomic.AddInt64(&s.stats.BatchesTransmitFail, 1)
+		return
+	}
+	atomic.AddInt64(&s.stats.BatchesTransmitted, 1)
+	atomic.AddInt64(&s.stats.PointsTransmitted, int64(len(points)))
+}
+
+// parseAggregates parses the lines of flushed aggregates into points.
+func (s *Service) parseAggregates(lines []string) []models.Point {
+	points := make([]models.Point, 0, len(lines))
+	for _, line := range lines {
+		point, err := s.parser.Parse(line)
+		if err != nil {
+			s.logger.Info(fmt.Sprintf("unable to parse aggregate: %s: %s", line, err))
+			atomic.AddInt64(&s.stats.PointsParseFail, 1)
+			continue
+		}
+		atomic.AddInt64(&s.stats.PointsAggregated, 1)
+		points = append(points, point)
+	}
+	return points
+}
+
 // processBatches continually drains the given batcher and writes the batches to the database.
 func (s *Service) processBatches(batcher *tsdb.PointBatcher) {
 	defer s.wg.Done()
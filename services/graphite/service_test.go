@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -193,6 +194,65 @@ func Test_Service_TCP(t *testing.T) {
 	wg.Wait()
 }
 
+func Test_Service_Pickle(t *testing.T) {
+	t.Parallel()
+
+	config := Config{}
+	config.Database = "graphitedb"
+	config.Protocol = PickleProtocol
+	config.BatchSize = 0 // No batching.
+	config.BatchTimeout = toml.Duration(time.Second)
+	config.BindAddress = ":0"
+
+	service := NewTestService(&config)
+
+	// Allow test to wait until points are written.
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	var mu sync.Mutex
+	var got []string
+	service.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range points {
+			got = append(got, p.String())
+			wg.Done()
+		}
+		return nil
+	}
+
+	if err := service.Service.Open(); err != nil {
+		t.Fatalf("failed to open Graphite service: %s", err.Error())
+	}
+	defer service.Service.Close()
+
+	// Connect to the graphite endpoint we just spun up
+	_, port, _ := net.SplitHostPort(service.Service.Addr().String())
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// [("cpu", (1500000000, 1.5)), ("mem", (1500000001, 2))] pickled with protocol 2.
+	payload := "\x80\x02]q\x00(X\x03\x00\x00\x00cpuq\x01J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\x03\x00\x00\x00memq\x04J\x01/hYK\x02\x86q\x05\x86q\x06e."
+	data := []byte{0, 0, 0, byte(len(payload))}
+	data = append(data, payload...)
+	_, err = conn.Write(data)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if exp := []string{"cpu value=1.5 1500000000000000000", "mem value=2 1500000001000000000"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points:\n\nexp=%q\n\ngot=%q", exp, got)
+	}
+}
+
 func Test_Service_UDP(t *testing.T) {
 	t.Parallel()
 
@@ -251,6 +311,50 @@ func Test_Service_UDP(t *testing.T) {
 	conn.Close()
 }
 
+// Ensure the service writes the aggregates of the intervals that have not
+// ended when it closes.
+func Test_Service_Aggregate_Close(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC().Round(time.Second)
+
+	config := NewConfig()
+	config.Database = "graphitedb"
+	config.BindAddress = "127.0.0.1:0"
+	config.Aggregations = []string{"<env>.requests.all (3600) = sum <env>.requests.<host>"}
+	config.DropAggregated = true
+
+	service := NewTestService(&config)
+
+	var (
+		mu      sync.Mutex
+		written []string
+	)
+	service.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range points {
+			written = append(written, p.String())
+		}
+		return nil
+	}
+
+	if err := service.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	service.Service.handleLine(fmt.Sprintf("prod.requests.a 1 %d", now.Unix()))
+	service.Service.handleLine(fmt.Sprintf("prod.requests.b 2 %d", now.Unix()))
+	if err := service.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := now.Truncate(time.Hour)
+	exp := models.MustNewPoint("prod.requests.all", models.NewTags(nil), models.Fields{"value": 3.0}, start)
+	if !reflect.DeepEqual(written, []string{exp.String()}) {
+		t.Fatalf("unexpected points: %q", written)
+	}
+}
+
 type TestService struct {
 	Service       *Service
 	MetaClient    *internal.MetaClientMock
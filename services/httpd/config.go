@@ -9,6 +9,19 @@ const (
 
 	// DefaultBindSocket is the default unix socket to bind to.
 	DefaultBindSocket = "/var/run/influxdb.sock"
+
+	// DefaultWriteBatchSize is the default number of points of a write
+	// request that are parsed and written at once.
+	DefaultWriteBatchSize = 5000
+
+	// DefaultHTTPSClientAuth is the default mode of HTTPS client certificate
+	// verification.
+	DefaultHTTPSClientAuth = ClientAuthNone
+
+	// DefaultMaxPrometheusRequestSize is the default maximum size of the body
+	// of a Prometheus remote read or write request, before and after it is
+	// decompressed.
+	DefaultMaxPrometheusRequestSize = 32 * 1024 * 1024
 )
 
 // Config represents a configuration for a HTTP service.
@@ -22,12 +35,25 @@ type Config struct {
 	HTTPSEnabled       bool   `toml:"https-enabled"`
 	HTTPSCertificate   string `toml:"https-certificate"`
 	HTTPSPrivateKey    string `toml:"https-private-key"`
+	HTTPSClientAuth    string `toml:"https-client-auth"`
+	HTTPSClientCA      string `toml:"https-client-ca"`
 	MaxRowLimit        int    `toml:"max-row-limit"`
 	MaxConnectionLimit int    `toml:"max-connection-limit"`
 	SharedSecret       string `toml:"shared-secret"`
 	Realm              string `toml:"realm"`
 	UnixSocketEnabled  bool   `toml:"unix-socket-enabled"`
 	BindSocket         string `toml:"bind-socket"`
+	WriteBatchSize     int    `toml:"write-batch-size"`
+
+	// MaxPrometheusRequestSize is the maximum size, in bytes, of the body of
+	// a Prometheus remote read or write request, before and after it is
+	// decompressed. A value of 0 disables the limit.
+	MaxPrometheusRequestSize int `toml:"max-prometheus-request-size"`
+
+	// HTTPSClientCertUsers maps the identities of client certificates, such
+	// as "CN=telegraf" or "URI:spiffe://cluster/ns/monitoring/sa/telegraf",
+	// to the users they authenticate as.
+	HTTPSClientCertUsers map[string]string `toml:"https-client-cert-users"`
 }
 
 // NewConfig returns a new Config with default settings.
@@ -39,9 +65,13 @@ func NewConfig() Config {
 		PprofEnabled:      true,
 		HTTPSEnabled:      false,
 		HTTPSCertificate:  "/etc/ssl/influxdb.pem",
+		HTTPSClientAuth:   DefaultHTTPSClientAuth,
 		MaxRowLimit:       DefaultChunkSize,
 		Realm:             DefaultRealm,
 		UnixSocketEnabled: false,
 		BindSocket:        DefaultBindSocket,
+		WriteBatchSize:    DefaultWriteBatchSize,
+
+		MaxPrometheusRequestSize: DefaultMaxPrometheusRequestSize,
 	}
 }
@@ -18,8 +18,13 @@ log-enabled = true
 write-tracing = true
 https-enabled = true
 https-certificate = "/dev/null"
+https-client-auth = "require"
+https-client-ca = "/etc/ssl/ca.pem"
 unix-socket-enabled = true
 bind-socket = "/var/run/influxdb.sock"
+
+[https-client-cert-users]
+"CN=telegraf" = "telegraf"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -39,6 +44,12 @@ bind-socket = "/var/run/influxdb.sock"
 		t.Fatalf("unexpected https enabled: %v", c.HTTPSEnabled)
 	} else if c.HTTPSCertificate != "/dev/null" {
 		t.Fatalf("unexpected https certificate: %v", c.HTTPSCertificate)
+	} else if c.HTTPSClientAuth != httpd.ClientAuthRequire {
+		t.Fatalf("unexpected https client auth: %v", c.HTTPSClientAuth)
+	} else if c.HTTPSClientCA != "/etc/ssl/ca.pem" {
+		t.Fatalf("unexpected https client ca: %v", c.HTTPSClientCA)
+	} else if c.HTTPSClientCertUsers["CN=telegraf"] != "telegraf" {
+		t.Fatalf("unexpected https client cert users: %v", c.HTTPSClientCertUsers)
 	} else if c.UnixSocketEnabled != true {
 		t.Fatalf("unexpected unix socket enabled: %v", c.UnixSocketEnabled)
 	} else if c.BindSocket != "/var/run/influxdb.sock" {
@@ -2,6 +2,10 @@ package httpd_test
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +16,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -19,11 +24,17 @@ import (
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/darshanman40/influxdb/coordinator"
 	"github.com/darshanman40/influxdb/influxql"
 	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/monitor"
+	"github.com/darshanman40/influxdb/prometheus/remote"
+	"github.com/darshanman40/influxdb/services/audit"
 	"github.com/darshanman40/influxdb/services/httpd"
 	"github.com/darshanman40/influxdb/services/httpd/internal"
 	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
 )
 
 // Ensure the handler returns results from a query (including nil results).
@@ -327,6 +338,51 @@ func TestHandler_Query_Auth(t *testing.T) {
 	}
 }
 
+// Ensure the handler authenticates users with verified client certificates.
+func TestHandler_Query_ClientCertificate(t *testing.T) {
+	h := NewHandler(true)
+	h.Config.HTTPSClientCertUsers = map[string]string{"URI:spiffe://cluster.local/sa/grafana": "grafana"}
+	h.MetaClient.UsersFn = func() []meta.UserInfo {
+		return []meta.UserInfo{{Name: "admin", Admin: true}}
+	}
+	h.MetaClient.UserFn = func(username string) (*meta.UserInfo, error) {
+		if username != "grafana" {
+			t.Errorf("unexpected user: %s", username)
+		}
+		return &meta.UserInfo{Name: username}, nil
+	}
+	h.QueryAuthorizer.AuthorizeQueryFn = func(u *meta.UserInfo, query *influxql.Query, database string) error {
+		if u == nil || u.Name != "grafana" {
+			t.Errorf("unexpected user: %v", u)
+		}
+		return nil
+	}
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+		ctx.Results <- &influxql.Result{StatementID: 1}
+		return nil
+	}
+
+	for i, tt := range []struct {
+		cert *Certificate
+		code int
+	}{
+		{cert: MustCertificate(pkix.Name{CommonName: "grafana"}, []asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte("spiffe://cluster.local/sa/grafana")}}), code: http.StatusOK},
+		{cert: MustCertificate(pkix.Name{CommonName: "grafana"}, nil), code: http.StatusUnauthorized},
+		{code: http.StatusUnauthorized},
+	} {
+		r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+		if tt.cert != nil {
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{tt.cert.Certificate}}}
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%d. unexpected status: got=%d exp=%d\noutput: %s", i, w.Code, tt.code, w.Body.String())
+		}
+	}
+}
+
 // Ensure the handler returns results from a query (including nil results).
 func TestHandler_QueryRegex(t *testing.T) {
 	h := NewHandler(false)
@@ -403,6 +459,30 @@ func TestHandler_Query_Chunked(t *testing.T) {
 	}
 }
 
+// Ensure the handler returns 429 when a query is refused by the query quota
+// of its database.
+func TestHandler_Query_QuotaExceeded(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.TaskManager.QueryQuota = func(database string, n int) error {
+		if database != "foo" {
+			t.Fatalf("unexpected database: %s", database)
+		}
+		return tsdb.QuotaExceededError{Database: "foo", Quota: tsdb.QuotaQueries, Usage: 2, Limit: 2}
+	}
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+		t.Fatal("unexpected statement execution")
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"queries quota exceeded: db=foo (2/2)"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
 // Ensure the handler can accept an async query.
 func TestHandler_Query_Async(t *testing.T) {
 	done := make(chan struct{})
@@ -620,6 +700,519 @@ func TestHandler_Query_CloseNotify(t *testing.T) {
 	}
 }
 
+// Ensure the handler accepts a write request with an empty body.
+func TestHandler_Write_EmptyBody(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		t.Fatal("unexpected write")
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0", strings.NewReader("")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler writes the body in batches and reports rejected lines.
+func TestHandler_Write_Batches(t *testing.T) {
+	h := NewHandler(false)
+	h.Config.WriteBatchSize = 2
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+
+	var batches []int
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		batches = append(batches, len(points))
+		return nil
+	}
+
+	body := "cpu value=1 1\ncpu value=2 2\ncpu value= 3\ncpu value=4 4\ncpu value=5 5\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := w.Body.String(); !strings.Contains(body, "partial write") || !strings.Contains(body, "(line 3)") {
+		t.Fatalf("unexpected body: %s", body)
+	} else if exp := []int{2, 2}; !reflect.DeepEqual(batches, exp) {
+		t.Fatalf("unexpected batches: exp=%v, got=%v", exp, batches)
+	}
+}
+
+// Ensure the handler only describes the first lines that were not written,
+// and counts the points dropped by every batch.
+func TestHandler_Write_FailedLines(t *testing.T) {
+	h := NewHandler(false)
+	h.Config.WriteBatchSize = 2
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		return nil
+	}
+
+	body := strings.Repeat("cpu value= 1\n", 1005) + "cpu value=1 1\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := w.Body.String(); strings.Count(body, "unable to parse") != 1000 || !strings.Contains(body, "5 more lines not written") {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		return tsdb.PartialWriteError{Reason: "field type conflict", Dropped: 1}
+	}
+	body = strings.Repeat("cpu value=1 1\n", 6)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := w.Body.String(); !strings.Contains(body, "partial write: field type conflict dropped=3") {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler refuses writes exceeding a quota of the database.
+func TestHandler_Write_Quota(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+
+	for _, tt := range []struct {
+		quota string
+		code  int
+	}{
+		{quota: tsdb.QuotaWriteRate, code: http.StatusTooManyRequests},
+		{quota: tsdb.QuotaDisk, code: http.StatusForbidden},
+		{quota: tsdb.QuotaSeries, code: http.StatusForbidden},
+	} {
+		quota := tt.quota
+		h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+			return tsdb.QuotaExceededError{Database: database, Quota: quota, Usage: 10, Limit: 10}
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0", strings.NewReader("cpu value=1 1\n")))
+		if w.Code != tt.code {
+			t.Fatalf("%s: unexpected status: %d", tt.quota, w.Code)
+		} else if exp := fmt.Sprintf(`{"error":"%s quota exceeded: db=db0 (10/10)"}`, tt.quota); strings.TrimSpace(w.Body.String()) != exp {
+			t.Fatalf("%s: unexpected body: %s", tt.quota, w.Body.String())
+		}
+	}
+}
+
+// Ensure the handler lists the rejected lines of a write when asked to.
+func TestHandler_Write_Details(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		return tsdb.PartialWriteError{
+			Reason:        "field type conflict: input field \"value\" on measurement \"cpu\" is type string, already exists as type float",
+			Dropped:       1,
+			DroppedPoints: []tsdb.DroppedPoint{{Point: points[1], Reason: "field type conflict", Index: 1}},
+		}
+	}
+
+	body := "cpu value=1 1\ncpu value= 2\ncpu value=\"x\" 3\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0&details=true", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if exp := `{"error":"partial write","accepted":1,"rejected":2,"lines":[{"line":2,"reason":"missing field value"},{"line":3,"reason":"field type conflict"}]}`; w.Body.String() != exp {
+		t.Fatalf("unexpected body:\n\nexp=%s\n\ngot=%s", exp, w.Body.String())
+	}
+
+	// Without details, only the reason of the partial write is returned.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if exp := `{"error":"partial write: field type conflict: input field \"value\" on measurement \"cpu\" is type string, already exists as type float dropped=1"}`; strings.TrimSpace(w.Body.String()) != exp {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// Ensure the handler only lists the first rejected lines of a write, while
+// counting all of them.
+func TestHandler_Write_Details_Limit(t *testing.T) {
+	h := NewHandler(false)
+	h.Config.WriteBatchSize = 100
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+
+	body := strings.Repeat("cpu value= 1\n", 1005)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0&details=true", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	var resp struct {
+		Rejected int `json:"rejected"`
+		Lines    []struct {
+			Line int `json:"line"`
+		} `json:"lines"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.Rejected != 1005 || len(resp.Lines) != 1000 {
+		t.Fatalf("unexpected rejected lines: %d/%d", len(resp.Lines), resp.Rejected)
+	} else if first, last := resp.Lines[0].Line, resp.Lines[999].Line; first != 1 || last != 1000 {
+		t.Fatalf("unexpected lines: %d-%d", first, last)
+	}
+}
+
+// Ensure the handler reports the lines of points dropped after a transform
+// rule rewrote them.
+func TestHandler_Write_Details_Transform(t *testing.T) {
+	tr, err := coordinator.NewTransformer([]coordinator.TransformConfig{
+		{Database: "db0", TagExclude: []string{"pid"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		// The shard drops the second point as transformed, which is no longer
+		// the point given, and reports it at its index like the points writer.
+		transformed, _, _ := tr.Transform(database, points)
+		return tsdb.PartialWriteError{
+			Reason:        "field type conflict",
+			Dropped:       1,
+			DroppedPoints: []tsdb.DroppedPoint{{Point: transformed[1], Reason: "field type conflict", Index: 1}},
+		}
+	}
+
+	body := "cpu,pid=1 value=1 1\ncpu value= 2\ncpu,pid=2 value=\"x\" 3\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0&details=true", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if exp := `{"error":"partial write","accepted":1,"rejected":2,"lines":[{"line":2,"reason":"missing field value"},{"line":3,"reason":"field type conflict"}]}`; w.Body.String() != exp {
+		t.Fatalf("unexpected body:\n\nexp=%s\n\ngot=%s", exp, w.Body.String())
+	}
+}
+
+// Ensure the handler only writes the series of a user's measurement grants.
+func TestHandler_Write_MeasurementGrants(t *testing.T) {
+	h := NewHandler(true)
+	h.Handler.WriteAuthorizer = &HandlerWriteAuthorizer{}
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.MetaClient.UsersFn = func() []meta.UserInfo {
+		return []meta.UserInfo{{Name: "admin", Admin: true}}
+	}
+	h.MetaClient.AuthenticateFn = func(u, p string) (*meta.UserInfo, error) {
+		return &meta.UserInfo{
+			Name: "user1",
+			Grants: []meta.MeasurementGrant{
+				{Database: "db0", Name: "cpu", Condition: "host = 'a'", Privilege: influxql.WritePrivilege},
+			},
+		}, nil
+	}
+
+	var written []string
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		for _, p := range points {
+			written = append(written, p.String())
+		}
+		return nil
+	}
+
+	body := "cpu,host=a value=1 1\ncpu,host=b value=2 2\nmem,host=a value=3 3\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0&details=true&u=user1&p=abcd", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if exp := `{"error":"partial write","accepted":1,"rejected":2,"lines":[{"line":2,"reason":"unable to write 'cpu,host=b': not authorized"},{"line":3,"reason":"unable to write 'mem,host=a': not authorized"}]}`; w.Body.String() != exp {
+		t.Fatalf("unexpected body:\n\nexp=%s\n\ngot=%s", exp, w.Body.String())
+	} else if exp := []string{"cpu,host=a value=1 1"}; !reflect.DeepEqual(written, exp) {
+		t.Fatalf("unexpected points written: %v", written)
+	}
+}
+
+// Ensure the handler authenticates writes with API tokens, restricted to the
+// privileges of the token.
+func TestHandler_Write_Token(t *testing.T) {
+	h := NewHandler(true)
+	h.Handler.WriteAuthorizer = &HandlerWriteAuthorizer{}
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.MetaClient.UsersFn = func() []meta.UserInfo {
+		return []meta.UserInfo{{Name: "admin", Admin: true}}
+	}
+	h.MetaClient.AuthenticateTokenFn = func(token string) (*meta.UserInfo, error) {
+		switch token {
+		case "0123abcd":
+			return &meta.UserInfo{
+				Name:       "admin",
+				Privileges: map[string]influxql.Privilege{"db0": influxql.WritePrivilege},
+			}, nil
+		case "expired":
+			return nil, meta.ErrTokenExpired
+		}
+		return nil, meta.ErrAuthenticate
+	}
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		return nil
+	}
+
+	for i, tt := range []struct {
+		token string
+		db    string
+		code  int
+		body  string
+	}{
+		{token: "0123abcd", db: "db0", code: http.StatusNoContent},
+		{token: "0123abcd", db: "db1", code: http.StatusForbidden, body: `{"error":"\"admin\" user is not authorized to write to database \"db1\""}`},
+		{token: "expired", db: "db0", code: http.StatusUnauthorized, body: `{"error":"token expired"}`},
+		{token: "unknown", db: "db0", code: http.StatusUnauthorized, body: `{"error":"authentication failed"}`},
+	} {
+		r := MustNewRequest("POST", "/write?db="+tt.db, strings.NewReader("cpu value=1 1\n"))
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%d. unexpected status: got=%d exp=%d\noutput: %s", i, w.Code, tt.code, w.Body.String())
+		} else if tt.body != "" && strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Errorf("%d. unexpected body: %s", i, w.Body.String())
+		}
+	}
+}
+
+// Ensure authentication and authorization failures are recorded in the audit
+// log, and that the user and address of queries are passed to the executor.
+func TestHandler_AuditLog(t *testing.T) {
+	h := NewHandler(true)
+	h.Handler.WriteAuthorizer = &HandlerWriteAuthorizer{}
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.MetaClient.UsersFn = func() []meta.UserInfo {
+		return []meta.UserInfo{{Name: "admin", Admin: true}}
+	}
+	h.MetaClient.AuthenticateFn = func(username, password string) (*meta.UserInfo, error) {
+		if username == "bob" && password == "secret" {
+			return &meta.UserInfo{
+				Name:       "bob",
+				Privileges: map[string]influxql.Privilege{"db0": influxql.AllPrivileges},
+			}, nil
+		}
+		return nil, meta.ErrAuthenticate
+	}
+	h.QueryAuthorizer.AuthorizeQueryFn = func(u *meta.UserInfo, query *influxql.Query, database string) error {
+		return nil
+	}
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+		if ctx.User != "bob" || ctx.RemoteAddr != "127.0.0.1:1234" {
+			t.Errorf("unexpected user and address: %s %s", ctx.User, ctx.RemoteAddr)
+		}
+		ctx.Results <- &influxql.Result{StatementID: 1}
+		return nil
+	}
+
+	var entries []audit.Entry
+	h.Handler.AuditLog = HandlerAuditLog(func(e audit.Entry) {
+		entries = append(entries, e)
+	})
+
+	for _, r := range []*http.Request{
+		MustNewJSONRequest("GET", "/query?u=bob&p=wrong&db=db0&q=SHOW+MEASUREMENTS", nil),
+		MustNewJSONRequest("GET", "/query?u=bob&p=secret&db=db0&q=SHOW+MEASUREMENTS", nil),
+		MustNewRequest("POST", "/write?db=db1&u=bob&p=secret", strings.NewReader("cpu value=1 1\n")),
+	} {
+		r.RemoteAddr = "127.0.0.1:1234"
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if !reflect.DeepEqual(entries, []audit.Entry{
+		{Event: audit.EventAuthentication, User: "bob", RemoteAddr: "127.0.0.1:1234", Result: "authentication failed"},
+		{Event: audit.EventAuthorization, User: "bob", RemoteAddr: "127.0.0.1:1234", Database: "db1", Result: `"bob" user is not authorized to write to database "db1"`},
+	}) {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+}
+
+// HandlerAuditLog records the entries of the audit log by calling a function.
+type HandlerAuditLog func(e audit.Entry)
+
+func (fn HandlerAuditLog) Log(e audit.Entry) { fn(e) }
+
+// Ensure the handler writes Prometheus remote write requests as points.
+func TestHandler_PromWrite(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{{
+			Labels:  []*remote.LabelPair{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}},
+			Samples: []*remote.Sample{{TimestampMs: 1000, Value: 1}},
+		}},
+	}
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+
+	var called bool
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		called = true
+		if database != "db0" {
+			t.Fatalf("unexpected database: %s", database)
+		} else if len(points) != 1 || points[0].String() != "up,job=api value=1 1000000000" {
+			t.Fatalf("unexpected points: %v", points)
+		}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/write?db=db0", bytes.NewReader(snappy.Encode(nil, data))))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if !called {
+		t.Fatal("expected points to be written")
+	}
+}
+
+// Ensure the handler answers Prometheus remote read requests with a query.
+func TestHandler_PromRead(t *testing.T) {
+	req := &remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000,
+			EndTimestampMs:   2000,
+			Matchers:         []*remote.LabelMatcher{{Name: "__name__", Value: "up"}},
+		}},
+	}
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+		if exp := `SELECT value FROM db0..up WHERE time >= '1970-01-01T00:00:01Z' AND time <= '1970-01-01T00:00:02Z' GROUP BY *`; stmt.String() != exp {
+			t.Fatalf("unexpected query: %s", stmt.String())
+		}
+
+		// Send the series in two chunks.
+		for i := 1; i <= 2; i++ {
+			ctx.Results <- &influxql.Result{StatementID: ctx.StatementID, Series: models.Rows{{
+				Name:    "up",
+				Tags:    map[string]string{"job": "api"},
+				Columns: []string{"time", "value"},
+				Values:  [][]interface{}{{time.Unix(int64(i), 0).UTC(), float64(i)}},
+			}}}
+		}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/read?db=db0", bytes.NewReader(snappy.Encode(nil, data))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+
+	buf, err := snappy.Decode(nil, w.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp remote.ReadResponse
+	if err := proto.Unmarshal(buf, &resp); err != nil {
+		t.Fatal(err)
+	} else if s := resp.String(); s != `results:<timeseries:<labels:<name:"__name__" value:"up" > labels:<name:"job" value:"api" > samples:<value:1 timestamp_ms:1000 > samples:<value:2 timestamp_ms:2000 > > > ` {
+		t.Fatalf("unexpected response: %s", s)
+	}
+}
+
+// Ensure the user and address of Prometheus remote read requests are passed
+// to the executor, so that the limits of the user apply.
+func TestHandler_PromRead_User(t *testing.T) {
+	data, err := proto.Marshal(&remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000,
+			EndTimestampMs:   2000,
+			Matchers:         []*remote.LabelMatcher{{Name: "__name__", Value: "up"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(true)
+	h.MetaClient.UsersFn = func() []meta.UserInfo {
+		return []meta.UserInfo{{Name: "admin", Admin: true}}
+	}
+	h.MetaClient.AuthenticateFn = func(username, password string) (*meta.UserInfo, error) {
+		return &meta.UserInfo{Name: "bob"}, nil
+	}
+	h.QueryAuthorizer.AuthorizeQueryFn = func(u *meta.UserInfo, query *influxql.Query, database string) error {
+		return nil
+	}
+
+	var called bool
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx influxql.ExecutionContext) error {
+		if ctx.User != "bob" || ctx.RemoteAddr != "127.0.0.1:1234" {
+			t.Errorf("unexpected user and address: %s %s", ctx.User, ctx.RemoteAddr)
+		}
+		called = true
+		return nil
+	}
+
+	r := MustNewRequest("POST", "/api/v1/prom/read?db=db0&u=bob&p=secret", bytes.NewReader(snappy.Encode(nil, data)))
+	r.RemoteAddr = "127.0.0.1:1234"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if !called {
+		t.Fatal("expected query to be executed")
+	}
+}
+
+// Ensure the handler rejects Prometheus requests larger than the maximum size,
+// before or after they are decompressed.
+func TestHandler_PromWrite_TooLarge(t *testing.T) {
+	h := NewHandler(false)
+	h.Config.MaxPrometheusRequestSize = 64
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		t.Fatal("unexpected write")
+		return nil
+	}
+
+	for _, body := range [][]byte{
+		bytes.Repeat([]byte{'a'}, 128),
+		snappy.Encode(nil, bytes.Repeat([]byte{0}, 1024)),
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/write?db=db0", bytes.NewReader(body)))
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+		}
+	}
+}
+
 // Ensure the handler handles ping requests correctly.
 // TODO: This should be expanded to verify the MetaClient check in servePing is working correctly
 func TestHandler_Ping(t *testing.T) {
@@ -695,6 +1288,49 @@ func TestHandler_Status(t *testing.T) {
 	}
 }
 
+// Ensure the handler renders statistics in the Prometheus exposition format.
+func TestHandler_Metrics(t *testing.T) {
+	h := NewHandler(false)
+	h.Monitor.StatisticsFn = func(tags map[string]string) ([]*monitor.Statistic, error) {
+		return []*monitor.Statistic{
+			{Statistic: models.Statistic{
+				Name:   "httpd",
+				Tags:   map[string]string{"bind": ":8086", "path": `C:\"db"`},
+				Values: map[string]interface{}{"queryReq": int64(3), "reqDurationNs": float64(1.5)},
+			}},
+			{Statistic: models.Statistic{
+				Name:   "httpd",
+				Tags:   map[string]string{"bind": ":8087"},
+				Values: map[string]interface{}{"queryReq": int64(4)},
+			}},
+			{Statistic: models.Statistic{
+				Name:   "sys",
+				Values: map[string]interface{}{"uptime": true, "version": "1.2.0"},
+			}},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if ct := w.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+
+	exp := `# TYPE influxdb_httpd_query_req untyped
+influxdb_httpd_query_req{bind=":8086",path="C:\\\"db\""} 3
+influxdb_httpd_query_req{bind=":8087"} 4
+# TYPE influxdb_httpd_req_duration_ns untyped
+influxdb_httpd_req_duration_ns{bind=":8086",path="C:\\\"db\""} 1.5
+# TYPE influxdb_sys_uptime untyped
+influxdb_sys_uptime 1
+`
+	if body := w.Body.String(); body != exp {
+		t.Fatalf("unexpected body:\n%s", body)
+	}
+}
+
 // Ensure write endpoint can handle bad requests
 func TestHandler_HandleBadRequestBody(t *testing.T) {
 	b := bytes.NewReader(make([]byte, 10))
@@ -733,6 +1369,8 @@ type Handler struct {
 	MetaClient        HandlerMetaStore
 	StatementExecutor HandlerStatementExecutor
 	QueryAuthorizer   HandlerQueryAuthorizer
+	PointsWriter      HandlerPointsWriter
+	Monitor           HandlerMonitor
 }
 
 // NewHandler returns a new instance of Handler.
@@ -748,17 +1386,20 @@ func NewHandler(requireAuthentication bool) *Handler {
 	h.Handler.QueryExecutor = influxql.NewQueryExecutor()
 	h.Handler.QueryExecutor.StatementExecutor = &h.StatementExecutor
 	h.Handler.QueryAuthorizer = &h.QueryAuthorizer
+	h.Handler.PointsWriter = &h.PointsWriter
+	h.Handler.Monitor = &h.Monitor
 	h.Handler.Version = "0.0.0"
 	return h
 }
 
 // HandlerMetaStore is a mock implementation of Handler.MetaClient.
 type HandlerMetaStore struct {
-	PingFn         func(d time.Duration) error
-	DatabaseFn     func(name string) *meta.DatabaseInfo
-	AuthenticateFn func(username, password string) (ui *meta.UserInfo, err error)
-	UsersFn        func() []meta.UserInfo
-	UserFn         func(username string) (*meta.UserInfo, error)
+	PingFn              func(d time.Duration) error
+	DatabaseFn          func(name string) *meta.DatabaseInfo
+	AuthenticateFn      func(username, password string) (ui *meta.UserInfo, err error)
+	AuthenticateTokenFn func(token string) (*meta.UserInfo, error)
+	UsersFn             func() []meta.UserInfo
+	UserFn              func(username string) (*meta.UserInfo, error)
 }
 
 func (s *HandlerMetaStore) Ping(b bool) error {
@@ -777,6 +1418,10 @@ func (s *HandlerMetaStore) Authenticate(username, password string) (ui *meta.Use
 	return s.AuthenticateFn(username, password)
 }
 
+func (s *HandlerMetaStore) AuthenticateToken(token string) (*meta.UserInfo, error) {
+	return s.AuthenticateTokenFn(token)
+}
+
 func (s *HandlerMetaStore) Users() []meta.UserInfo {
 	return s.UsersFn()
 }
@@ -803,6 +1448,31 @@ func (a *HandlerQueryAuthorizer) AuthorizeQuery(u *meta.UserInfo, query *influxq
 	return a.AuthorizeQueryFn(u, query, database)
 }
 
+// HandlerWriteAuthorizer is a mock implementation of Handler.WriteAuthorizer.
+type HandlerWriteAuthorizer struct{}
+
+func (a *HandlerWriteAuthorizer) AuthorizeWrite(username, database string) error {
+	return nil
+}
+
+// HandlerPointsWriter is a mock implementation of Handler.PointsWriter.
+type HandlerPointsWriter struct {
+	WritePointsFn func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+}
+
+func (w *HandlerPointsWriter) WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+	return w.WritePointsFn(database, retentionPolicy, consistencyLevel, points)
+}
+
+// HandlerMonitor is a mock implementation of Handler.Monitor.
+type HandlerMonitor struct {
+	StatisticsFn func(tags map[string]string) ([]*monitor.Statistic, error)
+}
+
+func (m *HandlerMonitor) Statistics(tags map[string]string) ([]*monitor.Statistic, error) {
+	return m.StatisticsFn(tags)
+}
+
 // MustNewRequest returns a new HTTP request. Panic on error.
 func MustNewRequest(method, urlStr string, body io.Reader) *http.Request {
 	r, err := http.NewRequest(method, urlStr, body)
@@ -1,10 +1,121 @@
 package httpd
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"sync"
 )
 
+// Modes of HTTPS client certificate verification.
+const (
+	// ClientAuthNone doesn't request client certificates.
+	ClientAuthNone = "none"
+
+	// ClientAuthVerify verifies client certificates if they are given.
+	ClientAuthVerify = "verify"
+
+	// ClientAuthRequire requires a valid client certificate on every
+	// connection.
+	ClientAuthRequire = "require"
+)
+
+// NewTLSConfig returns the TLS configuration of an HTTPS listener serving the
+// certificate and key. Client certificates are verified against the CA bundle
+// in the clientCA file depending on the clientAuth mode.
+func NewTLSConfig(cert, key, clientCA, clientAuth string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+	}
+
+	switch clientAuth {
+	case ClientAuthNone, "":
+		return config, nil
+	case ClientAuthVerify:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("invalid https client auth mode %q, expected %q, %q or %q", clientAuth, ClientAuthNone, ClientAuthVerify, ClientAuthRequire)
+	}
+
+	if clientCA == "" {
+		return nil, fmt.Errorf("https client CA required to verify client certificates")
+	}
+	pem, err := ioutil.ReadFile(clientCA)
+	if err != nil {
+		return nil, err
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in https client CA %s", clientCA)
+	}
+	return config, nil
+}
+
+// CertificateIdentities returns the identities of a client certificate that
+// can be mapped to users: its subject common name as "CN=name", and its
+// subject alternative names as "DNS:name", "EMAIL:address" and "URI:uri".
+func CertificateIdentities(cert *x509.Certificate) []string {
+	var ids []string
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, "CN="+cert.Subject.CommonName)
+	}
+	for _, name := range cert.DNSNames {
+		ids = append(ids, "DNS:"+name)
+	}
+	for _, addr := range cert.EmailAddresses {
+		ids = append(ids, "EMAIL:"+addr)
+	}
+	for _, uri := range certificateURIs(cert) {
+		ids = append(ids, "URI:"+uri)
+	}
+	return ids
+}
+
+// oidSubjectAltName is the object identifier of the subject alternative name
+// certificate extension.
+var oidSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// certificateURIs returns the URI subject alternative names of cert, such as
+// SPIFFE IDs. They are parsed from the certificate extension since
+// crypto/x509 doesn't expose them.
+func certificateURIs(cert *x509.Certificate) []string {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+
+		var seq asn1.RawValue
+		if rest, err := asn1.Unmarshal(ext.Value, &seq); err != nil || len(rest) != 0 || !seq.IsCompound {
+			return nil
+		}
+
+		var uris []string
+		for rest := seq.Bytes; len(rest) > 0; {
+			var v asn1.RawValue
+			var err error
+			if rest, err = asn1.Unmarshal(rest, &v); err != nil {
+				return uris
+			}
+
+			// URIs are the uniformResourceIdentifier [6] choice of GeneralName.
+			if v.Class == asn1.ClassContextSpecific && v.Tag == 6 {
+				uris = append(uris, string(v.Bytes))
+			}
+		}
+		return uris
+	}
+	return nil
+}
+
 // LimitListener returns a Listener that accepts at most n simultaneous
 // connections from the provided Listener and will drop extra connections.
 func LimitListener(l net.Listener, n int) net.Listener {
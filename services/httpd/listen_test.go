@@ -1,8 +1,21 @@
 package httpd_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
 	"io"
+	"io/ioutil"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -94,3 +107,111 @@ func BenchmarkLimitListener(b *testing.B) {
 	}
 	wg.Wait()
 }
+
+func TestNewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpd-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cert := MustCertificate(pkix.Name{CommonName: "localhost"}, nil)
+	certPath := filepath.Join(dir, "cert.pem")
+	if err := ioutil.WriteFile(certPath, append(cert.CertPEM, cert.KeyPEM...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	emptyPath := filepath.Join(dir, "empty.pem")
+	if err := ioutil.WriteFile(emptyPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		clientCA   string
+		clientAuth string
+		exp        tls.ClientAuthType
+		err        bool
+	}{
+		{clientAuth: httpd.ClientAuthNone, exp: tls.NoClientCert},
+		{clientAuth: "", exp: tls.NoClientCert},
+		{clientCA: certPath, clientAuth: httpd.ClientAuthVerify, exp: tls.VerifyClientCertIfGiven},
+		{clientCA: certPath, clientAuth: httpd.ClientAuthRequire, exp: tls.RequireAndVerifyClientCert},
+		{clientAuth: httpd.ClientAuthRequire, err: true},
+		{clientCA: emptyPath, clientAuth: httpd.ClientAuthRequire, err: true},
+		{clientCA: certPath, clientAuth: "always", err: true},
+	} {
+		config, err := httpd.NewTLSConfig(certPath, certPath, tt.clientCA, tt.clientAuth)
+		if (err != nil) != tt.err {
+			t.Errorf("%s %q: unexpected error: %v", tt.clientCA, tt.clientAuth, err)
+		} else if err == nil && config.ClientAuth != tt.exp {
+			t.Errorf("%s %q: unexpected client auth: got %v, exp %v", tt.clientCA, tt.clientAuth, config.ClientAuth, tt.exp)
+		}
+	}
+}
+
+func TestCertificateIdentities(t *testing.T) {
+	cert := MustCertificate(pkix.Name{CommonName: "telegraf"}, []asn1.RawValue{
+		{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte("telegraf.monitoring.svc")},
+		{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte("spiffe://cluster.local/ns/monitoring/sa/telegraf")},
+	})
+
+	if ids, exp := httpd.CertificateIdentities(cert.Certificate), []string{
+		"CN=telegraf",
+		"DNS:telegraf.monitoring.svc",
+		"URI:spiffe://cluster.local/ns/monitoring/sa/telegraf",
+	}; !reflect.DeepEqual(ids, exp) {
+		t.Fatalf("unexpected identities:\ngot %v\nexp %v", ids, exp)
+	}
+}
+
+// Certificate is a self-signed certificate generated for tests.
+type Certificate struct {
+	*x509.Certificate
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// MustCertificate returns a new self-signed certificate for subject, with the
+// subject alternative names sans. Panic on error.
+func MustCertificate(subject pkix.Name, sans []asn1.RawValue) *Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if len(sans) > 0 {
+		value, err := asn1.Marshal(sans)
+		if err != nil {
+			panic(err)
+		}
+		template.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: value}}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+	return &Certificate{
+		Certificate: cert,
+		CertPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
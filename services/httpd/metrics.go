@@ -0,0 +1,177 @@
+package httpd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/darshanman40/influxdb/monitor"
+)
+
+// metricsNamespace prefixes the name of every metric exposed on /metrics.
+const metricsNamespace = "influxdb"
+
+// serveMetrics renders the statistics of the monitor in the Prometheus text
+// exposition format.
+func (h *Handler) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.Monitor.Statistics(nil)
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeMetrics(w, stats)
+}
+
+// writeMetrics writes stats to w in the Prometheus text exposition format.
+// Each statistic value becomes a sample of a metric named after the
+// statistic and the value's key, labelled with the statistic's tags.
+// Values that are not numeric or boolean are skipped.
+func writeMetrics(w io.Writer, stats []*monitor.Statistic) {
+	// Samples of a metric must be written together, so group them by name.
+	samples := make(map[string][]string)
+	for _, s := range stats {
+		labels := metricLabels(s.Tags)
+		for k, v := range s.Values {
+			value, ok := metricValue(v)
+			if !ok {
+				continue
+			}
+			name := metricName(metricsNamespace, s.Name, k)
+			samples[name] = append(samples[name], name+labels+" "+value)
+		}
+	}
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "# TYPE %s untyped\n", name)
+		lines := samples[name]
+		sort.Strings(lines)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// metricName joins parts into a valid metric name, converting each part from
+// camel case to snake case and replacing invalid characters with underscores.
+func metricName(parts ...string) string {
+	var buf bytes.Buffer
+	for i, part := range parts {
+		if i > 0 {
+			buf.WriteByte('_')
+		}
+		buf.WriteString(sanitizeMetricName(part))
+	}
+	return buf.String()
+}
+
+// sanitizeMetricName converts s to snake case and replaces every character
+// that is not valid in a metric or label name with an underscore.
+func sanitizeMetricName(s string) string {
+	var buf bytes.Buffer
+	var prev rune
+	for i, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+				buf.WriteByte('_')
+			}
+			buf.WriteRune(unicode.ToLower(r))
+		case (r >= 'a' && r <= 'z') || r == '_':
+			buf.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				buf.WriteByte('_')
+			}
+			buf.WriteRune(r)
+		default:
+			buf.WriteByte('_')
+		}
+		prev = r
+	}
+	return buf.String()
+}
+
+// metricLabels returns the label set for tags, with keys sorted.
+func metricLabels(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", sanitizeLabelName(k), labelValueReplacer.Replace(tags[k])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sanitizeLabelName replaces every character that is not valid in a label
+// name with an underscore. Unlike metric names, label names keep their case.
+func sanitizeLabelName(s string) string {
+	var buf bytes.Buffer
+	for i, r := range s {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_':
+			buf.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				buf.WriteByte('_')
+			}
+			buf.WriteRune(r)
+		default:
+			buf.WriteByte('_')
+		}
+	}
+	return buf.String()
+}
+
+// labelValueReplacer escapes label values.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricValue formats a statistic value as a sample value.
+func metricValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int:
+		return strconv.Itoa(v), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN", true
+		case math.IsInf(v, 1):
+			return "+Inf", true
+		case math.IsInf(v, -1):
+			return "-Inf", true
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	default:
+		return "", false
+	}
+}
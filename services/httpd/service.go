@@ -22,6 +22,8 @@ const (
 	statRequest                      = "req"                  // Number of HTTP requests served
 	statQueryRequest                 = "queryReq"             // Number of query requests served
 	statWriteRequest                 = "writeReq"             // Number of write requests serverd
+	statPromWriteRequest             = "promWriteReq"         // Number of Prometheus remote write requests served
+	statPromReadRequest              = "promReadReq"          // Number of Prometheus remote read requests served
 	statPingRequest                  = "pingReq"              // Number of ping requests served
 	statStatusRequest                = "statusReq"            // Number of status requests served
 	statWriteRequestBytesReceived    = "writeReqBytes"        // Sum of all bytes in write requests
@@ -49,6 +51,9 @@ type Service struct {
 	limit int
 	err   chan error
 
+	clientAuth string
+	clientCA   string
+
 	unixSocket         bool
 	bindSocket         string
 	unixSocketListener net.Listener
@@ -66,6 +71,8 @@ func NewService(c Config) *Service {
 		cert:       c.HTTPSCertificate,
 		key:        c.HTTPSPrivateKey,
 		limit:      c.MaxConnectionLimit,
+		clientAuth: c.HTTPSClientAuth,
+		clientCA:   c.HTTPSClientCA,
 		err:        make(chan error),
 		unixSocket: c.UnixSocketEnabled,
 		bindSocket: c.BindSocket,
@@ -86,14 +93,12 @@ func (s *Service) Open() error {
 
 	// Open listener.
 	if s.https {
-		cert, err := tls.LoadX509KeyPair(s.cert, s.key)
+		config, err := NewTLSConfig(s.cert, s.key, s.clientCA, s.clientAuth)
 		if err != nil {
 			return err
 		}
 
-		listener, err := tls.Listen("tcp", s.addr, &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		})
+		listener, err := tls.Listen("tcp", s.addr, config)
 		if err != nil {
 			return err
 		}
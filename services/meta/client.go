@@ -6,6 +6,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"io"
@@ -29,6 +30,14 @@ const (
 	// SaltBytes is the number of bytes used for salts.
 	SaltBytes = 32
 
+	// TokenBytes is the number of random bytes in API tokens.
+	TokenBytes = 32
+
+	// TokenLastUsedInterval is the minimum time between updates of the
+	// last-used time of a token, which limits meta data writes for tokens
+	// used by every request.
+	TokenLastUsedInterval = time.Minute
+
 	metaFile = "meta.db"
 
 	// ShardGroupDeletedExpiration is the amount of time before a shard group info will be removed from cached
@@ -57,6 +66,17 @@ type Client struct {
 	// Authentication cache.
 	authCache map[string]authUser
 
+	// Users with the privileges of their roles resolved, by name.
+	users map[string]*UserInfo
+
+	// Requirements of new passwords and bcrypt cost of their hashes.
+	passwordPolicy passwordPolicy
+	hashCost       int
+
+	// Failed authentications by user.
+	lockoutMu sync.Mutex
+	lockout   authLockout
+
 	path string
 
 	retentionAutoCreate bool
@@ -81,6 +101,16 @@ func NewClient(config *Config) *Client {
 		authCache:           make(map[string]authUser, 0),
 		path:                config.Dir,
 		retentionAutoCreate: config.RetentionAutoCreate,
+		passwordPolicy: passwordPolicy{
+			minLength:           config.PasswordMinLength,
+			minCharacterClasses: config.PasswordMinCharacterClasses,
+		},
+		hashCost: config.PasswordHashCost,
+		lockout: authLockout{
+			maxFailures: config.MaxAuthFailures,
+			duration:    time.Duration(config.AuthLockoutDuration),
+			users:       make(map[string]*authFailures),
+		},
 	}
 }
 
@@ -93,6 +123,7 @@ func (c *Client) Open() error {
 	if err := c.Load(); err != nil {
 		return err
 	}
+	c.updateAuthCache()
 
 	// If this is a brand new instance, persist to disk immediatly.
 	if c.cacheData.Index == 1 {
@@ -363,6 +394,84 @@ func (c *Client) UpdateRetentionPolicy(database, name string, rpu *RetentionPoli
 	return nil
 }
 
+// UpdateDatabaseQuota updates the quota of a database.
+func (c *Client) UpdateDatabaseQuota(name string, qu *QuotaUpdate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.UpdateDatabaseQuota(name, qu); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDatabaseQueryLimits updates the query limits of a database.
+func (c *Client) UpdateDatabaseQueryLimits(name string, lu *QueryLimitsUpdate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.UpdateDatabaseQueryLimits(name, lu); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateUserQueryLimits updates the query limits of a user.
+func (c *Client) UpdateUserQueryLimits(name string, lu *QueryLimitsUpdate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.UpdateUserQueryLimits(name, lu); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DatabaseQueryLimits returns the query limits of a database, which are unset
+// if the database doesn't exist.
+func (c *Client) DatabaseQueryLimits(name string) influxql.QueryLimits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if di := c.cacheData.Database(name); di != nil {
+		return di.QueryLimits
+	}
+	return influxql.QueryLimits{}
+}
+
+// UserQueryLimits returns the query limits of a user, which are unset if the
+// user doesn't exist.
+func (c *Client) UserQueryLimits(name string) influxql.QueryLimits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if ui := c.cacheData.User(name); ui != nil {
+		return ui.QueryLimits
+	}
+	return influxql.QueryLimits{}
+}
+
 // Users returns a slice of UserInfo representing the currently known users.
 func (c *Client) Users() []UserInfo {
 	c.mu.RLock()
@@ -377,23 +486,48 @@ func (c *Client) Users() []UserInfo {
 }
 
 // User returns the user with the given name, or ErrUserNotFound.
+// The user's privileges include those of its roles.
 func (c *Client) User(name string) (*UserInfo, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	for _, u := range c.cacheData.Users {
-		if u.Name == name {
-			return &u, nil
-		}
+	if u := c.user(name); u != nil {
+		return u, nil
 	}
-
 	return nil, ErrUserNotFound
 }
 
+// user returns a copy of the named user with the privileges of its roles
+// resolved, or nil if the user doesn't exist.
+// This method assumes c's mutex is already locked.
+func (c *Client) user(name string) *UserInfo {
+	if u, ok := c.users[name]; ok {
+		other := *u
+		return &other
+	} else if u := c.cacheData.User(name); u != nil {
+		return c.cacheData.ResolveUser(u)
+	}
+	return nil
+}
+
 // bcryptCost is the cost associated with generating password with bcrypt.
 // This setting is lowered during testing to improve test suite performance.
 var bcryptCost = bcrypt.DefaultCost
 
+// passwordHashCost returns the configured bcrypt cost of password hashes.
+func (c *Client) passwordHashCost() int {
+	if c.hashCost == 0 {
+		return bcryptCost
+	}
+	return c.hashCost
+}
+
+// hashPassword returns the bcrypt hash of password.
+func (c *Client) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), c.passwordHashCost())
+	return string(hash), err
+}
+
 // hashWithSalt returns a salted hash of password using salt.
 func (c *Client) hashWithSalt(salt []byte, password string) []byte {
 	hasher := sha256.New()
@@ -427,13 +561,17 @@ func (c *Client) CreateUser(name, password string, admin bool) (*UserInfo, error
 		return u, nil
 	}
 
+	if err := c.passwordPolicy.check(password); err != nil {
+		return nil, err
+	}
+
 	// Hash the password before serializing it.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := c.hashPassword(password)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := data.CreateUser(name, string(hash), admin); err != nil {
+	if err := data.CreateUser(name, hash, admin); err != nil {
 		return nil, err
 	}
 
@@ -451,15 +589,19 @@ func (c *Client) UpdateUser(name, password string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if err := c.passwordPolicy.check(password); err != nil {
+		return err
+	}
+
 	data := c.cacheData.Clone()
 
 	// Hash the password before serializing it.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := c.hashPassword(password)
 	if err != nil {
 		return err
 	}
 
-	if err := data.UpdateUser(name, string(hash)); err != nil {
+	if err := data.UpdateUser(name, hash); err != nil {
 		return err
 	}
 
@@ -508,6 +650,25 @@ func (c *Client) SetPrivilege(username, database string, p influxql.Privilege) e
 	return nil
 }
 
+// SetMeasurementPrivilege sets the privilege of a grant for the given user on
+// the measurements and series of a database.
+func (c *Client) SetMeasurementPrivilege(username string, g MeasurementGrant) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetMeasurementPrivilege(username, g); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // SetAdminPrivilege sets or unsets admin privilege to the given username.
 func (c *Client) SetAdminPrivilege(username string, admin bool) error {
 	c.mu.Lock()
@@ -526,7 +687,8 @@ func (c *Client) SetAdminPrivilege(username string, admin bool) error {
 	return nil
 }
 
-// UserPrivileges returns the privileges for a user mapped by database name.
+// UserPrivileges returns the privileges granted directly to a user, mapped by
+// database name. Privileges the user has through its roles are not included.
 func (c *Client) UserPrivileges(username string) (map[string]influxql.Privilege, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -538,7 +700,8 @@ func (c *Client) UserPrivileges(username string) (map[string]influxql.Privilege,
 	return p, nil
 }
 
-// UserPrivilege returns the privilege for the given user on the given database.
+// UserPrivilege returns the effective privilege for the given user on the given
+// database, including the privileges of the user's roles.
 func (c *Client) UserPrivilege(username, database string) (*influxql.Privilege, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -550,6 +713,226 @@ func (c *Client) UserPrivilege(username, database string) (*influxql.Privilege,
 	return p, nil
 }
 
+// UserMeasurementGrants returns the measurement grants of the given user.
+func (c *Client) UserMeasurementGrants(username string) ([]MeasurementGrant, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ui := c.cacheData.User(username)
+	if ui == nil {
+		return nil, ErrUserNotFound
+	}
+	return ui.Grants, nil
+}
+
+// CreateRole creates a role with the given name.
+func (c *Client) CreateRole(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.CreateRole(name); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DropRole removes the role with the given name.
+func (c *Client) DropRole(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.DropRole(name); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetRolePrivilege sets a privilege for the given role on the given database.
+func (c *Client) SetRolePrivilege(role, database string, p influxql.Privilege) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetRolePrivilege(role, database, p); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetUserRole adds the given user to a role, or removes the user from the
+// role if member is false.
+func (c *Client) SetUserRole(username, role string, member bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserRole(username, role, member); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Role returns the role with the given name.
+func (c *Client) Role(name string) (*RoleInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ri := c.cacheData.Role(name)
+	if ri == nil {
+		return nil, ErrRoleNotFound
+	}
+	other := ri.clone()
+	return &other, nil
+}
+
+// Roles returns a list of all roles.
+func (c *Client) Roles() []RoleInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheData.CloneRoles()
+}
+
+// CreateToken creates a new API token for a user, restricted to a privilege
+// on a database, and returns it. The token expires after expires, or never if
+// it is zero. Only the hash of the token is stored.
+func (c *Client) CreateToken(name, username, database string, p influxql.Privilege, expires time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	token, hash, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	ti := TokenInfo{
+		Name:      name,
+		User:      username,
+		Hash:      hash,
+		Database:  database,
+		Privilege: p,
+		CreatedAt: now,
+	}
+	if expires > 0 {
+		ti.Expiration = now.Add(expires)
+	}
+
+	if err := data.CreateToken(ti); err != nil {
+		return "", err
+	}
+
+	if err := c.commit(data); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// DropToken removes an API token, revoking it.
+func (c *Client) DropToken(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.DropToken(name); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Tokens returns a list of all API tokens.
+func (c *Client) Tokens() []TokenInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheData.CloneTokens()
+}
+
+// AuthenticateToken returns the UserInfo of the user an API token
+// authenticates as, restricted to the privileges of the token.
+func (c *Client) AuthenticateToken(token string) (*UserInfo, error) {
+	hash := hashToken(token)
+
+	// Find the token and its user.
+	c.mu.RLock()
+	var ti *TokenInfo
+	for i := range c.cacheData.Tokens {
+		if subtle.ConstantTimeCompare([]byte(c.cacheData.Tokens[i].Hash), []byte(hash)) == 1 {
+			other := c.cacheData.Tokens[i]
+			ti = &other
+			break
+		}
+	}
+	var ui *UserInfo
+	if ti != nil {
+		ui = c.user(ti.User)
+	}
+	c.mu.RUnlock()
+
+	if ti == nil || ui == nil {
+		return nil, ErrAuthenticate
+	}
+
+	now := time.Now().UTC()
+	if ti.Expired(now) {
+		return nil, ErrTokenExpired
+	}
+
+	if now.Sub(ti.LastUsed) >= TokenLastUsedInterval {
+		c.mu.Lock()
+		data := c.cacheData.Clone()
+		err := data.SetTokenLastUsed(ti.Name, now)
+		if err == nil {
+			err = c.commit(data)
+		}
+		c.mu.Unlock()
+
+		if err == ErrTokenNotFound {
+			// The token was dropped since it was found.
+			return nil, ErrAuthenticate
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return ti.restrict(ui), nil
+}
+
 // AdminUserExists returns true if any user has admin privilege.
 func (c *Client) AdminUserExists() bool {
 	c.mu.RLock()
@@ -567,12 +950,20 @@ func (c *Client) AdminUserExists() bool {
 func (c *Client) Authenticate(username, password string) (*UserInfo, error) {
 	// Find user.
 	c.mu.RLock()
-	userInfo := c.cacheData.User(username)
+	userInfo := c.user(username)
 	c.mu.RUnlock()
 	if userInfo == nil {
 		return nil, ErrUserNotFound
 	}
 
+	// Refuse users locked out, even with the right password.
+	c.lockoutMu.Lock()
+	locked := c.lockout.locked(username, time.Now())
+	c.lockoutMu.Unlock()
+	if locked {
+		return nil, ErrUserLocked
+	}
+
 	// Check the local auth cache first.
 	c.mu.RLock()
 	au, ok := c.authCache[username]
@@ -580,6 +971,7 @@ func (c *Client) Authenticate(username, password string) (*UserInfo, error) {
 	if ok {
 		// verify the password using the cached salt and hash
 		if bytes.Equal(c.hashWithSalt(au.salt, password), au.hash) {
+			c.authSucceeded(username)
 			return userInfo, nil
 		}
 
@@ -588,8 +980,20 @@ func (c *Client) Authenticate(username, password string) (*UserInfo, error) {
 
 	// Compare password with user hash.
 	if err := bcrypt.CompareHashAndPassword([]byte(userInfo.Hash), []byte(password)); err != nil {
+		c.lockoutMu.Lock()
+		c.lockout.fail(username, time.Now())
+		c.lockoutMu.Unlock()
 		return nil, ErrAuthenticate
 	}
+	c.authSucceeded(username)
+
+	// Upgrade hashes with another cost now that the password is known.
+	bhash := userInfo.Hash
+	if hash, err := c.rehashPassword(username, password, bhash); err != nil {
+		c.logger.Info(fmt.Sprintf("failed to rehash password of user %s: %s", username, err))
+	} else {
+		bhash = hash
+	}
 
 	// generate a salt and hash of the password for the cache
 	salt, hashed, err := c.saltedHash(password)
@@ -597,11 +1001,52 @@ func (c *Client) Authenticate(username, password string) (*UserInfo, error) {
 		return nil, err
 	}
 	c.mu.Lock()
-	c.authCache[username] = authUser{salt: salt, hash: hashed, bhash: userInfo.Hash}
+	c.authCache[username] = authUser{salt: salt, hash: hashed, bhash: bhash}
 	c.mu.Unlock()
 	return userInfo, nil
 }
 
+// authSucceeded forgets the failed authentications of the user.
+func (c *Client) authSucceeded(username string) {
+	c.lockoutMu.Lock()
+	c.lockout.succeed(username)
+	c.lockoutMu.Unlock()
+}
+
+// rehashPassword replaces the password hash of the user if its cost isn't
+// the configured one, and returns the current hash. The hash isn't replaced
+// if the password was changed meanwhile.
+func (c *Client) rehashPassword(username, password, hash string) (string, error) {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return hash, err
+	} else if cost == c.passwordHashCost() {
+		return hash, nil
+	}
+
+	newHash, err := c.hashPassword(password)
+	if err != nil {
+		return hash, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+	if u := data.User(username); u == nil || u.Hash != hash {
+		return hash, nil
+	}
+
+	if err := data.UpdateUser(username, newHash); err != nil {
+		return hash, err
+	}
+
+	if err := c.commit(data); err != nil {
+		return hash, err
+	}
+	return newHash, nil
+}
+
 // UserCount returns the number of users stored.
 func (c *Client) UserCount() int {
 	c.mu.RLock()
@@ -976,6 +1421,7 @@ func (c *Client) commit(data *Data) error {
 
 	// update in memory
 	c.cacheData = data
+	c.updateAuthCache()
 
 	// close channels to signal changes
 	close(c.changed)
@@ -998,19 +1444,26 @@ func (c *Client) WithLogger(log zap.Logger) {
 	c.logger = *log.With(zap.String("service", "metaclient"))
 }
 
+// updateAuthCache rebuilds the authentication cache and the resolved users
+// after the meta data changed.
+// This method assumes c's mutex is already locked.
 func (c *Client) updateAuthCache() {
 	// copy cached user info for still-present users
 	newCache := make(map[string]authUser, len(c.authCache))
+	users := make(map[string]*UserInfo, len(c.cacheData.Users))
 
-	for _, userInfo := range c.cacheData.Users {
+	for i := range c.cacheData.Users {
+		userInfo := &c.cacheData.Users[i]
 		if cached, ok := c.authCache[userInfo.Name]; ok {
 			if cached.bhash == userInfo.Hash {
 				newCache[userInfo.Name] = cached
 			}
 		}
+		users[userInfo.Name] = c.cacheData.ResolveUser(userInfo)
 	}
 
 	c.authCache = newCache
+	c.users = users
 }
 
 // snapshot saves the current meta data to disk.
@@ -16,6 +16,8 @@ import (
 
 	"github.com/darshanman40/influxdb/influxql"
 	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/toml"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestMetaClient_CreateDatabaseOnly(t *testing.T) {
@@ -526,6 +528,129 @@ func TestMetaClient_UpdateRetentionPolicy(t *testing.T) {
 	}
 }
 
+func TestMetaClient_UpdateDatabaseQuota(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	if _, err := c.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	seriesN, diskBytes := int64(1000), int64(1<<30)
+	if err := c.UpdateDatabaseQuota("db0", &meta.QuotaUpdate{
+		MaxSeriesN:   &seriesN,
+		MaxDiskBytes: &diskBytes,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the given limits are updated.
+	writeRate := int64(5000)
+	if err := c.UpdateDatabaseQuota("db0", &meta.QuotaUpdate{MaxWriteRate: &writeRate}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := meta.DatabaseQuota{MaxSeriesN: 1000, MaxDiskBytes: 1 << 30, MaxWriteRate: 5000}
+	if got := c.Database("db0").Quota; got != exp {
+		t.Fatalf("unexpected quota:\n\texp: %+v\n\tgot: %+v", exp, got)
+	}
+
+	// The quota survives encoding.
+	data := c.Data()
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if got := other.Database("db0").Quota; got != exp {
+		t.Fatalf("unexpected decoded quota:\n\texp: %+v\n\tgot: %+v", exp, got)
+	}
+
+	negative := int64(-1)
+	if err := c.UpdateDatabaseQuota("db0", &meta.QuotaUpdate{MaxConcurrentQueries: &negative}); err != meta.ErrQuotaNegative {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.UpdateDatabaseQuota("db1", &meta.QuotaUpdate{MaxWriteRate: &writeRate}); err == nil || err.Error() != influxdb.ErrDatabaseNotFound("db1").Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMetaClient_UpdateQueryLimits(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	if _, err := c.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.CreateUser("bob", "pass", false); err != nil {
+		t.Fatal(err)
+	}
+
+	pointN, queries := 1000, 4
+	if err := c.UpdateDatabaseQueryLimits("db0", &meta.QueryLimitsUpdate{
+		MaxSelectPointN:      &pointN,
+		MaxConcurrentQueries: &queries,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout, priority := time.Minute, 2
+	if err := c.UpdateUserQueryLimits("bob", &meta.QueryLimitsUpdate{
+		QueryTimeout: &timeout,
+		Priority:     &priority,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expDB := influxql.QueryLimits{MaxSelectPointN: 1000, MaxConcurrentQueries: 4}
+	expUser := influxql.QueryLimits{QueryTimeout: time.Minute, Priority: 2}
+	if got := c.DatabaseQueryLimits("db0"); got != expDB {
+		t.Fatalf("unexpected database limits:\n\texp: %+v\n\tgot: %+v", expDB, got)
+	} else if got := c.UserQueryLimits("bob"); got != expUser {
+		t.Fatalf("unexpected user limits:\n\texp: %+v\n\tgot: %+v", expUser, got)
+	} else if got := c.UserQueryLimits("alice"); got != (influxql.QueryLimits{}) {
+		t.Fatalf("unexpected limits of unknown user: %+v", got)
+	}
+
+	// The limits survive encoding.
+	data := c.Data()
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if got := other.Database("db0").QueryLimits; got != expDB {
+		t.Fatalf("unexpected decoded database limits:\n\texp: %+v\n\tgot: %+v", expDB, got)
+	} else if got := other.User("bob").QueryLimits; got != expUser {
+		t.Fatalf("unexpected decoded user limits:\n\texp: %+v\n\tgot: %+v", expUser, got)
+	}
+
+	// A zero limit is unset.
+	zero := 0
+	if err := c.UpdateDatabaseQueryLimits("db0", &meta.QueryLimitsUpdate{MaxConcurrentQueries: &zero}); err != nil {
+		t.Fatal(err)
+	} else if got := c.DatabaseQueryLimits("db0"); got != (influxql.QueryLimits{MaxSelectPointN: 1000}) {
+		t.Fatalf("unexpected database limits: %+v", got)
+	}
+
+	negative := -1
+	if err := c.UpdateUserQueryLimits("bob", &meta.QueryLimitsUpdate{Priority: &negative}); err != meta.ErrQueryLimitNegative {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.UpdateUserQueryLimits("alice", &meta.QueryLimitsUpdate{Priority: &priority}); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.UpdateDatabaseQueryLimits("db1", &meta.QueryLimitsUpdate{Priority: &priority}); err == nil || err.Error() != influxdb.ErrDatabaseNotFound("db1").Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestMetaClient_DropRetentionPolicy(t *testing.T) {
 	t.Parallel()
 
@@ -1137,6 +1262,63 @@ func TestMetaClient_PersistClusterIDAfterRestart(t *testing.T) {
 	}
 }
 
+func TestMetaClient_SetMeasurementPrivilege(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.CreateUser("wilma", "password", false); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	g := meta.MeasurementGrant{Database: "db0", Name: "cpu", Condition: "host = 'serverA'", Privilege: influxql.ReadPrivilege}
+	if err := c.SetMeasurementPrivilege("wilma", g); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid grants are rejected.
+	bad := meta.MeasurementGrant{Database: "db0", Condition: "value > 1", Privilege: influxql.ReadPrivilege}
+	if err := c.SetMeasurementPrivilege("wilma", bad); err == nil {
+		t.Fatal("expected error for invalid grant condition")
+	}
+	if err := c.SetMeasurementPrivilege("betty", g); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Grants are persisted across restarts.
+	c.Close()
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	grants, err := c.UserMeasurementGrants("wilma")
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := []meta.MeasurementGrant{g}; !reflect.DeepEqual(grants, exp) {
+		t.Fatalf("unexpected grants: exp %v, got %v", exp, grants)
+	}
+
+	// Dropping the database removes its grants.
+	if err := c.DropDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	if grants, err := c.UserMeasurementGrants("wilma"); err != nil {
+		t.Fatal(err)
+	} else if len(grants) != 0 {
+		t.Fatalf("unexpected grants: %v", grants)
+	}
+}
+
 func newClient() (string, *meta.Client) {
 	cfg := newConfig()
 	c := meta.NewClient(cfg)
@@ -1196,3 +1378,276 @@ func freePorts(i int) []string {
 	}
 	return ports
 }
+
+func TestMetaClient_Roles(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.CreateUser("fred", "supersecure", false); err != nil {
+		t.Fatal(err)
+	} else if err := c.CreateRole("readers"); err != nil {
+		t.Fatal(err)
+	} else if err := c.SetRolePrivilege("readers", "db0", influxql.ReadPrivilege); err != nil {
+		t.Fatal(err)
+	} else if err := c.SetUserRole("fred", "readers", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Users are authorized with the privileges of their roles.
+	if u, err := c.Authenticate("fred", "supersecure"); err != nil {
+		t.Fatal(err)
+	} else if !u.Authorize(influxql.ReadPrivilege, "db0") {
+		t.Fatal("expected read privilege on db0 from role")
+	}
+
+	// Changing the role's privileges applies to its users.
+	if err := c.SetRolePrivilege("readers", "db0", influxql.NoPrivileges); err != nil {
+		t.Fatal(err)
+	} else if u, err := c.User("fred"); err != nil {
+		t.Fatal(err)
+	} else if u.Authorize(influxql.ReadPrivilege, "db0") {
+		t.Fatal("unexpected read privilege on db0")
+	} else if err := c.SetRolePrivilege("readers", "db0", influxql.WritePrivilege); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// Roles are persisted.
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if roles := c.Roles(); len(roles) != 1 || roles[0].Name != "readers" {
+		t.Fatalf("unexpected roles: %v", roles)
+	} else if u, err := c.User("fred"); err != nil {
+		t.Fatal(err)
+	} else if !u.Authorize(influxql.WritePrivilege, "db0") {
+		t.Fatal("expected write privilege on db0 from role")
+	}
+
+	if err := c.DropRole("readers"); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.Role("readers"); err != meta.ErrRoleNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if u, err := c.User("fred"); err != nil {
+		t.Fatal(err)
+	} else if u.Authorize(influxql.WritePrivilege, "db0") {
+		t.Fatal("unexpected write privilege on db0")
+	}
+}
+
+func TestMetaClient_Tokens(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.CreateUser("admin", "supersecure", true); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := c.CreateToken("telegraf", "admin", "db0", influxql.WritePrivilege, 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(token) != 2*meta.TokenBytes {
+		t.Fatalf("unexpected token: %s", token)
+	}
+
+	// The token is not stored.
+	if tokens := c.Tokens(); len(tokens) != 1 || tokens[0].Hash == "" || tokens[0].Hash == token {
+		t.Fatalf("unexpected tokens: %v", tokens)
+	} else if !tokens[0].LastUsed.IsZero() {
+		t.Fatalf("unexpected last used time: %s", tokens[0].LastUsed)
+	}
+
+	// The token authenticates as the user, restricted to its privilege.
+	u, err := c.AuthenticateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	} else if u.Name != "admin" || u.Admin {
+		t.Fatalf("unexpected user: %+v", u)
+	} else if !u.Authorize(influxql.WritePrivilege, "db0") {
+		t.Fatal("expected write privilege on db0")
+	} else if u.Authorize(influxql.ReadPrivilege, "db0") {
+		t.Fatal("unexpected read privilege on db0")
+	}
+
+	if _, err := c.AuthenticateToken("invalid"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Expired tokens are rejected.
+	expired, err := c.CreateToken("expired", "admin", "db0", influxql.ReadPrivilege, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, err := c.AuthenticateToken(expired); err != meta.ErrTokenExpired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Close()
+
+	// Tokens and their last used time are persisted.
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if tokens := c.Tokens(); len(tokens) != 2 || tokens[0].LastUsed.IsZero() {
+		t.Fatalf("unexpected tokens: %v", tokens)
+	} else if _, err := c.AuthenticateToken(token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dropped tokens are revoked.
+	if err := c.DropToken("telegraf"); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.AuthenticateToken(token); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMetaClient_PasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+	cfg.PasswordMinLength = 8
+	cfg.PasswordMinCharacterClasses = 3
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.CreateUser("fred", "Sh0rt", false); err == nil || err.Error() != meta.ErrPasswordTooShort(8).Error() {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.CreateUser("fred", "password", false); err == nil || err.Error() != meta.ErrPasswordTooSimple(3).Error() {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.CreateUser("fred", "Passw0rd", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.UpdateUser("fred", "password1"); err == nil || err.Error() != meta.ErrPasswordTooSimple(3).Error() {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := c.UpdateUser("fred", "pass-word1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Authenticate("fred", "pass-word1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMetaClient_AuthLockout(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+	cfg.MaxAuthFailures = 2
+	cfg.AuthLockoutDuration = toml.Duration(100 * time.Millisecond)
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.CreateUser("fred", "supersecure", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// A successful authentication resets the failures.
+	if _, err := c.Authenticate("fred", "bad"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("fred", "supersecure"); err != nil {
+		t.Fatal(err)
+	} else if _, err := c.Authenticate("fred", "bad"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("fred", "supersecure"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Consecutive failures lock the user out, even with the right password.
+	if _, err := c.Authenticate("fred", "bad"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("fred", "bad"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := c.Authenticate("fred", "supersecure"); err != meta.ErrUserLocked {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The lockout ends after its duration.
+	time.Sleep(100 * time.Millisecond)
+	if _, err := c.Authenticate("fred", "supersecure"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMetaClient_Authenticate_Rehash(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.CreateUser("fred", "supersecure", false); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// Reopen the client with another hash cost.
+	cfg.PasswordHashCost = bcrypt.MinCost + 1
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// The hash is upgraded once the user authenticates.
+	if _, err := c.Authenticate("fred", "badpassword"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if cost := MustHashCost(c, "fred"); cost != bcrypt.MinCost {
+		t.Fatalf("unexpected cost: %d", cost)
+	}
+	if _, err := c.Authenticate("fred", "supersecure"); err != nil {
+		t.Fatal(err)
+	} else if cost := MustHashCost(c, "fred"); cost != bcrypt.MinCost+1 {
+		t.Fatalf("unexpected cost: %d", cost)
+	}
+	if _, err := c.Authenticate("fred", "supersecure"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// MustHashCost returns the bcrypt cost of the password hash of a user.
+func MustHashCost(c *meta.Client, name string) int {
+	u, err := c.User(name)
+	if err != nil {
+		panic(err)
+	}
+	cost, err := bcrypt.Cost([]byte(u.Hash))
+	if err != nil {
+		panic(err)
+	}
+	return cost
+}
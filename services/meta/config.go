@@ -2,7 +2,11 @@ package meta
 
 import (
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/darshanman40/influxdb/toml"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -11,6 +15,10 @@ const (
 
 	// DefaultLoggingEnabled determines if log messages are printed for the meta service.
 	DefaultLoggingEnabled = true
+
+	// DefaultAuthLockoutDuration is the default duration users are locked out
+	// after too many failed authentications.
+	DefaultAuthLockoutDuration = 15 * time.Minute
 )
 
 // Config represents the meta configuration.
@@ -19,6 +27,22 @@ type Config struct {
 
 	RetentionAutoCreate bool `toml:"retention-autocreate"`
 	LoggingEnabled      bool `toml:"logging-enabled"`
+
+	// Requirements of the passwords of new users and of password changes.
+	// Character classes are lowercase and uppercase letters, digits and
+	// other characters.
+	PasswordMinLength           int `toml:"password-min-length"`
+	PasswordMinCharacterClasses int `toml:"password-min-character-classes"`
+
+	// PasswordHashCost is the bcrypt cost of password hashes, or zero for
+	// the default cost. Hashes with another cost are upgraded on the next
+	// successful authentication.
+	PasswordHashCost int `toml:"password-hash-cost"`
+
+	// Number of consecutive failed authentications after which a user is
+	// locked out for the lockout duration. Zero disables lockouts.
+	MaxAuthFailures     int           `toml:"max-auth-failures"`
+	AuthLockoutDuration toml.Duration `toml:"auth-lockout-duration"`
 }
 
 // NewConfig builds a new configuration with default values.
@@ -26,6 +50,7 @@ func NewConfig() *Config {
 	return &Config{
 		RetentionAutoCreate: true,
 		LoggingEnabled:      DefaultLoggingEnabled,
+		AuthLockoutDuration: toml.Duration(DefaultAuthLockoutDuration),
 	}
 }
 
@@ -33,6 +58,16 @@ func NewConfig() *Config {
 func (c *Config) Validate() error {
 	if c.Dir == "" {
 		return errors.New("Meta.Dir must be specified")
+	} else if c.PasswordMinLength < 0 {
+		return errors.New("Meta.PasswordMinLength must not be negative")
+	} else if c.PasswordMinCharacterClasses < 0 || c.PasswordMinCharacterClasses > passwordCharacterClasses {
+		return fmt.Errorf("Meta.PasswordMinCharacterClasses must be between 0 and %d", passwordCharacterClasses)
+	} else if c.PasswordHashCost != 0 && (c.PasswordHashCost < bcrypt.MinCost || c.PasswordHashCost > bcrypt.MaxCost) {
+		return fmt.Errorf("Meta.PasswordHashCost must be 0 or between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	} else if c.MaxAuthFailures < 0 {
+		return errors.New("Meta.MaxAuthFailures must not be negative")
+	} else if c.MaxAuthFailures > 0 && c.AuthLockoutDuration <= 0 {
+		return errors.New("Meta.AuthLockoutDuration must be positive")
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package meta_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/darshanman40/influxdb/services/meta"
@@ -13,6 +14,11 @@ func TestConfig_Parse(t *testing.T) {
 	if _, err := toml.Decode(`
 dir = "/tmp/foo"
 logging-enabled = false
+password-min-length = 12
+password-min-character-classes = 3
+password-hash-cost = 12
+max-auth-failures = 5
+auth-lockout-duration = "30m"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -22,5 +28,36 @@ logging-enabled = false
 		t.Fatalf("unexpected dir: %s", c.Dir)
 	} else if c.LoggingEnabled {
 		t.Fatalf("unexpected logging enabled: %v", c.LoggingEnabled)
+	} else if c.PasswordMinLength != 12 {
+		t.Fatalf("unexpected password min length: %d", c.PasswordMinLength)
+	} else if c.PasswordMinCharacterClasses != 3 {
+		t.Fatalf("unexpected password min character classes: %d", c.PasswordMinCharacterClasses)
+	} else if c.PasswordHashCost != 12 {
+		t.Fatalf("unexpected password hash cost: %d", c.PasswordHashCost)
+	} else if c.MaxAuthFailures != 5 {
+		t.Fatalf("unexpected max auth failures: %d", c.MaxAuthFailures)
+	} else if time.Duration(c.AuthLockoutDuration) != 30*time.Minute {
+		t.Fatalf("unexpected auth lockout duration: %s", c.AuthLockoutDuration)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfig_Validate_Password(t *testing.T) {
+	for _, s := range []string{
+		`password-min-length = -1`,
+		`password-min-character-classes = 5`,
+		`password-hash-cost = 1`,
+		"max-auth-failures = 3\nauth-lockout-duration = \"0s\"",
+	} {
+		c := meta.NewConfig()
+		c.Dir = "/tmp/foo"
+		if _, err := toml.Decode(s, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Validate(); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
 	}
 }
@@ -41,6 +41,8 @@ type Data struct {
 	ClusterID uint64
 	Databases []DatabaseInfo
 	Users     []UserInfo
+	Roles     []RoleInfo
+	Tokens    []TokenInfo
 
 	MaxShardGroupID uint64
 	MaxShardID      uint64
@@ -119,13 +121,160 @@ func (data *Data) DropDatabase(name string) error {
 			// Remove all user privileges associated with this database.
 			for i := range data.Users {
 				delete(data.Users[i].Privileges, name)
+
+				grants := data.Users[i].Grants[:0]
+				for _, g := range data.Users[i].Grants {
+					if g.Database != name {
+						grants = append(grants, g)
+					}
+				}
+				data.Users[i].Grants = grants
+				data.Users[i].compileGrants()
+			}
+			for i := range data.Roles {
+				delete(data.Roles[i].Privileges, name)
 			}
+
+			// Remove the tokens restricted to this database.
+			tokens := data.Tokens[:0]
+			for _, ti := range data.Tokens {
+				if ti.Database != name {
+					tokens = append(tokens, ti)
+				}
+			}
+			data.Tokens = tokens
 			break
 		}
 	}
 	return nil
 }
 
+// QuotaUpdate represents database quota fields to be updated.
+type QuotaUpdate struct {
+	MaxSeriesN           *int64
+	MaxDiskBytes         *int64
+	MaxWriteRate         *int64
+	MaxConcurrentQueries *int64
+}
+
+// UpdateDatabaseQuota updates the quota of an existing database. Zero limits
+// remove the corresponding quota.
+func (data *Data) UpdateDatabaseQuota(name string, qu *QuotaUpdate) error {
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+
+	for _, v := range []*int64{qu.MaxSeriesN, qu.MaxDiskBytes, qu.MaxWriteRate, qu.MaxConcurrentQueries} {
+		if v != nil && *v < 0 {
+			return ErrQuotaNegative
+		}
+	}
+
+	if qu.MaxSeriesN != nil {
+		di.Quota.MaxSeriesN = *qu.MaxSeriesN
+	}
+	if qu.MaxDiskBytes != nil {
+		di.Quota.MaxDiskBytes = *qu.MaxDiskBytes
+	}
+	if qu.MaxWriteRate != nil {
+		di.Quota.MaxWriteRate = *qu.MaxWriteRate
+	}
+	if qu.MaxConcurrentQueries != nil {
+		di.Quota.MaxConcurrentQueries = *qu.MaxConcurrentQueries
+	}
+	return nil
+}
+
+// QueryLimitsUpdate represents query limit fields to be updated.
+type QueryLimitsUpdate struct {
+	MaxSelectPointN      *int
+	MaxSelectSeriesN     *int
+	MaxSelectBucketsN    *int
+	QueryTimeout         *time.Duration
+	MaxConcurrentQueries *int
+	Priority             *int
+}
+
+// apply updates the query limits. Zero limits are unset.
+func (lu *QueryLimitsUpdate) apply(l *influxql.QueryLimits) error {
+	for _, v := range []*int{lu.MaxSelectPointN, lu.MaxSelectSeriesN, lu.MaxSelectBucketsN, lu.MaxConcurrentQueries, lu.Priority} {
+		if v != nil && *v < 0 {
+			return ErrQueryLimitNegative
+		}
+	}
+	if lu.QueryTimeout != nil && *lu.QueryTimeout < 0 {
+		return ErrQueryLimitNegative
+	}
+
+	if lu.MaxSelectPointN != nil {
+		l.MaxSelectPointN = *lu.MaxSelectPointN
+	}
+	if lu.MaxSelectSeriesN != nil {
+		l.MaxSelectSeriesN = *lu.MaxSelectSeriesN
+	}
+	if lu.MaxSelectBucketsN != nil {
+		l.MaxSelectBucketsN = *lu.MaxSelectBucketsN
+	}
+	if lu.QueryTimeout != nil {
+		l.QueryTimeout = *lu.QueryTimeout
+	}
+	if lu.MaxConcurrentQueries != nil {
+		l.MaxConcurrentQueries = *lu.MaxConcurrentQueries
+	}
+	if lu.Priority != nil {
+		l.Priority = *lu.Priority
+	}
+	return nil
+}
+
+// UpdateDatabaseQueryLimits updates the query limits of an existing database.
+func (data *Data) UpdateDatabaseQueryLimits(name string, lu *QueryLimitsUpdate) error {
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+	return lu.apply(&di.QueryLimits)
+}
+
+// UpdateUserQueryLimits updates the query limits of an existing user.
+func (data *Data) UpdateUserQueryLimits(name string, lu *QueryLimitsUpdate) error {
+	ui := data.User(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+	return lu.apply(&ui.QueryLimits)
+}
+
+// marshalQueryLimits serializes query limits to a protobuf representation,
+// or nil if none of them is set.
+func marshalQueryLimits(l influxql.QueryLimits) *internal.QueryLimits {
+	if l == (influxql.QueryLimits{}) {
+		return nil
+	}
+	return &internal.QueryLimits{
+		MaxSelectPointN:      proto.Int64(int64(l.MaxSelectPointN)),
+		MaxSelectSeriesN:     proto.Int64(int64(l.MaxSelectSeriesN)),
+		MaxSelectBucketsN:    proto.Int64(int64(l.MaxSelectBucketsN)),
+		QueryTimeout:         proto.Int64(int64(l.QueryTimeout)),
+		MaxConcurrentQueries: proto.Int64(int64(l.MaxConcurrentQueries)),
+		Priority:             proto.Int64(int64(l.Priority)),
+	}
+}
+
+// unmarshalQueryLimits deserializes query limits from a protobuf
+// representation.
+func unmarshalQueryLimits(pb *internal.QueryLimits) influxql.QueryLimits {
+	return influxql.QueryLimits{
+		MaxSelectPointN:      int(pb.GetMaxSelectPointN()),
+		MaxSelectSeriesN:     int(pb.GetMaxSelectSeriesN()),
+		MaxSelectBucketsN:    int(pb.GetMaxSelectBucketsN()),
+		QueryTimeout:         time.Duration(pb.GetQueryTimeout()),
+		MaxConcurrentQueries: int(pb.GetMaxConcurrentQueries()),
+		Priority:             int(pb.GetPriority()),
+	}
+}
+
 // RetentionPolicy returns a retention policy for a database by name.
 func (data *Data) RetentionPolicy(database, name string) (*RetentionPolicyInfo, error) {
 	di := data.Database(database)
@@ -576,6 +725,15 @@ func (data *Data) DropUser(name string) error {
 	for i := range data.Users {
 		if data.Users[i].Name == name {
 			data.Users = append(data.Users[:i], data.Users[i+1:]...)
+
+			// Remove the tokens authenticating as this user.
+			tokens := data.Tokens[:0]
+			for _, ti := range data.Tokens {
+				if ti.User != name {
+					tokens = append(tokens, ti)
+				}
+			}
+			data.Tokens = tokens
 			return nil
 		}
 	}
@@ -621,6 +779,40 @@ func (data *Data) SetPrivilege(name, database string, p influxql.Privilege) erro
 	return nil
 }
 
+// SetMeasurementPrivilege sets the privilege of a user's grant on the
+// measurements and series selected by g. The grant is removed if the
+// privilege is NoPrivileges.
+func (data *Data) SetMeasurementPrivilege(name string, g MeasurementGrant) error {
+	ui := data.User(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	for i := range ui.Grants {
+		if !ui.Grants[i].sameSeries(g) {
+			continue
+		}
+
+		if g.Privilege == influxql.NoPrivileges {
+			ui.Grants = append(ui.Grants[:i], ui.Grants[i+1:]...)
+			ui.compileGrants()
+		} else {
+			ui.Grants[i].Privilege = g.Privilege
+		}
+		return nil
+	}
+
+	if g.Privilege != influxql.NoPrivileges {
+		ui.Grants = append(ui.Grants, g)
+		ui.compileGrants()
+	}
+	return nil
+}
+
 // SetAdminPrivilege sets the admin privilege for a user.
 func (data *Data) SetAdminPrivilege(name string, admin bool) error {
 	ui := data.User(name)
@@ -633,7 +825,7 @@ func (data *Data) SetAdminPrivilege(name string, admin bool) error {
 	return nil
 }
 
-// UserPrivileges gets the privileges for a user.
+// UserPrivileges gets the privileges granted directly to a user.
 func (data *Data) UserPrivileges(name string) (map[string]influxql.Privilege, error) {
 	ui := data.User(name)
 	if ui == nil {
@@ -643,20 +835,187 @@ func (data *Data) UserPrivileges(name string) (map[string]influxql.Privilege, er
 	return ui.Privileges, nil
 }
 
-// UserPrivilege gets the privilege for a user on a database.
+// UserPrivilege gets the effective privilege for a user on a database,
+// including the privileges of the user's roles.
 func (data *Data) UserPrivilege(name, database string) (*influxql.Privilege, error) {
 	ui := data.User(name)
 	if ui == nil {
 		return nil, ErrUserNotFound
 	}
 
-	for db, p := range ui.Privileges {
-		if db == database {
-			return &p, nil
+	p := data.ResolveUser(ui).Privileges[database]
+	return &p, nil
+}
+
+// ResolveUser returns a copy of ui with the privileges of its roles added to
+// its own privileges.
+func (data *Data) ResolveUser(ui *UserInfo) *UserInfo {
+	other := ui.clone()
+	for _, name := range ui.Roles {
+		ri := data.Role(name)
+		if ri == nil {
+			continue
+		}
+
+		for db, p := range ri.Privileges {
+			if other.Privileges == nil {
+				other.Privileges = make(map[string]influxql.Privilege)
+			}
+			other.Privileges[db] |= p
+		}
+	}
+	return &other
+}
+
+// Role returns a role by name.
+func (data *Data) Role(name string) *RoleInfo {
+	for i := range data.Roles {
+		if data.Roles[i].Name == name {
+			return &data.Roles[i]
+		}
+	}
+	return nil
+}
+
+// CreateRole creates a new role.
+func (data *Data) CreateRole(name string) error {
+	// Ensure the role doesn't already exist.
+	if name == "" {
+		return ErrRoleNameRequired
+	} else if data.Role(name) != nil {
+		return ErrRoleExists
+	}
+
+	data.Roles = append(data.Roles, RoleInfo{Name: name})
+	return nil
+}
+
+// DropRole removes an existing role by name, along with its memberships.
+func (data *Data) DropRole(name string) error {
+	for i := range data.Roles {
+		if data.Roles[i].Name != name {
+			continue
 		}
+		data.Roles = append(data.Roles[:i], data.Roles[i+1:]...)
+
+		for j := range data.Users {
+			data.Users[j].Roles = removeString(data.Users[j].Roles, name)
+		}
+		return nil
 	}
+	return ErrRoleNotFound
+}
+
+// SetRolePrivilege sets a privilege for a role on a database.
+func (data *Data) SetRolePrivilege(name, database string, p influxql.Privilege) error {
+	ri := data.Role(name)
+	if ri == nil {
+		return ErrRoleNotFound
+	}
+
+	if ri.Privileges == nil {
+		ri.Privileges = make(map[string]influxql.Privilege)
+	}
+	ri.Privileges[database] = p
+
+	return nil
+}
 
-	return influxql.NewPrivilege(influxql.NoPrivileges), nil
+// SetUserRole adds a user to a role, or removes the user from the role if
+// member is false.
+func (data *Data) SetUserRole(username, role string, member bool) error {
+	ui := data.User(username)
+	if ui == nil {
+		return ErrUserNotFound
+	} else if data.Role(role) == nil {
+		return ErrRoleNotFound
+	}
+
+	ui.Roles = removeString(ui.Roles, role)
+	if member {
+		ui.Roles = append(ui.Roles, role)
+	}
+	return nil
+}
+
+// CloneRoles returns a copy of the role infos.
+func (data *Data) CloneRoles() []RoleInfo {
+	if len(data.Roles) == 0 {
+		return nil
+	}
+	roles := make([]RoleInfo, len(data.Roles))
+	for i := range data.Roles {
+		roles[i] = data.Roles[i].clone()
+	}
+	return roles
+}
+
+// Token returns a token by name.
+func (data *Data) Token(name string) *TokenInfo {
+	for i := range data.Tokens {
+		if data.Tokens[i].Name == name {
+			return &data.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// CreateToken creates a new API token.
+func (data *Data) CreateToken(ti TokenInfo) error {
+	if ti.Name == "" {
+		return ErrTokenNameRequired
+	} else if ti.Database == "" {
+		return ErrDatabaseNameRequired
+	} else if data.Token(ti.Name) != nil {
+		return ErrTokenExists
+	} else if data.User(ti.User) == nil {
+		return ErrUserNotFound
+	}
+
+	data.Tokens = append(data.Tokens, ti)
+	return nil
+}
+
+// DropToken removes an existing token by name.
+func (data *Data) DropToken(name string) error {
+	for i := range data.Tokens {
+		if data.Tokens[i].Name == name {
+			data.Tokens = append(data.Tokens[:i], data.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// SetTokenLastUsed sets the time a token was last used to authenticate.
+func (data *Data) SetTokenLastUsed(name string, t time.Time) error {
+	ti := data.Token(name)
+	if ti == nil {
+		return ErrTokenNotFound
+	}
+	ti.LastUsed = t
+	return nil
+}
+
+// CloneTokens returns a copy of the token infos.
+func (data *Data) CloneTokens() []TokenInfo {
+	if len(data.Tokens) == 0 {
+		return nil
+	}
+	tokens := make([]TokenInfo, len(data.Tokens))
+	copy(tokens, data.Tokens)
+	return tokens
+}
+
+// removeString returns a without the elements equal to s.
+func removeString(a []string, s string) []string {
+	var other []string
+	for _, x := range a {
+		if x != s {
+			other = append(other, x)
+		}
+	}
+	return other
 }
 
 // Clone returns a copy of data with a new version.
@@ -665,6 +1024,8 @@ func (data *Data) Clone() *Data {
 
 	other.Databases = data.CloneDatabases()
 	other.Users = data.CloneUsers()
+	other.Roles = data.CloneRoles()
+	other.Tokens = data.CloneTokens()
 
 	return &other
 }
@@ -693,6 +1054,16 @@ func (data *Data) marshal() *internal.Data {
 		pb.Users[i] = data.Users[i].marshal()
 	}
 
+	pb.Roles = make([]*internal.RoleInfo, len(data.Roles))
+	for i := range data.Roles {
+		pb.Roles[i] = data.Roles[i].marshal()
+	}
+
+	pb.Tokens = make([]*internal.TokenInfo, len(data.Tokens))
+	for i := range data.Tokens {
+		pb.Tokens[i] = data.Tokens[i].marshal()
+	}
+
 	return pb
 }
 
@@ -714,6 +1085,20 @@ func (data *Data) unmarshal(pb *internal.Data) {
 	for i, x := range pb.GetUsers() {
 		data.Users[i].unmarshal(x)
 	}
+
+	data.Roles = nil
+	for _, x := range pb.GetRoles() {
+		var ri RoleInfo
+		ri.unmarshal(x)
+		data.Roles = append(data.Roles, ri)
+	}
+
+	data.Tokens = nil
+	for _, x := range pb.GetTokens() {
+		var ti TokenInfo
+		ti.unmarshal(x)
+		data.Tokens = append(data.Tokens, ti)
+	}
 }
 
 // MarshalBinary encodes the metadata to a binary format.
@@ -775,6 +1160,27 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy string
 	RetentionPolicies      []RetentionPolicyInfo
 	ContinuousQueries      []ContinuousQueryInfo
+	Quota                  DatabaseQuota
+
+	// QueryLimits override the defaults of the query engine for the queries
+	// against the database.
+	QueryLimits influxql.QueryLimits
+}
+
+// DatabaseQuota represents the resource limits of a database. Zero limits
+// are unlimited.
+type DatabaseQuota struct {
+	// Maximum number of series.
+	MaxSeriesN int64
+
+	// Maximum size of the shards on disk, in bytes.
+	MaxDiskBytes int64
+
+	// Maximum number of points written per second.
+	MaxWriteRate int64
+
+	// Maximum number of queries running at the same time.
+	MaxConcurrentQueries int64
 }
 
 // RetentionPolicy returns a retention policy by name.
@@ -856,6 +1262,21 @@ func (di DatabaseInfo) marshal() *internal.DatabaseInfo {
 	for i := range di.ContinuousQueries {
 		pb.ContinuousQueries[i] = di.ContinuousQueries[i].marshal()
 	}
+
+	if di.Quota.MaxSeriesN != 0 {
+		pb.MaxSeriesN = proto.Int64(di.Quota.MaxSeriesN)
+	}
+	if di.Quota.MaxDiskBytes != 0 {
+		pb.MaxDiskBytes = proto.Int64(di.Quota.MaxDiskBytes)
+	}
+	if di.Quota.MaxWriteRate != 0 {
+		pb.MaxWriteRate = proto.Int64(di.Quota.MaxWriteRate)
+	}
+	if di.Quota.MaxConcurrentQueries != 0 {
+		pb.MaxConcurrentQueries = proto.Int64(di.Quota.MaxConcurrentQueries)
+	}
+
+	pb.QueryLimits = marshalQueryLimits(di.QueryLimits)
 	return pb
 }
 
@@ -877,6 +1298,14 @@ func (di *DatabaseInfo) unmarshal(pb *internal.DatabaseInfo) {
 			di.ContinuousQueries[i].unmarshal(x)
 		}
 	}
+
+	di.Quota = DatabaseQuota{
+		MaxSeriesN:           pb.GetMaxSeriesN(),
+		MaxDiskBytes:         pb.GetMaxDiskBytes(),
+		MaxWriteRate:         pb.GetMaxWriteRate(),
+		MaxConcurrentQueries: pb.GetMaxConcurrentQueries(),
+	}
+	di.QueryLimits = unmarshalQueryLimits(pb.GetQueryLimits())
 }
 
 // RetentionPolicySpec represents the specification for a new retention policy.
@@ -1432,6 +1861,19 @@ type UserInfo struct {
 	Hash       string
 	Admin      bool
 	Privileges map[string]influxql.Privilege
+
+	// Grants are privileges on the series of some measurements of a database.
+	Grants []MeasurementGrant
+
+	// compiled holds the compiled Grants, by index, set along with them.
+	compiled []*compiledGrant
+
+	// Roles are the names of the roles the user is a member of.
+	Roles []string
+
+	// QueryLimits override the defaults of the query engine and the limits
+	// of databases for the queries of the user.
+	QueryLimits influxql.QueryLimits
 }
 
 // Authorize returns true if the user is authorized and false if not.
@@ -1454,6 +1896,16 @@ func (ui UserInfo) clone() UserInfo {
 		}
 	}
 
+	if ui.Grants != nil {
+		other.Grants = make([]MeasurementGrant, len(ui.Grants))
+		copy(other.Grants, ui.Grants)
+	}
+
+	if ui.Roles != nil {
+		other.Roles = make([]string, len(ui.Roles))
+		copy(other.Roles, ui.Roles)
+	}
+
 	return other
 }
 
@@ -1472,6 +1924,19 @@ func (ui UserInfo) marshal() *internal.UserInfo {
 		})
 	}
 
+	for _, g := range ui.Grants {
+		pb.Grants = append(pb.Grants, &internal.MeasurementGrant{
+			Database:  proto.String(g.Database),
+			Name:      proto.String(g.Name),
+			Regex:     proto.String(g.Regex),
+			Condition: proto.String(g.Condition),
+			Privilege: proto.Int32(int32(g.Privilege)),
+		})
+	}
+
+	pb.Roles = ui.Roles
+	pb.QueryLimits = marshalQueryLimits(ui.QueryLimits)
+
 	return pb
 }
 
@@ -1485,6 +1950,111 @@ func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
 	for _, p := range pb.GetPrivileges() {
 		ui.Privileges[p.GetDatabase()] = influxql.Privilege(p.GetPrivilege())
 	}
+
+	ui.Grants = nil
+	for _, g := range pb.GetGrants() {
+		ui.Grants = append(ui.Grants, MeasurementGrant{
+			Database:  g.GetDatabase(),
+			Name:      g.GetName(),
+			Regex:     g.GetRegex(),
+			Condition: g.GetCondition(),
+			Privilege: influxql.Privilege(g.GetPrivilege()),
+		})
+	}
+	ui.compileGrants()
+
+	ui.Roles = pb.GetRoles()
+	ui.QueryLimits = unmarshalQueryLimits(pb.GetQueryLimits())
+}
+
+// RoleInfo represents a named set of privileges that users can be given.
+type RoleInfo struct {
+	Name       string
+	Privileges map[string]influxql.Privilege
+}
+
+// clone returns a deep copy of ri.
+func (ri RoleInfo) clone() RoleInfo {
+	other := ri
+
+	if ri.Privileges != nil {
+		other.Privileges = make(map[string]influxql.Privilege)
+		for k, v := range ri.Privileges {
+			other.Privileges[k] = v
+		}
+	}
+
+	return other
+}
+
+// marshal serializes to a protobuf representation.
+func (ri RoleInfo) marshal() *internal.RoleInfo {
+	pb := &internal.RoleInfo{
+		Name: proto.String(ri.Name),
+	}
+
+	for database, privilege := range ri.Privileges {
+		pb.Privileges = append(pb.Privileges, &internal.UserPrivilege{
+			Database:  proto.String(database),
+			Privilege: proto.Int32(int32(privilege)),
+		})
+	}
+
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ri *RoleInfo) unmarshal(pb *internal.RoleInfo) {
+	ri.Name = pb.GetName()
+
+	ri.Privileges = make(map[string]influxql.Privilege)
+	for _, p := range pb.GetPrivileges() {
+		ri.Privileges[p.GetDatabase()] = influxql.Privilege(p.GetPrivilege())
+	}
+}
+
+// TokenInfo represents an API token authenticating requests as a user, with
+// privileges restricted to a single database.
+type TokenInfo struct {
+	Name string
+	User string
+
+	// Hash is the hex encoded SHA-256 hash of the token. The token itself
+	// is only returned when it is created.
+	Hash string
+
+	Database  string
+	Privilege influxql.Privilege
+
+	CreatedAt  time.Time
+	Expiration time.Time // Zero if the token never expires.
+	LastUsed   time.Time
+}
+
+// marshal serializes to a protobuf representation.
+func (ti TokenInfo) marshal() *internal.TokenInfo {
+	return &internal.TokenInfo{
+		Name:       proto.String(ti.Name),
+		User:       proto.String(ti.User),
+		Hash:       proto.String(ti.Hash),
+		Database:   proto.String(ti.Database),
+		Privilege:  proto.Int32(int32(ti.Privilege)),
+		CreatedAt:  proto.Int64(MarshalTime(ti.CreatedAt)),
+		Expiration: proto.Int64(MarshalTime(ti.Expiration)),
+		LastUsed:   proto.Int64(MarshalTime(ti.LastUsed)),
+	}
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ti *TokenInfo) unmarshal(pb *internal.TokenInfo) {
+	ti.Name = pb.GetName()
+	ti.User = pb.GetUser()
+	ti.Hash = pb.GetHash()
+	ti.Database = pb.GetDatabase()
+	ti.Privilege = influxql.Privilege(pb.GetPrivilege())
+	ti.CreatedAt = UnmarshalTime(pb.GetCreatedAt())
+	ti.Expiration = UnmarshalTime(pb.GetExpiration())
+	ti.LastUsed = UnmarshalTime(pb.GetLastUsed())
 }
 
 // Lease represents a lease held on a resource.
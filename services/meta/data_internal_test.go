@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"testing"
+
+	"github.com/darshanman40/influxdb/influxql"
 )
 
 func Test_newShardOwner(t *testing.T) {
@@ -87,3 +89,39 @@ func Test_Data_RetentionPolicy_MarshalBinary(t *testing.T) {
 		t.Errorf("unexpected DeletedAt time.  got: %s, exp: %s", got, exp)
 	}
 }
+
+// Ensure the grants of a user are compiled once, when they are set.
+func Test_Data_SetMeasurementPrivilege_Compiled(t *testing.T) {
+	data := &Data{Users: []UserInfo{{Name: "u"}}}
+	for _, g := range []MeasurementGrant{
+		{Database: "db0", Name: "cpu", Condition: "host = 'a'", Privilege: influxql.ReadPrivilege},
+		{Database: "db0", Regex: "^mem", Privilege: influxql.ReadPrivilege},
+	} {
+		if err := data.SetMeasurementPrivilege("u", g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ui := data.User("u")
+	if len(ui.compiled) != 2 {
+		t.Fatalf("unexpected compiled grants: %v", ui.compiled)
+	}
+	a := ui.SeriesAuthorizer(influxql.ReadPrivilege, "db0")
+	if len(a.grants) != 2 || a.grants[0] != ui.compiled[0] || a.grants[1] != ui.compiled[1] {
+		t.Fatalf("unexpected authorizer grants: %v", a.grants)
+	}
+
+	// Grants are compiled when they are unmarshaled.
+	var other UserInfo
+	other.unmarshal(ui.marshal())
+	if len(other.compiled) != 2 || other.compiled[0].cond.String() != "host::tag = 'a'" {
+		t.Fatalf("unexpected compiled grants: %v", other.compiled)
+	}
+
+	// Removing a grant compiles the remaining ones.
+	if err := data.SetMeasurementPrivilege("u", MeasurementGrant{Database: "db0", Name: "cpu", Condition: "host = 'a'"}); err != nil {
+		t.Fatal(err)
+	} else if ui := data.User("u"); len(ui.compiled) != 1 || ui.compiled[0].regex == nil {
+		t.Fatalf("unexpected compiled grants: %v", ui.compiled)
+	}
+}
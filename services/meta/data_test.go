@@ -109,3 +109,130 @@ func Test_Data_CreateRetentionPolicy(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_Data_Roles(t *testing.T) {
+	data := &meta.Data{
+		Databases: []meta.DatabaseInfo{{Name: "db0"}, {Name: "db1"}},
+		Users: []meta.UserInfo{{
+			Name:       "u",
+			Privileges: map[string]influxql.Privilege{"db0": influxql.ReadPrivilege},
+		}},
+	}
+
+	if err := data.CreateRole("r"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateRole("r"); err != meta.ErrRoleExists {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.CreateRole(""); err != meta.ErrRoleNameRequired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := data.SetRolePrivilege("r", "db0", influxql.WritePrivilege); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetRolePrivilege("r", "db1", influxql.ReadPrivilege); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetRolePrivilege("x", "db0", influxql.ReadPrivilege); err != meta.ErrRoleNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := data.SetUserRole("u", "r", true); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetUserRole("u", "x", true); err != meta.ErrRoleNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.SetUserRole("x", "r", true); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The effective privilege combines the user's and the role's privileges.
+	if p, err := data.UserPrivilege("u", "db0"); err != nil {
+		t.Fatal(err)
+	} else if *p != influxql.AllPrivileges {
+		t.Fatalf("unexpected privilege: %v", *p)
+	}
+
+	// The privileges granted directly are unchanged.
+	if privs, err := data.UserPrivileges("u"); err != nil {
+		t.Fatal(err)
+	} else if exp := map[string]influxql.Privilege{"db0": influxql.ReadPrivilege}; !reflect.DeepEqual(privs, exp) {
+		t.Fatalf("unexpected privileges: %v", privs)
+	}
+
+	// Dropping a database removes the role's privileges on it.
+	if err := data.DropDatabase("db1"); err != nil {
+		t.Fatal(err)
+	} else if exp := map[string]influxql.Privilege{"db0": influxql.WritePrivilege}; !reflect.DeepEqual(data.Role("r").Privileges, exp) {
+		t.Fatalf("unexpected role privileges: %v", data.Role("r").Privileges)
+	}
+
+	// Dropping a role removes it from its users.
+	if err := data.DropRole("r"); err != nil {
+		t.Fatal(err)
+	} else if err := data.DropRole("r"); err != meta.ErrRoleNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(data.Users[0].Roles) != 0 {
+		t.Fatalf("unexpected roles: %v", data.Users[0].Roles)
+	}
+
+	if p, err := data.UserPrivilege("u", "db0"); err != nil {
+		t.Fatal(err)
+	} else if *p != influxql.ReadPrivilege {
+		t.Fatalf("unexpected privilege: %v", *p)
+	}
+}
+
+func Test_Data_Tokens(t *testing.T) {
+	data := &meta.Data{
+		Databases: []meta.DatabaseInfo{{Name: "db0"}, {Name: "db1"}},
+		Users:     []meta.UserInfo{{Name: "u0"}, {Name: "u1"}},
+	}
+
+	for _, ti := range []meta.TokenInfo{
+		{Name: "t0", User: "u0", Database: "db0", Privilege: influxql.WritePrivilege},
+		{Name: "t1", User: "u0", Database: "db1", Privilege: influxql.ReadPrivilege},
+		{Name: "t2", User: "u1", Database: "db0", Privilege: influxql.ReadPrivilege},
+	} {
+		if err := data.CreateToken(ti); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		ti  meta.TokenInfo
+		err error
+	}{
+		{ti: meta.TokenInfo{Name: "t0", User: "u0", Database: "db0"}, err: meta.ErrTokenExists},
+		{ti: meta.TokenInfo{User: "u0", Database: "db0"}, err: meta.ErrTokenNameRequired},
+		{ti: meta.TokenInfo{Name: "t3", User: "u0"}, err: meta.ErrDatabaseNameRequired},
+		{ti: meta.TokenInfo{Name: "t3", User: "x", Database: "db0"}, err: meta.ErrUserNotFound},
+	} {
+		if err := data.CreateToken(tt.ti); err != tt.err {
+			t.Errorf("%+v: unexpected error: got %v, exp %v", tt.ti, err, tt.err)
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := data.SetTokenLastUsed("t0", now); err != nil {
+		t.Fatal(err)
+	} else if !data.Token("t0").LastUsed.Equal(now) {
+		t.Fatalf("unexpected last used time: %s", data.Token("t0").LastUsed)
+	}
+
+	// Dropping a database or a user removes their tokens.
+	if err := data.DropDatabase("db1"); err != nil {
+		t.Fatal(err)
+	} else if data.Token("t1") != nil {
+		t.Fatal("expected token t1 to be dropped with db1")
+	} else if err := data.DropUser("u1"); err != nil {
+		t.Fatal(err)
+	} else if data.Token("t2") != nil {
+		t.Fatal("expected token t2 to be dropped with u1")
+	}
+
+	if err := data.DropToken("t0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.DropToken("t0"); err != meta.ErrTokenNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(data.Tokens) != 0 {
+		t.Fatalf("unexpected tokens: %v", data.Tokens)
+	}
+}
@@ -95,6 +95,12 @@ var (
 	ErrSubscriptionNotFound = errors.New("subscription not found")
 )
 
+// ErrQuotaNegative is returned when setting a negative database quota.
+var ErrQuotaNegative = errors.New("quota must not be negative")
+
+// ErrQueryLimitNegative is returned when setting a negative query limit.
+var ErrQueryLimitNegative = errors.New("query limit must not be negative")
+
 // ErrInvalidSubscriptionURL is returned when the subscription's destination URL is invalid.
 func ErrInvalidSubscriptionURL(url string) error {
 	return fmt.Errorf("invalid subscription URL: %s", url)
@@ -112,4 +118,45 @@ var (
 
 	// ErrAuthenticate is returned when authentication fails.
 	ErrAuthenticate = errors.New("authentication failed")
+
+	// ErrUserLocked is returned when authenticating a user locked out after
+	// too many failed authentications.
+	ErrUserLocked = errors.New("user locked out after too many failed authentications")
+)
+
+// ErrPasswordTooShort is returned when a password has less than the minimum
+// number of characters.
+func ErrPasswordTooShort(n int) error {
+	return fmt.Errorf("password must have at least %d characters", n)
+}
+
+// ErrPasswordTooSimple is returned when a password has characters of less
+// than the minimum number of classes.
+func ErrPasswordTooSimple(n int) error {
+	return fmt.Errorf("password must have characters of at least %d of lowercase letters, uppercase letters, digits and other characters", n)
+}
+
+var (
+	// ErrRoleExists is returned when creating an already existing role.
+	ErrRoleExists = errors.New("role already exists")
+
+	// ErrRoleNotFound is returned when mutating a role that doesn't exist.
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrRoleNameRequired is returned when creating a role without a name.
+	ErrRoleNameRequired = errors.New("role name required")
+)
+
+var (
+	// ErrTokenExists is returned when creating an already existing token.
+	ErrTokenExists = errors.New("token already exists")
+
+	// ErrTokenNotFound is returned when dropping a token that doesn't exist.
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrTokenNameRequired is returned when creating a token without a name.
+	ErrTokenNameRequired = errors.New("token name required")
+
+	// ErrTokenExpired is returned when authenticating with an expired token.
+	ErrTokenExpired = errors.New("token expired")
 )
@@ -0,0 +1,331 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/models"
+)
+
+// MeasurementGrant is a privilege granted to a user on the series of some
+// measurements of a database.
+type MeasurementGrant struct {
+	Database string
+
+	// Name and Regex select the measurements of the grant by name or by
+	// regular expression. The grant applies to all measurements if both are
+	// empty.
+	Name  string
+	Regex string
+
+	// Condition is an InfluxQL condition on tags selecting the series of the
+	// grant. The grant applies to all series of its measurements if empty.
+	Condition string
+
+	Privilege influxql.Privilege
+}
+
+// Validate returns an error if the grant is invalid. Conditions may only
+// compare tags to strings or regular expressions, combined with AND and OR.
+func (g MeasurementGrant) Validate() error {
+	if g.Database == "" {
+		return ErrDatabaseNameRequired
+	} else if g.Name != "" && g.Regex != "" {
+		return errors.New("grant can't have both a measurement name and regex")
+	}
+
+	_, err := g.compile()
+	return err
+}
+
+// sameSeries returns true if g and other select the same series.
+func (g MeasurementGrant) sameSeries(other MeasurementGrant) bool {
+	return g.Database == other.Database && g.Name == other.Name &&
+		g.Regex == other.Regex && g.Condition == other.Condition
+}
+
+// compile parses the measurement regex and condition of the grant.
+func (g MeasurementGrant) compile() (*compiledGrant, error) {
+	cg := &compiledGrant{name: g.Name}
+	if g.Regex != "" {
+		re, err := regexp.Compile(g.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grant measurement regex: %s", err)
+		}
+		cg.regex = re
+	}
+
+	if g.Condition != "" {
+		expr, err := influxql.ParseExpr(g.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grant condition: %s", err)
+		} else if err := validateGrantCondition(expr); err != nil {
+			return nil, err
+		}
+		cg.cond = expr
+	}
+	return cg, nil
+}
+
+// validateGrantCondition returns an error unless expr only compares tags to
+// strings or regular expressions. It types the tag references of expr as tags.
+func validateGrantCondition(expr influxql.Expr) error {
+	switch expr := expr.(type) {
+	case *influxql.ParenExpr:
+		return validateGrantCondition(expr.Expr)
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND, influxql.OR:
+			if err := validateGrantCondition(expr.LHS); err != nil {
+				return err
+			}
+			return validateGrantCondition(expr.RHS)
+		case influxql.EQ, influxql.NEQ, influxql.EQREGEX, influxql.NEQREGEX:
+			ref, ok := expr.LHS.(*influxql.VarRef)
+			if !ok || influxql.IsSystemName(ref.Val) || ref.Val == "_name" {
+				return fmt.Errorf("invalid grant condition %s: left side must be a tag key", expr)
+			}
+			ref.Type = influxql.Tag
+
+			if influxql.IsRegexOp(expr.Op) {
+				_, ok = expr.RHS.(*influxql.RegexLiteral)
+			} else {
+				_, ok = expr.RHS.(*influxql.StringLiteral)
+			}
+			if !ok {
+				return fmt.Errorf("invalid grant condition %s: right side must be a tag value string or regex", expr)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid grant condition %s: only tag comparisons are allowed", expr)
+}
+
+// compiledGrant is a parsed MeasurementGrant. It is shared by the copies of
+// a user and must not be modified.
+type compiledGrant struct {
+	name  string
+	regex *regexp.Regexp
+	cond  influxql.Expr
+}
+
+// compileGrants compiles the grants of the user, once they are set, so that
+// authorizers don't parse them for every request.
+func (ui *UserInfo) compileGrants() {
+	ui.compiled = compileGrants(ui.Grants)
+}
+
+// compiledGrants returns the compiled grants of the user, by the index of the
+// grant. Grants are compiled now if they weren't set through the meta data.
+func (ui *UserInfo) compiledGrants() []*compiledGrant {
+	if len(ui.compiled) == len(ui.Grants) {
+		return ui.compiled
+	}
+	return compileGrants(ui.Grants)
+}
+
+// compileGrants returns the compiled grants, by index. Grants are validated
+// when they are set, so invalid ones are nil rather than failing every
+// request.
+func compileGrants(grants []MeasurementGrant) []*compiledGrant {
+	if len(grants) == 0 {
+		return nil
+	}
+	a := make([]*compiledGrant, len(grants))
+	for i, g := range grants {
+		a[i], _ = g.compile()
+	}
+	return a
+}
+
+// matchMeasurement returns true if the grant applies to the measurement.
+func (g *compiledGrant) matchMeasurement(name string) bool {
+	if g.regex != nil {
+		return g.regex.MatchString(name)
+	}
+	return g.name == "" || g.name == name
+}
+
+// matchTags returns true if the tags of a series meet the grant's condition.
+func (g *compiledGrant) matchTags(tags models.Tags) bool {
+	return g.cond == nil || evalGrantCondition(g.cond, tags)
+}
+
+// evalGrantCondition evaluates a validated grant condition against the tags
+// of a series. Missing tags have an empty value.
+func evalGrantCondition(expr influxql.Expr, tags models.Tags) bool {
+	switch expr := expr.(type) {
+	case *influxql.ParenExpr:
+		return evalGrantCondition(expr.Expr, tags)
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND:
+			return evalGrantCondition(expr.LHS, tags) && evalGrantCondition(expr.RHS, tags)
+		case influxql.OR:
+			return evalGrantCondition(expr.LHS, tags) || evalGrantCondition(expr.RHS, tags)
+		}
+
+		value := tags.GetString(expr.LHS.(*influxql.VarRef).Val)
+		switch expr.Op {
+		case influxql.EQ:
+			return value == expr.RHS.(*influxql.StringLiteral).Val
+		case influxql.NEQ:
+			return value != expr.RHS.(*influxql.StringLiteral).Val
+		case influxql.EQREGEX:
+			return expr.RHS.(*influxql.RegexLiteral).Val.MatchString(value)
+		case influxql.NEQREGEX:
+			return !expr.RHS.(*influxql.RegexLiteral).Val.MatchString(value)
+		}
+	}
+	return false
+}
+
+// SeriesAuthorizer determines which series of a database a user may access
+// with a privilege.
+type SeriesAuthorizer struct {
+	all    bool // Access to the whole database.
+	grants []*compiledGrant
+}
+
+// SeriesAuthorizer returns the authorizer of the series of database the user
+// may access with privilege.
+func (ui *UserInfo) SeriesAuthorizer(privilege influxql.Privilege, database string) *SeriesAuthorizer {
+	if ui.Authorize(privilege, database) {
+		return &SeriesAuthorizer{all: true}
+	}
+
+	a := &SeriesAuthorizer{}
+	compiled := ui.compiledGrants()
+	for i, g := range ui.Grants {
+		if g.Database != database || (g.Privilege != privilege && g.Privilege != influxql.AllPrivileges) {
+			continue
+		} else if compiled[i] == nil {
+			continue
+		}
+		a.grants = append(a.grants, compiled[i])
+	}
+	return a
+}
+
+// AuthorizeMeasurements returns true if the user may access some of the
+// series of database with privilege, either through a privilege on the
+// whole database or through measurement grants.
+func (ui *UserInfo) AuthorizeMeasurements(privilege influxql.Privilege, database string) bool {
+	if ui.Authorize(privilege, database) {
+		return true
+	}
+	for _, g := range ui.Grants {
+		if g.Database == database && (g.Privilege == privilege || g.Privilege == influxql.AllPrivileges) {
+			return true
+		}
+	}
+	return false
+}
+
+// MeasurementCondition returns the condition the series of a measurement
+// must meet for the user to read them. It implements influxql.ReadAuthorizer.
+func (ui *UserInfo) MeasurementCondition(database, measurement string) (influxql.Expr, bool) {
+	return ui.SeriesAuthorizer(influxql.ReadPrivilege, database).MeasurementCondition(measurement)
+}
+
+// DatabaseCondition returns the condition the series of a database must meet
+// for the user to read them. It implements influxql.ReadAuthorizer.
+func (ui *UserInfo) DatabaseCondition(database string) (influxql.Expr, bool) {
+	return ui.SeriesAuthorizer(influxql.ReadPrivilege, database).DatabaseCondition()
+}
+
+// AuthorizeSeries returns true if the series of a measurement with tags may
+// be accessed.
+func (a *SeriesAuthorizer) AuthorizeSeries(measurement string, tags models.Tags) bool {
+	if a.all {
+		return true
+	}
+	for _, g := range a.grants {
+		if g.matchMeasurement(measurement) && g.matchTags(tags) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizeAll returns true if all series of the database may be accessed.
+func (a *SeriesAuthorizer) AuthorizeAll() bool {
+	return a.all
+}
+
+// MeasurementCondition returns the condition on tags the series of a
+// measurement must meet to be accessed, or nil if all of them may be. It
+// returns false if none of them may be accessed.
+func (a *SeriesAuthorizer) MeasurementCondition(measurement string) (influxql.Expr, bool) {
+	if a.all {
+		return nil, true
+	}
+
+	var conds []influxql.Expr
+	for _, g := range a.grants {
+		if !g.matchMeasurement(measurement) {
+			continue
+		} else if g.cond == nil {
+			return nil, true
+		}
+		conds = append(conds, influxql.CloneExpr(g.cond))
+	}
+	if len(conds) == 0 {
+		return nil, false
+	}
+	return orConditions(conds), true
+}
+
+// DatabaseCondition returns the condition the series of the database must
+// meet to be accessed, referring to measurement names as _name, or nil if all
+// of them may be. It returns false if none of them may be accessed.
+func (a *SeriesAuthorizer) DatabaseCondition() (influxql.Expr, bool) {
+	if a.all {
+		return nil, true
+	}
+
+	var conds []influxql.Expr
+	for _, g := range a.grants {
+		var cond influxql.Expr
+		if g.regex != nil {
+			cond = &influxql.BinaryExpr{Op: influxql.EQREGEX, LHS: &influxql.VarRef{Val: "_name"}, RHS: &influxql.RegexLiteral{Val: g.regex}}
+		} else if g.name != "" {
+			cond = &influxql.BinaryExpr{Op: influxql.EQ, LHS: &influxql.VarRef{Val: "_name"}, RHS: &influxql.StringLiteral{Val: g.name}}
+		}
+
+		if g.cond != nil {
+			if cond == nil {
+				cond = influxql.CloneExpr(g.cond)
+			} else {
+				cond = &influxql.BinaryExpr{Op: influxql.AND, LHS: cond, RHS: &influxql.ParenExpr{Expr: influxql.CloneExpr(g.cond)}}
+			}
+		}
+
+		if cond == nil {
+			return nil, true
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return nil, false
+	}
+	return orConditions(conds), true
+}
+
+// orConditions returns the disjunction of conds.
+func orConditions(conds []influxql.Expr) influxql.Expr {
+	expr := conds[0]
+	for _, cond := range conds[1:] {
+		expr = &influxql.BinaryExpr{
+			Op:  influxql.OR,
+			LHS: &influxql.ParenExpr{Expr: expr},
+			RHS: &influxql.ParenExpr{Expr: cond},
+		}
+	}
+	if len(conds) > 1 {
+		return &influxql.ParenExpr{Expr: expr}
+	}
+	return expr
+}
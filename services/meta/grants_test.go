@@ -0,0 +1,173 @@
+package meta_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+)
+
+func TestMeasurementGrant_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		grant meta.MeasurementGrant
+		err   bool
+	}{
+		{grant: meta.MeasurementGrant{Database: "db0", Name: "cpu"}},
+		{grant: meta.MeasurementGrant{Database: "db0", Regex: "^cpu"}},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "host = 'a' OR (region =~ /us-/ AND dc != 'x')"}},
+		{grant: meta.MeasurementGrant{Name: "cpu"}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Name: "cpu", Regex: "cpu"}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Regex: "("}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "host ="}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "value > 1"}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "host = 1"}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "host =~ 'a'"}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "_name = 'cpu'"}, err: true},
+		{grant: meta.MeasurementGrant{Database: "db0", Condition: "time > now()"}, err: true},
+	} {
+		if err := tt.grant.Validate(); (err != nil) != tt.err {
+			t.Errorf("%+v: unexpected error: %v", tt.grant, err)
+		}
+	}
+}
+
+func TestData_SetMeasurementPrivilege(t *testing.T) {
+	data := &meta.Data{Users: []meta.UserInfo{{Name: "u"}}}
+
+	g := meta.MeasurementGrant{Database: "db0", Name: "cpu", Privilege: influxql.ReadPrivilege}
+	if err := data.SetMeasurementPrivilege("u", g); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting a grant on the same series replaces its privilege.
+	g.Privilege = influxql.AllPrivileges
+	if err := data.SetMeasurementPrivilege("u", g); err != nil {
+		t.Fatal(err)
+	} else if exp := []meta.MeasurementGrant{g}; !reflect.DeepEqual(data.Users[0].Grants, exp) {
+		t.Fatalf("unexpected grants: %v", data.Users[0].Grants)
+	}
+
+	other := meta.MeasurementGrant{Database: "db0", Name: "cpu", Condition: "host = 'a'", Privilege: influxql.WritePrivilege}
+	if err := data.SetMeasurementPrivilege("u", other); err != nil {
+		t.Fatal(err)
+	} else if len(data.Users[0].Grants) != 2 {
+		t.Fatalf("unexpected grants: %v", data.Users[0].Grants)
+	}
+
+	// No privileges removes the grant.
+	g.Privilege = influxql.NoPrivileges
+	if err := data.SetMeasurementPrivilege("u", g); err != nil {
+		t.Fatal(err)
+	} else if exp := []meta.MeasurementGrant{other}; !reflect.DeepEqual(data.Users[0].Grants, exp) {
+		t.Fatalf("unexpected grants: %v", data.Users[0].Grants)
+	}
+
+	if err := data.SetMeasurementPrivilege("x", g); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUserInfo_SeriesAuthorizer(t *testing.T) {
+	ui := &meta.UserInfo{
+		Name: "u",
+		Grants: []meta.MeasurementGrant{
+			{Database: "db0", Name: "cpu", Condition: "host = 'a'", Privilege: influxql.WritePrivilege},
+			{Database: "db0", Regex: "^mem", Condition: "host !~ /^b/", Privilege: influxql.AllPrivileges},
+			{Database: "db0", Name: "disk", Privilege: influxql.ReadPrivilege},
+			{Database: "db1", Name: "cpu", Privilege: influxql.WritePrivilege},
+		},
+	}
+
+	a := ui.SeriesAuthorizer(influxql.WritePrivilege, "db0")
+	if a.AuthorizeAll() {
+		t.Fatal("unexpected access to the whole database")
+	}
+
+	for _, tt := range []struct {
+		name string
+		tags map[string]string
+		exp  bool
+	}{
+		{name: "cpu", tags: map[string]string{"host": "a"}, exp: true},
+		{name: "cpu", tags: map[string]string{"host": "b"}, exp: false},
+		{name: "cpu", exp: false},
+		{name: "memory", tags: map[string]string{"host": "a"}, exp: true},
+		{name: "memory", exp: true},
+		{name: "memory", tags: map[string]string{"host": "b1"}, exp: false},
+		{name: "disk", exp: false},
+		{name: "net", exp: false},
+	} {
+		if got := a.AuthorizeSeries(tt.name, models.NewTags(tt.tags)); got != tt.exp {
+			t.Errorf("%s %v: got %v, exp %v", tt.name, tt.tags, got, tt.exp)
+		}
+	}
+
+	if !ui.AuthorizeMeasurements(influxql.ReadPrivilege, "db0") {
+		t.Fatal("expected read access to db0 measurements")
+	} else if ui.AuthorizeMeasurements(influxql.ReadPrivilege, "db1") {
+		t.Fatal("unexpected read access to db1 measurements")
+	}
+
+	// Database privileges authorize all series.
+	ui.Privileges = map[string]influxql.Privilege{"db1": influxql.WritePrivilege}
+	if a := ui.SeriesAuthorizer(influxql.WritePrivilege, "db1"); !a.AuthorizeAll() || !a.AuthorizeSeries("net", nil) {
+		t.Fatal("expected access to the whole database")
+	}
+}
+
+func TestUserInfo_MeasurementCondition(t *testing.T) {
+	ui := &meta.UserInfo{
+		Name: "u",
+		Grants: []meta.MeasurementGrant{
+			{Database: "db0", Name: "cpu", Condition: "host = 'a'", Privilege: influxql.ReadPrivilege},
+			{Database: "db0", Regex: "^c", Condition: "region = 'us'", Privilege: influxql.ReadPrivilege},
+			{Database: "db0", Name: "disk", Privilege: influxql.ReadPrivilege},
+			{Database: "db0", Name: "net", Privilege: influxql.WritePrivilege},
+		},
+		Privileges: map[string]influxql.Privilege{"db1": influxql.ReadPrivilege},
+	}
+
+	for _, tt := range []struct {
+		db, name string
+		cond     string
+		ok       bool
+	}{
+		{db: "db0", name: "cpu", cond: "((host::tag = 'a') OR (region::tag = 'us'))", ok: true},
+		{db: "db0", name: "conn", cond: "region::tag = 'us'", ok: true},
+		{db: "db0", name: "disk", ok: true},
+		{db: "db0", name: "net", ok: false},
+		{db: "db1", name: "net", ok: true},
+		{db: "db2", name: "cpu", ok: false},
+	} {
+		cond, ok := ui.MeasurementCondition(tt.db, tt.name)
+		if ok != tt.ok {
+			t.Errorf("%s.%s: got ok %v, exp %v", tt.db, tt.name, ok, tt.ok)
+		} else if tt.cond == "" && cond != nil {
+			t.Errorf("%s.%s: unexpected condition %s", tt.db, tt.name, cond)
+		} else if tt.cond != "" && (cond == nil || cond.String() != tt.cond) {
+			t.Errorf("%s.%s: got condition %v, exp %s", tt.db, tt.name, cond, tt.cond)
+		}
+
+		// Conditions refer to tags.
+		influxql.WalkFunc(cond, func(n influxql.Node) {
+			if ref, ok := n.(*influxql.VarRef); ok && ref.Type != influxql.Tag {
+				t.Errorf("%s.%s: %s is not a tag reference", tt.db, tt.name, ref)
+			}
+		})
+	}
+
+	cond, ok := ui.DatabaseCondition("db0")
+	if !ok {
+		t.Fatal("expected access to db0")
+	} else if exp := `(((_name = 'cpu' AND (host::tag = 'a')) OR (_name =~ /^c/ AND (region::tag = 'us'))) OR (_name = 'disk'))`; cond.String() != exp {
+		t.Fatalf("unexpected condition:\ngot %s\nexp %s", cond, exp)
+	}
+
+	if cond, ok := ui.DatabaseCondition("db1"); !ok || cond != nil {
+		t.Fatalf("unexpected condition for db1: %v %v", cond, ok)
+	} else if _, ok := ui.DatabaseCondition("db2"); ok {
+		t.Fatal("unexpected access to db2")
+	}
+}
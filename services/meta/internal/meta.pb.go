@@ -21,6 +21,9 @@ It has these top-level messages:
 	ContinuousQueryInfo
 	UserInfo
 	UserPrivilege
+	MeasurementGrant
+	RoleInfo
+	TokenInfo
 	Command
 	CreateNodeCommand
 	DeleteNodeCommand
@@ -196,9 +199,11 @@ type Data struct {
 	MaxShardGroupID *uint64         `protobuf:"varint,8,req,name=MaxShardGroupID" json:"MaxShardGroupID,omitempty"`
 	MaxShardID      *uint64         `protobuf:"varint,9,req,name=MaxShardID" json:"MaxShardID,omitempty"`
 	// added for 0.10.0
-	DataNodes        []*NodeInfo `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
-	MetaNodes        []*NodeInfo `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
-	XXX_unrecognized []byte      `json:"-"`
+	DataNodes        []*NodeInfo  `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
+	MetaNodes        []*NodeInfo  `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
+	Roles            []*RoleInfo  `protobuf:"bytes,12,rep,name=Roles" json:"Roles,omitempty"`
+	Tokens           []*TokenInfo `protobuf:"bytes,13,rep,name=Tokens" json:"Tokens,omitempty"`
+	XXX_unrecognized []byte       `json:"-"`
 }
 
 func (m *Data) Reset()                    { *m = Data{} }
@@ -283,6 +288,20 @@ func (m *Data) GetMetaNodes() []*NodeInfo {
 	return nil
 }
 
+func (m *Data) GetRoles() []*RoleInfo {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *Data) GetTokens() []*TokenInfo {
+	if m != nil {
+		return m.Tokens
+	}
+	return nil
+}
+
 type NodeInfo struct {
 	ID               *uint64 `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
 	Host             *string `protobuf:"bytes,2,req,name=Host" json:"Host,omitempty"`
@@ -321,6 +340,11 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy *string                `protobuf:"bytes,2,req,name=DefaultRetentionPolicy" json:"DefaultRetentionPolicy,omitempty"`
 	RetentionPolicies      []*RetentionPolicyInfo `protobuf:"bytes,3,rep,name=RetentionPolicies" json:"RetentionPolicies,omitempty"`
 	ContinuousQueries      []*ContinuousQueryInfo `protobuf:"bytes,4,rep,name=ContinuousQueries" json:"ContinuousQueries,omitempty"`
+	MaxSeriesN             *int64                 `protobuf:"varint,5,opt,name=MaxSeriesN" json:"MaxSeriesN,omitempty"`
+	MaxDiskBytes           *int64                 `protobuf:"varint,6,opt,name=MaxDiskBytes" json:"MaxDiskBytes,omitempty"`
+	MaxWriteRate           *int64                 `protobuf:"varint,7,opt,name=MaxWriteRate" json:"MaxWriteRate,omitempty"`
+	MaxConcurrentQueries   *int64                 `protobuf:"varint,8,opt,name=MaxConcurrentQueries" json:"MaxConcurrentQueries,omitempty"`
+	QueryLimits            *QueryLimits           `protobuf:"bytes,9,opt,name=QueryLimits" json:"QueryLimits,omitempty"`
 	XXX_unrecognized       []byte                 `json:"-"`
 }
 
@@ -357,6 +381,41 @@ func (m *DatabaseInfo) GetContinuousQueries() []*ContinuousQueryInfo {
 	return nil
 }
 
+func (m *DatabaseInfo) GetMaxSeriesN() int64 {
+	if m != nil && m.MaxSeriesN != nil {
+		return *m.MaxSeriesN
+	}
+	return 0
+}
+
+func (m *DatabaseInfo) GetMaxDiskBytes() int64 {
+	if m != nil && m.MaxDiskBytes != nil {
+		return *m.MaxDiskBytes
+	}
+	return 0
+}
+
+func (m *DatabaseInfo) GetMaxWriteRate() int64 {
+	if m != nil && m.MaxWriteRate != nil {
+		return *m.MaxWriteRate
+	}
+	return 0
+}
+
+func (m *DatabaseInfo) GetMaxConcurrentQueries() int64 {
+	if m != nil && m.MaxConcurrentQueries != nil {
+		return *m.MaxConcurrentQueries
+	}
+	return 0
+}
+
+func (m *DatabaseInfo) GetQueryLimits() *QueryLimits {
+	if m != nil {
+		return m.QueryLimits
+	}
+	return nil
+}
+
 type RetentionPolicySpec struct {
 	Name               *string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
 	Duration           *int64  `protobuf:"varint,2,opt,name=Duration" json:"Duration,omitempty"`
@@ -621,11 +680,14 @@ func (m *ContinuousQueryInfo) GetQuery() string {
 }
 
 type UserInfo struct {
-	Name             *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
-	Hash             *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
-	Admin            *bool            `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
-	Privileges       []*UserPrivilege `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
-	XXX_unrecognized []byte           `json:"-"`
+	Name             *string             `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Hash             *string             `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
+	Admin            *bool               `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
+	Privileges       []*UserPrivilege    `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
+	Grants           []*MeasurementGrant `protobuf:"bytes,5,rep,name=Grants" json:"Grants,omitempty"`
+	Roles            []string            `protobuf:"bytes,6,rep,name=Roles" json:"Roles,omitempty"`
+	QueryLimits      *QueryLimits        `protobuf:"bytes,7,opt,name=QueryLimits" json:"QueryLimits,omitempty"`
+	XXX_unrecognized []byte              `json:"-"`
 }
 
 func (m *UserInfo) Reset()                    { *m = UserInfo{} }
@@ -661,6 +723,27 @@ func (m *UserInfo) GetPrivileges() []*UserPrivilege {
 	return nil
 }
 
+func (m *UserInfo) GetGrants() []*MeasurementGrant {
+	if m != nil {
+		return m.Grants
+	}
+	return nil
+}
+
+func (m *UserInfo) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *UserInfo) GetQueryLimits() *QueryLimits {
+	if m != nil {
+		return m.QueryLimits
+	}
+	return nil
+}
+
 type UserPrivilege struct {
 	Database         *string `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
 	Privilege        *int32  `protobuf:"varint,2,req,name=Privilege" json:"Privilege,omitempty"`
@@ -686,6 +769,206 @@ func (m *UserPrivilege) GetPrivilege() int32 {
 	return 0
 }
 
+type MeasurementGrant struct {
+	Database         *string `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
+	Name             *string `protobuf:"bytes,2,opt,name=Name" json:"Name,omitempty"`
+	Regex            *string `protobuf:"bytes,3,opt,name=Regex" json:"Regex,omitempty"`
+	Condition        *string `protobuf:"bytes,4,opt,name=Condition" json:"Condition,omitempty"`
+	Privilege        *int32  `protobuf:"varint,5,req,name=Privilege" json:"Privilege,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *MeasurementGrant) Reset()         { *m = MeasurementGrant{} }
+func (m *MeasurementGrant) String() string { return proto.CompactTextString(m) }
+func (*MeasurementGrant) ProtoMessage()    {}
+
+func (m *MeasurementGrant) GetDatabase() string {
+	if m != nil && m.Database != nil {
+		return *m.Database
+	}
+	return ""
+}
+
+func (m *MeasurementGrant) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *MeasurementGrant) GetRegex() string {
+	if m != nil && m.Regex != nil {
+		return *m.Regex
+	}
+	return ""
+}
+
+func (m *MeasurementGrant) GetCondition() string {
+	if m != nil && m.Condition != nil {
+		return *m.Condition
+	}
+	return ""
+}
+
+func (m *MeasurementGrant) GetPrivilege() int32 {
+	if m != nil && m.Privilege != nil {
+		return *m.Privilege
+	}
+	return 0
+}
+
+type RoleInfo struct {
+	Name             *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Privileges       []*UserPrivilege `protobuf:"bytes,2,rep,name=Privileges" json:"Privileges,omitempty"`
+	XXX_unrecognized []byte           `json:"-"`
+}
+
+func (m *RoleInfo) Reset()         { *m = RoleInfo{} }
+func (m *RoleInfo) String() string { return proto.CompactTextString(m) }
+func (*RoleInfo) ProtoMessage()    {}
+
+func (m *RoleInfo) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *RoleInfo) GetPrivileges() []*UserPrivilege {
+	if m != nil {
+		return m.Privileges
+	}
+	return nil
+}
+
+type TokenInfo struct {
+	Name             *string `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	User             *string `protobuf:"bytes,2,req,name=User" json:"User,omitempty"`
+	Hash             *string `protobuf:"bytes,3,req,name=Hash" json:"Hash,omitempty"`
+	Database         *string `protobuf:"bytes,4,req,name=Database" json:"Database,omitempty"`
+	Privilege        *int32  `protobuf:"varint,5,req,name=Privilege" json:"Privilege,omitempty"`
+	CreatedAt        *int64  `protobuf:"varint,6,req,name=CreatedAt" json:"CreatedAt,omitempty"`
+	Expiration       *int64  `protobuf:"varint,7,opt,name=Expiration" json:"Expiration,omitempty"`
+	LastUsed         *int64  `protobuf:"varint,8,opt,name=LastUsed" json:"LastUsed,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *TokenInfo) Reset()         { *m = TokenInfo{} }
+func (m *TokenInfo) String() string { return proto.CompactTextString(m) }
+func (*TokenInfo) ProtoMessage()    {}
+
+func (m *TokenInfo) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetUser() string {
+	if m != nil && m.User != nil {
+		return *m.User
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetHash() string {
+	if m != nil && m.Hash != nil {
+		return *m.Hash
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetDatabase() string {
+	if m != nil && m.Database != nil {
+		return *m.Database
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetPrivilege() int32 {
+	if m != nil && m.Privilege != nil {
+		return *m.Privilege
+	}
+	return 0
+}
+
+func (m *TokenInfo) GetCreatedAt() int64 {
+	if m != nil && m.CreatedAt != nil {
+		return *m.CreatedAt
+	}
+	return 0
+}
+
+func (m *TokenInfo) GetExpiration() int64 {
+	if m != nil && m.Expiration != nil {
+		return *m.Expiration
+	}
+	return 0
+}
+
+func (m *TokenInfo) GetLastUsed() int64 {
+	if m != nil && m.LastUsed != nil {
+		return *m.LastUsed
+	}
+	return 0
+}
+
+type QueryLimits struct {
+	MaxSelectPointN      *int64 `protobuf:"varint,1,opt,name=MaxSelectPointN" json:"MaxSelectPointN,omitempty"`
+	MaxSelectSeriesN     *int64 `protobuf:"varint,2,opt,name=MaxSelectSeriesN" json:"MaxSelectSeriesN,omitempty"`
+	MaxSelectBucketsN    *int64 `protobuf:"varint,3,opt,name=MaxSelectBucketsN" json:"MaxSelectBucketsN,omitempty"`
+	QueryTimeout         *int64 `protobuf:"varint,4,opt,name=QueryTimeout" json:"QueryTimeout,omitempty"`
+	MaxConcurrentQueries *int64 `protobuf:"varint,5,opt,name=MaxConcurrentQueries" json:"MaxConcurrentQueries,omitempty"`
+	Priority             *int64 `protobuf:"varint,6,opt,name=Priority" json:"Priority,omitempty"`
+	XXX_unrecognized     []byte `json:"-"`
+}
+
+func (m *QueryLimits) Reset()         { *m = QueryLimits{} }
+func (m *QueryLimits) String() string { return proto.CompactTextString(m) }
+func (*QueryLimits) ProtoMessage()    {}
+
+func (m *QueryLimits) GetMaxSelectPointN() int64 {
+	if m != nil && m.MaxSelectPointN != nil {
+		return *m.MaxSelectPointN
+	}
+	return 0
+}
+
+func (m *QueryLimits) GetMaxSelectSeriesN() int64 {
+	if m != nil && m.MaxSelectSeriesN != nil {
+		return *m.MaxSelectSeriesN
+	}
+	return 0
+}
+
+func (m *QueryLimits) GetMaxSelectBucketsN() int64 {
+	if m != nil && m.MaxSelectBucketsN != nil {
+		return *m.MaxSelectBucketsN
+	}
+	return 0
+}
+
+func (m *QueryLimits) GetQueryTimeout() int64 {
+	if m != nil && m.QueryTimeout != nil {
+		return *m.QueryTimeout
+	}
+	return 0
+}
+
+func (m *QueryLimits) GetMaxConcurrentQueries() int64 {
+	if m != nil && m.MaxConcurrentQueries != nil {
+		return *m.MaxConcurrentQueries
+	}
+	return 0
+}
+
+func (m *QueryLimits) GetPriority() int64 {
+	if m != nil && m.Priority != nil {
+		return *m.Priority
+	}
+	return 0
+}
+
 type Command struct {
 	Type                         *Command_Type `protobuf:"varint,1,req,name=type,enum=meta.Command_Type" json:"type,omitempty"`
 	proto.XXX_InternalExtensions `json:"-"`
@@ -1828,6 +2111,10 @@ func init() {
 	proto.RegisterType((*ContinuousQueryInfo)(nil), "meta.ContinuousQueryInfo")
 	proto.RegisterType((*UserInfo)(nil), "meta.UserInfo")
 	proto.RegisterType((*UserPrivilege)(nil), "meta.UserPrivilege")
+	proto.RegisterType((*MeasurementGrant)(nil), "meta.MeasurementGrant")
+	proto.RegisterType((*RoleInfo)(nil), "meta.RoleInfo")
+	proto.RegisterType((*TokenInfo)(nil), "meta.TokenInfo")
+	proto.RegisterType((*QueryLimits)(nil), "meta.QueryLimits")
 	proto.RegisterType((*Command)(nil), "meta.Command")
 	proto.RegisterType((*CreateNodeCommand)(nil), "meta.CreateNodeCommand")
 	proto.RegisterType((*DeleteNodeCommand)(nil), "meta.DeleteNodeCommand")
@@ -0,0 +1,96 @@
+package meta
+
+import (
+	"time"
+	"unicode"
+)
+
+// passwordCharacterClasses is the number of character classes of passwords.
+const passwordCharacterClasses = 4
+
+// passwordPolicy holds the requirements of passwords.
+type passwordPolicy struct {
+	minLength           int
+	minCharacterClasses int
+}
+
+// check returns an error if password doesn't meet the requirements.
+func (p passwordPolicy) check(password string) error {
+	var n int
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		n++
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	if n < p.minLength {
+		return ErrPasswordTooShort(p.minLength)
+	}
+
+	var classes int
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < p.minCharacterClasses {
+		return ErrPasswordTooSimple(p.minCharacterClasses)
+	}
+	return nil
+}
+
+// authFailures tracks the failed authentications of a user.
+type authFailures struct {
+	n           int       // Consecutive failures.
+	last        time.Time // Time of the last failure.
+	lockedUntil time.Time
+}
+
+// authLockout locks users out after too many consecutive failed
+// authentications. Failures older than the lockout duration are forgotten.
+type authLockout struct {
+	maxFailures int
+	duration    time.Duration
+	users       map[string]*authFailures
+}
+
+// locked returns true if the user is locked out at now.
+func (l *authLockout) locked(name string, now time.Time) bool {
+	f := l.users[name]
+	return f != nil && now.Before(f.lockedUntil)
+}
+
+// fail records a failed authentication of the user at now, and locks the
+// user out if it's one too many.
+func (l *authLockout) fail(name string, now time.Time) {
+	if l.maxFailures <= 0 {
+		return
+	}
+
+	f := l.users[name]
+	if f == nil || now.Sub(f.last) >= l.duration {
+		f = &authFailures{}
+		l.users[name] = f
+	}
+
+	f.n++
+	f.last = now
+	if f.n >= l.maxFailures {
+		f.n = 0
+		f.lockedUntil = now.Add(l.duration)
+	}
+}
+
+// succeed forgets the failed authentications of the user.
+func (l *authLockout) succeed(name string) {
+	delete(l.users, name)
+}
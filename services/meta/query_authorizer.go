@@ -82,7 +82,7 @@ func (a *QueryAuthorizer) AuthorizeQuery(u *UserInfo, query *influxql.Query, dat
 			if db == "" {
 				db = database
 			}
-			if !u.Authorize(p.Privilege, db) {
+			if !u.Authorize(p.Privilege, db) && !authorizeSeriesRead(u, stmt, p.Privilege, db) {
 				return &ErrAuthorize{
 					Query:    query,
 					User:     u.Name,
@@ -95,6 +95,26 @@ func (a *QueryAuthorizer) AuthorizeQuery(u *UserInfo, query *influxql.Query, dat
 	return nil
 }
 
+// authorizeSeriesRead returns true if u may execute a statement reading series
+// of database through measurement grants. The series read by the statement
+// are then restricted by the user's grants when it is executed.
+func authorizeSeriesRead(u *UserInfo, stmt influxql.Statement, privilege influxql.Privilege, database string) bool {
+	if privilege != influxql.ReadPrivilege {
+		return false
+	}
+
+	switch stmt.(type) {
+	case *influxql.SelectStatement,
+		*influxql.ShowFieldKeysStatement,
+		*influxql.ShowMeasurementsStatement,
+		*influxql.ShowSeriesStatement,
+		*influxql.ShowTagKeysStatement,
+		*influxql.ShowTagValuesStatement:
+		return u.AuthorizeMeasurements(privilege, database)
+	}
+	return false
+}
+
 // ErrAuthorize represents an authorization error.
 type ErrAuthorize struct {
 	Query    *influxql.Query
@@ -0,0 +1,63 @@
+package meta
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+)
+
+// newToken returns a new random API token and its hash.
+func newToken() (token, hash string, err error) {
+	b := make([]byte, TokenBytes)
+	if _, err := io.ReadFull(crand.Reader, b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the hash of an API token stored in the meta data. Tokens
+// are random so, unlike passwords, they don't need a salt or a slow hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Expired returns true if the token has expired at t.
+func (ti *TokenInfo) Expired(t time.Time) bool {
+	return !ti.Expiration.IsZero() && !t.Before(ti.Expiration)
+}
+
+// restrict returns a copy of the resolved user ui with only the privileges
+// within the scope of the token. The user loses admin privilege, and keeps
+// only the token's privilege on its database.
+func (ti *TokenInfo) restrict(ui *UserInfo) *UserInfo {
+	other := &UserInfo{
+		Name:       ui.Name,
+		Privileges: make(map[string]influxql.Privilege),
+	}
+
+	if ui.Admin {
+		other.Privileges[ti.Database] = ti.Privilege
+		return other
+	}
+
+	if p := ui.Privileges[ti.Database] & ti.Privilege; p != influxql.NoPrivileges {
+		other.Privileges[ti.Database] = p
+	}
+
+	for _, g := range ui.Grants {
+		if g.Database != ti.Database {
+			continue
+		}
+		g.Privilege &= ti.Privilege
+		if g.Privilege != influxql.NoPrivileges {
+			other.Grants = append(other.Grants, g)
+		}
+	}
+	return other
+}
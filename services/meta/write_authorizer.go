@@ -16,10 +16,13 @@ func NewWriteAuthorizer(c *Client) *WriteAuthorizer {
 	return &WriteAuthorizer{Client: c}
 }
 
-// AuthorizeWrite returns nil if the user has permission to write to the database.
+// AuthorizeWrite returns nil if the user has permission to write to the
+// database. Users with write grants on some measurements of the database are
+// authorized, and the points they write must be checked with the user's
+// SeriesAuthorizer.
 func (a WriteAuthorizer) AuthorizeWrite(username, database string) error {
 	u, err := a.Client.User(username)
-	if err != nil || u == nil || !u.Authorize(influxql.WritePrivilege, database) {
+	if err != nil || u == nil || !u.AuthorizeMeasurements(influxql.WritePrivilege, database) {
 		return &ErrAuthorize{
 			Database: database,
 			Message:  fmt.Sprintf("%s not authorized to write to %s", username, database),
@@ -30,6 +30,14 @@ const (
 
 	// DefaultCertificate is the default location of the certificate used when TLS is enabled.
 	DefaultCertificate = "/etc/ssl/influxdb.pem"
+
+	// DefaultMaxQuerySeries is the default maximum number of series a query
+	// of the HTTP API may read.
+	DefaultMaxQuerySeries = 10000
+
+	// DefaultMaxQueryPoints is the default maximum number of points a query
+	// of the HTTP API may read.
+	DefaultMaxQueryPoints = 1000000
 )
 
 // Config represents the configuration of the OpenTSDB service.
@@ -45,6 +53,9 @@ type Config struct {
 	BatchPending     int           `toml:"batch-pending"`
 	BatchTimeout     toml.Duration `toml:"batch-timeout"`
 	LogPointErrors   bool          `toml:"log-point-errors"`
+	AuthEnabled      bool          `toml:"auth-enabled"`
+	MaxQuerySeries   int           `toml:"max-query-series"`
+	MaxQueryPoints   int           `toml:"max-query-points"`
 }
 
 // NewConfig returns a new config for the service.
@@ -60,6 +71,8 @@ func NewConfig() Config {
 		BatchPending:     DefaultBatchPending,
 		BatchTimeout:     toml.Duration(DefaultBatchTimeout),
 		LogPointErrors:   true,
+		MaxQuerySeries:   DefaultMaxQuerySeries,
+		MaxQueryPoints:   DefaultMaxQueryPoints,
 	}
 }
 
@@ -91,6 +104,12 @@ func (c *Config) WithDefaults() *Config {
 	if d.BatchTimeout == 0 {
 		d.BatchTimeout = toml.Duration(DefaultBatchTimeout)
 	}
+	if d.MaxQuerySeries == 0 {
+		d.MaxQuerySeries = DefaultMaxQuerySeries
+	}
+	if d.MaxQueryPoints == 0 {
+		d.MaxQueryPoints = DefaultMaxQueryPoints
+	}
 
 	return &d
 }
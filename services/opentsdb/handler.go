@@ -14,7 +14,10 @@ import (
 	"time"
 
 	"github.com/darshanman40/influxdb"
+	"github.com/darshanman40/influxdb/influxql"
 	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +30,23 @@ type Handler struct {
 		WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
 	}
 
+	QueryExecutor interface {
+		ExecuteQuery(query *influxql.Query, opt influxql.ExecutionOptions, closing chan struct{}) <-chan *influxql.Result
+	}
+
+	MetaClient interface {
+		Authenticate(username, password string) (*meta.UserInfo, error)
+	}
+
+	// AuthEnabled requires queries to authenticate a user with read access
+	// to the database.
+	AuthEnabled bool
+
+	// MaxQuerySeries and MaxQueryPoints limit the number of series and points
+	// a query may read. There is no limit if zero.
+	MaxQuerySeries int
+	MaxQueryPoints int
+
 	Logger zap.Logger
 
 	stats *Statistics
@@ -39,6 +59,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	case "/api/put":
 		h.servePut(w, r)
+	case "/api/query":
+		h.serveQuery(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -100,8 +122,11 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Convert points into TSDB points.
+	// Convert points into TSDB points, keeping the datapoint of each point so
+	// that points dropped by the write can be reported.
+	var failed []putError
 	points := make([]models.Point, 0, len(dps))
+	index := make([]int, 0, len(dps))
 	for i := range dps {
 		p := dps[i]
 
@@ -120,23 +145,66 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 			if h.stats != nil {
 				atomic.AddInt64(&h.stats.InvalidDroppedPoints, 1)
 			}
+			failed = append(failed, putError{Datapoint: p, Error: err.Error()})
 			continue
 		}
 		points = append(points, pt)
+		index = append(index, i)
 	}
 
-	// Write points.
-	if err := h.PointsWriter.WritePoints(h.Database, h.RetentionPolicy, models.ConsistencyLevelAny, points); influxdb.IsClientError(err) {
-		h.Logger.Info(fmt.Sprint("write series error: ", err))
-		http.Error(w, "write series error: "+err.Error(), http.StatusBadRequest)
-		return
-	} else if err != nil {
-		h.Logger.Info(fmt.Sprint("write series error: ", err))
-		http.Error(w, "write series error: "+err.Error(), http.StatusInternalServerError)
-		return
+	// Write points. Points dropped by a partial write are reported as failed
+	// datapoints, like the ones that could not be converted.
+	if err := h.PointsWriter.WritePoints(h.Database, h.RetentionPolicy, models.ConsistencyLevelAny, points); err != nil {
+		if werr, ok := err.(tsdb.PartialWriteError); ok && len(werr.DroppedPoints) > 0 {
+			h.Logger.Info(fmt.Sprint("write series error: ", err))
+			for _, d := range werr.DroppedPoints {
+				if d.Index >= 0 && d.Index < len(index) {
+					failed = append(failed, putError{Datapoint: dps[index[d.Index]], Error: d.Reason})
+				}
+			}
+		} else if influxdb.IsClientError(err) {
+			h.Logger.Info(fmt.Sprint("write series error: ", err))
+			http.Error(w, "write series error: "+err.Error(), http.StatusBadRequest)
+			return
+		} else {
+			h.Logger.Info(fmt.Sprint("write series error: ", err))
+			http.Error(w, "write series error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Respond like OpenTSDB: with a summary of the write, and the failed
+	// datapoints, if requested and otherwise with no content unless some
+	// datapoints failed.
+	code := http.StatusOK
+	if len(failed) > 0 {
+		code = http.StatusBadRequest
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	query := r.URL.Query()
+	if _, ok := query["details"]; ok {
+		if failed == nil {
+			failed = []putError{}
+		}
+		writeJSON(w, putDetails{Errors: failed, Failed: len(failed), Success: len(dps) - len(failed)}, code)
+	} else if _, ok := query["summary"]; ok {
+		writeJSON(w, putSummary{Failed: len(failed), Success: len(dps) - len(failed)}, code)
+	} else if len(failed) > 0 {
+		writeJSON(w, errorResponse{Error: errorDetails{
+			Code:    http.StatusBadRequest,
+			Message: "One or more data points had errors",
+			Details: `Please see the TSD logs or append "details" to the put request`,
+		}}, http.StatusBadRequest)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writeJSON writes v as the JSON body of a response with the given status code.
+func writeJSON(w http.ResponseWriter, v interface{}, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
 }
 
 // chanListener represents a listener that receives connections through a channel.
@@ -196,3 +264,34 @@ type point struct {
 	Value  float64           `json:"value"`
 	Tags   map[string]string `json:"tags,omitempty"`
 }
+
+// putError is a datapoint that failed to be written, as reported by /api/put
+// with the details option.
+type putError struct {
+	Datapoint point  `json:"datapoint"`
+	Error     string `json:"error"`
+}
+
+// putSummary is the response of /api/put with the summary option.
+type putSummary struct {
+	Failed  int `json:"failed"`
+	Success int `json:"success"`
+}
+
+// putDetails is the response of /api/put with the details option.
+type putDetails struct {
+	Errors  []putError `json:"errors"`
+	Failed  int        `json:"failed"`
+	Success int        `json:"success"`
+}
+
+// errorResponse is an OpenTSDB error response.
+type errorResponse struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
@@ -0,0 +1,743 @@
+package opentsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
+)
+
+// queryRequest is a request of OpenTSDB's /api/query endpoint.
+type queryRequest struct {
+	Start        interface{} `json:"start"`
+	End          interface{} `json:"end"`
+	Queries      []subQuery  `json:"queries"`
+	MsResolution bool        `json:"msResolution"`
+}
+
+// subQuery selects the series of one metric and aggregates them into one
+// result for each combination of the values of its group by tags.
+type subQuery struct {
+	Aggregator string            `json:"aggregator"`
+	Metric     string            `json:"metric"`
+	Rate       bool              `json:"rate"`
+	Downsample string            `json:"downsample"`
+	Tags       map[string]string `json:"tags"`
+	Filters    []queryFilter     `json:"filters"`
+}
+
+// queryFilter filters the series of a metric on the values of a tag.
+type queryFilter struct {
+	Type    string `json:"type"`
+	TagK    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+// queryResult is one aggregated series of a query response.
+type queryResult struct {
+	Metric        string             `json:"metric"`
+	Tags          map[string]string  `json:"tags"`
+	AggregateTags []string           `json:"aggregateTags"`
+	DPS           map[string]float64 `json:"dps"`
+}
+
+// aggregators maps the aggregators supported across series to the function
+// computing them.
+var aggregators = map[string]func([]float64) float64{
+	"sum":    sumOf,
+	"zimsum": sumOf,
+	"avg":    func(a []float64) float64 { return sumOf(a) / float64(len(a)) },
+	"min":    minOf,
+	"mimmin": minOf,
+	"max":    maxOf,
+	"mimmax": maxOf,
+	"count":  func(a []float64) float64 { return float64(len(a)) },
+	"dev":    devOf,
+	"none":   func(a []float64) float64 { return a[len(a)-1] },
+}
+
+// downsamplers maps the downsampling functions to InfluxQL functions.
+var downsamplers = map[string]string{
+	"avg":    "mean",
+	"sum":    "sum",
+	"zimsum": "sum",
+	"min":    "min",
+	"mimmin": "min",
+	"max":    "max",
+	"mimmax": "max",
+	"count":  "count",
+	"first":  "first",
+	"last":   "last",
+	"dev":    "stddev",
+	"median": "median",
+}
+
+// filterRegex matches a filter given as a tag value, such as wildcard(web*).
+var filterRegex = regexp.MustCompile(`^(literal_or|iliteral_or|not_literal_or|not_iliteral_or|wildcard|iwildcard|regexp)\((.*)\)$`)
+
+// serveQuery implements OpenTSDB's HTTP /api/query endpoint. Each sub query is
+// translated into an InfluxQL statement selecting, and downsampling, the
+// matching series of the configured database; the series are then aggregated
+// by their group by tags.
+func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request) {
+	if h.QueryExecutor == nil {
+		queryError(w, "query API is not available", http.StatusNotImplemented)
+		return
+	}
+
+	var req queryRequest
+	switch r.Method {
+	case "GET":
+		q, err := parseQueryString(r)
+		if err != nil {
+			queryError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req = *q
+	case "POST":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			queryError(w, "json decode error: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts := influxql.ExecutionOptions{
+		Database:   h.Database,
+		ReadOnly:   true,
+		RemoteAddr: r.RemoteAddr,
+	}
+	if h.AuthEnabled {
+		user, err := h.authenticate(r)
+		if err != nil {
+			queryError(w, err.Error(), http.StatusUnauthorized)
+			return
+		} else if !user.Authorize(influxql.ReadPrivilege, h.Database) {
+			queryError(w, fmt.Sprintf("%s not authorized to read database %s", user.Name, h.Database), http.StatusForbidden)
+			return
+		}
+
+		// Restrict the series read to those of the user's grants.
+		opts.User, opts.Authorizer = user.Name, user
+	}
+
+	query, err := req.influxql(h.Database, h.RetentionPolicy, time.Now())
+	if err != nil {
+		queryError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Closing aborts the query if it reads too many series or points.
+	closing := make(chan struct{})
+	defer close(closing)
+	results := h.QueryExecutor.ExecuteQuery(query, opts, closing)
+
+	var (
+		nseries, npoints int
+		partial          = make([]bool, len(query.Statements))
+	)
+	rows := make([]models.Rows, len(query.Statements))
+	for res := range results {
+		if res == nil {
+			continue
+		} else if qerr, ok := res.Err.(tsdb.QuotaExceededError); ok {
+			queryError(w, qerr.Error(), quotaErrorStatus(qerr))
+			return
+		} else if res.Err != nil {
+			queryError(w, res.Err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// A row following a partial row continues its series.
+		for _, row := range res.Series {
+			if !partial[res.StatementID] {
+				nseries++
+			}
+			partial[res.StatementID] = row.Partial
+			npoints += len(row.Values)
+		}
+		if h.MaxQuerySeries > 0 && nseries > h.MaxQuerySeries {
+			queryError(w, fmt.Sprintf("query exceeded the maximum of %d series", h.MaxQuerySeries), http.StatusRequestEntityTooLarge)
+			return
+		} else if h.MaxQueryPoints > 0 && npoints > h.MaxQueryPoints {
+			queryError(w, fmt.Sprintf("query exceeded the maximum of %d points", h.MaxQueryPoints), http.StatusRequestEntityTooLarge)
+			return
+		}
+		rows[res.StatementID] = append(rows[res.StatementID], res.Series...)
+	}
+
+	resp := []*queryResult{}
+	for i := range req.Queries {
+		resp = append(resp, req.Queries[i].results(rows[i], req.MsResolution)...)
+	}
+	writeJSON(w, resp, http.StatusOK)
+}
+
+// authenticate returns the user of the credentials of a request, given with
+// basic authentication or the u and p query parameters.
+func (h *Handler) authenticate(r *http.Request) (*meta.UserInfo, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		q := r.URL.Query()
+		username, password = q.Get("u"), q.Get("p")
+	}
+	if username == "" {
+		return nil, errors.New("unable to parse authentication credentials")
+	}
+	return h.MetaClient.Authenticate(username, password)
+}
+
+// quotaErrorStatus returns the status code of a query refused by a quota of
+// the database: too many requests if it may succeed later, forbidden
+// otherwise.
+func quotaErrorStatus(err tsdb.QuotaExceededError) int {
+	if err.Temporary() {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusForbidden
+}
+
+// queryError writes an OpenTSDB error response.
+func queryError(w http.ResponseWriter, msg string, code int) {
+	writeJSON(w, errorResponse{Error: errorDetails{Code: code, Message: msg}}, code)
+}
+
+// parseQueryString parses the query string form of a query request, such as
+// ?start=1h-ago&m=sum:1m-avg:sys.cpu{host=*}.
+func parseQueryString(r *http.Request) (*queryRequest, error) {
+	values := r.URL.Query()
+	req := &queryRequest{}
+	if v := values.Get("start"); v != "" {
+		req.Start = v
+	}
+	if v := values.Get("end"); v != "" {
+		req.End = v
+	}
+	_, req.MsResolution = values["ms"]
+
+	for _, m := range values["m"] {
+		q, err := parseMetricQuery(m)
+		if err != nil {
+			return nil, err
+		}
+		req.Queries = append(req.Queries, *q)
+	}
+	return req, nil
+}
+
+// parseMetricQuery parses a sub query of the form:
+//
+//	aggregator:[downsample:][rate:]metric[{tag=filter,...}][{tag=filter,...}]
+//
+// Filters in the first braces group the results, filters in the second don't.
+func parseMetricQuery(m string) (*subQuery, error) {
+	parts := splitTopLevel(m, ':')
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid metric query: %s", m)
+	}
+
+	q := &subQuery{Aggregator: parts[0]}
+	for _, p := range parts[1 : len(parts)-1] {
+		if p == "rate" || strings.HasPrefix(p, "rate{") {
+			q.Rate = true
+		} else {
+			q.Downsample = p
+		}
+	}
+
+	metric := parts[len(parts)-1]
+	groups := 0
+	for {
+		i := strings.IndexByte(metric, '{')
+		if i < 0 {
+			break
+		}
+		end := strings.LastIndexByte(metric, '}')
+		if end < i {
+			return nil, fmt.Errorf("invalid metric query: %s", m)
+		}
+
+		// Find the brace closing the one at i.
+		depth := 0
+		for j := i; j <= end; j++ {
+			if metric[j] == '{' {
+				depth++
+			} else if metric[j] == '}' {
+				if depth--; depth == 0 {
+					end = j
+					break
+				}
+			}
+		}
+
+		for _, f := range splitTopLevel(metric[i+1:end], ',') {
+			if f == "" {
+				continue
+			}
+			kv := strings.SplitN(f, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("invalid tag filter: %s", f)
+			}
+			q.Filters = append(q.Filters, parseTagFilter(kv[0], kv[1], groups == 0))
+		}
+		metric = metric[:i] + metric[end+1:]
+		groups++
+	}
+	q.Metric = metric
+	return q, nil
+}
+
+// splitTopLevel splits s around each sep that is not enclosed in braces or
+// parentheses.
+func splitTopLevel(s string, sep byte) []string {
+	var a []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				a = append(a, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(a, s[start:])
+}
+
+// parseTagFilter returns the filter of a tag given in the tags of a query,
+// either with an explicit type, such as regexp(web.*), or as a literal value,
+// values separated by | or a pattern with * wildcards.
+func parseTagFilter(tagk, v string, groupBy bool) queryFilter {
+	if m := filterRegex.FindStringSubmatch(v); m != nil {
+		return queryFilter{Type: m[1], TagK: tagk, Filter: m[2], GroupBy: groupBy}
+	} else if strings.Contains(v, "*") {
+		return queryFilter{Type: "wildcard", TagK: tagk, Filter: v, GroupBy: groupBy}
+	}
+	return queryFilter{Type: "literal_or", TagK: tagk, Filter: v, GroupBy: groupBy}
+}
+
+// expr returns the InfluxQL condition of the filter.
+func (f *queryFilter) expr() (influxql.Expr, error) {
+	tag := &influxql.VarRef{Val: f.TagK}
+	switch f.Type {
+	case "literal_or", "not_literal_or":
+		op, join := influxql.EQ, influxql.OR
+		if f.Type == "not_literal_or" {
+			op, join = influxql.NEQ, influxql.AND
+		}
+
+		var expr influxql.Expr
+		for _, v := range strings.Split(f.Filter, "|") {
+			e := &influxql.BinaryExpr{Op: op, LHS: tag, RHS: &influxql.StringLiteral{Val: v}}
+			if expr == nil {
+				expr = e
+			} else {
+				expr = &influxql.BinaryExpr{Op: join, LHS: expr, RHS: e}
+			}
+		}
+		return &influxql.ParenExpr{Expr: expr}, nil
+	case "iliteral_or", "not_iliteral_or":
+		values := strings.Split(f.Filter, "|")
+		for i := range values {
+			values[i] = regexp.QuoteMeta(values[i])
+		}
+		op := influxql.EQREGEX
+		if f.Type == "not_iliteral_or" {
+			op = influxql.NEQREGEX
+		}
+		re := regexp.MustCompile(`(?i)^(?:` + strings.Join(values, "|") + `)$`)
+		return &influxql.BinaryExpr{Op: op, LHS: tag, RHS: &influxql.RegexLiteral{Val: re}}, nil
+	case "wildcard", "iwildcard":
+		// A lone wildcard still requires the series to have the tag.
+		pattern := ".+"
+		if f.Filter != "*" {
+			parts := strings.Split(f.Filter, "*")
+			for i := range parts {
+				parts[i] = regexp.QuoteMeta(parts[i])
+			}
+			pattern = strings.Join(parts, ".*")
+		}
+		if f.Type == "iwildcard" {
+			pattern = "(?i)" + pattern
+		}
+		re := regexp.MustCompile("^" + pattern + "$")
+		return &influxql.BinaryExpr{Op: influxql.EQREGEX, LHS: tag, RHS: &influxql.RegexLiteral{Val: re}}, nil
+	case "regexp":
+		re, err := regexp.Compile(f.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp filter on tag %s: %s", f.TagK, err)
+		}
+		return &influxql.BinaryExpr{Op: influxql.EQREGEX, LHS: tag, RHS: &influxql.RegexLiteral{Val: re}}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter type: %s", f.Type)
+	}
+}
+
+// influxql returns a query with one SELECT statement for each sub query of
+// the request. Relative times are relative to now.
+func (req *queryRequest) influxql(db, rp string, now time.Time) (*influxql.Query, error) {
+	if req.Start == nil {
+		return nil, errors.New("missing start time")
+	}
+	start, err := parseQueryTime(req.Start, now)
+	if err != nil {
+		return nil, err
+	}
+
+	end := now
+	if req.End != nil {
+		if end, err = parseQueryTime(req.End, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(req.Queries) == 0 {
+		return nil, errors.New("missing sub queries")
+	}
+
+	q := &influxql.Query{}
+	for i := range req.Queries {
+		stmt, err := req.Queries[i].statement(db, rp, start, end)
+		if err != nil {
+			return nil, err
+		}
+		q.Statements = append(q.Statements, stmt)
+	}
+	return q, nil
+}
+
+// statement returns the statement selecting the series of the sub query,
+// downsampled if requested, grouped by all of their tags.
+func (q *subQuery) statement(db, rp string, start, end time.Time) (*influxql.SelectStatement, error) {
+	if q.Metric == "" {
+		return nil, errors.New("missing metric")
+	} else if _, ok := aggregators[q.Aggregator]; !ok {
+		return nil, fmt.Errorf("unknown aggregator: %s", q.Aggregator)
+	}
+
+	var cond influxql.Expr = &influxql.BinaryExpr{
+		Op:  influxql.AND,
+		LHS: &influxql.BinaryExpr{Op: influxql.GTE, LHS: &influxql.VarRef{Val: "time"}, RHS: &influxql.TimeLiteral{Val: start.UTC()}},
+		RHS: &influxql.BinaryExpr{Op: influxql.LTE, LHS: &influxql.VarRef{Val: "time"}, RHS: &influxql.TimeLiteral{Val: end.UTC()}},
+	}
+	for _, f := range q.filters() {
+		expr, err := f.expr()
+		if err != nil {
+			return nil, err
+		}
+		cond = &influxql.BinaryExpr{Op: influxql.AND, LHS: cond, RHS: expr}
+	}
+
+	stmt := &influxql.SelectStatement{
+		Fields:     []*influxql.Field{{Expr: &influxql.VarRef{Val: "value"}}},
+		Sources:    []influxql.Source{&influxql.Measurement{Database: db, RetentionPolicy: rp, Name: q.Metric}},
+		Dimensions: []*influxql.Dimension{{Expr: &influxql.Wildcard{}}},
+		Condition:  cond,
+		IsRawQuery: true,
+	}
+	if q.Downsample == "" {
+		return stmt, nil
+	}
+
+	// Downsampling is of the form interval-function[-fill].
+	parts := strings.Split(q.Downsample, "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid downsample: %s", q.Downsample)
+	}
+	interval, err := parseQueryDuration(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid downsample interval: %s", parts[0])
+	}
+	fn, ok := downsamplers[parts[1]]
+	if !ok {
+		return nil, fmt.Errorf("unknown downsample function: %s", parts[1])
+	}
+
+	stmt.Fill = influxql.NoFill
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "none":
+		case "zero":
+			stmt.Fill, stmt.FillValue = influxql.NumberFill, 0
+		default:
+			return nil, fmt.Errorf("unsupported downsample fill policy: %s", parts[2])
+		}
+	}
+
+	stmt.Fields[0] = &influxql.Field{
+		Expr:  &influxql.Call{Name: fn, Args: []influxql.Expr{&influxql.VarRef{Val: "value"}}},
+		Alias: "value",
+	}
+	stmt.Dimensions = append(stmt.Dimensions, &influxql.Dimension{
+		Expr: &influxql.Call{Name: "time", Args: []influxql.Expr{&influxql.DurationLiteral{Val: interval}}},
+	})
+	stmt.IsRawQuery = false
+	return stmt, nil
+}
+
+// filters returns the filters of the sub query, including its tags.
+func (q *subQuery) filters() []queryFilter {
+	filters := q.Filters
+	keys := make([]string, 0, len(q.Tags))
+	for k := range q.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		filters = append(filters, parseTagFilter(k, q.Tags[k], true))
+	}
+	return filters
+}
+
+// results aggregates the rows selected by the statement of the sub query,
+// one row per series, into the results of each group.
+func (q *subQuery) results(rows models.Rows, ms bool) []*queryResult {
+	var groupBy []string
+	for _, f := range q.filters() {
+		if f.GroupBy {
+			groupBy = append(groupBy, f.TagK)
+		}
+	}
+	sort.Strings(groupBy)
+
+	type group struct {
+		series []map[string]string
+		values map[int64][]float64
+	}
+	var (
+		groups []*group
+		index  = make(map[string]*group)
+	)
+
+	for i, row := range rows {
+		tags := make(map[string]string)
+		for k, v := range row.Tags {
+			if v != "" {
+				tags[k] = v
+			}
+		}
+
+		// Series aren't aggregated together by the none aggregator.
+		var key string
+		if q.Aggregator == "none" {
+			key = strconv.Itoa(i)
+		} else {
+			for _, k := range groupBy {
+				key += k + "=" + tags[k] + "\x00"
+			}
+		}
+
+		g := index[key]
+		if g == nil {
+			g = &group{values: make(map[int64][]float64)}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.series = append(g.series, tags)
+
+		var (
+			prev  bool
+			prevT time.Time
+			prevV float64
+		)
+		for _, v := range row.Values {
+			t, ok := v[0].(time.Time)
+			if !ok {
+				continue
+			}
+
+			var value float64
+			switch x := v[1].(type) {
+			case float64:
+				value = x
+			case int64:
+				value = float64(x)
+			default:
+				continue
+			}
+
+			if q.Rate {
+				ok, t0, v0 := prev, prevT, prevV
+				prev, prevT, prevV = true, t, value
+				if !ok || !t.After(t0) {
+					continue
+				}
+				value = (value - v0) / t.Sub(t0).Seconds()
+			}
+
+			ts := t.Unix()
+			if ms {
+				ts = t.UnixNano() / int64(time.Millisecond)
+			}
+			g.values[ts] = append(g.values[ts], value)
+		}
+	}
+
+	aggregate := aggregators[q.Aggregator]
+	results := make([]*queryResult, 0, len(groups))
+	for _, g := range groups {
+		res := &queryResult{
+			Metric:        q.Metric,
+			Tags:          make(map[string]string),
+			AggregateTags: []string{},
+			DPS:           make(map[string]float64, len(g.values)),
+		}
+
+		// Tags with the same value in every series of the group are the tags
+		// of the result; the others were aggregated.
+		all := make(map[string]struct{})
+		for _, tags := range g.series {
+			for k := range tags {
+				all[k] = struct{}{}
+			}
+		}
+		for k := range all {
+			v, common := g.series[0][k]
+			for _, tags := range g.series[1:] {
+				if tags[k] != v {
+					common = false
+					break
+				}
+			}
+			if common {
+				res.Tags[k] = v
+			} else {
+				res.AggregateTags = append(res.AggregateTags, k)
+			}
+		}
+		sort.Strings(res.AggregateTags)
+
+		for ts, values := range g.values {
+			res.DPS[strconv.FormatInt(ts, 10)] = aggregate(values)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// queryTimeLayouts are the layouts of absolute query times.
+var queryTimeLayouts = []string{
+	"2006/01/02-15:04:05",
+	"2006/01/02-15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+}
+
+// parseQueryTime parses a query time: a Unix timestamp in seconds or
+// milliseconds, a relative time such as 1h-ago or an absolute time in UTC
+// such as 2017/01/02-15:04:05.
+func parseQueryTime(v interface{}, now time.Time) (time.Time, error) {
+	switch v := v.(type) {
+	case float64:
+		return unixQueryTime(int64(v)), nil
+	case string:
+		if strings.HasSuffix(v, "-ago") {
+			d, err := parseQueryDuration(strings.TrimSuffix(v, "-ago"))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid relative time: %s", v)
+			}
+			return now.Add(-d), nil
+		} else if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return unixQueryTime(n), nil
+		}
+
+		for _, layout := range queryTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid time: %s", v)
+	default:
+		return time.Time{}, fmt.Errorf("invalid time: %v", v)
+	}
+}
+
+// unixQueryTime returns the time of a timestamp in seconds or, if it has more
+// than 10 digits, milliseconds.
+func unixQueryTime(n int64) time.Time {
+	if n < 10000000000 {
+		return time.Unix(n, 0)
+	}
+	return time.Unix(0, n*int64(time.Millisecond))
+}
+
+// queryDurationUnits are the units of durations in OpenTSDB queries.
+var queryDurationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"n":  30 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// parseQueryDuration parses a duration such as 5m or 1d.
+func parseQueryDuration(s string) (time.Duration, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	unit, ok := queryDurationUnits[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil || n <= 0 || n > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func sumOf(a []float64) float64 {
+	var sum float64
+	for _, v := range a {
+		sum += v
+	}
+	return sum
+}
+
+func minOf(a []float64) float64 {
+	min := a[0]
+	for _, v := range a[1:] {
+		min = math.Min(min, v)
+	}
+	return min
+}
+
+func maxOf(a []float64) float64 {
+	max := a[0]
+	for _, v := range a[1:] {
+		max = math.Max(max, v)
+	}
+	return max
+}
+
+func devOf(a []float64) float64 {
+	mean := sumOf(a) / float64(len(a))
+	var sum float64
+	for _, v := range a {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(a)))
+}
@@ -0,0 +1,253 @@
+package opentsdb
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/internal"
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+)
+
+// Ensure metric queries in the query string form are parsed.
+func Test_parseMetricQuery(t *testing.T) {
+	for _, tt := range []struct {
+		m   string
+		exp *subQuery
+	}{
+		{
+			m:   "sum:sys.cpu",
+			exp: &subQuery{Aggregator: "sum", Metric: "sys.cpu"},
+		},
+		{
+			m: "avg:1m-max:rate:sys.cpu{host=web*,dc=lga|sjc}{cpu=regexp(0|1)}",
+			exp: &subQuery{
+				Aggregator: "avg",
+				Metric:     "sys.cpu",
+				Rate:       true,
+				Downsample: "1m-max",
+				Filters: []queryFilter{
+					{Type: "wildcard", TagK: "host", Filter: "web*", GroupBy: true},
+					{Type: "literal_or", TagK: "dc", Filter: "lga|sjc", GroupBy: true},
+					{Type: "regexp", TagK: "cpu", Filter: "0|1"},
+				},
+			},
+		},
+	} {
+		q, err := parseMetricQuery(tt.m)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.m, err)
+		} else if !reflect.DeepEqual(q, tt.exp) {
+			t.Fatalf("%s: unexpected query:\n\nexp=%#v\n\ngot=%#v", tt.m, tt.exp, q)
+		}
+	}
+}
+
+// Ensure query requests are translated into InfluxQL.
+func Test_queryRequest_influxql(t *testing.T) {
+	now := time.Unix(1346850000, 0)
+	for _, tt := range []struct {
+		req queryRequest
+		exp string
+		err string
+	}{
+		{
+			req: queryRequest{Start: 1346846400.0, Queries: []subQuery{{Aggregator: "sum", Metric: "sys.cpu"}}},
+			exp: `SELECT value FROM db0.rp0."sys.cpu" WHERE time >= '2012-09-05T12:00:00Z' AND time <= '2012-09-05T13:00:00Z' GROUP BY *`,
+		},
+		{
+			req: queryRequest{
+				Start: "1h-ago",
+				End:   "1346849400000",
+				Queries: []subQuery{{
+					Aggregator: "max",
+					Metric:     "sys.cpu",
+					Downsample: "5m-avg-zero",
+					Tags:       map[string]string{"host": "*"},
+					Filters:    []queryFilter{{Type: "not_literal_or", TagK: "dc", Filter: "lga|sjc"}},
+				}},
+			},
+			exp: `SELECT mean(value) AS value FROM db0.rp0."sys.cpu" WHERE time >= '2012-09-05T12:00:00Z' AND time <= '2012-09-05T12:50:00Z' AND (dc != 'lga' AND dc != 'sjc') AND host =~ /^.+$/ GROUP BY *, time(5m) fill(0)`,
+		},
+		{
+			req: queryRequest{Start: "2012/09/05-12:00", Queries: []subQuery{{Aggregator: "sum", Metric: "sys.cpu", Downsample: "1x-avg"}}},
+			err: "invalid downsample interval: 1x",
+		},
+		{
+			req: queryRequest{Start: "1h-ago", Queries: []subQuery{{Aggregator: "p99", Metric: "sys.cpu"}}},
+			err: "unknown aggregator: p99",
+		},
+		{
+			req: queryRequest{Queries: []subQuery{{Aggregator: "sum", Metric: "sys.cpu"}}},
+			err: "missing start time",
+		},
+		{
+			req: queryRequest{Start: "9223372036854775807y-ago", Queries: []subQuery{{Aggregator: "sum", Metric: "sys.cpu"}}},
+			err: "invalid relative time: 9223372036854775807y-ago",
+		},
+		{
+			req: queryRequest{Start: "1h-ago", Queries: []subQuery{{Aggregator: "sum", Metric: "sys.cpu", Downsample: "300y-avg"}}},
+			err: "invalid downsample interval: 300y",
+		},
+	} {
+		q, err := tt.req.influxql("db0", "rp0", now)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("unexpected error: exp=%s, got=%v", tt.err, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		} else if got := q.String(); got != tt.exp {
+			t.Fatalf("unexpected query:\n\nexp=%s\n\ngot=%s", tt.exp, got)
+		}
+	}
+}
+
+// Ensure the handler aggregates the series selected for a query by their
+// group by tags.
+func TestHandler_Query(t *testing.T) {
+	h := &Handler{Database: "db0", QueryExecutor: QueryExecutorFunc(func(q *influxql.Query, opt influxql.ExecutionOptions) []*influxql.Result {
+		if opt.Database != "db0" {
+			t.Fatalf("unexpected database: %s", opt.Database)
+		} else if len(q.Statements) != 1 {
+			t.Fatalf("unexpected query: %s", q)
+		}
+
+		t0, t1 := time.Unix(1346846400, 0).UTC(), time.Unix(1346846460, 0).UTC()
+		return []*influxql.Result{{Series: models.Rows{
+			{Name: "sys.cpu", Tags: map[string]string{"dc": "lga", "host": "web01"}, Values: [][]interface{}{{t0, 1.0}, {t1, 2.0}}},
+			{Name: "sys.cpu", Tags: map[string]string{"dc": "lga", "host": "web02"}, Values: [][]interface{}{{t0, 3.0}}},
+			{Name: "sys.cpu", Tags: map[string]string{"dc": "sjc", "host": "web03"}, Values: [][]interface{}{{t0, int64(5)}}},
+		}}}
+	})}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/query?start=1346846400&m=sum:1m-avg:sys.cpu{dc=*}", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+	if exp := `[{"metric":"sys.cpu","tags":{"dc":"lga"},"aggregateTags":["host"],"dps":{"1346846400":4,"1346846460":2}},` +
+		`{"metric":"sys.cpu","tags":{"dc":"sjc","host":"web03"},"aggregateTags":[],"dps":{"1346846400":5}}]`; strings.TrimSpace(w.Body.String()) != exp {
+		t.Fatalf("unexpected body:\n\nexp=%s\n\ngot=%s", exp, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/query", strings.NewReader(`{"start":"1h-ago","queries":[{"aggregator":"sum","metric":"sys.cpu","downsample":"1m-p99"}]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if exp := `{"error":{"code":400,"message":"unknown downsample function: p99"}}`; strings.TrimSpace(w.Body.String()) != exp {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// Ensure the handler requires a user with read access to the database when
+// authentication is enabled.
+func TestHandler_Query_Auth(t *testing.T) {
+	h := &Handler{
+		Database:    "db0",
+		AuthEnabled: true,
+		MetaClient:  &internal.MetaClientMock{},
+		QueryExecutor: QueryExecutorFunc(func(q *influxql.Query, opt influxql.ExecutionOptions) []*influxql.Result {
+			if opt.User != "reader" || opt.Authorizer == nil {
+				t.Fatalf("unexpected user: %s", opt.User)
+			}
+			return nil
+		}),
+	}
+	h.MetaClient.(*internal.MetaClientMock).AuthenticateFn = func(u, p string) (*meta.UserInfo, error) {
+		if p != "secret" {
+			return nil, meta.ErrAuthenticate
+		}
+		// Only the reader has read access.
+		if u != "reader" {
+			return &meta.UserInfo{Name: u, Privileges: map[string]influxql.Privilege{"db0": influxql.WritePrivilege}}, nil
+		}
+		return &meta.UserInfo{Name: u, Privileges: map[string]influxql.Privilege{"db0": influxql.ReadPrivilege}}, nil
+	}
+
+	for _, tt := range []struct {
+		url  string
+		code int
+	}{
+		{url: "/api/query?start=1h-ago&m=sum:sys.cpu", code: http.StatusUnauthorized},
+		{url: "/api/query?start=1h-ago&m=sum:sys.cpu&u=reader&p=bad", code: http.StatusUnauthorized},
+		{url: "/api/query?start=1h-ago&m=sum:sys.cpu&u=writer&p=secret", code: http.StatusForbidden},
+		{url: "/api/query?start=1h-ago&m=sum:sys.cpu&u=reader&p=secret", code: http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("GET", tt.url, nil))
+		if w.Code != tt.code {
+			t.Fatalf("%s: unexpected status: %d: %s", tt.url, w.Code, w.Body.String())
+		}
+	}
+}
+
+// Ensure the handler fails queries reading too many series or points.
+func TestHandler_Query_Limits(t *testing.T) {
+	t0 := time.Unix(1346846400, 0).UTC()
+	h := &Handler{Database: "db0", QueryExecutor: QueryExecutorFunc(func(q *influxql.Query, opt influxql.ExecutionOptions) []*influxql.Result {
+		return []*influxql.Result{
+			{Series: models.Rows{
+				{Name: "sys.cpu", Tags: map[string]string{"host": "web01"}, Values: [][]interface{}{{t0, 1.0}}, Partial: true},
+			}},
+			{Series: models.Rows{
+				{Name: "sys.cpu", Tags: map[string]string{"host": "web01"}, Values: [][]interface{}{{t0, 2.0}}},
+				{Name: "sys.cpu", Tags: map[string]string{"host": "web02"}, Values: [][]interface{}{{t0, 3.0}}},
+			}},
+		}
+	})}
+
+	for _, tt := range []struct {
+		series, points int
+		code           int
+		body           string
+	}{
+		{series: 2, points: 3, code: http.StatusOK},
+		{series: 1, code: http.StatusRequestEntityTooLarge, body: `{"error":{"code":413,"message":"query exceeded the maximum of 1 series"}}`},
+		{points: 2, code: http.StatusRequestEntityTooLarge, body: `{"error":{"code":413,"message":"query exceeded the maximum of 2 points"}}`},
+	} {
+		h.MaxQuerySeries, h.MaxQueryPoints = tt.series, tt.points
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("GET", "/api/query?start=1346846400&m=none:sys.cpu", nil))
+		if w.Code != tt.code {
+			t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+		} else if tt.body != "" && strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Fatalf("unexpected body: %s", w.Body.String())
+		}
+	}
+}
+
+// QueryExecutorFunc is a query executor returning results from a function.
+type QueryExecutorFunc func(q *influxql.Query, opt influxql.ExecutionOptions) []*influxql.Result
+
+func (fn QueryExecutorFunc) ExecuteQuery(q *influxql.Query, opt influxql.ExecutionOptions, closing chan struct{}) <-chan *influxql.Result {
+	ch := make(chan *influxql.Result)
+	go func() {
+		defer close(ch)
+		for _, r := range fn(q, opt) {
+			select {
+			case ch <- r:
+			case <-closing:
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// MustNewRequest returns a new HTTP request. Panic on error.
+func MustNewRequest(method, urlStr string, body io.Reader) *http.Request {
+	r, err := http.NewRequest(method, urlStr, body)
+	if err != nil {
+		panic(err.Error())
+	}
+	return r
+}
@@ -16,6 +16,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/darshanman40/influxdb/influxql"
 	"github.com/darshanman40/influxdb/models"
 	"github.com/darshanman40/influxdb/services/meta"
 	"github.com/darshanman40/influxdb/tsdb"
@@ -64,6 +65,10 @@ type Service struct {
 	}
 	MetaClient interface {
 		CreateDatabase(name string) (*meta.DatabaseInfo, error)
+		Authenticate(username, password string) (*meta.UserInfo, error)
+	}
+	QueryExecutor interface {
+		ExecuteQuery(query *influxql.Query, opt influxql.ExecutionOptions, closing chan struct{}) <-chan *influxql.Result
 	}
 
 	// Points received over the telnet protocol are batched.
@@ -75,6 +80,12 @@ type Service struct {
 	LogPointErrors bool
 	Logger         zap.Logger
 
+	// Queries of the HTTP API are authenticated, if enabled, and limited in
+	// the number of series and points they read.
+	authEnabled    bool
+	maxQuerySeries int
+	maxQueryPoints int
+
 	stats       *Statistics
 	defaultTags models.StatisticTags
 }
@@ -95,6 +106,9 @@ func NewService(c Config) (*Service, error) {
 		batchTimeout:    time.Duration(d.BatchTimeout),
 		Logger:          *zap.NewNop(),
 		LogPointErrors:  d.LogPointErrors,
+		authEnabled:     d.AuthEnabled,
+		maxQuerySeries:  d.MaxQuerySeries,
+		maxQueryPoints:  d.MaxQueryPoints,
 		stats:           &Statistics{},
 		defaultTags:     models.StatisticTags{"bind": d.BindAddress},
 	}
@@ -441,6 +455,11 @@ func (s *Service) serveHTTP() {
 		Database:        s.Database,
 		RetentionPolicy: s.RetentionPolicy,
 		PointsWriter:    s.PointsWriter,
+		QueryExecutor:   s.QueryExecutor,
+		MetaClient:      s.MetaClient,
+		AuthEnabled:     s.authEnabled,
+		MaxQuerySeries:  s.maxQuerySeries,
+		MaxQueryPoints:  s.maxQueryPoints,
 		Logger:          s.Logger,
 		stats:           s.stats,
 	}
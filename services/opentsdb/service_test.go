@@ -3,6 +3,7 @@ package opentsdb
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"reflect"
@@ -15,6 +16,7 @@ import (
 	"github.com/darshanman40/influxdb/internal"
 	"github.com/darshanman40/influxdb/models"
 	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
 	"go.uber.org/zap"
 )
 
@@ -247,6 +249,69 @@ func TestService_HTTP(t *testing.T) {
 	}
 }
 
+// Ensure failed datapoints are reported with the details option.
+func TestService_HTTP_Details(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService("db0", "127.0.0.1:0")
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	// Mock points writer dropping the point of the second datapoint.
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		if len(points) != 2 {
+			t.Fatalf("unexpected points: %v", points)
+		}
+		return tsdb.PartialWriteError{
+			Reason:        "field type conflict",
+			Dropped:       1,
+			DroppedPoints: []tsdb.DroppedPoint{{Point: points[1], Reason: "field type conflict", Index: 1}},
+		}
+	}
+
+	body := `[
+		{"metric":"sys.cpu.nice", "timestamp":1346846400, "value":18, "tags":{"host":"web01"}},
+		{"metric":"sys.cpu.nice", "timestamp":1346846401, "value":9, "tags":{"host":"web02"}},
+		{"metric":"sys.cpu.nice", "timestamp":9999999999999, "value":3}
+	]`
+
+	for _, tt := range []struct {
+		query string
+		body  string
+	}{
+		{
+			query: "?details",
+			body:  `{"errors":[{"datapoint":{"metric":"sys.cpu.nice","timestamp":9999999999999,"value":3},"error":"` + models.ErrTimeOutOfRange.Error() + `"},{"datapoint":{"metric":"sys.cpu.nice","timestamp":1346846401,"value":9,"tags":{"host":"web02"}},"error":"field type conflict"}],"failed":2,"success":1}`,
+		},
+		{
+			query: "?summary",
+			body:  `{"failed":2,"success":1}`,
+		},
+		{
+			query: "",
+			body:  `{"error":{"code":400,"message":"One or more data points had errors","details":"Please see the TSD logs or append \"details\" to the put request"}}`,
+		},
+	} {
+		resp, err := http.Post("http://"+s.Service.Addr().String()+"/api/put"+tt.query, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: unexpected status code: %d", tt.query, resp.StatusCode)
+		} else if got := strings.TrimSpace(string(b)); got != tt.body {
+			t.Fatalf("%s: unexpected body:\n\nexp=%s\n\ngot=%s", tt.query, tt.body, got)
+		}
+	}
+}
+
 type TestService struct {
 	Service       *Service
 	MetaClient    *internal.MetaClientMock
@@ -22,6 +22,11 @@ type Service struct {
 		DeleteShard(shardID uint64) error
 	}
 
+	// ResultCache drops the cached query results of deleted shard groups.
+	ResultCache interface {
+		InvalidateRange(database string, start, end time.Time)
+	}
+
 	enabled       bool
 	checkInterval time.Duration
 	wg            sync.WaitGroup
@@ -80,6 +85,9 @@ func (s *Service) deleteShardGroups() {
 							s.logger.Info(fmt.Sprintf("failed to delete shard group %d from database %s, retention policy %s: %s",
 								g.ID, d.Name, r.Name, err.Error()))
 						} else {
+							if s.ResultCache != nil {
+								s.ResultCache.InvalidateRange(d.Name, g.StartTime, g.EndTime)
+							}
 							s.logger.Info(fmt.Sprintf("deleted shard group %d from database %s, retention policy %s",
 								g.ID, d.Name, r.Name))
 						}
@@ -0,0 +1,232 @@
+package statsd
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/graphite"
+)
+
+// series identifies the measurement, tags and field a metric is written to.
+type series struct {
+	name  string
+	tags  models.Tags
+	field string
+}
+
+// key returns a key unique to the series.
+func (s *series) key() string {
+	return string(models.MakeKey([]byte(s.name), s.tags)) + " " + s.field
+}
+
+type counter struct {
+	series
+	value float64
+}
+
+type gauge struct {
+	series
+	value   float64
+	updated bool // Updated during the current interval?
+}
+
+type timer struct {
+	series
+	values []float64
+	count  float64 // Number of values, accounting for the sample rates.
+}
+
+type set struct {
+	series
+	members map[string]struct{}
+}
+
+// aggregator aggregates StatsD metrics over a flush interval.
+type aggregator struct {
+	parser       *graphite.Parser
+	percentiles  []float64
+	deleteGauges bool
+
+	mu       sync.Mutex
+	counters map[string]*counter
+	gauges   map[string]*gauge
+	timers   map[string]*timer
+	sets     map[string]*set
+}
+
+// newAggregator returns an aggregator converting metric names with the given
+// graphite templates.
+func newAggregator(templates []string, percentiles []float64, deleteGauges bool) (*aggregator, error) {
+	parser, err := graphite.NewParser(templates, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &aggregator{
+		parser:       parser,
+		percentiles:  percentiles,
+		deleteGauges: deleteGauges,
+		counters:     make(map[string]*counter),
+		gauges:       make(map[string]*gauge),
+		timers:       make(map[string]*timer),
+		sets:         make(map[string]*set),
+	}, nil
+}
+
+// Add adds a metric value to the current interval.
+func (a *aggregator) Add(m metric) error {
+	name, tags, field, err := a.parser.ApplyTemplate(m.name)
+	if err != nil {
+		return err
+	} else if name == "" {
+		name = m.name
+	}
+	for k, v := range m.tags {
+		tags[k] = v
+	}
+
+	s := series{name: name, tags: models.NewTags(tags), field: field}
+	key := s.key()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch m.typ {
+	case "c":
+		c := a.counters[key]
+		if c == nil {
+			c = &counter{series: s}
+			a.counters[key] = c
+		}
+		c.value += m.value / m.sampleRate
+	case "g":
+		g := a.gauges[key]
+		if g == nil {
+			g = &gauge{series: s}
+			a.gauges[key] = g
+		}
+		if m.delta {
+			g.value += m.value
+		} else {
+			g.value = m.value
+		}
+		g.updated = true
+	case "ms", "h", "d":
+		t := a.timers[key]
+		if t == nil {
+			t = &timer{series: s}
+			a.timers[key] = t
+		}
+		t.values = append(t.values, m.value)
+		t.count += 1 / m.sampleRate
+	case "s":
+		st := a.sets[key]
+		if st == nil {
+			st = &set{series: s, members: make(map[string]struct{})}
+			a.sets[key] = st
+		}
+		st.members[m.member] = struct{}{}
+	}
+	return nil
+}
+
+// Flush returns the points of the current interval, timestamped with now, and
+// starts a new interval. Gauges are kept across intervals unless they are
+// deleted when not updated. It also returns the number of invalid points.
+func (a *aggregator) Flush(now time.Time) ([]models.Point, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var (
+		points  []models.Point
+		invalid int
+	)
+	add := func(s *series, fields models.Fields) {
+		p, err := models.NewPoint(s.name, s.tags, fields, now)
+		if err != nil {
+			invalid++
+			return
+		}
+		points = append(points, p)
+	}
+
+	for key, c := range a.counters {
+		add(&c.series, models.Fields{fieldName(c.field, "value"): c.value})
+		delete(a.counters, key)
+	}
+
+	for key, g := range a.gauges {
+		if !g.updated && a.deleteGauges {
+			delete(a.gauges, key)
+			continue
+		}
+		add(&g.series, models.Fields{fieldName(g.field, "value"): g.value})
+		g.updated = false
+	}
+
+	for key, st := range a.sets {
+		add(&st.series, models.Fields{fieldName(st.field, "value"): float64(len(st.members))})
+		delete(a.sets, key)
+	}
+
+	for key, t := range a.timers {
+		add(&t.series, t.fields(a.percentiles))
+		delete(a.timers, key)
+	}
+
+	return points, invalid
+}
+
+// fields returns the statistics of the timer's values.
+func (t *timer) fields(percentiles []float64) models.Fields {
+	values := t.values
+	sort.Float64s(values)
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	prefix := ""
+	if t.field != "" {
+		prefix = t.field + "_"
+	}
+	fields := models.Fields{
+		prefix + "count":  t.count,
+		prefix + "sum":    sum,
+		prefix + "mean":   mean,
+		prefix + "lower":  values[0],
+		prefix + "upper":  values[len(values)-1],
+		prefix + "stddev": math.Sqrt(variance),
+	}
+
+	// Percentiles use the nearest rank method.
+	for _, p := range percentiles {
+		i := int(math.Ceil(p/100*float64(len(values)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		name := strings.Replace(strconv.FormatFloat(p, 'f', -1, 64), ".", "_", -1) + "_percentile"
+		fields[prefix+name] = values[i]
+	}
+	return fields
+}
+
+// fieldName returns the field name given by a template, or def.
+func fieldName(field, def string) string {
+	if field != "" {
+		return field
+	}
+	return def
+}
@@ -0,0 +1,76 @@
+package statsd
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_aggregator(t *testing.T) {
+	a, err := newAggregator([]string{"app.* .measurement.field"}, []float64{50, 99.9}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"requests:1|c",
+		"requests:2|c|@0.5",
+		"requests:1|c|#host:web01",
+		"app.http.errors:3|c",
+		"connections:10|g",
+		"connections:-4|g",
+		"latency:10|ms",
+		"latency:30|ms",
+		"latency:20|ms|@0.5",
+		"users:alice|s",
+		"users:bob|s",
+		"users:alice|s",
+	} {
+		metrics, err := parseLine(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range metrics {
+			if err := a.Add(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	now := time.Unix(1500000000, 0)
+	if got, exp := flushStrings(t, a, now), []string{
+		"connections value=6 1500000000000000000",
+		"http errors=3 1500000000000000000",
+		"latency 50_percentile=20,99_9_percentile=30,count=4,lower=10,mean=20,stddev=8.16496580927726,sum=60,upper=30 1500000000000000000",
+		"requests value=5 1500000000000000000",
+		"requests,host=web01 value=1 1500000000000000000",
+		"users value=2 1500000000000000000",
+	}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points:\n\nexp=%q\n\ngot=%q", exp, got)
+	}
+
+	// Only the gauge is kept, and only while it is updated.
+	metrics, _ := parseLine("connections:+1|g")
+	a.Add(metrics[0])
+	if got, exp := flushStrings(t, a, now), []string{"connections value=7 1500000000000000000"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points:\n\nexp=%q\n\ngot=%q", exp, got)
+	}
+	if got := flushStrings(t, a, now); len(got) != 0 {
+		t.Fatalf("unexpected points: %q", got)
+	}
+}
+
+func flushStrings(t *testing.T, a *aggregator, now time.Time) []string {
+	points, invalid := a.Flush(now)
+	if invalid != 0 {
+		t.Fatalf("unexpected invalid points: %d", invalid)
+	}
+
+	var a2 []string
+	for _, p := range points {
+		a2 = append(a2, p.String())
+	}
+	sort.Strings(a2)
+	return a2
+}
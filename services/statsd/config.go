@@ -0,0 +1,130 @@
+package statsd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darshanman40/influxdb/services/graphite"
+	"github.com/darshanman40/influxdb/toml"
+)
+
+const (
+	// DefaultBindAddress is the default binding interface if none is specified.
+	DefaultBindAddress = ":8125"
+
+	// DefaultDatabase is the default database for StatsD metrics.
+	DefaultDatabase = "statsd"
+
+	// DefaultRetentionPolicy is the default retention policy used for writes.
+	DefaultRetentionPolicy = ""
+
+	// DefaultBatchSize is the default write batch size.
+	DefaultBatchSize = 5000
+
+	// DefaultBatchPending is the default number of pending write batches.
+	DefaultBatchPending = 10
+
+	// DefaultBatchTimeout is the default write batch timeout.
+	DefaultBatchTimeout = time.Second
+
+	// DefaultFlushInterval is the default interval over which metrics are
+	// aggregated before being written.
+	DefaultFlushInterval = 10 * time.Second
+
+	// DefaultReadBuffer is the default buffer size for the UDP listener.
+	// Sets the size of the operating system's receive buffer associated with
+	// the UDP traffic. Keep in mind that the OS must be able
+	// to handle the number set here or the UDP listener will error and exit.
+	//
+	// DefaultReadBuffer = 0 means to use the OS default, which is usually too
+	// small for high UDP performance.
+	DefaultReadBuffer = 0
+)
+
+// DefaultPercentiles are the default percentiles computed for timers.
+var DefaultPercentiles = []float64{90}
+
+// Config holds various configuration settings for the StatsD listener.
+type Config struct {
+	Enabled     bool   `toml:"enabled"`
+	BindAddress string `toml:"bind-address"`
+
+	Database        string        `toml:"database"`
+	RetentionPolicy string        `toml:"retention-policy"`
+	BatchSize       int           `toml:"batch-size"`
+	BatchPending    int           `toml:"batch-pending"`
+	BatchTimeout    toml.Duration `toml:"batch-timeout"`
+	ReadBuffer      int           `toml:"read-buffer"`
+
+	// FlushInterval is the interval over which metrics are aggregated into
+	// the points written.
+	FlushInterval toml.Duration `toml:"flush-interval"`
+
+	// Percentiles are the percentiles computed for timers.
+	Percentiles []float64 `toml:"percentiles"`
+
+	// DeleteGauges drops gauges that weren't updated during an interval,
+	// instead of writing their last value again.
+	DeleteGauges bool `toml:"delete-gauges"`
+
+	// Templates convert metric names to measurements, tags and fields, like
+	// the templates of the graphite input.
+	Templates []string `toml:"templates"`
+}
+
+// NewConfig returns a new instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		BindAddress:     DefaultBindAddress,
+		Database:        DefaultDatabase,
+		RetentionPolicy: DefaultRetentionPolicy,
+		BatchSize:       DefaultBatchSize,
+		BatchPending:    DefaultBatchPending,
+		BatchTimeout:    toml.Duration(DefaultBatchTimeout),
+		FlushInterval:   toml.Duration(DefaultFlushInterval),
+		Percentiles:     DefaultPercentiles,
+	}
+}
+
+// WithDefaults takes the given config and returns a new config with any required
+// default values set.
+func (c *Config) WithDefaults() *Config {
+	d := *c
+	if d.BindAddress == "" {
+		d.BindAddress = DefaultBindAddress
+	}
+	if d.Database == "" {
+		d.Database = DefaultDatabase
+	}
+	if d.BatchSize == 0 {
+		d.BatchSize = DefaultBatchSize
+	}
+	if d.BatchPending == 0 {
+		d.BatchPending = DefaultBatchPending
+	}
+	if d.BatchTimeout == 0 {
+		d.BatchTimeout = toml.Duration(DefaultBatchTimeout)
+	}
+	if d.FlushInterval == 0 {
+		d.FlushInterval = toml.Duration(DefaultFlushInterval)
+	}
+	if d.Percentiles == nil {
+		d.Percentiles = DefaultPercentiles
+	}
+	return &d
+}
+
+// Validate validates the config's templates and percentiles.
+func (c *Config) Validate() error {
+	g := graphite.Config{Templates: c.Templates}
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	for _, p := range c.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid percentile: %v", p)
+		}
+	}
+	return nil
+}
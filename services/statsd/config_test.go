@@ -0,0 +1,61 @@
+package statsd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/darshanman40/influxdb/services/statsd"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c statsd.Config
+	if _, err := toml.Decode(`
+enabled = true
+bind-address = ":8126"
+database = "metrics"
+flush-interval = "1m"
+percentiles = [90.0, 99.9]
+delete-gauges = true
+templates = ["app.* measurement.field"]
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if c.Enabled != true {
+		t.Fatalf("unexpected enabled: %v", c.Enabled)
+	} else if c.BindAddress != ":8126" {
+		t.Fatalf("unexpected bind address: %s", c.BindAddress)
+	} else if c.Database != "metrics" {
+		t.Fatalf("unexpected database: %s", c.Database)
+	} else if time.Duration(c.FlushInterval) != time.Minute {
+		t.Fatalf("unexpected flush interval: %s", time.Duration(c.FlushInterval))
+	} else if !reflect.DeepEqual(c.Percentiles, []float64{90, 99.9}) {
+		t.Fatalf("unexpected percentiles: %v", c.Percentiles)
+	} else if !c.DeleteGauges {
+		t.Fatalf("unexpected delete gauges: %v", c.DeleteGauges)
+	} else if !reflect.DeepEqual(c.Templates, []string{"app.* measurement.field"}) {
+		t.Fatalf("unexpected templates: %v", c.Templates)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := statsd.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.Percentiles = []float64{0}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for invalid percentile")
+	}
+
+	c = statsd.NewConfig()
+	c.Templates = []string{"host.region"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for template without measurement")
+	}
+}
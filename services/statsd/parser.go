@@ -0,0 +1,111 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// metric is a value of a StatsD metric.
+type metric struct {
+	name       string
+	typ        string // One of "c", "g", "ms", "h", "d" or "s".
+	value      float64
+	delta      bool   // Gauge value relative to the current one.
+	member     string // Value of a set.
+	sampleRate float64
+	tags       map[string]string
+}
+
+// parseLine parses a line of the StatsD protocol:
+//
+//	name:value|type[|@sample_rate][|#tag:value,...]
+//
+// A line may hold several values of the metric, such as name:1|c:2|c, unless
+// it has DogStatsD tags.
+func parseLine(line string) ([]metric, error) {
+	i := strings.IndexByte(line, ':')
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid metric: %q", line)
+	}
+	name, rest := line[:i], line[i+1:]
+
+	// DogStatsD tags may contain colons, so lines with tags have one value.
+	var tags map[string]string
+	values := []string{rest}
+	if i := strings.Index(rest, "|#"); i >= 0 {
+		tags = parseTags(rest[i+2:])
+		values[0] = rest[:i]
+	} else {
+		values = strings.Split(rest, ":")
+	}
+
+	metrics := make([]metric, 0, len(values))
+	for _, v := range values {
+		m, err := parseValue(name, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric %q: %s", line, err)
+		}
+		m.tags = tags
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// parseValue parses a value of a metric: value|type[|@sample_rate].
+func parseValue(name, s string) (metric, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) < 2 || len(parts) > 3 {
+		return metric{}, fmt.Errorf("expected value|type[|@sample_rate], got %q", s)
+	}
+
+	m := metric{name: name, typ: parts[1], sampleRate: 1}
+	if len(parts) == 3 {
+		if !strings.HasPrefix(parts[2], "@") {
+			return metric{}, fmt.Errorf("invalid sample rate: %q", parts[2])
+		}
+		rate, err := strconv.ParseFloat(parts[2][1:], 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return metric{}, fmt.Errorf("invalid sample rate: %q", parts[2])
+		}
+		m.sampleRate = rate
+	}
+
+	switch m.typ {
+	case "s":
+		m.member = parts[0]
+		return m, nil
+	case "g":
+		// A signed gauge value changes the gauge instead of setting it.
+		m.delta = strings.HasPrefix(parts[0], "+") || strings.HasPrefix(parts[0], "-")
+	case "c", "ms", "h", "d":
+	default:
+		return metric{}, fmt.Errorf("unknown metric type: %q", m.typ)
+	}
+
+	v, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return metric{}, fmt.Errorf("invalid value: %q", parts[0])
+	}
+	m.value = v
+	return m, nil
+}
+
+// parseTags parses DogStatsD tags: tag:value,... Tags without a value are
+// set to "true".
+func parseTags(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, t := range strings.Split(s, ",") {
+		if t == "" {
+			continue
+		}
+		if i := strings.IndexByte(t, ':'); i == 0 {
+			continue
+		} else if i > 0 {
+			tags[t[:i]] = t[i+1:]
+		} else {
+			tags[t] = "true"
+		}
+	}
+	return tags
+}
@@ -0,0 +1,64 @@
+package statsd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseLine(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		exp  []metric
+	}{
+		{
+			line: "requests:1|c",
+			exp:  []metric{{name: "requests", typ: "c", value: 1, sampleRate: 1}},
+		},
+		{
+			line: "requests:2|c|@0.5:3|c",
+			exp: []metric{
+				{name: "requests", typ: "c", value: 2, sampleRate: 0.5},
+				{name: "requests", typ: "c", value: 3, sampleRate: 1},
+			},
+		},
+		{
+			line: "connections:-5|g",
+			exp:  []metric{{name: "connections", typ: "g", value: -5, delta: true, sampleRate: 1}},
+		},
+		{
+			line: "latency:12.5|ms|#host:web01,region:us-west,canary",
+			exp: []metric{{name: "latency", typ: "ms", value: 12.5, sampleRate: 1, tags: map[string]string{
+				"host":   "web01",
+				"region": "us-west",
+				"canary": "true",
+			}}},
+		},
+		{
+			line: "users:alice|s",
+			exp:  []metric{{name: "users", typ: "s", member: "alice", sampleRate: 1}},
+		},
+	} {
+		metrics, err := parseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.line, err)
+		} else if !reflect.DeepEqual(metrics, tt.exp) {
+			t.Fatalf("%s: unexpected metrics:\n\nexp=%+v\n\ngot=%+v", tt.line, tt.exp, metrics)
+		}
+	}
+}
+
+func Test_parseLine_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"requests",
+		":1|c",
+		"requests:1",
+		"requests:x|c",
+		"requests:1|q",
+		"requests:1|c|0.5",
+		"requests:1|c|@2",
+	} {
+		if _, err := parseLine(line); err == nil {
+			t.Fatalf("%s: expected error", line)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+// Package statsd provides the StatsD input service for InfluxDB.
+package statsd // import "github.com/darshanman40/influxdb/services/statsd"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// Arbitrary, like the UDP input's parser channel length.
+	parserChanLen = 1000
+
+	// MaxUDPPayload is largest payload size the StatsD service will accept.
+	MaxUDPPayload = 64 * 1024
+)
+
+// statistics gathered by the StatsD package.
+const (
+	statMetricsReceived      = "metricsRx"
+	statBytesReceived        = "bytesRx"
+	statMetricsParseFail     = "metricsParseFail"
+	statReadFail             = "readFail"
+	statBatchesTransmitted   = "batchesTx"
+	statPointsTransmitted    = "pointsTx"
+	statBatchesTransmitFail  = "batchesTxFail"
+	statDroppedPointsInvalid = "droppedPointsInvalid"
+)
+
+// Service is a UDP service that listens for StatsD metrics, aggregates them
+// over a flush interval and writes the aggregates as points.
+type Service struct {
+	conn *net.UDPConn
+	addr *net.UDPAddr
+	wg   sync.WaitGroup
+
+	mu    sync.RWMutex
+	ready bool          // Has the required database been created?
+	done  chan struct{} // Is the service closing or closed?
+
+	parserChan chan []byte
+	batcher    *tsdb.PointBatcher
+	aggregator *aggregator
+	config     Config
+
+	PointsWriter interface {
+		WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+	}
+
+	MetaClient interface {
+		CreateDatabase(name string) (*meta.DatabaseInfo, error)
+	}
+
+	Logger      zap.Logger
+	stats       *Statistics
+	defaultTags models.StatisticTags
+}
+
+// NewService returns a new instance of Service.
+func NewService(c Config) (*Service, error) {
+	d := *c.WithDefaults()
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	a, err := newAggregator(d.Templates, d.Percentiles, d.DeleteGauges)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		config:      d,
+		parserChan:  make(chan []byte, parserChanLen),
+		aggregator:  a,
+		Logger:      *zap.NewNop(),
+		stats:       &Statistics{},
+		defaultTags: models.StatisticTags{"bind": d.BindAddress},
+	}, nil
+}
+
+// Open starts the service.
+func (s *Service) Open() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed() {
+		return nil // Already open.
+	}
+	s.done = make(chan struct{})
+
+	if s.config.Database == "" {
+		return errors.New("database has to be specified in config")
+	}
+
+	s.addr, err = net.ResolveUDPAddr("udp", s.config.BindAddress)
+	if err != nil {
+		s.Logger.Info(fmt.Sprintf("Failed to resolve UDP address %s: %s", s.config.BindAddress, err))
+		return err
+	}
+
+	s.conn, err = net.ListenUDP("udp", s.addr)
+	if err != nil {
+		s.Logger.Info(fmt.Sprintf("Failed to set up UDP listener at address %s: %s", s.addr, err))
+		return err
+	}
+	s.addr = s.conn.LocalAddr().(*net.UDPAddr)
+
+	if s.config.ReadBuffer != 0 {
+		err = s.conn.SetReadBuffer(s.config.ReadBuffer)
+		if err != nil {
+			s.Logger.Info(fmt.Sprintf("Failed to set UDP read buffer to %d: %s",
+				s.config.ReadBuffer, err))
+			return err
+		}
+	}
+
+	s.Logger.Info(fmt.Sprintf("Started listening on UDP: %s", s.addr))
+
+	s.batcher = tsdb.NewPointBatcher(s.config.BatchSize, s.config.BatchPending, time.Duration(s.config.BatchTimeout))
+	s.batcher.Start()
+
+	s.wg.Add(4)
+	go s.serve()
+	go s.parser()
+	go s.flusher()
+	go s.writer()
+
+	return nil
+}
+
+// Statistics maintains statistics for the StatsD service.
+type Statistics struct {
+	MetricsReceived      int64
+	BytesReceived        int64
+	MetricsParseFail     int64
+	ReadFail             int64
+	BatchesTransmitted   int64
+	PointsTransmitted    int64
+	BatchesTransmitFail  int64
+	InvalidDroppedPoints int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	return []models.Statistic{{
+		Name: "statsd",
+		Tags: s.defaultTags.Merge(tags),
+		Values: map[string]interface{}{
+			statMetricsReceived:      atomic.LoadInt64(&s.stats.MetricsReceived),
+			statBytesReceived:        atomic.LoadInt64(&s.stats.BytesReceived),
+			statMetricsParseFail:     atomic.LoadInt64(&s.stats.MetricsParseFail),
+			statReadFail:             atomic.LoadInt64(&s.stats.ReadFail),
+			statBatchesTransmitted:   atomic.LoadInt64(&s.stats.BatchesTransmitted),
+			statPointsTransmitted:    atomic.LoadInt64(&s.stats.PointsTransmitted),
+			statBatchesTransmitFail:  atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+			statDroppedPointsInvalid: atomic.LoadInt64(&s.stats.InvalidDroppedPoints),
+		},
+	}}
+}
+
+func (s *Service) writer() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case batch := <-s.batcher.Out():
+			// Will attempt to create database if not yet created.
+			if err := s.createInternalStorage(); err != nil {
+				s.Logger.Info(fmt.Sprintf("Required database %s does not yet exist: %s", s.config.Database, err.Error()))
+				continue
+			}
+
+			if err := s.PointsWriter.WritePoints(s.config.Database, s.config.RetentionPolicy, models.ConsistencyLevelAny, batch); err == nil {
+				atomic.AddInt64(&s.stats.BatchesTransmitted, 1)
+				atomic.AddInt64(&s.stats.PointsTransmitted, int64(len(batch)))
+			} else {
+				s.Logger.Info(fmt.Sprintf("failed to write point batch to database %q: %s", s.config.Database, err))
+				atomic.AddInt64(&s.stats.BatchesTransmitFail, 1)
+			}
+
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Service) serve() {
+	defer s.wg.Done()
+
+	buf := make([]byte, MaxUDPPayload)
+	for {
+		select {
+		case <-s.done:
+			// We closed the connection, time to go.
+			return
+		default:
+			// Keep processing.
+			n, _, err := s.conn.ReadFromUDP(buf)
+			if err != nil {
+				atomic.AddInt64(&s.stats.ReadFail, 1)
+				s.Logger.Info(fmt.Sprintf("Failed to read UDP message: %s", err))
+				continue
+			}
+			atomic.AddInt64(&s.stats.BytesReceived, int64(n))
+
+			bufCopy := make([]byte, n)
+			copy(bufCopy, buf[:n])
+			select {
+			case s.parserChan <- bufCopy:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// parser parses the lines of each packet and adds their metrics to the
+// aggregates of the current interval.
+func (s *Service) parser() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case buf := <-s.parserChan:
+			for _, line := range bytes.Split(buf, []byte("\n")) {
+				line = bytes.TrimSpace(line)
+				if len(line) == 0 {
+					continue
+				}
+
+				metrics, err := parseLine(string(line))
+				if err != nil {
+					atomic.AddInt64(&s.stats.MetricsParseFail, 1)
+					s.Logger.Info(fmt.Sprintf("Failed to parse metric: %s", err))
+					continue
+				}
+
+				for _, m := range metrics {
+					if err := s.aggregator.Add(m); err != nil {
+						atomic.AddInt64(&s.stats.MetricsParseFail, 1)
+						s.Logger.Info(fmt.Sprintf("Failed to apply template to metric %s: %s", m.name, err))
+						continue
+					}
+					atomic.AddInt64(&s.stats.MetricsReceived, 1)
+				}
+			}
+		}
+	}
+}
+
+// flusher sends the aggregates of each interval to the batcher.
+func (s *Service) flusher() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.config.FlushInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			points, invalid := s.aggregator.Flush(now.UTC())
+			atomic.AddInt64(&s.stats.InvalidDroppedPoints, int64(invalid))
+			for _, p := range points {
+				select {
+				case s.batcher.In() <- p:
+				case <-s.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close closes the service and the underlying listener.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed() {
+		return nil // Already closed.
+	}
+	close(s.done)
+
+	if s.conn != nil {
+		s.conn.Close()
+	}
+
+	s.wg.Wait()
+	if s.batcher != nil {
+		s.batcher.Stop()
+	}
+
+	// Release all remaining resources.
+	s.done = nil
+	s.conn = nil
+	s.batcher = nil
+
+	s.Logger.Info("Service closed")
+
+	return nil
+}
+
+// Closed returns true if the service is currently closed.
+func (s *Service) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed()
+}
+
+func (s *Service) closed() bool {
+	select {
+	case <-s.done:
+		// Service is closing.
+		return true
+	default:
+	}
+	return s.done == nil
+}
+
+// createInternalStorage ensures that the required database has been created.
+func (s *Service) createInternalStorage() error {
+	s.mu.RLock()
+	ready := s.ready
+	s.mu.RUnlock()
+	if ready {
+		return nil
+	}
+
+	if _, err := s.MetaClient.CreateDatabase(s.config.Database); err != nil {
+		return err
+	}
+
+	// The service is now ready.
+	s.mu.Lock()
+	s.ready = true
+	s.mu.Unlock()
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.Logger = *log.With(zap.String("service", "statsd"))
+}
+
+// Addr returns the listener's address.
+func (s *Service) Addr() net.Addr {
+	return s.addr
+}
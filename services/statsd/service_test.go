@@ -0,0 +1,160 @@
+package statsd
+
+import (
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darshanman40/influxdb/internal"
+	"github.com/darshanman40/influxdb/models"
+	"github.com/darshanman40/influxdb/services/meta"
+	"github.com/darshanman40/influxdb/toml"
+	"go.uber.org/zap"
+)
+
+func TestService_OpenClose(t *testing.T) {
+	service := NewTestService(nil)
+
+	// Closing a closed service is fine.
+	if err := service.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Opening an already open service is fine.
+	if err := service.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening a previously opened service is fine.
+	if err := service.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tidy up.
+	if err := service.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure metrics received over UDP are aggregated and written at the end of
+// the flush interval.
+func TestService_Metrics(t *testing.T) {
+	t.Parallel()
+
+	c := NewConfig()
+	c.BindAddress = "127.0.0.1:0"
+	c.FlushInterval = toml.Duration(100 * time.Millisecond)
+	c.BatchTimeout = toml.Duration(10 * time.Millisecond)
+	c.DeleteGauges = true
+	s := NewTestService(&c)
+
+	pointCh := make(chan models.Point, 100)
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		if database != "statsd" {
+			t.Errorf("unexpected database: %s", database)
+		}
+		for _, p := range points {
+			pointCh <- p
+		}
+		return nil
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn, err := net.Dial("udp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("requests:1|c|#host:web01\nrequests:2|c|#host:web01\nbad\ntemperature:21.5|g\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for len(got) < 2 {
+		select {
+		case p := <-pointCh:
+			// Drop the timestamp, which is the time of the flush.
+			got = append(got, strings.Join(strings.Fields(p.String())[:2], " "))
+		case <-timeout:
+			t.Fatalf("timed out waiting for points, got %q", got)
+		}
+	}
+	sort.Strings(got)
+
+	if exp := []string{
+		"requests,host=web01 value=3",
+		"temperature value=21.5",
+	}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points:\n\nexp=%q\n\ngot=%q", exp, got)
+	}
+}
+
+type TestService struct {
+	Service       *Service
+	Config        Config
+	MetaClient    *internal.MetaClientMock
+	WritePointsFn func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+}
+
+func NewTestService(c *Config) *TestService {
+	if c == nil {
+		defaultC := NewConfig()
+		defaultC.BindAddress = "127.0.0.1:0"
+		c = &defaultC
+	}
+
+	srv, err := NewService(*c)
+	if err != nil {
+		panic(err)
+	}
+
+	service := &TestService{
+		Service:    srv,
+		Config:     *c,
+		MetaClient: &internal.MetaClientMock{},
+	}
+	service.MetaClient.CreateDatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return nil, nil
+	}
+
+	service.Service.WithLogger(*getLogger(testing.Verbose()))
+	service.Service.MetaClient = service.MetaClient
+	service.Service.PointsWriter = service
+	return service
+}
+
+func (s *TestService) WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+	return s.WritePointsFn(database, retentionPolicy, consistencyLevel, points)
+}
+
+func getLogger(verbose bool) *zap.Logger {
+	var z *zap.Logger
+	var err error
+	if verbose {
+		z, err = zap.NewDevelopment()
+	} else {
+		z, err = zap.NewProduction()
+	}
+
+	if err != nil {
+		panic(err)
+	}
+	return z
+}
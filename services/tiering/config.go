@@ -0,0 +1,77 @@
+package tiering
+
+import (
+	"errors"
+	"time"
+
+	"github.com/darshanman40/influxdb/toml"
+)
+
+const (
+	// DefaultCheckInterval is the default interval between checks for cold shards.
+	DefaultCheckInterval = 30 * time.Minute
+
+	// DefaultColdAfter is the default time after the end of a shard group
+	// before its shards are moved to the cold tier.
+	DefaultColdAfter = 30 * 24 * time.Hour
+
+	// DefaultS3Region is the default region used to sign S3 requests.
+	DefaultS3Region = "us-east-1"
+
+	// DefaultS3Timeout is the default time limit of a request to the S3
+	// endpoint, including the transfer of the object.
+	DefaultS3Timeout = 10 * time.Minute
+)
+
+// Config represents the configuration for the tiering service.
+type Config struct {
+	Enabled       bool          `toml:"enabled"`
+	CheckInterval toml.Duration `toml:"check-interval"`
+	ColdAfter     toml.Duration `toml:"cold-after"`
+
+	// Dir is the directory that cold shards are moved to. When an S3
+	// endpoint is set, it only holds a manifest of each cold shard.
+	Dir string `toml:"dir"`
+
+	S3Endpoint        string `toml:"s3-endpoint"`
+	S3Bucket          string `toml:"s3-bucket"`
+	S3Region          string `toml:"s3-region"`
+	S3AccessKeyID     string `toml:"s3-access-key-id"`
+	S3SecretAccessKey string `toml:"s3-secret-access-key"`
+
+	// S3Timeout limits the time of each request to the S3 endpoint, so that
+	// an unresponsive endpoint doesn't stall the moves to the cold tier and
+	// the queries fetching cold shards.
+	S3Timeout toml.Duration `toml:"s3-timeout"`
+}
+
+// NewConfig returns an instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		CheckInterval: toml.Duration(DefaultCheckInterval),
+		ColdAfter:     toml.Duration(DefaultColdAfter),
+		S3Region:      DefaultS3Region,
+		S3Timeout:     toml.Duration(DefaultS3Timeout),
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Dir == "" {
+		return errors.New("dir must be specified")
+	} else if c.CheckInterval <= 0 {
+		return errors.New("check-interval must be positive")
+	} else if c.ColdAfter <= 0 {
+		return errors.New("cold-after must be positive")
+	} else if c.S3Endpoint != "" && c.S3Bucket == "" {
+		return errors.New("s3-bucket must be specified with s3-endpoint")
+	} else if c.S3Endpoint != "" && c.S3Timeout <= 0 {
+		return errors.New("s3-timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package tiering_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/darshanman40/influxdb/services/tiering"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c tiering.Config
+	if _, err := toml.Decode(`
+enabled = true
+check-interval = "1s"
+cold-after = "24h"
+dir = "/var/lib/influxdb/cold"
+s3-endpoint = "http://localhost:9000"
+s3-bucket = "influxdb"
+s3-timeout = "1m"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if c.Enabled != true {
+		t.Fatalf("unexpected enabled state: %v", c.Enabled)
+	} else if time.Duration(c.CheckInterval) != time.Second {
+		t.Fatalf("unexpected check interval: %v", c.CheckInterval)
+	} else if time.Duration(c.ColdAfter) != 24*time.Hour {
+		t.Fatalf("unexpected cold after: %v", c.ColdAfter)
+	} else if c.Dir != "/var/lib/influxdb/cold" {
+		t.Fatalf("unexpected dir: %s", c.Dir)
+	} else if c.S3Endpoint != "http://localhost:9000" {
+		t.Fatalf("unexpected s3 endpoint: %s", c.S3Endpoint)
+	} else if c.S3Bucket != "influxdb" {
+		t.Fatalf("unexpected s3 bucket: %s", c.S3Bucket)
+	} else if time.Duration(c.S3Timeout) != time.Minute {
+		t.Fatalf("unexpected s3 timeout: %v", c.S3Timeout)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := tiering.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail from NewConfig: %s", err)
+	}
+
+	c = tiering.NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for missing dir, got nil")
+	}
+
+	c = tiering.NewConfig()
+	c.Enabled = true
+	c.Dir = "/tmp"
+	c.ColdAfter = 0
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for cold-after = 0, got nil")
+	}
+
+	c = tiering.NewConfig()
+	c.Enabled = true
+	c.Dir = "/tmp"
+	c.S3Endpoint = "http://localhost:9000"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for missing s3-bucket, got nil")
+	}
+
+	c = tiering.NewConfig()
+	c.Enabled = true
+	c.Dir = "/tmp"
+	c.S3Endpoint = "http://localhost:9000"
+	c.S3Bucket = "influxdb"
+	c.S3Timeout = 0
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for s3-timeout = 0, got nil")
+	}
+}
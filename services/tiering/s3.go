@@ -0,0 +1,186 @@
+package tiering
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// unsignedPayload is the payload hash sent in place of the SHA256 of the
+// request body, which lets uploads be streamed.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Client is an object store backed by a bucket of an S3-compatible
+// endpoint. Objects are addressed path-style and requests are signed with
+// AWS Signature Version 4 when credentials are set.
+type S3Client struct {
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	Client *http.Client
+
+	// now returns the time used to sign requests.
+	now func() time.Time
+}
+
+// NewS3Client returns a client for the S3 settings of c.
+func NewS3Client(c Config) *S3Client {
+	return &S3Client{
+		Endpoint:        c.S3Endpoint,
+		Bucket:          c.S3Bucket,
+		Region:          c.S3Region,
+		AccessKeyID:     c.S3AccessKeyID,
+		SecretAccessKey: c.S3SecretAccessKey,
+		Client:          &http.Client{Timeout: time.Duration(c.S3Timeout)},
+		now:             time.Now,
+	}
+}
+
+// Put uploads size bytes read from r to the object key.
+func (c *S3Client) Put(key string, r io.Reader, size int64) error {
+	req, err := c.newRequest("PUT", key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	resp, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Get returns a reader for the object key. The caller must close it.
+func (c *S3Client) Get(key string) (io.ReadCloser, error) {
+	req, err := c.newRequest("GET", key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object key. Deleting a missing object is not an error.
+func (c *S3Client) Delete(key string) error {
+	req, err := c.newRequest("DELETE", key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, http.StatusOK, http.StatusNoContent, http.StatusNotFound)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// newRequest returns a signed request for the object key.
+func (c *S3Client) newRequest(method, key string, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(strings.TrimSuffix(c.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/" + c.Bucket + "/" + key
+	u.RawPath = escapePath(u.Path)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-amz-content-sha256", unsignedPayload)
+	if c.AccessKeyID != "" {
+		c.sign(req, c.now().UTC())
+	}
+	return req, nil
+}
+
+// do sends req and returns an error unless the response has one of the
+// expected status codes.
+func (c *S3Client) do(req *http.Request, codes ...int) (*http.Response, error) {
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("s3: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// sign adds an AWS Signature Version 4 authorization header to req.
+func (c *S3Client) sign(req *http.Request, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("x-amz-date", amzDate)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + unsignedPayload,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + c.Region + "/s3/aws4_request"
+	h := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(h[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath percent-encodes every byte of a path except unreserved
+// characters and slashes, as required for signing.
+func escapePath(path string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(path); i++ {
+		b := path[i]
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' || b == '/' {
+			buf.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&buf, "%%%02X", b)
+	}
+	return buf.String()
+}
@@ -0,0 +1,132 @@
+package tiering_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darshanman40/influxdb/services/tiering"
+	"github.com/darshanman40/influxdb/toml"
+)
+
+// Ensure the S3 client can upload, download and delete objects.
+func TestS3Client(t *testing.T) {
+	s := NewS3Server()
+	defer s.Close()
+
+	c := tiering.NewS3Client(tiering.Config{
+		S3Endpoint:        s.URL,
+		S3Bucket:          "influxdb",
+		S3Region:          tiering.DefaultS3Region,
+		S3AccessKeyID:     "AKID",
+		S3SecretAccessKey: "secret",
+	})
+
+	if err := c.Put("db0/rp0/1/000000001-000000001.tsm", bytes.NewReader([]byte("data")), 4); err != nil {
+		t.Fatal(err)
+	} else if _, ok := s.objects["/influxdb/db0/rp0/1/000000001-000000001.tsm"]; !ok {
+		t.Fatalf("unexpected objects: %v", s.objects)
+	}
+
+	// Every request is signed.
+	if !strings.HasPrefix(s.auth, "AWS4-HMAC-SHA256 Credential=AKID/") {
+		t.Fatalf("unexpected authorization: %s", s.auth)
+	}
+
+	rc, err := c.Get("db0/rp0/1/000000001-000000001.tsm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "data" {
+		t.Fatalf("unexpected data: %q", buf)
+	}
+
+	if err := c.Delete("db0/rp0/1/000000001-000000001.tsm"); err != nil {
+		t.Fatal(err)
+	} else if len(s.objects) != 0 {
+		t.Fatalf("unexpected objects: %v", s.objects)
+	}
+
+	// Missing objects return an error.
+	if _, err := c.Get("db0/rp0/1/000000001-000000001.tsm"); err == nil {
+		t.Fatal("expected error for missing object")
+	}
+}
+
+// Ensure requests to an unresponsive endpoint time out.
+func TestS3Client_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer s.Close()
+	defer close(done)
+
+	c := tiering.NewS3Client(tiering.Config{
+		S3Endpoint: s.URL,
+		S3Bucket:   "influxdb",
+		S3Timeout:  toml.Duration(10 * time.Millisecond),
+	})
+
+	if _, err := c.Get("db0/rp0/1/000000001-000000001.tsm"); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
+
+// S3Server is a local stand-in for an S3-compatible endpoint.
+type S3Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	auth    string
+}
+
+// NewS3Server returns a new, running instance of S3Server.
+func NewS3Server() *S3Server {
+	s := &S3Server{objects: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *S3Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.auth = r.Header.Get("Authorization")
+	if r.Header.Get("x-amz-date") == "" || r.Header.Get("x-amz-content-sha256") == "" {
+		http.Error(w, "missing signature headers", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		buf, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[r.URL.Path] = buf
+	case "GET":
+		buf, ok := s.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(buf)
+	case "DELETE":
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
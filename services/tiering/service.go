@@ -0,0 +1,114 @@
+// Package tiering provides the service that moves cold shards to a secondary
+// directory or an S3-compatible object store.
+package tiering // import "github.com/darshanman40/influxdb/services/tiering"
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/darshanman40/influxdb/services/meta"
+	"go.uber.org/zap"
+)
+
+// Service represents the service that moves shards to the cold tier.
+type Service struct {
+	MetaClient interface {
+		Databases() []meta.DatabaseInfo
+	}
+	TSDBStore interface {
+		ShardIDs() []uint64
+		TierShard(shardID uint64) error
+	}
+
+	// ResultCache drops the cached query results of moved shard groups.
+	ResultCache interface {
+		InvalidateRange(database string, start, end time.Time)
+	}
+
+	checkInterval time.Duration
+	coldAfter     time.Duration
+	wg            sync.WaitGroup
+	done          chan struct{}
+
+	logger zap.Logger
+}
+
+// NewService returns a configured tiering service.
+func NewService(c Config) *Service {
+	return &Service{
+		checkInterval: time.Duration(c.CheckInterval),
+		coldAfter:     time.Duration(c.ColdAfter),
+		done:          make(chan struct{}),
+		logger:        *zap.NewNop(),
+	}
+}
+
+// Open starts moving cold shards.
+func (s *Service) Open() error {
+	s.logger.Info(fmt.Sprint("Starting tiering service with check interval of ", s.checkInterval))
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Close stops moving cold shards.
+func (s *Service) Close() error {
+	s.logger.Info("tiering service terminating")
+	close(s.done)
+	s.wg.Wait()
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.logger = *log.With(zap.String("service", "tiering"))
+}
+
+func (s *Service) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case <-ticker.C:
+			s.tierShards(time.Now().UTC())
+		}
+	}
+}
+
+// tierShards moves the local shards of every shard group that ended more
+// than coldAfter before now to the cold tier.
+func (s *Service) tierShards(now time.Time) {
+	local := make(map[uint64]struct{})
+	for _, id := range s.TSDBStore.ShardIDs() {
+		local[id] = struct{}{}
+	}
+
+	cutoff := now.Add(-s.coldAfter)
+	for _, d := range s.MetaClient.Databases() {
+		for _, r := range d.RetentionPolicies {
+			for _, g := range r.ShardGroups {
+				if g.Deleted() || !g.EndTime.Before(cutoff) {
+					continue
+				}
+
+				for _, sh := range g.Shards {
+					if _, ok := local[sh.ID]; !ok {
+						continue
+					}
+					if err := s.TSDBStore.TierShard(sh.ID); err != nil {
+						s.logger.Info(fmt.Sprintf("failed to move shard ID %d from database %s, retention policy %s to the cold tier: %s",
+							sh.ID, d.Name, r.Name, err.Error()))
+					} else if s.ResultCache != nil {
+						s.ResultCache.InvalidateRange(d.Name, g.StartTime, g.EndTime)
+					}
+				}
+			}
+		}
+	}
+}
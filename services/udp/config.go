@@ -1,6 +1,7 @@
 package udp
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/darshanman40/influxdb/toml"
@@ -10,6 +11,9 @@ const (
 	// DefaultBindAddress is the default binding interface if none is specified.
 	DefaultBindAddress = ":8089"
 
+	// DefaultNetwork is the default network the service listens on.
+	DefaultNetwork = "udp"
+
 	// DefaultDatabase is the default database for UDP traffic.
 	DefaultDatabase = "udp"
 
@@ -47,6 +51,11 @@ type Config struct {
 	Enabled     bool   `toml:"enabled"`
 	BindAddress string `toml:"bind-address"`
 
+	// Network is the network of the listener: "udp", "tcp", or "unix" or
+	// "unixgram" for Unix stream and datagram sockets, in which case the bind
+	// address is the path of the socket.
+	Network string `toml:"network"`
+
 	Database        string        `toml:"database"`
 	RetentionPolicy string        `toml:"retention-policy"`
 	BatchSize       int           `toml:"batch-size"`
@@ -63,6 +72,7 @@ type Config struct {
 func NewConfig() Config {
 	return Config{
 		BindAddress:     DefaultBindAddress,
+		Network:         DefaultNetwork,
 		Database:        DefaultDatabase,
 		RetentionPolicy: DefaultRetentionPolicy,
 		BatchSize:       DefaultBatchSize,
@@ -75,6 +85,9 @@ func NewConfig() Config {
 // default values set.
 func (c *Config) WithDefaults() *Config {
 	d := *c
+	if d.Network == "" {
+		d.Network = DefaultNetwork
+	}
 	if d.Database == "" {
 		d.Database = DefaultDatabase
 	}
@@ -95,3 +108,13 @@ func (c *Config) WithDefaults() *Config {
 	}
 	return &d
 }
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	switch c.Network {
+	case "", "udp", "tcp", "unix", "unixgram":
+		return nil
+	default:
+		return fmt.Errorf("unsupported network: %s", c.Network)
+	}
+}
@@ -41,3 +41,18 @@ udp-payload-size = 1500
 		t.Fatalf("unexpected batch timeout: %v", c.BatchTimeout)
 	}
 }
+
+func TestConfig_Validate(t *testing.T) {
+	c := udp.NewConfig()
+	for _, network := range []string{"udp", "tcp", "unix", "unixgram"} {
+		c.Network = network
+		if err := c.Validate(); err != nil {
+			t.Fatalf("%s: unexpected error: %s", network, err)
+		}
+	}
+
+	c.Network = "sctp"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -4,7 +4,11 @@ package udp // import "github.com/darshanman40/influxdb/services/udp"
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"os"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,14 +36,22 @@ const (
 	statBatchesTransmitted  = "batchesTx"
 	statPointsTransmitted   = "pointsTx"
 	statBatchesTransmitFail = "batchesTxFail"
+	statConnectionsActive   = "connsActive"
+	statConnectionsHandled  = "connsHandled"
 )
 
 // Service is a UDP service that will listen for incoming packets of line protocol.
+// It can also listen on TCP or a Unix socket, as set by the Network option.
 type Service struct {
-	conn *net.UDPConn
-	addr *net.UDPAddr
+	conn net.PacketConn // Listener for the udp and unixgram networks.
+	ln   net.Listener   // Listener for the tcp and unix networks.
+	addr net.Addr
 	wg   sync.WaitGroup
 
+	connMu sync.Mutex
+	conns  map[uint64]*streamConn // Active connections of a stream listener.
+	nextID uint64
+
 	mu    sync.RWMutex
 	ready bool          // Has the required database been created?
 	done  chan struct{} // Is the service closing or closed?
@@ -68,6 +80,7 @@ func NewService(c Config) *Service {
 		config:      d,
 		parserChan:  make(chan []byte, parserChanLen),
 		batcher:     tsdb.NewPointBatcher(d.BatchSize, d.BatchPending, time.Duration(d.BatchTimeout)),
+		conns:       make(map[uint64]*streamConn),
 		Logger:      *zap.NewNop(),
 		stats:       &Statistics{},
 		defaultTags: models.StatisticTags{"bind": d.BindAddress},
@@ -90,21 +103,54 @@ func (s *Service) Open() (err error) {
 	if s.config.Database == "" {
 		return errors.New("database has to be specified in config")
 	}
-
-	s.addr, err = net.ResolveUDPAddr("udp", s.config.BindAddress)
-	if err != nil {
-		s.Logger.Info(fmt.Sprintf("Failed to resolve UDP address %s: %s", s.config.BindAddress, err))
+	if err := s.config.Validate(); err != nil {
 		return err
 	}
 
-	s.conn, err = net.ListenUDP("udp", s.addr)
-	if err != nil {
-		s.Logger.Info(fmt.Sprintf("Failed to set up UDP listener at address %s: %s", s.addr, err))
-		return err
+	switch s.config.Network {
+	case "tcp", "unix":
+		return s.openStream()
+	default:
+		return s.openPacket()
 	}
+}
+
+// openPacket listens for datagrams on the udp or unixgram network.
+func (s *Service) openPacket() error {
+	var (
+		conn interface {
+			net.PacketConn
+			SetReadBuffer(bytes int) error
+		}
+		err error
+	)
+	if s.config.Network == "unixgram" {
+		if err := removeStaleSocket(s.config.BindAddress); err != nil {
+			return err
+		}
+
+		addr := &net.UnixAddr{Name: s.config.BindAddress, Net: "unixgram"}
+		if conn, err = net.ListenUnixgram("unixgram", addr); err != nil {
+			s.Logger.Info(fmt.Sprintf("Failed to set up unixgram listener at %s: %s", s.config.BindAddress, err))
+			return err
+		}
+	} else {
+		addr, err := net.ResolveUDPAddr("udp", s.config.BindAddress)
+		if err != nil {
+			s.Logger.Info(fmt.Sprintf("Failed to resolve UDP address %s: %s", s.config.BindAddress, err))
+			return err
+		}
+
+		if conn, err = net.ListenUDP("udp", addr); err != nil {
+			s.Logger.Info(fmt.Sprintf("Failed to set up UDP listener at address %s: %s", addr, err))
+			return err
+		}
+	}
+	s.conn = conn
+	s.addr = conn.LocalAddr()
 
 	if s.config.ReadBuffer != 0 {
-		err = s.conn.SetReadBuffer(s.config.ReadBuffer)
+		err = conn.SetReadBuffer(s.config.ReadBuffer)
 		if err != nil {
 			s.Logger.Info(fmt.Sprintf("Failed to set UDP read buffer to %d: %s",
 				s.config.ReadBuffer, err))
@@ -112,7 +158,7 @@ func (s *Service) Open() (err error) {
 		}
 	}
 
-	s.Logger.Info(fmt.Sprintf("Started listening on UDP: %s", s.config.BindAddress))
+	s.Logger.Info(fmt.Sprintf("Started listening on %s: %s", s.config.Network, s.config.BindAddress))
 
 	s.wg.Add(3)
 	go s.serve()
@@ -122,6 +168,49 @@ func (s *Service) Open() (err error) {
 	return nil
 }
 
+// openStream listens for connections on the tcp or unix network. Each
+// connection sends newline separated line protocol.
+func (s *Service) openStream() error {
+	if s.config.Network == "unix" {
+		if err := removeStaleSocket(s.config.BindAddress); err != nil {
+			return err
+		}
+	}
+
+	ln, err := net.Listen(s.config.Network, s.config.BindAddress)
+	if err != nil {
+		s.Logger.Info(fmt.Sprintf("Failed to set up %s listener at address %s: %s", s.config.Network, s.config.BindAddress, err))
+		return err
+	}
+	s.ln = ln
+	s.addr = ln.Addr()
+
+	s.Logger.Info(fmt.Sprintf("Started listening on %s: %s", s.config.Network, s.config.BindAddress))
+
+	s.batcher.Start()
+	s.wg.Add(2)
+	go s.accept()
+	go s.writer()
+
+	return nil
+}
+
+// removeStaleSocket removes the Unix socket at path, left behind by a
+// previous listener. Files that aren't sockets are left alone.
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("bind address %s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
 // Statistics maintains statistics for the UDP service.
 type Statistics struct {
 	PointsReceived      int64
@@ -131,11 +220,37 @@ type Statistics struct {
 	BatchesTransmitted  int64
 	PointsTransmitted   int64
 	BatchesTransmitFail int64
+	ActiveConnections   int64
+	HandledConnections  int64
+}
+
+// ConnectionStatistics maintains statistics for a connection to a TCP or
+// Unix stream listener.
+type ConnectionStatistics struct {
+	PointsReceived  int64
+	BytesReceived   int64
+	PointsParseFail int64
+}
+
+// streamConn is an active connection to a stream listener.
+type streamConn struct {
+	id    uint64
+	conn  net.Conn
+	stats *ConnectionStatistics
 }
 
-// Statistics returns statistics for periodic monitoring.
+// streamConns sorts connections by id.
+type streamConns []*streamConn
+
+func (a streamConns) Len() int           { return len(a) }
+func (a streamConns) Less(i, j int) bool { return a[i].id < a[j].id }
+func (a streamConns) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// Statistics returns statistics for periodic monitoring. Stream listeners
+// also return the statistics of each active connection, which are counted in
+// the listener's as well. Each connection is a new series, tagged with its id.
 func (s *Service) Statistics(tags map[string]string) []models.Statistic {
-	return []models.Statistic{{
+	statistics := []models.Statistic{{
 		Name: "udp",
 		Tags: s.defaultTags.Merge(tags),
 		Values: map[string]interface{}{
@@ -146,8 +261,43 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statBatchesTransmitted:  atomic.LoadInt64(&s.stats.BatchesTransmitted),
 			statPointsTransmitted:   atomic.LoadInt64(&s.stats.PointsTransmitted),
 			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+			statConnectionsActive:   atomic.LoadInt64(&s.stats.ActiveConnections),
+			statConnectionsHandled:  atomic.LoadInt64(&s.stats.HandledConnections),
 		},
 	}}
+
+	s.connMu.Lock()
+	conns := make([]*streamConn, 0, len(s.conns))
+	for _, c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.connMu.Unlock()
+	sort.Sort(streamConns(conns))
+
+	for _, c := range conns {
+		connTags := s.defaultTags.Merge(tags)
+		connTags["conn"] = strconv.FormatUint(c.id, 10)
+		connTags["remote"] = remoteAddr(c.conn)
+		statistics = append(statistics, models.Statistic{
+			Name: "udp_connection",
+			Tags: connTags,
+			Values: map[string]interface{}{
+				statPointsReceived:  atomic.LoadInt64(&c.stats.PointsReceived),
+				statBytesReceived:   atomic.LoadInt64(&c.stats.BytesReceived),
+				statPointsParseFail: atomic.LoadInt64(&c.stats.PointsParseFail),
+			},
+		})
+	}
+	return statistics
+}
+
+// remoteAddr returns the address of the peer of conn. Clients of Unix sockets
+// are usually unnamed.
+func remoteAddr(conn net.Conn) string {
+	if addr := conn.RemoteAddr(); addr != nil && addr.String() != "" && addr.String() != "@" {
+		return addr.String()
+	}
+	return "unnamed"
 }
 
 func (s *Service) writer() {
@@ -188,7 +338,7 @@ func (s *Service) serve() {
 			return
 		default:
 			// Keep processing.
-			n, _, err := s.conn.ReadFromUDP(buf)
+			n, _, err := s.conn.ReadFrom(buf)
 			if err != nil {
 				atomic.AddInt64(&s.stats.ReadFail, 1)
 				s.Logger.Info(fmt.Sprintf("Failed to read UDP message: %s", err))
@@ -226,6 +376,123 @@ func (s *Service) parser() {
 	}
 }
 
+// accept accepts connections to the stream listener until it is closed.
+func (s *Service) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				s.Logger.Info(fmt.Sprintf("Temporary error accepting connection: %s", err))
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			s.Logger.Info(fmt.Sprintf("Failed to accept connection: %s", err))
+			return
+		}
+
+		c := s.trackConn(conn)
+		if c == nil {
+			// The service closed while the connection was accepted.
+			conn.Close()
+			return
+		}
+
+		s.wg.Add(1)
+		go s.handleConn(c)
+	}
+}
+
+// trackConn registers conn as an active connection. It returns nil if the
+// service is closing.
+func (s *Service) trackConn(conn net.Conn) *streamConn {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	s.nextID++
+	c := &streamConn{id: s.nextID, conn: conn, stats: &ConnectionStatistics{}}
+	s.conns[c.id] = c
+	atomic.AddInt64(&s.stats.ActiveConnections, 1)
+	atomic.AddInt64(&s.stats.HandledConnections, 1)
+	return c
+}
+
+// handleConn reads points from a connection to the stream listener until the
+// client closes it or the service closes.
+func (s *Service) handleConn(c *streamConn) {
+	defer s.wg.Done()
+	defer func() {
+		c.conn.Close()
+
+		s.connMu.Lock()
+		delete(s.conns, c.id)
+		s.connMu.Unlock()
+		atomic.AddInt64(&s.stats.ActiveConnections, -1)
+	}()
+
+	remote := remoteAddr(c.conn)
+	r := models.NewPointReader(&countingReader{r: c.conn, stats: []*int64{&s.stats.BytesReceived, &c.stats.BytesReceived}}, time.Time{}, s.config.Precision)
+	r.MaxLineSize = MAX_UDP_PAYLOAD
+	for {
+		points, failed, err := r.ReadPoints(1)
+		for _, e := range failed {
+			atomic.AddInt64(&s.stats.PointsParseFail, 1)
+			atomic.AddInt64(&c.stats.PointsParseFail, 1)
+			s.Logger.Info(fmt.Sprintf("Failed to parse points from %s: %s", remote, e))
+		}
+
+		for _, p := range points {
+			select {
+			case s.batcher.In() <- p:
+			case <-s.done:
+				return
+			}
+		}
+		atomic.AddInt64(&s.stats.PointsReceived, int64(len(points)))
+		atomic.AddInt64(&c.stats.PointsReceived, int64(len(points)))
+
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			select {
+			case <-s.done:
+				// The connection was closed by Close.
+			default:
+				atomic.AddInt64(&s.stats.ReadFail, 1)
+				s.Logger.Info(fmt.Sprintf("Failed to read from %s: %s", remote, err))
+			}
+			return
+		}
+	}
+}
+
+// countingReader adds the number of bytes read from r to each of stats.
+type countingReader struct {
+	r     io.Reader
+	stats []*int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for _, stat := range r.stats {
+		atomic.AddInt64(stat, int64(n))
+	}
+	return n, err
+}
+
 // Close closes the service and the underlying listener.
 func (s *Service) Close() error {
 	s.mu.Lock()
@@ -238,7 +505,19 @@ func (s *Service) Close() error {
 
 	if s.conn != nil {
 		s.conn.Close()
+		if s.config.Network == "unixgram" {
+			os.Remove(s.config.BindAddress)
+		}
+	}
+	if s.ln != nil {
+		s.ln.Close()
+	}
+
+	s.connMu.Lock()
+	for _, c := range s.conns {
+		c.conn.Close()
 	}
+	s.connMu.Unlock()
 
 	s.batcher.Flush()
 	s.wg.Wait()
@@ -246,6 +525,7 @@ func (s *Service) Close() error {
 	// Release all remaining resources.
 	s.done = nil
 	s.conn = nil
+	s.ln = nil
 
 	s.Logger.Info("Service closed")
 
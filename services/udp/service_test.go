@@ -2,6 +2,11 @@ package udp
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -123,6 +128,183 @@ func TestService_CreatesDatabase(t *testing.T) {
 	s.Service.Close()
 }
 
+func TestService_TCP(t *testing.T) {
+	t.Parallel()
+
+	c := NewConfig()
+	c.Network = "tcp"
+	c.BindAddress = "127.0.0.1:0"
+	c.BatchSize = 1
+	testStreamService(t, c)
+}
+
+func TestService_Unix(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "udp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	c.Network = "unix"
+	c.BindAddress = filepath.Join(dir, "influxdb.sock")
+	c.BatchSize = 1
+	testStreamService(t, c)
+}
+
+// testStreamService writes points over two connections to a stream listener
+// and ensures they are written and counted per connection.
+func testStreamService(t *testing.T, c Config) {
+	s := NewTestService(&c)
+	s.MetaClient.CreateDatabaseFn = func(string) (*meta.DatabaseInfo, error) { return nil, nil }
+
+	written := make(chan models.Point, 10)
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		for _, p := range points {
+			written <- p
+		}
+		return nil
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn1, err := net.Dial(c.Network, s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn1.Close()
+
+	conn2, err := net.Dial(c.Network, s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+
+	if _, err := conn1.Write([]byte("bad line\ncpu value=1 10\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn2.Write([]byte("mem value=2 20\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case p := <-written:
+			got[p.String()] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for points")
+		}
+	}
+	for _, exp := range []string{"cpu value=1 10", "mem value=2 20"} {
+		if !got[exp] {
+			t.Fatalf("point %q not written, got %v", exp, got)
+		}
+	}
+
+	stats := s.Service.Statistics(nil)
+	if len(stats) != 3 {
+		t.Fatalf("got %d statistics, expected 3", len(stats))
+	}
+	if got, exp := stats[0].Values[statConnectionsActive], int64(2); got != exp {
+		t.Fatalf("got %v active connections, expected %v", got, exp)
+	}
+	if got, exp := stats[0].Values[statPointsParseFail], int64(1); got != exp {
+		t.Fatalf("got %v parse failures, expected %v", got, exp)
+	}
+	if got, exp := stats[0].Values[statPointsReceived], int64(2); got != exp {
+		t.Fatalf("got %v points, expected %v", got, exp)
+	}
+
+	// Each active connection reports its own statistics.
+	for i, exp := range []int64{1, 0} {
+		st := stats[i+1]
+		if st.Name != "udp_connection" || st.Tags["conn"] != fmt.Sprint(i+1) {
+			t.Fatalf("unexpected connection statistic: %v", st)
+		}
+		if got := st.Values[statPointsParseFail]; got != exp {
+			t.Fatalf("connection %d: got %v parse failures, expected %v", i+1, got, exp)
+		}
+		if got := st.Values[statPointsReceived]; got != int64(1) {
+			t.Fatalf("connection %d: got %v points, expected 1", i+1, got)
+		}
+	}
+
+	// Closing a connection stops tracking it.
+	conn1.Close()
+	timeout := time.After(5 * time.Second)
+	for len(s.Service.Statistics(nil)) != 2 || s.Service.Statistics(nil)[0].Values[statConnectionsActive] != int64(1) {
+		select {
+		case <-timeout:
+			t.Fatal("connection still tracked after it was closed")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestService_Unixgram(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "udp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	c.Network = "unixgram"
+	c.BindAddress = filepath.Join(dir, "influxdb.sock")
+	c.BatchSize = 1
+
+	s := NewTestService(&c)
+	s.MetaClient.CreateDatabaseFn = func(string) (*meta.DatabaseInfo, error) { return nil, nil }
+
+	written := make(chan models.Point, 10)
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		for _, p := range points {
+			written <- p
+		}
+		return nil
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("unixgram", c.BindAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("cpu value=1 10\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case p := <-written:
+		if got, exp := p.String(), "cpu value=1 10"; got != exp {
+			t.Fatalf("got %q, expected %q", got, exp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for points")
+	}
+
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The socket is removed on close, so the service can be reopened.
+	if _, err := os.Stat(c.BindAddress); !os.IsNotExist(err) {
+		t.Fatalf("socket not removed: %v", err)
+	}
+}
+
 type TestService struct {
 	Service       *Service
 	Config        Config
@@ -14,6 +14,13 @@ const (
 
 	// tsdb/engine/wal configuration options
 
+	// DefaultWALFsyncDelay is the amount of time that a write waits for other
+	// writes before the WAL is fsynced.
+	DefaultWALFsyncDelay = time.Duration(0)
+
+	// DefaultWALDurability is the default durability mode of the WAL.
+	DefaultWALDurability = WALDurabilitySync
+
 	// Default settings for TSM
 
 	// DefaultCacheMaxMemorySize is the maximum size a shard's cache can
@@ -33,6 +40,11 @@ const (
 	// will compact all TSM files in a shard if it hasn't received a write or delete
 	DefaultCompactFullWriteColdDuration = time.Duration(4 * time.Hour)
 
+	// DefaultBackfillThreshold is how much older than the newest data in a
+	// shard a write must be before it is treated as a backfill. A value of 0
+	// disables the backfill path.
+	DefaultBackfillThreshold = time.Duration(0)
+
 	// DefaultMaxPointsPerBlock is the maximum number of points in an encoded
 	// block in a TSM file
 	DefaultMaxPointsPerBlock = 1000
@@ -44,6 +56,16 @@ const (
 	DefaultMaxValuesPerTag = 100000
 )
 
+const (
+	// WALDurabilitySync acknowledges writes once they have been fsynced to the WAL.
+	WALDurabilitySync = "sync"
+
+	// WALDurabilityAsync acknowledges writes once they have been written to the WAL
+	// and fsyncs in the background.  Writes acknowledged within the fsync delay can
+	// be lost if the host crashes.
+	WALDurabilityAsync = "async"
+)
+
 // Config holds the configuration for the tsbd package.
 type Config struct {
 	Dir    string `toml:"dir"`
@@ -52,6 +74,16 @@ type Config struct {
 	// General WAL configuration options
 	WALDir string `toml:"wal-dir"`
 
+	// WALFsyncDelay is the amount of time that a write waits before the WAL is
+	// fsynced.  Writes that arrive within the delay share a single fsync.  A value
+	// of 0 fsyncs immediately, batching only the writes that arrive while an
+	// fsync is in progress.
+	WALFsyncDelay toml.Duration `toml:"wal-fsync-delay"`
+
+	// WALDurability is either "sync" or "async".  See WALDurabilitySync and
+	// WALDurabilityAsync.
+	WALDurability string `toml:"wal-durability"`
+
 	// Query logging
 	QueryLogEnabled bool `toml:"query-log-enabled"`
 
@@ -61,6 +93,25 @@ type Config struct {
 	CacheSnapshotWriteColdDuration toml.Duration `toml:"cache-snapshot-write-cold-duration"`
 	CompactFullWriteColdDuration   toml.Duration `toml:"compact-full-write-cold-duration"`
 
+	// BackfillThreshold is how far behind the newest data in a shard a value's
+	// timestamp must be for it to be written to separate backfill TSM files when
+	// the cache is snapshotted.  Backfill files are merged into the existing
+	// data in a single compaction rather than through the level compactions.
+	// A value of 0 disables the backfill path.
+	BackfillThreshold toml.Duration `toml:"backfill-threshold"`
+
+	// VerifyBlockChecksums enables verification of the checksum of every TSM
+	// block as it is read by queries and compactions.  A file containing a
+	// corrupt block is quarantined and the rest of the shard stays readable.
+	VerifyBlockChecksums bool `toml:"verify-block-checksums"`
+
+	// EncryptionKeyFile is the path of the file holding the keys that
+	// encrypt the TSM files, their tombstones, the field types, the
+	// manifests of the shards held by an object store and the WAL.  Files
+	// are written encrypted with the last key of the file and read with any
+	// of its keys.  Data isn't encrypted if unset.
+	EncryptionKeyFile string `toml:"encryption-key-file"`
+
 	// Limits
 
 	// MaxSeriesPerDatabase is the maximum number of series a node can hold per database.
@@ -81,12 +132,16 @@ func NewConfig() Config {
 	return Config{
 		Engine: DefaultEngine,
 
+		WALFsyncDelay: toml.Duration(DefaultWALFsyncDelay),
+		WALDurability: DefaultWALDurability,
+
 		QueryLogEnabled: true,
 
 		CacheMaxMemorySize:             DefaultCacheMaxMemorySize,
 		CacheSnapshotMemorySize:        DefaultCacheSnapshotMemorySize,
 		CacheSnapshotWriteColdDuration: toml.Duration(DefaultCacheSnapshotWriteColdDuration),
 		CompactFullWriteColdDuration:   toml.Duration(DefaultCompactFullWriteColdDuration),
+		BackfillThreshold:              toml.Duration(DefaultBackfillThreshold),
 
 		MaxSeriesPerDatabase: DefaultMaxSeriesPerDatabase,
 		MaxValuesPerTag:      DefaultMaxValuesPerTag,
@@ -114,5 +169,15 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("unrecognized engine %s", c.Engine)
 	}
 
+	if c.WALFsyncDelay < 0 {
+		return errors.New("Data.WALFsyncDelay must be non-negative")
+	}
+
+	switch c.WALDurability {
+	case WALDurabilitySync, WALDurabilityAsync:
+	default:
+		return fmt.Errorf("unrecognized wal-durability %s", c.WALDurability)
+	}
+
 	return nil
 }
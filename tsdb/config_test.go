@@ -2,6 +2,7 @@ package tsdb_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/darshanman40/influxdb/tsdb"
@@ -13,6 +14,8 @@ func TestConfig_Parse(t *testing.T) {
 	if _, err := toml.Decode(`
 dir = "/var/lib/influxdb/data"
 wal-dir = "/var/lib/influxdb/wal"
+wal-fsync-delay = "10ms"
+wal-durability = "async"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -27,6 +30,12 @@ wal-dir = "/var/lib/influxdb/wal"
 	if got, exp := c.WALDir, "/var/lib/influxdb/wal"; got != exp {
 		t.Errorf("unexpected wal-dir:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
 	}
+	if got, exp := time.Duration(c.WALFsyncDelay), 10*time.Millisecond; got != exp {
+		t.Errorf("unexpected wal-fsync-delay:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+	if got, exp := c.WALDurability, tsdb.WALDurabilityAsync; got != exp {
+		t.Errorf("unexpected wal-durability:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
 }
 
 func TestConfig_Validate_Error(t *testing.T) {
@@ -45,4 +54,10 @@ func TestConfig_Validate_Error(t *testing.T) {
 	if err := c.Validate(); err == nil || err.Error() != "unrecognized engine fake1" {
 		t.Errorf("unexpected error: %s", err)
 	}
+
+	c.Engine = tsdb.DefaultEngine
+	c.WALDurability = "fake"
+	if err := c.Validate(); err == nil || err.Error() != "unrecognized wal-durability fake" {
+		t.Errorf("unexpected error: %s", err)
+	}
 }
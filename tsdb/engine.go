@@ -40,9 +40,12 @@ type Engine interface {
 	DeleteSeries(keys []string) error
 	DeleteSeriesRange(keys []string, min, max int64) error
 	DeleteMeasurement(name string, seriesKeys []string) error
+	DeleteField(measurement, field string, seriesKeys []string) error
+	AlterField(measurement, field string, typ influxql.DataType, seriesKeys []string) error
 	SeriesCount() (n int, err error)
 	MeasurementFields(measurement string) *MeasurementFields
 	CreateSnapshot() (string, error)
+	WriteSnapshot() error
 	SetEnabled(enabled bool)
 
 	// Format will return the format for the engine
@@ -585,6 +585,9 @@ func (c *Cache) ApplyEntryFn(f func(key string, entry *entry) error) error {
 type CacheLoader struct {
 	files []string
 
+	// Keys decrypts the encrypted segment entries.
+	Keys *KeyRing
+
 	Logger zap.Logger
 }
 
@@ -615,12 +618,17 @@ func (cl *CacheLoader) Load(cache *Cache) error {
 			}
 			cl.Logger.Info(fmt.Sprintf("reading file %s, size %d", f.Name(), stat.Size()))
 
-			r := NewWALSegmentReader(f)
+			r := NewWALSegmentReaderWithKeys(f, cl.Keys)
 			defer r.Close()
 
 			for r.Next() {
 				entry, err := r.Read()
-				if err != nil {
+				if isEncryptionKeyError(err) && (err != ErrDecryptionFailed || r.Count() == 0) {
+					// The segment isn't truncated as the entry may be
+					// readable with the right keys.  Entries failing to
+					// decrypt after others did are corrupt.
+					return fmt.Errorf("file %s: %v", f.Name(), err)
+				} else if err != nil {
 					n := r.Count()
 					cl.Logger.Info(fmt.Sprintf("file %s corrupt at position %d, truncating", f.Name(), n))
 					if err := f.Truncate(n); err != nil {
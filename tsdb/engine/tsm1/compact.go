@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,12 +29,25 @@ import (
 
 const maxTSMFileSize = uint32(2048 * 1024 * 1024) // 2GB
 
+// maxBackfillGenerations is the number of backfill generations that must accumulate
+// before they are merged into the existing TSM files.
+const maxBackfillGenerations = 4
+
 const (
 	// CompactionTempExtension is the extension used for temporary files created during compaction.
 	CompactionTempExtension = "tmp"
 
 	// TSMFileExtension is the extension used for TSM files.
 	TSMFileExtension = "tsm"
+
+	// BackfillTSMFileExtension is the extension used for TSM files written with the
+	// backfilled values of a snapshot.  They keep it until a backfill compaction
+	// merges them into the existing TSM files.
+	BackfillTSMFileExtension = "backfill." + TSMFileExtension
+
+	// CorruptTSMFileExtension is appended to TSM files, and their tombstones, that
+	// were quarantined after failing checksum verification.
+	CorruptTSMFileExtension = "corrupt"
 )
 
 var (
@@ -53,6 +67,7 @@ type CompactionPlanner interface {
 	Plan(lastWrite time.Time) []CompactionGroup
 	PlanLevel(level int) []CompactionGroup
 	PlanOptimize() []CompactionGroup
+	PlanBackfill() []CompactionGroup
 }
 
 // DefaultPlanner implements CompactionPlanner using a strategy to roll up
@@ -114,6 +129,28 @@ func (t *tsmGeneration) level() int {
 	return 4
 }
 
+// minTime returns the earliest timestamp stored in the generation.
+func (t *tsmGeneration) minTime() int64 {
+	min := int64(math.MaxInt64)
+	for _, f := range t.files {
+		if f.MinTime < min {
+			min = f.MinTime
+		}
+	}
+	return min
+}
+
+// maxTime returns the latest timestamp stored in the generation.
+func (t *tsmGeneration) maxTime() int64 {
+	max := int64(math.MinInt64)
+	for _, f := range t.files {
+		if f.MaxTime > max {
+			max = f.MaxTime
+		}
+	}
+	return max
+}
+
 func (t *tsmGeneration) lastModified() int64 {
 	var max int64
 	for _, f := range t.files {
@@ -124,6 +161,17 @@ func (t *tsmGeneration) lastModified() int64 {
 	return max
 }
 
+// isBackfill returns true if the generation was written with the backfilled values
+// of a snapshot.
+func (t *tsmGeneration) isBackfill() bool {
+	return strings.HasSuffix(t.files[0].Path, "."+BackfillTSMFileExtension)
+}
+
+// overlapsTimeRange returns true if the time range of the generation intersects min and max.
+func (t *tsmGeneration) overlapsTimeRange(min, max int64) bool {
+	return t.minTime() <= max && t.maxTime() >= min
+}
+
 // count returns the number of files in the generation.
 func (t *tsmGeneration) count() int {
 	return len(t.files)
@@ -153,12 +201,21 @@ func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 	}
 
 	// Group each generation by level such that two adjacent generations in the same
-	// level become part of the same group.
+	// level become part of the same group.  Backfill generations are left to PlanBackfill
+	// and break up groups so that generations on either side are not compacted together.
 	var currentGen tsmGenerations
 	var groups []tsmGenerations
 	for i := 0; i < len(generations); i++ {
 		cur := generations[i]
 
+		if cur.isBackfill() {
+			if len(currentGen) > 0 {
+				groups = append(groups, currentGen)
+				currentGen = nil
+			}
+			continue
+		}
+
 		if len(currentGen) == 0 || currentGen[0].level() == cur.level() {
 			currentGen = append(currentGen, cur)
 			continue
@@ -283,6 +340,66 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	return cGroups
 }
 
+// PlanBackfill returns the backfill generations along with the level 4 generations
+// whose time range they overlap so that they can be merged in a single compaction.
+// Nothing is returned until enough backfill generations have accumulated, or if a
+// generation outside of the group overlaps it and sits between its generations.
+func (c *DefaultPlanner) PlanBackfill() []CompactionGroup {
+	generations := c.findGenerations()
+
+	var backfill tsmGenerations
+	minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, g := range generations {
+		if !g.isBackfill() {
+			continue
+		}
+		backfill = append(backfill, g)
+
+		if t := g.minTime(); t < minTime {
+			minTime = t
+		}
+		if t := g.maxTime(); t > maxTime {
+			maxTime = t
+		}
+	}
+
+	if len(backfill) < maxBackfillGenerations {
+		return nil
+	}
+
+	// Pull in every level 4 generation holding data for the backfilled time range so
+	// the overlapping blocks are only rewritten once.
+	var group tsmGenerations
+	for _, g := range generations {
+		if g.isBackfill() || g.level() == 4 && g.overlapsTimeRange(minTime, maxTime) {
+			group = append(group, g)
+		}
+	}
+
+	// The compacted files take the place of the newest generation in the group.  If a
+	// generation that is not part of the group falls in between and overlaps the same
+	// time range, merging past it could cause its points to be overwritten by older ones.
+	first, last := group[0].id, group[len(group)-1].id
+	for _, g := range generations {
+		if g.id <= first || g.id >= last || group.contains(g.id) {
+			continue
+		}
+		if g.overlapsTimeRange(minTime, maxTime) {
+			return nil
+		}
+	}
+
+	var cGroup CompactionGroup
+	for _, gen := range group {
+		for _, f := range gen.files {
+			cGroup = append(cGroup, f.Path)
+		}
+	}
+	sort.Strings(cGroup)
+
+	return []CompactionGroup{cGroup}
+}
+
 // Plan returns a set of TSM files to rewrite for level 4 or higher.  The planning returns
 // multiple groups if possible to allow compactions to run concurrently.
 func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
@@ -512,6 +629,14 @@ type Compactor struct {
 	Dir  string
 	Size int
 
+	// VerifyChecksums enables checksum verification of the blocks read
+	// from the TSM files being compacted.
+	VerifyChecksums bool
+
+	// Keys decrypts the TSM files being compacted and encrypts the new
+	// files with its active key, if set.
+	Keys *KeyRing
+
 	FileStore interface {
 		NextGeneration() int
 	}
@@ -600,8 +725,55 @@ func (c *Compactor) WriteSnapshot(cache *Cache) ([]string, error) {
 	return files, err
 }
 
+// WriteBackfillSnapshot writes a Cache snapshot to new TSM files, separating out values
+// older than horizon into their own generation so they can be merged into the existing
+// TSM files in a single backfill compaction.
+func (c *Compactor) WriteBackfillSnapshot(cache *Cache, horizon int64) ([]string, error) {
+	c.mu.RLock()
+	enabled := c.snapshotsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errSnapshotsDisabled
+	}
+
+	iter := newCacheKeyIteratorRange(cache, tsdb.DefaultMaxPointsPerBlock, horizon, math.MaxInt64)
+	files, err := c.writeNewFiles(c.FileStore.NextGeneration(), 0, iter)
+	if err != nil {
+		return nil, err
+	}
+
+	iter = newCacheKeyIteratorRange(cache, tsdb.DefaultMaxPointsPerBlock, math.MinInt64, horizon-1)
+	backfill, err := c.writeNewFilesExt(c.FileStore.NextGeneration(), 0, BackfillTSMFileExtension, iter)
+	if err != nil {
+		// Remove the files of the newer values so the snapshot can be written again.
+		removeFiles(files)
+		return nil, err
+	}
+	files = append(files, backfill...)
+
+	// See if we were disabled while writing a snapshot
+	c.mu.RLock()
+	enabled = c.snapshotsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		removeFiles(files)
+		return nil, errSnapshotsDisabled
+	}
+
+	return files, nil
+}
+
 // compact writes multiple smaller TSM files into 1 or more larger files.
 func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
+	return c.compactSequence(fast, 0, tsmFiles)
+}
+
+// compactSequence writes multiple smaller TSM files into 1 or more larger files.  The new
+// files are numbered starting after minSequence if it is larger than any of the sequence
+// numbers in tsmFiles.
+func (c *Compactor) compactSequence(fast bool, minSequence int, tsmFiles []string) ([]string, error) {
 	size := c.Size
 	if size <= 0 {
 		size = tsdb.DefaultMaxPointsPerBlock
@@ -626,6 +798,10 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 		}
 	}
 
+	if maxSequence < minSequence {
+		maxSequence = minSequence
+	}
+
 	// For each TSM file, create a TSM reader
 	var trs []*TSMReader
 	for _, file := range tsmFiles {
@@ -634,7 +810,7 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 			return nil, err
 		}
 
-		tr, err := NewTSMReader(f)
+		tr, err := newTSMReader(f, c.VerifyChecksums, c.Keys)
 		if err != nil {
 			return nil, err
 		}
@@ -713,16 +889,52 @@ func (c *Compactor) CompactFast(tsmFiles []string) ([]string, error) {
 
 }
 
+// CompactBackfill merges backfill TSM files with the TSM files they overlap.  The new
+// files are always written as level 4 files so they are not picked up again by the
+// level or backfill planners.
+func (c *Compactor) CompactBackfill(tsmFiles []string) ([]string, error) {
+	c.mu.RLock()
+	enabled := c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	if !c.add(tsmFiles) {
+		return nil, errCompactionInProgress
+	}
+	defer c.remove(tsmFiles)
+
+	files, err := c.compactSequence(false, 3, tsmFiles)
+
+	// See if we were disabled while writing a snapshot
+	c.mu.RLock()
+	enabled = c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	return files, err
+}
+
 // writeNewFiles writes from the iterator into new TSM files, rotating
 // to a new file once it has reached the max TSM file size.
 func (c *Compactor) writeNewFiles(generation, sequence int, iter KeyIterator) ([]string, error) {
+	return c.writeNewFilesExt(generation, sequence, TSMFileExtension, iter)
+}
+
+// writeNewFilesExt is like writeNewFiles but names the new files with the given extension.
+func (c *Compactor) writeNewFilesExt(generation, sequence int, ext string, iter KeyIterator) ([]string, error) {
 	// These are the new TSM files written
 	var files []string
 
 	for {
 		sequence++
 		// New TSM files are written to a temp file and renamed when fully completed.
-		fileName := filepath.Join(c.Dir, fmt.Sprintf("%09d-%09d.%s.tmp", generation, sequence, TSMFileExtension))
+		fileName := filepath.Join(c.Dir, fmt.Sprintf("%09d-%09d.%s.tmp", generation, sequence, ext))
 
 		// Write as much as possible to this file
 		err := c.write(fileName, iter)
@@ -753,6 +965,13 @@ func (c *Compactor) writeNewFiles(generation, sequence int, iter KeyIterator) ([
 	return files, nil
 }
 
+// removeFiles removes the new TSM files of a snapshot that could not be completed.
+func removeFiles(files []string) {
+	for _, f := range files {
+		os.RemoveAll(f)
+	}
+}
+
 func (c *Compactor) write(path string, iter KeyIterator) (err error) {
 	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
 	if err != nil {
@@ -760,7 +979,7 @@ func (c *Compactor) write(path string, iter KeyIterator) (err error) {
 	}
 
 	// Create the write for the new TSM file.
-	w, err := NewTSMWriter(fd)
+	w, err := NewTSMWriterWithKeys(fd, c.Keys)
 	if err != nil {
 		return err
 	}
@@ -1286,6 +1505,9 @@ type cacheKeyIterator struct {
 	size  int
 	order []string
 
+	// minTime, maxTime limit the values encoded for each key.
+	minTime, maxTime int64
+
 	i      int
 	blocks [][]cacheBlock
 	ready  []chan struct{}
@@ -1300,6 +1522,12 @@ type cacheBlock struct {
 
 // NewCacheKeyIterator returns a new KeyIterator from a Cache.
 func NewCacheKeyIterator(cache *Cache, size int) KeyIterator {
+	return newCacheKeyIteratorRange(cache, size, math.MinInt64, math.MaxInt64)
+}
+
+// newCacheKeyIteratorRange returns a new KeyIterator from a Cache that only returns
+// values between min and max inclusive.  The cache must be deduplicated.
+func newCacheKeyIteratorRange(cache *Cache, size int, min, max int64) KeyIterator {
 	keys := cache.Keys()
 
 	chans := make([]chan struct{}, len(keys))
@@ -1308,12 +1536,14 @@ func NewCacheKeyIterator(cache *Cache, size int) KeyIterator {
 	}
 
 	cki := &cacheKeyIterator{
-		i:      -1,
-		size:   size,
-		cache:  cache,
-		order:  keys,
-		ready:  chans,
-		blocks: make([][]cacheBlock, len(keys)),
+		i:       -1,
+		size:    size,
+		cache:   cache,
+		order:   keys,
+		minTime: min,
+		maxTime: max,
+		ready:   chans,
+		blocks:  make([][]cacheBlock, len(keys)),
 	}
 	go cki.encode()
 	return cki
@@ -1349,6 +1579,13 @@ func (c *cacheKeyIterator) encodeRange(start, stop int) {
 		key := c.order[i]
 		values := c.cache.values(key)
 
+		// Values are sorted so the range can be sliced out without copying.
+		if c.minTime != math.MinInt64 || c.maxTime != math.MaxInt64 {
+			lo := sort.Search(len(values), func(j int) bool { return values[j].UnixNano() >= c.minTime })
+			hi := sort.Search(len(values), func(j int) bool { return values[j].UnixNano() > c.maxTime })
+			values = values[lo:hi]
+		}
+
 		for len(values) > 0 {
 			minTime, maxTime := values[0].UnixNano(), values[len(values)-1].UnixNano()
 			var b []byte
@@ -1381,14 +1618,20 @@ func (c *cacheKeyIterator) Next() bool {
 			return true
 		}
 	}
-	c.i++
 
-	if c.i >= len(c.ready) {
-		return false
-	}
+	// Skip over any keys that have no values in range.
+	for {
+		c.i++
 
-	<-c.ready[c.i]
-	return true
+		if c.i >= len(c.ready) {
+			return false
+		}
+
+		<-c.ready[c.i]
+		if len(c.blocks[c.i]) > 0 {
+			return true
+		}
+	}
 }
 
 func (c *cacheKeyIterator) Read() (string, int64, int64, []byte, error) {
@@ -1414,6 +1657,15 @@ func (a tsmGenerations) hasTombstones() bool {
 	return false
 }
 
+func (a tsmGenerations) contains(id int) bool {
+	for _, g := range a {
+		if g.id == id {
+			return true
+		}
+	}
+	return false
+}
+
 func (a tsmGenerations) chunk(size int) []tsmGenerations {
 	var chunks []tsmGenerations
 	for len(a) > 0 {
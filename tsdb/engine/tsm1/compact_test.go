@@ -5,6 +5,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,6 +89,126 @@ func TestCompactor_Snapshot(t *testing.T) {
 	}
 }
 
+// Tests that a Cache snapshot writes values older than the horizon to their own generation
+func TestCompactor_WriteBackfillSnapshot(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	v1 := tsm1.NewValue(1, float64(1))
+	v2 := tsm1.NewValue(2, float64(2))
+	v3 := tsm1.NewValue(100, float64(3))
+	v4 := tsm1.NewValue(200, float64(4))
+
+	points1 := map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{v1, v3},
+		"cpu,host=B#!~#value": []tsm1.Value{v2},
+		"cpu,host=C#!~#value": []tsm1.Value{v4},
+	}
+
+	c := tsm1.NewCache(0, "")
+	for k, v := range points1 {
+		if err := c.Write(k, v); err != nil {
+			t.Fatalf("failed to write key foo to cache: %s", err.Error())
+		}
+	}
+
+	compactor := &tsm1.Compactor{
+		Dir:       dir,
+		FileStore: &fakeFileStore{},
+	}
+	compactor.Open()
+
+	files, err := compactor.WriteBackfillSnapshot(c, 100)
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	}
+
+	if got, exp := len(files), 2; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	if !strings.HasSuffix(files[1], "."+tsm1.BackfillTSMFileExtension+".tmp") {
+		t.Fatalf("backfill file name mismatch: got %v", files[1])
+	}
+
+	var data = []struct {
+		file string
+		keys []string
+		min  int64
+		max  int64
+	}{
+		{files[0], []string{"cpu,host=A#!~#value", "cpu,host=C#!~#value"}, 100, 200},
+		{files[1], []string{"cpu,host=A#!~#value", "cpu,host=B#!~#value"}, 1, 2},
+	}
+
+	for _, d := range data {
+		r := MustOpenTSMReader(d.file)
+
+		if got, exp := r.KeyCount(), len(d.keys); got != exp {
+			t.Fatalf("keys length mismatch: got %v, exp %v", got, exp)
+		}
+
+		for i, key := range d.keys {
+			if got, _ := r.Key(i); got != key {
+				t.Fatalf("key mismatch: got %v, exp %v", got, key)
+			}
+		}
+
+		if min, max := r.TimeRange(); min != d.min || max != d.max {
+			t.Fatalf("time range mismatch: got %v-%v, exp %v-%v", min, max, d.min, d.max)
+		}
+		r.Close()
+	}
+}
+
+// Ensures that a backfill compaction writes level 4 files
+func TestCompactor_CompactBackfill(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{tsm1.NewValue(1, 1.1), tsm1.NewValue(3, 1.3)},
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{tsm1.NewValue(2, 1.2)},
+	})
+
+	compactor := &tsm1.Compactor{
+		Dir:       dir,
+		FileStore: &fakeFileStore{},
+	}
+	compactor.Open()
+
+	files, err := compactor.CompactBackfill([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	}
+
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	gen, seq, err := tsm1.ParseTSMFileName(files[0])
+	if err != nil {
+		t.Fatalf("unexpected error parsing file name: %v", err)
+	}
+	if gen != 2 || seq != 4 {
+		t.Fatalf("file name mismatch: got %v-%v, exp %v-%v", gen, seq, 2, 4)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	values, err := r.ReadAll("cpu,host=A#!~#value")
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+
+	if got, exp := len(values), 3; got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
 // Ensures that a compaction will properly merge multiple TSM files
 func TestCompactor_CompactFull(t *testing.T) {
 	dir := MustTempDir()
@@ -1770,6 +1891,108 @@ func TestDefaultPlanner_Plan_FullOnCold(t *testing.T) {
 	}
 }
 
+// Ensure that backfill generations are left out of level compactions
+func TestDefaultPlanner_PlanLevel_SkipBackfill(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{Path: "01-01.tsm1", Size: 1 * 1024 * 1024, MinTime: 2500, MaxTime: 3500},
+		tsm1.FileStat{Path: "02-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 1, MaxTime: 10},
+		tsm1.FileStat{Path: "03-01.tsm1", Size: 1 * 1024 * 1024, MinTime: 1, MaxTime: 20},
+		tsm1.FileStat{Path: "04-01.tsm1", Size: 1 * 1024 * 1024, MinTime: 3000, MaxTime: 4000},
+	}
+
+	cp := &tsm1.DefaultPlanner{
+		FileStore: &fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		},
+	}
+
+	tsm := cp.PlanLevel(1)
+	if exp, got := 1, len(tsm); got != exp {
+		t.Fatalf("compaction group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	expFiles := []tsm1.FileStat{data[2], data[3]}
+	if exp, got := len(expFiles), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+}
+
+// Ensure that backfill generations are merged with the level 4 generations they overlap
+func TestDefaultPlanner_PlanBackfill(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{Path: "01-04.tsm1", Size: 256 * 1024 * 1024, MinTime: 0, MaxTime: 100},
+		tsm1.FileStat{Path: "02-04.tsm1", Size: 256 * 1024 * 1024, MinTime: 101, MaxTime: 200},
+		tsm1.FileStat{Path: "03-04.tsm1", Size: 256 * 1024 * 1024, MinTime: 1000, MaxTime: 2000},
+		tsm1.FileStat{Path: "04-01.tsm1", Size: 1 * 1024 * 1024, MinTime: 2000, MaxTime: 3000},
+		tsm1.FileStat{Path: "05-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 10, MaxTime: 20},
+		tsm1.FileStat{Path: "06-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 20, MaxTime: 30},
+		tsm1.FileStat{Path: "07-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 30, MaxTime: 40},
+		tsm1.FileStat{Path: "08-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 40, MaxTime: 50},
+	}
+
+	cp := &tsm1.DefaultPlanner{
+		FileStore: &fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		},
+	}
+
+	tsm := cp.PlanBackfill()
+	if exp, got := 1, len(tsm); got != exp {
+		t.Fatalf("compaction group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	expFiles := []tsm1.FileStat{data[0], data[4], data[5], data[6], data[7]}
+	if exp, got := len(expFiles), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+
+	// Fewer backfill generations should not be planned yet
+	data = data[:7]
+	if exp, got := 0, len(cp.PlanBackfill()); got != exp {
+		t.Fatalf("compaction group length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+// Ensure that backfill generations are not merged past a generation that overlaps them
+func TestDefaultPlanner_PlanBackfill_OverlappingGeneration(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{Path: "01-04.tsm1", Size: 256 * 1024 * 1024, MinTime: 0, MaxTime: 100},
+		tsm1.FileStat{Path: "02-01.tsm1", Size: 1 * 1024 * 1024, MinTime: 50, MaxTime: 3000},
+		tsm1.FileStat{Path: "03-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 10, MaxTime: 20},
+		tsm1.FileStat{Path: "04-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 20, MaxTime: 30},
+		tsm1.FileStat{Path: "05-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 30, MaxTime: 40},
+		tsm1.FileStat{Path: "06-01.backfill.tsm", Size: 1 * 1024 * 1024, MinTime: 40, MaxTime: 50},
+	}
+
+	cp := &tsm1.DefaultPlanner{
+		FileStore: &fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		},
+	}
+
+	if exp, got := 0, len(cp.PlanBackfill()); got != exp {
+		t.Fatalf("compaction group length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
 // Ensure that the planner will not return files that are over the max
 // allowable size
 func TestDefaultPlanner_Plan_SkipMaxSizeFiles(t *testing.T) {
@@ -2250,6 +2473,7 @@ type fakeFileStore struct {
 	PathsFn      func() []tsm1.FileStat
 	lastModified time.Time
 	blockCount   int
+	generation   int
 }
 
 func (w *fakeFileStore) Stats() []tsm1.FileStat {
@@ -2257,7 +2481,8 @@ func (w *fakeFileStore) Stats() []tsm1.FileStat {
 }
 
 func (w *fakeFileStore) NextGeneration() int {
-	return 1
+	w.generation++
+	return w.generation
 }
 
 func (w *fakeFileStore) LastModified() time.Time {
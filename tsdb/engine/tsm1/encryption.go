@@ -0,0 +1,242 @@
+package tsm1
+
+/*
+Encrypted TSM blocks, TSM indexes and WAL entries are sealed with AES-GCM using
+a key of a KeyRing.  Each sealed buffer starts with the ID of the key it was
+encrypted with, so that data written with older keys can still be read after a
+new key is added to the key file.
+
+┌───────────────────────────────────────────────┐
+│                  Sealed Data                  │
+├─────────┬──────────┬─────────────────┬────────┤
+│ Key ID  │  Nonce   │   Ciphertext    │  Tag   │
+│ 4 bytes │ 12 bytes │     N bytes     │16 bytes│
+└─────────┴──────────┴─────────────────┴────────┘
+
+The key ID is authenticated along with the ciphertext, as are the ID of the
+file holding the sealed data and the position of the data in the file, so that
+sealed data can't be moved within a file or to another file.  The ID of a file
+is the nonce of its first sealed data, which is itself sealed with an empty
+file ID.
+
+A key file lists one key per line as a numeric ID followed by the hex encoded
+16, 24 or 32 byte AES key.  Blank lines and lines starting with # are ignored.
+The last key of the file encrypts new data.
+*/
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// keyIDSize is the size in bytes of the key ID of sealed data.
+	keyIDSize = 4
+
+	// nonceSize is the size in bytes of the AES-GCM nonce of sealed data.
+	nonceSize = 12
+
+	// sealOverhead is the number of bytes added to data when it is sealed.
+	sealOverhead = keyIDSize + nonceSize + 16
+
+	// sealedFileMagic starts the small files of a shard, such as its field
+	// types, that are sealed as a whole.
+	sealedFileMagic uint32 = 0x16D15EA1
+)
+
+var (
+	// ErrNoEncryptionKeys is returned when reading encrypted data without
+	// encryption keys.
+	ErrNoEncryptionKeys = errors.New("data is encrypted but no encryption keys are configured")
+
+	// ErrEncryptionKeyNotFound is returned when reading data encrypted with a
+	// key missing from the key file.
+	ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+
+	// ErrDecryptionFailed is returned when encrypted data can't be
+	// authenticated, because it is corrupt or the key is wrong.
+	ErrDecryptionFailed = errors.New("decryption failed")
+)
+
+// KeyRing holds the keys used to encrypt TSM files and WAL segments.
+type KeyRing struct {
+	aeads    map[uint32]cipher.AEAD
+	activeID uint32
+}
+
+// NewKeyRing returns a new, empty KeyRing.
+func NewKeyRing() *KeyRing {
+	return &KeyRing{aeads: make(map[uint32]cipher.AEAD)}
+}
+
+// LoadKeyFile returns a KeyRing holding the keys of a key file.
+func LoadKeyFile(path string) (*KeyRing, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys, err := ParseKeyFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return keys, nil
+}
+
+// ParseKeyFile returns a KeyRing holding the keys read from r.
+func ParseKeyFile(r io.Reader) (*KeyRing, error) {
+	keys := NewKeyRing()
+
+	scanner := bufio.NewScanner(r)
+	for lineN := 1; scanner.Scan(); lineN++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected a key ID and a key", lineN)
+		}
+
+		id, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid key ID: %s", lineN, fields[0])
+		}
+
+		key, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: key is not hex encoded", lineN)
+		}
+
+		if err := keys.Add(uint32(id), key); err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineN, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys.aeads) == 0 {
+		return nil, errors.New("no encryption keys")
+	}
+	return keys, nil
+}
+
+// Add adds a 16, 24 or 32 byte AES key to the key ring and makes it the key
+// encrypting new data.
+func (k *KeyRing) Add(id uint32, key []byte) error {
+	if _, ok := k.aeads[id]; ok {
+		return fmt.Errorf("duplicate key ID: %d", id)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	k.aeads[id] = aead
+	k.activeID = id
+	return nil
+}
+
+// ActiveID returns the ID of the key encrypting new data.
+func (k *KeyRing) ActiveID() uint32 {
+	return k.activeID
+}
+
+// Seal appends the encryption of b with the active key to dst.  The additional
+// data ad is authenticated along with b and must be given to Open.
+func (k *KeyRing) Seal(dst, b, ad []byte) ([]byte, error) {
+	var header [keyIDSize + nonceSize]byte
+	binary.BigEndian.PutUint32(header[:keyIDSize], k.activeID)
+	if _, err := io.ReadFull(rand.Reader, header[keyIDSize:]); err != nil {
+		return nil, err
+	}
+
+	dst = append(dst, header[:]...)
+	return k.aeads[k.activeID].Seal(dst, header[keyIDSize:], b, additionalData(header[:keyIDSize], ad)), nil
+}
+
+// Open appends the decryption of the sealed data b to dst.  The additional
+// data ad must be that given to Seal.
+func (k *KeyRing) Open(dst, b, ad []byte) ([]byte, error) {
+	if k == nil {
+		return nil, ErrNoEncryptionKeys
+	} else if len(b) < sealOverhead {
+		return nil, ErrDecryptionFailed
+	}
+
+	aead, ok := k.aeads[binary.BigEndian.Uint32(b[:keyIDSize])]
+	if !ok {
+		return nil, ErrEncryptionKeyNotFound
+	}
+
+	dst, err := aead.Open(dst, b[keyIDSize:keyIDSize+nonceSize], b[keyIDSize+nonceSize:], additionalData(b[:keyIDSize], ad))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return dst, nil
+}
+
+// additionalData returns the data authenticated along with sealed data: the
+// ID of its key followed by ad.
+func additionalData(keyID, ad []byte) []byte {
+	return append(append(make([]byte, 0, len(keyID)+len(ad)), keyID...), ad...)
+}
+
+// positionData returns the additional data of data sealed at offset in the
+// file identified by fileID.
+func positionData(fileID []byte, offset int64) []byte {
+	b := make([]byte, len(fileID)+8)
+	copy(b, fileID)
+	binary.BigEndian.PutUint64(b[len(fileID):], uint64(offset))
+	return b
+}
+
+// sealedFileID returns the file ID given by the sealed data b, the first
+// sealed data of its file.
+func sealedFileID(b []byte) []byte {
+	if len(b) < sealOverhead {
+		return nil
+	}
+	return append([]byte(nil), b[keyIDSize:keyIDSize+nonceSize]...)
+}
+
+// sealFile returns the content of a small file sealed as a whole, identified
+// by its name.
+func sealFile(keys *KeyRing, name string, b []byte) ([]byte, error) {
+	var magic [4]byte
+	binary.BigEndian.PutUint32(magic[:], sealedFileMagic)
+	return keys.Seal(magic[:], b, []byte(name))
+}
+
+// openFile returns the content of a small file identified by its name,
+// decrypting it if it was sealed by sealFile.
+func openFile(keys *KeyRing, name string, b []byte) ([]byte, error) {
+	if len(b) < 4 || binary.BigEndian.Uint32(b[:4]) != sealedFileMagic {
+		return b, nil
+	}
+	return keys.Open(nil, b[4:], []byte(name))
+}
+
+// isEncryptionKeyError returns true if err is due to the encryption keys
+// rather than to the encrypted data.
+func isEncryptionKeyError(err error) bool {
+	return err == ErrNoEncryptionKeys || err == ErrEncryptionKeyNotFound || err == ErrDecryptionFailed
+}
@@ -0,0 +1,300 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/darshanman40/influxdb/tsdb/engine/tsm1"
+)
+
+// Ensure a key file can be parsed and its last key is the active key.
+func TestParseKeyFile(t *testing.T) {
+	keys, err := tsm1.ParseKeyFile(strings.NewReader(`
+# Rotated on 2017-01-01.
+1 000102030405060708090a0b0c0d0e0f
+2 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+`))
+	if err != nil {
+		t.Fatal(err)
+	} else if keys.ActiveID() != 2 {
+		t.Fatalf("unexpected active key: %d", keys.ActiveID())
+	}
+
+	for _, tt := range []struct {
+		s   string
+		err string
+	}{
+		{s: ``, err: `no encryption keys`},
+		{s: `1`, err: `line 1: expected a key ID and a key`},
+		{s: `x 000102030405060708090a0b0c0d0e0f`, err: `line 1: invalid key ID: x`},
+		{s: `1 xyz`, err: `line 1: key is not hex encoded`},
+		{s: `1 0001`, err: `line 1: crypto/aes: invalid key size 2`},
+		{s: "1 000102030405060708090a0b0c0d0e0f\n1 000102030405060708090a0b0c0d0e0f", err: `line 2: duplicate key ID: 1`},
+	} {
+		if _, err := tsm1.ParseKeyFile(strings.NewReader(tt.s)); err == nil || err.Error() != tt.err {
+			t.Errorf("%q: unexpected error: got %v, exp %s", tt.s, err, tt.err)
+		}
+	}
+}
+
+// Ensure TSM files written with a rotated key can be read with the new key
+// file and not without keys.
+func TestTSMWriter_Write_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keys := tsm1.NewKeyRing()
+	if err := keys.Add(1, bytes.Repeat([]byte{1}, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	f := MustTempFile(dir)
+	w, err := tsm1.NewTSMWriterWithKeys(f, keys)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	values := []tsm1.Value{tsm1.NewValue(0, "secret-value"), tsm1.NewValue(1, "other-value")}
+	if err := w.Write("cpu,host=secret-host#!~#value", values); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	} else if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	} else if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	// Neither the keys nor the values are stored in clear.
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	} else if b[4] != tsm1.EncryptedVersion {
+		t.Fatalf("unexpected version: %d", b[4])
+	} else if bytes.Contains(b, []byte("secret")) {
+		t.Fatal("file contains plaintext")
+	}
+
+	// A new key only encrypts new files.
+	if err := keys.Add(2, bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	fd, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReaderWithKeys(fd, keys)
+	if err != nil {
+		t.Fatalf("unexpected error creating reader: %v", err)
+	}
+	defer r.Close()
+
+	readValues, err := r.ReadAll("cpu,host=secret-host#!~#value")
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	} else if len(readValues) != len(values) {
+		t.Fatalf("read values length mismatch: got %v, exp %v", len(readValues), len(values))
+	}
+	for i, v := range values {
+		if v.Value() != readValues[i].Value() {
+			t.Fatalf("read value mismatch(%d): got %v, exp %v", i, readValues[i].Value(), v.Value())
+		}
+	}
+
+	// The checksums of decrypted blocks match their data.
+	itr := r.BlockIterator()
+	for itr.Next() {
+		if _, _, _, _, _, err := itr.Read(); err != nil {
+			t.Fatalf("unexpected error reading block: %v", err)
+		}
+	}
+
+	fd, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if _, err := tsm1.NewTSMReader(fd); err == nil || !strings.Contains(err.Error(), tsm1.ErrNoEncryptionKeys.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure WAL entries written with keys are encrypted, and that segments
+// aren't truncated when loaded without the keys.
+func TestWALSegmentWriter_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keys := tsm1.NewKeyRing()
+	if err := keys.Add(7, bytes.Repeat([]byte{7}, 16)); err != nil {
+		t.Fatal(err)
+	}
+
+	f := MustTempFile(dir)
+	w := tsm1.NewWALSegmentWriterWithKeys(f, keys)
+
+	entry := &tsm1.WriteWALEntry{
+		Values: map[string][]tsm1.Value{
+			"cpu,host=secret-host#!~#value": []tsm1.Value{tsm1.NewValue(1, "secret-value")},
+		},
+	}
+	if err := w.Write(mustMarshalEntry(entry)); err != nil {
+		t.Fatalf("unexpected error writing entry: %v", err)
+	} else if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := ioutil.ReadFile(f.Name()); err != nil {
+		t.Fatal(err)
+	} else if bytes.Contains(b, []byte("secret")) {
+		t.Fatal("segment contains plaintext")
+	}
+
+	fd, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := tsm1.NewWALSegmentReaderWithKeys(fd, keys)
+	if !r.Next() {
+		t.Fatal("expected next, got false")
+	}
+	we, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error reading entry: %v", err)
+	}
+	e, ok := we.(*tsm1.WriteWALEntry)
+	if !ok {
+		t.Fatalf("expected WriteWALEntry: got %#v", we)
+	} else if v := e.Values["cpu,host=secret-host#!~#value"]; len(v) != 1 || v[0].Value() != "secret-value" {
+		t.Fatalf("unexpected values: %v", v)
+	}
+	r.Close()
+
+	// Loading the segment without the keys fails and leaves it intact.
+	stat, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := tsm1.NewCache(1024, "")
+	loader := tsm1.NewCacheLoader([]string{f.Name()})
+	if err := loader.Load(cache); err == nil || !strings.Contains(err.Error(), tsm1.ErrNoEncryptionKeys.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if other, err := os.Stat(f.Name()); err != nil {
+		t.Fatal(err)
+	} else if other.Size() != stat.Size() {
+		t.Fatalf("segment truncated: got %d bytes, exp %d", other.Size(), stat.Size())
+	}
+
+	loader = tsm1.NewCacheLoader([]string{f.Name()})
+	loader.Keys = keys
+	if err := loader.Load(cache); err != nil {
+		t.Fatal(err)
+	} else if v := cache.Values("cpu,host=secret-host#!~#value"); len(v) != 1 {
+		t.Fatalf("unexpected values: %v", v)
+	}
+}
+
+// Ensure WAL entries moved within a segment fail to decrypt, and that the
+// segment is truncated before them when loaded.
+func TestWALSegmentWriter_Encrypted_Moved(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keys := tsm1.NewKeyRing()
+	if err := keys.Add(7, bytes.Repeat([]byte{7}, 16)); err != nil {
+		t.Fatal(err)
+	}
+
+	f := MustTempFile(dir)
+	w := tsm1.NewWALSegmentWriterWithKeys(f, keys)
+	for _, host := range []string{"a", "b", "c"} {
+		entry := &tsm1.WriteWALEntry{
+			Values: map[string][]tsm1.Value{
+				"cpu,host=" + host + "#!~#value": []tsm1.Value{tsm1.NewValue(1, 1.0)},
+			},
+		}
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			t.Fatalf("unexpected error writing entry: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the last two entries.
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries [][]byte
+	for len(b) > 0 {
+		n := 5 + int(binary.BigEndian.Uint32(b[1:5]))
+		entries, b = append(entries, b[:n]), b[n:]
+	}
+	if len(entries) != 3 {
+		t.Fatalf("unexpected entries: %d", len(entries))
+	}
+	moved := bytes.Join([][]byte{entries[0], entries[2], entries[1]}, nil)
+	if err := ioutil.WriteFile(f.Name(), moved, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := tsm1.NewCache(1024, "")
+	loader := tsm1.NewCacheLoader([]string{f.Name()})
+	loader.Keys = keys
+	if err := loader.Load(cache); err != nil {
+		t.Fatal(err)
+	} else if keys := cache.Keys(); len(keys) != 1 || keys[0] != "cpu,host=a#!~#value" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+
+	if stat, err := os.Stat(f.Name()); err != nil {
+		t.Fatal(err)
+	} else if stat.Size() != int64(len(entries[0])) {
+		t.Fatalf("unexpected segment size: %d", stat.Size())
+	}
+}
+
+// Ensure tombstones written with keys are encrypted and bound to their TSM
+// file.
+func TestTombstoner_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keys := tsm1.NewKeyRing()
+	if err := keys.Add(7, bytes.Repeat([]byte{7}, 16)); err != nil {
+		t.Fatal(err)
+	}
+
+	f := MustTempFile(dir)
+	ts := &tsm1.Tombstoner{Path: f.Name(), Keys: keys, FileID: []byte("file-a")}
+	if err := ts.Add([]string{"cpu,host=secret-host"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files := ts.TombstoneFiles()
+	if len(files) != 1 {
+		t.Fatalf("unexpected tombstone files: %v", files)
+	} else if b, err := ioutil.ReadFile(files[0].Path); err != nil {
+		t.Fatal(err)
+	} else if bytes.Contains(b, []byte("secret")) {
+		t.Fatal("tombstone contains plaintext")
+	}
+
+	if entries, err := ts.ReadAll(); err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 || entries[0].Key != "cpu,host=secret-host" {
+		t.Fatalf("unexpected tombstones: %v", entries)
+	}
+
+	other := &tsm1.Tombstoner{Path: f.Name(), Keys: keys, FileID: []byte("file-b")}
+	if _, err := other.ReadAll(); err != tsm1.ErrDecryptionFailed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
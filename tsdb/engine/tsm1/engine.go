@@ -4,6 +4,7 @@ package tsm1 // import "github.com/darshanman40/influxdb/tsdb/engine/tsm1"
 import (
 	"archive/tar"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -34,10 +35,16 @@ func init() {
 // Ensure Engine implements the interface.
 var _ tsdb.Engine = &Engine{}
 
+// KeyRing also seals the manifests of the shards held by an object store.
+var _ tsdb.Sealer = &KeyRing{}
+
 const (
 	// keyFieldSeparator separates the series key from the field name in the composite key
 	// that identifies a specific field in series
 	keyFieldSeparator = "#!~#"
+
+	// fieldTypesFile is the name of the file holding the types set by AlterField.
+	fieldTypesFile = "fields.types"
 )
 
 // Statistics gathered by the engine.
@@ -71,6 +78,11 @@ const (
 	statTSMFullCompactionsActive  = "tsmFullCompactionsActive"
 	statTSMFullCompactionError    = "tsmFullCompactionErr"
 	statTSMFullCompactionDuration = "tsmFullCompactionDuration"
+
+	statTSMBackfillCompactions        = "tsmBackfillCompactions"
+	statTSMBackfillCompactionsActive  = "tsmBackfillCompactionsActive"
+	statTSMBackfillCompactionError    = "tsmBackfillCompactionErr"
+	statTSMBackfillCompactionDuration = "tsmBackfillCompactionDuration"
 )
 
 // Engine represents a storage engine with compressed blocks.
@@ -104,6 +116,11 @@ type Engine struct {
 	fieldsMu          sync.RWMutex
 	measurementFields map[string]*tsdb.MeasurementFields
 
+	// Types set by AlterField, by measurement and field.  They are saved to the
+	// fieldTypesFile since the altered fields have no values to derive them from
+	// until new ones are written.
+	fieldTypes map[string]map[string]influxql.DataType
+
 	WAL            *WAL
 	Cache          *Cache
 	Compactor      *Compactor
@@ -121,21 +138,35 @@ type Engine struct {
 	// a snapshot of the cache to a TSM file
 	CacheFlushWriteColdDuration time.Duration
 
+	// BackfillThreshold specifies how far behind the newest data in the shard
+	// a value must be for it to be written to a separate backfill TSM file
+	// when the cache is snapshotted.  A value of 0 disables backfill files.
+	BackfillThreshold time.Duration
+
 	// Controls whether to enabled compactions when the engine is open
 	enableCompactionsOnOpen bool
 
+	// encryptionKeyFile is the key file of the keys encrypting the TSM files
+	// and the WAL, loaded when the engine is opened.  The keys also encrypt
+	// the fieldTypesFile.
+	encryptionKeyFile string
+	keys              *KeyRing
+
 	stats *EngineStatistics
 }
 
 // NewEngine returns a new instance of Engine.
 func NewEngine(id uint64, path string, walPath string, opt tsdb.EngineOptions) tsdb.Engine {
 	w := NewWAL(walPath)
+	w.SyncDelay = time.Duration(opt.Config.WALFsyncDelay)
+	w.Async = opt.Config.WALDurability == tsdb.WALDurabilityAsync
 	fs := NewFileStore(path)
 	cache := NewCache(uint64(opt.Config.CacheMaxMemorySize), path)
 
 	c := &Compactor{
-		Dir:       path,
-		FileStore: fs,
+		Dir:             path,
+		FileStore:       fs,
+		VerifyChecksums: opt.Config.VerifyBlockChecksums,
 	}
 
 	logger := *zap.NewNop()
@@ -147,6 +178,7 @@ func NewEngine(id uint64, path string, walPath string, opt tsdb.EngineOptions) t
 		traceLogging: opt.Config.TraceLoggingEnabled,
 
 		measurementFields: make(map[string]*tsdb.MeasurementFields),
+		fieldTypes:        make(map[string]map[string]influxql.DataType),
 
 		WAL:   w,
 		Cache: cache,
@@ -160,7 +192,9 @@ func NewEngine(id uint64, path string, walPath string, opt tsdb.EngineOptions) t
 
 		CacheFlushMemorySizeThreshold: opt.Config.CacheSnapshotMemorySize,
 		CacheFlushWriteColdDuration:   time.Duration(opt.Config.CacheSnapshotWriteColdDuration),
+		BackfillThreshold:             time.Duration(opt.Config.BackfillThreshold),
 		enableCompactionsOnOpen:       true,
+		encryptionKeyFile:             opt.Config.EncryptionKeyFile,
 		stats: &EngineStatistics{},
 	}
 
@@ -169,6 +203,8 @@ func NewEngine(id uint64, path string, walPath string, opt tsdb.EngineOptions) t
 		w.enableTraceLogging(true)
 	}
 
+	fs.EnableChecksumVerification(opt.Config.VerifyBlockChecksums)
+
 	return e
 }
 
@@ -334,6 +370,11 @@ type EngineStatistics struct {
 	TSMFullCompactionsActive  int64 // Gauge of full compactions currently running.
 	TSMFullCompactionErrors   int64 // Counter of full compactions that have failed due to error.
 	TSMFullCompactionDuration int64 // Counter of number of wall nanoseconds spent in full compactions.
+
+	TSMBackfillCompactions        int64 // Counter of backfill compactions that have ever run.
+	TSMBackfillCompactionsActive  int64 // Gauge of backfill compactions currently running.
+	TSMBackfillCompactionErrors   int64 // Counter of backfill compactions that have failed due to error.
+	TSMBackfillCompactionDuration int64 // Counter of number of wall nanoseconds spent in backfill compactions.
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -372,6 +413,11 @@ func (e *Engine) Statistics(tags map[string]string) []models.Statistic {
 			statTSMFullCompactionsActive:  atomic.LoadInt64(&e.stats.TSMFullCompactionsActive),
 			statTSMFullCompactionError:    atomic.LoadInt64(&e.stats.TSMFullCompactionErrors),
 			statTSMFullCompactionDuration: atomic.LoadInt64(&e.stats.TSMFullCompactionDuration),
+
+			statTSMBackfillCompactions:        atomic.LoadInt64(&e.stats.TSMBackfillCompactions),
+			statTSMBackfillCompactionsActive:  atomic.LoadInt64(&e.stats.TSMBackfillCompactionsActive),
+			statTSMBackfillCompactionError:    atomic.LoadInt64(&e.stats.TSMBackfillCompactionErrors),
+			statTSMBackfillCompactionDuration: atomic.LoadInt64(&e.stats.TSMBackfillCompactionDuration),
 		},
 	})
 	statistics = append(statistics, e.Cache.Statistics(tags)...)
@@ -390,6 +436,17 @@ func (e *Engine) Open() error {
 		return err
 	}
 
+	if e.encryptionKeyFile != "" {
+		keys, err := LoadKeyFile(e.encryptionKeyFile)
+		if err != nil {
+			return err
+		}
+		e.keys = keys
+		e.WAL.Keys = keys
+		e.FileStore.SetKeyRing(keys)
+		e.Compactor.Keys = keys
+	}
+
 	if err := e.WAL.Open(); err != nil {
 		return err
 	}
@@ -472,6 +529,24 @@ func (e *Engine) LoadMetadataIndex(shardID uint64, index *tsdb.DatabaseIndex) er
 		return err
 	}
 
+	// Add the altered fields that no value has been written to since.  Fields with
+	// values keep the type of their values.
+	if err := e.loadFieldTypes(); err != nil {
+		return err
+	}
+	for measurement, fields := range e.fieldTypes {
+		mf := e.MeasurementFields(measurement)
+		for field, typ := range fields {
+			if mf.Field(field) != nil {
+				continue
+			}
+			if err := mf.CreateFieldIfNotExists(field, typ, false); err != nil {
+				return err
+			}
+			index.CreateMeasurementIndexIfNotExists(measurement).SetFieldName(field)
+		}
+	}
+
 	e.traceLogger.Info(fmt.Sprintf("Meta data index for shard %d loaded in %v", shardID, time.Since(now)))
 	return nil
 }
@@ -818,11 +893,162 @@ func (e *Engine) DeleteSeriesRange(seriesKeys []string, min, max int64) error {
 func (e *Engine) DeleteMeasurement(name string, seriesKeys []string) error {
 	e.fieldsMu.Lock()
 	delete(e.measurementFields, name)
+	_, altered := e.fieldTypes[name]
+	delete(e.fieldTypes, name)
+	if altered {
+		if err := e.saveFieldTypes(); err != nil {
+			e.fieldsMu.Unlock()
+			return err
+		}
+	}
 	e.fieldsMu.Unlock()
 
 	return e.DeleteSeries(seriesKeys)
 }
 
+// DeleteField removes all values for a field of a measurement across the given series
+// and drops the field from the measurement's field set.
+func (e *Engine) DeleteField(measurement, field string, seriesKeys []string) error {
+	// Level compactions are disabled for the same reason as in DeleteSeriesRange.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	keyMap := make(map[string]struct{}, len(seriesKeys))
+	for _, k := range seriesKeys {
+		keyMap[SeriesFieldKey(k, field)] = struct{}{}
+	}
+
+	deleteKeys := make([]string, 0, len(seriesKeys))
+	if err := e.FileStore.WalkKeys(func(k []byte, _ byte) error {
+		key := string(k)
+		if _, ok := keyMap[key]; ok {
+			deleteKeys = append(deleteKeys, key)
+			// WalkKeys can return the same key more than once.
+			delete(keyMap, key)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Strings(deleteKeys)
+
+	if err := e.FileStore.Delete(deleteKeys); err != nil {
+		return err
+	}
+
+	walKeys := make([]string, 0, len(seriesKeys))
+	for _, k := range seriesKeys {
+		walKeys = append(walKeys, SeriesFieldKey(k, field))
+	}
+	sort.Strings(walKeys)
+
+	e.Cache.Delete(walKeys)
+	if _, err := e.WAL.Delete(walKeys); err != nil {
+		return err
+	}
+
+	e.fieldsMu.Lock()
+	defer e.fieldsMu.Unlock()
+	if m := e.measurementFields[measurement]; m != nil {
+		m.DeleteField(field)
+	}
+
+	if _, ok := e.fieldTypes[measurement][field]; ok {
+		delete(e.fieldTypes[measurement], field)
+		if len(e.fieldTypes[measurement]) == 0 {
+			delete(e.fieldTypes, measurement)
+		}
+		return e.saveFieldTypes()
+	}
+	return nil
+}
+
+// AlterField removes all values for a field of a measurement across the given series
+// and recreates the field with a new type.  The type is saved so the field keeps it
+// when the shard is reopened before any value of the new type is written.
+func (e *Engine) AlterField(measurement, field string, typ influxql.DataType, seriesKeys []string) error {
+	if err := e.DeleteField(measurement, field, seriesKeys); err != nil {
+		return err
+	}
+
+	m := e.MeasurementFields(measurement)
+
+	e.fieldsMu.Lock()
+	defer e.fieldsMu.Unlock()
+	if err := m.CreateFieldIfNotExists(field, typ, false); err != nil {
+		return err
+	}
+
+	if e.fieldTypes[measurement] == nil {
+		e.fieldTypes[measurement] = make(map[string]influxql.DataType)
+	}
+	e.fieldTypes[measurement][field] = typ
+	return e.saveFieldTypes()
+}
+
+// saveFieldTypes writes the types set by AlterField to the fieldTypesFile, replacing
+// it atomically.  The caller must hold fieldsMu.
+func (e *Engine) saveFieldTypes() error {
+	path := filepath.Join(e.path, fieldTypesFile)
+	if len(e.fieldTypes) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	buf, err := json.Marshal(e.fieldTypes)
+	if err != nil {
+		return err
+	}
+	if e.keys != nil {
+		if buf, err = sealFile(e.keys, fieldTypesFile, buf); err != nil {
+			return err
+		}
+	}
+
+	tmpPath := path + "." + CompactionTempExtension
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return renameFile(tmpPath, path)
+}
+
+// loadFieldTypes reads the types set by AlterField from the fieldTypesFile.
+func (e *Engine) loadFieldTypes() error {
+	buf, err := ioutil.ReadFile(filepath.Join(e.path, fieldTypesFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if buf, err = openFile(e.keys, fieldTypesFile, buf); err != nil {
+		return fmt.Errorf("error reading %s: %s", fieldTypesFile, err)
+	}
+
+	fieldTypes := make(map[string]map[string]influxql.DataType)
+	if err := json.Unmarshal(buf, &fieldTypes); err != nil {
+		return fmt.Errorf("error reading %s: %s", fieldTypesFile, err)
+	}
+
+	e.fieldsMu.Lock()
+	e.fieldTypes = fieldTypes
+	e.fieldsMu.Unlock()
+	return nil
+}
+
 // SeriesCount returns the number of series buckets on the shard.
 func (e *Engine) SeriesCount() (n int, err error) {
 	return e.index.SeriesN(), nil
@@ -915,8 +1141,13 @@ func (e *Engine) writeSnapshotAndCommit(closedFiles []string, snapshot *Cache) (
 			e.Cache.ClearSnapshot(false)
 		}
 	}()
-	// write the new snapshot files
-	newFiles, err := e.Compactor.WriteSnapshot(snapshot)
+	// write the new snapshot files, splitting out any backfilled values
+	var newFiles []string
+	if horizon := e.backfillHorizon(snapshot); horizon != math.MinInt64 {
+		newFiles, err = e.Compactor.WriteBackfillSnapshot(snapshot, horizon)
+	} else {
+		newFiles, err = e.Compactor.WriteSnapshot(snapshot)
+	}
 	if err != nil {
 		e.logger.Info(fmt.Sprintf("error writing snapshot from compactor: %v", err))
 		return err
@@ -941,6 +1172,40 @@ func (e *Engine) writeSnapshotAndCommit(closedFiles []string, snapshot *Cache) (
 	return nil
 }
 
+// backfillHorizon returns the timestamp before which values in the snapshot are
+// written to backfill TSM files.  It returns math.MinInt64 if backfill files are
+// disabled or the shard does not have any TSM files yet.
+func (e *Engine) backfillHorizon(snapshot *Cache) int64 {
+	if e.BackfillThreshold <= 0 {
+		return math.MinInt64
+	}
+
+	stats := e.FileStore.Stats()
+	if len(stats) == 0 {
+		return math.MinInt64
+	}
+
+	max := int64(math.MinInt64)
+	for _, f := range stats {
+		if f.MaxTime > max {
+			max = f.MaxTime
+		}
+	}
+
+	// The snapshot may hold data newer than anything on disk.  The snapshot is
+	// deduplicated so the last value of each entry is its latest.
+	_ = snapshot.ApplyEntryFn(func(_ string, entry *entry) error {
+		entry.mu.RLock()
+		if n := len(entry.values); n > 0 && entry.values[n-1].UnixNano() > max {
+			max = entry.values[n-1].UnixNano()
+		}
+		entry.mu.RUnlock()
+		return nil
+	})
+
+	return max - int64(e.BackfillThreshold)
+}
+
 // compactCache continually checks if the WAL cache should be written to disk.
 func (e *Engine) compactCache(quit <-chan struct{}) {
 	t := time.NewTicker(time.Second)
@@ -1023,6 +1288,7 @@ type compactionStrategy struct {
 	compactionGroups []CompactionGroup
 
 	fast        bool
+	backfill    bool
 	description string
 
 	durationStat *int64
@@ -1066,7 +1332,9 @@ func (s *compactionStrategy) compactGroup(groupNum int) {
 		atomic.AddInt64(s.activeStat, 1)
 		defer atomic.AddInt64(s.activeStat, -1)
 
-		if s.fast {
+		if s.backfill {
+			return s.compactor.CompactBackfill(group)
+		} else if s.fast {
 			return s.compactor.CompactFast(group)
 		} else {
 			return s.compactor.CompactFull(group)
@@ -1085,6 +1353,9 @@ func (s *compactionStrategy) compactGroup(groupNum int) {
 
 		s.logger.Info(fmt.Sprintf("error compacting TSM files: %v", err))
 		atomic.AddInt64(s.errorStat, 1)
+
+		// A corrupt file is quarantined so that it is left out of the next plan.
+		s.fileStore.checkBlockError(err)
 		time.Sleep(time.Second)
 		return
 	}
@@ -1130,6 +1401,10 @@ func (e *Engine) levelCompactionStrategy(fast bool, level int) *compactionStrate
 // fullCompactionStrategy returns a compactionStrategy for higher level generations of TSM files.
 // It returns nil if there are no TSM files to compact.
 func (e *Engine) fullCompactionStrategy() *compactionStrategy {
+	if s := e.backfillCompactionStrategy(); s != nil {
+		return s
+	}
+
 	optimize := false
 	compactionGroups := e.CompactionPlan.Plan(e.WAL.LastWriteTime())
 
@@ -1167,6 +1442,30 @@ func (e *Engine) fullCompactionStrategy() *compactionStrategy {
 	return s
 }
 
+// backfillCompactionStrategy returns a compactionStrategy for merging backfill TSM files.
+// It returns nil if there are no backfill TSM files to compact.
+func (e *Engine) backfillCompactionStrategy() *compactionStrategy {
+	compactionGroups := e.CompactionPlan.PlanBackfill()
+
+	if len(compactionGroups) == 0 {
+		return nil
+	}
+
+	return &compactionStrategy{
+		compactionGroups: compactionGroups,
+		logger:           e.logger,
+		fileStore:        e.FileStore,
+		compactor:        e.Compactor,
+		backfill:         true,
+
+		description:  "backfill",
+		activeStat:   &e.stats.TSMBackfillCompactionsActive,
+		successStat:  &e.stats.TSMBackfillCompactions,
+		errorStat:    &e.stats.TSMBackfillCompactionErrors,
+		durationStat: &e.stats.TSMBackfillCompactionDuration,
+	}
+}
+
 // reloadCache reads the WAL segment files and loads them into the cache.
 func (e *Engine) reloadCache() error {
 	now := time.Now()
@@ -1184,6 +1483,7 @@ func (e *Engine) reloadCache() error {
 	e.Cache.SetMaxSize(0)
 
 	loader := NewCacheLoader(files)
+	loader.Keys = e.WAL.Keys
 	loader.WithLogger(e.logger)
 	if err := loader.Load(e.Cache); err != nil {
 		return err
@@ -214,6 +214,123 @@ func TestEngine_Backup(t *testing.T) {
 	}
 }
 
+// Ensure an engine with a key file encrypts its files, reloads its WAL and
+// restores its encrypted backups only with the keys.
+func TestEngine_Backup_Encrypted(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tsm")
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "keys")
+	if err := ioutil.WriteFile(keyFile, []byte("1 000102030405060708090a0b0c0d0e0f\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	openEngine := func(name, keyFile string) (*tsm1.Engine, error) {
+		opt := tsdb.NewEngineOptions()
+		opt.Config.EncryptionKeyFile = keyFile
+		e := tsm1.NewEngine(1, filepath.Join(dir, name), filepath.Join(dir, name+"_wal"), opt).(*tsm1.Engine)
+		e.CompactionPlan = &mockPlanner{}
+		return e, e.Open()
+	}
+
+	e, err := openEngine("src", keyFile)
+	if err != nil {
+		t.Fatalf("failed to open tsm1 engine: %s", err)
+	}
+	if err := e.WritePoints([]models.Point{MustParsePointString("cpu,host=A value=1.1 1000000000")}); err != nil {
+		t.Fatalf("failed to write points: %s", err)
+	} else if err := e.WriteSnapshot(); err != nil {
+		t.Fatalf("failed to snapshot: %s", err)
+	} else if err := e.WritePoints([]models.Point{MustParsePointString("cpu,host=B value=1.2 2000000000")}); err != nil {
+		t.Fatalf("failed to write points: %s", err)
+	} else if err := e.DeleteSeriesRange([]string{"cpu,host=A"}, 0, 500); err != nil {
+		t.Fatalf("failed to delete series: %s", err)
+	} else if err := e.AlterField("cpu", "altered", influxql.Integer, nil); err != nil {
+		t.Fatalf("failed to alter field: %s", err)
+	} else if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The series keys and fields aren't stored in clear in the TSM files,
+	// their tombstones, the field types or the WAL.
+	var tombstones int
+	if err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || path == keyFile {
+			return err
+		}
+		if strings.HasSuffix(path, ".tombstone") {
+			tombstones++
+		}
+		if b, err := ioutil.ReadFile(path); err != nil {
+			return err
+		} else if bytes.Contains(b, []byte("host=")) || bytes.Contains(b, []byte("altered")) {
+			t.Errorf("%s contains plaintext", path)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if tombstones == 0 {
+		t.Fatal("expected tombstones")
+	} else if _, err := os.Stat(filepath.Join(dir, "src", "fields.types")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The WAL is reloaded into the cache.
+	if e, err = openEngine("src", keyFile); err != nil {
+		t.Fatalf("failed to reopen tsm1 engine: %s", err)
+	}
+	defer e.Close()
+	if v := e.Cache.Values("cpu,host=B#!~#value"); len(v) != 1 {
+		t.Fatalf("unexpected cached values: %v", v)
+	}
+
+	// The tombstones and the field types are read back.
+	if v, err := e.FileStore.Read("cpu,host=A#!~#value", 1000000000); err != nil {
+		t.Fatal(err)
+	} else if len(v) != 1 {
+		t.Fatalf("unexpected values: %v", v)
+	}
+	if err := e.LoadMetadataIndex(1, tsdb.NewDatabaseIndex("db0")); err != nil {
+		t.Fatal(err)
+	} else if f := e.MeasurementFields("cpu").Field("altered"); f == nil || f.Type != influxql.Integer {
+		t.Fatalf("unexpected field: %v", f)
+	}
+
+	var b bytes.Buffer
+	if err := e.Backup(&b, "", time.Unix(0, 0)); err != nil {
+		t.Fatalf("failed to backup: %s", err)
+	}
+	backup := b.Bytes()
+
+	restore := func(name, keyFile string) (*tsm1.Engine, error) {
+		e, err := openEngine(name, keyFile)
+		if err != nil {
+			return nil, err
+		} else if err := e.Restore(bytes.NewReader(backup), ""); err != nil {
+			return nil, err
+		} else if err := e.Close(); err != nil {
+			return nil, err
+		}
+		return openEngine(name, keyFile)
+	}
+
+	other, err := restore("dst", keyFile)
+	if err != nil {
+		t.Fatalf("failed to restore: %s", err)
+	}
+	defer other.Close()
+	if v, err := other.FileStore.Read("cpu,host=A#!~#value", 1000000000); err != nil {
+		t.Fatal(err)
+	} else if len(v) != 1 || v[0].Value() != 1.1 {
+		t.Fatalf("unexpected values: %v", v)
+	}
+
+	if _, err := restore("nokeys", ""); err == nil || !strings.Contains(err.Error(), tsm1.ErrNoEncryptionKeys.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure engine can create an ascending iterator for cached values.
 func TestEngine_CreateIterator_Cache_Ascending(t *testing.T) {
 	t.Parallel()
@@ -1015,6 +1132,7 @@ type mockPlanner struct{}
 func (m *mockPlanner) Plan(lastWrite time.Time) []tsm1.CompactionGroup { return nil }
 func (m *mockPlanner) PlanLevel(level int) []tsm1.CompactionGroup      { return nil }
 func (m *mockPlanner) PlanOptimize() []tsm1.CompactionGroup            { return nil }
+func (m *mockPlanner) PlanBackfill() []tsm1.CompactionGroup            { return nil }
 
 // ParseTags returns an instance of Tags for a comma-delimited list of key/values.
 func ParseTags(s string) influxql.Tags {
@@ -18,7 +18,7 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 	*buf = (*buf)[:0]
 	values, err := first.r.ReadFloatBlockAt(&first.entry, buf)
 	if err != nil {
-		return nil, err
+		return nil, c.fs.checkBlockError(err)
 	}
 
 	// Remove values we already read
@@ -86,7 +86,7 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 			var a []FloatValue
 			v, err := cur.r.ReadFloatBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterFloatValues(tombstones, v)
@@ -145,7 +145,7 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 			var a []FloatValue
 			v, err := cur.r.ReadFloatBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterFloatValues(tombstones, v)
@@ -181,7 +181,7 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 	*buf = (*buf)[:0]
 	values, err := first.r.ReadIntegerBlockAt(&first.entry, buf)
 	if err != nil {
-		return nil, err
+		return nil, c.fs.checkBlockError(err)
 	}
 
 	// Remove values we already read
@@ -249,7 +249,7 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 			var a []IntegerValue
 			v, err := cur.r.ReadIntegerBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterIntegerValues(tombstones, v)
@@ -308,7 +308,7 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 			var a []IntegerValue
 			v, err := cur.r.ReadIntegerBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterIntegerValues(tombstones, v)
@@ -344,7 +344,7 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 	*buf = (*buf)[:0]
 	values, err := first.r.ReadStringBlockAt(&first.entry, buf)
 	if err != nil {
-		return nil, err
+		return nil, c.fs.checkBlockError(err)
 	}
 
 	// Remove values we already read
@@ -412,7 +412,7 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 			var a []StringValue
 			v, err := cur.r.ReadStringBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterStringValues(tombstones, v)
@@ -471,7 +471,7 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 			var a []StringValue
 			v, err := cur.r.ReadStringBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterStringValues(tombstones, v)
@@ -507,7 +507,7 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 	*buf = (*buf)[:0]
 	values, err := first.r.ReadBooleanBlockAt(&first.entry, buf)
 	if err != nil {
-		return nil, err
+		return nil, c.fs.checkBlockError(err)
 	}
 
 	// Remove values we already read
@@ -575,7 +575,7 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 			var a []BooleanValue
 			v, err := cur.r.ReadBooleanBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterBooleanValues(tombstones, v)
@@ -634,7 +634,7 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 			var a []BooleanValue
 			v, err := cur.r.ReadBooleanBlockAt(&cur.entry, &a)
 			if err != nil {
-				return nil, err
+				return nil, c.fs.checkBlockError(err)
 			}
 			// Remove any tombstoned values
 			v = c.filterBooleanValues(tombstones, v)
@@ -118,8 +118,10 @@ type dereferencer interface {
 
 // Statistics gathered by the FileStore.
 const (
-	statFileStoreBytes = "diskBytes"
-	statFileStoreCount = "numFiles"
+	statFileStoreBytes            = "diskBytes"
+	statFileStoreCount            = "numFiles"
+	statFileStoreChecksumErrors   = "checksumErrors"
+	statFileStoreQuarantinedFiles = "numQuarantinedFiles"
 )
 
 // FileStore is an abstraction around multiple TSM files.
@@ -140,6 +142,16 @@ type FileStore struct {
 	logOutput    io.Writer  // Writer to be logger and traceLogger if active.
 	traceLogging bool
 
+	// verifyChecksums enables checksum verification of blocks as they are read.
+	verifyChecksums bool
+
+	// keys decrypts the encrypted TSM files.
+	keys *KeyRing
+
+	// quarantined holds files that failed checksum verification while they were
+	// in use.  They are closed when the FileStore is closed.
+	quarantined []TSMFile
+
 	stats  *FileStoreStatistics
 	purger *purger
 
@@ -199,6 +211,18 @@ func (f *FileStore) enableTraceLogging(enabled bool) {
 	}
 }
 
+// EnableChecksumVerification sets whether block checksums are verified when
+// blocks are read.  It must be called before the FileStore is opened.
+func (f *FileStore) EnableChecksumVerification(enabled bool) {
+	f.verifyChecksums = enabled
+}
+
+// SetKeyRing sets the keys decrypting the encrypted TSM files.  It must be
+// called before the FileStore is opened.
+func (f *FileStore) SetKeyRing(keys *KeyRing) {
+	f.keys = keys
+}
+
 // WithLogger sets the logger on the file store.
 func (f *FileStore) WithLogger(log zap.Logger) {
 	f.logger = *log.With(zap.String("service", "filestore"))
@@ -211,8 +235,10 @@ func (f *FileStore) WithLogger(log zap.Logger) {
 
 // FileStoreStatistics keeps statistics about the file store.
 type FileStoreStatistics struct {
-	DiskBytes int64
-	FileCount int64
+	DiskBytes        int64
+	FileCount        int64
+	ChecksumErrors   int64
+	QuarantinedFiles int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -221,8 +247,10 @@ func (f *FileStore) Statistics(tags map[string]string) []models.Statistic {
 		Name: "tsm1_filestore",
 		Tags: tags,
 		Values: map[string]interface{}{
-			statFileStoreBytes: atomic.LoadInt64(&f.stats.DiskBytes),
-			statFileStoreCount: atomic.LoadInt64(&f.stats.FileCount),
+			statFileStoreBytes:            atomic.LoadInt64(&f.stats.DiskBytes),
+			statFileStoreCount:            atomic.LoadInt64(&f.stats.FileCount),
+			statFileStoreChecksumErrors:   atomic.LoadInt64(&f.stats.ChecksumErrors),
+			statFileStoreQuarantinedFiles: atomic.LoadInt64(&f.stats.QuarantinedFiles),
 		},
 	}}
 }
@@ -392,6 +420,17 @@ func (f *FileStore) Open() error {
 		return err
 	}
 
+	// Quarantined files are not loaded, but their generations must not be reused.
+	quarantined, err := filepath.Glob(filepath.Join(f.dir, fmt.Sprintf("*.%s.%s", TSMFileExtension, CorruptTSMFileExtension)))
+	if err != nil {
+		return err
+	}
+	for _, fn := range quarantined {
+		if generation, _, err := ParseTSMFileName(fn); err == nil && generation >= f.currentGeneration {
+			f.currentGeneration = generation + 1
+		}
+	}
+
 	// struct to hold the result of opening each reader in a goroutine
 	type res struct {
 		r   *TSMReader
@@ -426,7 +465,7 @@ func (f *FileStore) Open() error {
 
 		go func(idx int, file *os.File) {
 			start := time.Now()
-			df, err := NewTSMReader(file)
+			df, err := newTSMReader(file, f.verifyChecksums, f.keys)
 			f.logger.Info(fmt.Sprintf("%s (#%d) opened in %v", file.Name(), idx, time.Since(start)))
 
 			if err != nil {
@@ -464,15 +503,100 @@ func (f *FileStore) Close() error {
 		file.Close()
 	}
 
+	for _, file := range f.quarantined {
+		if f.dereferencer != nil {
+			file.deref(f.dereferencer)
+		}
+		file.Close()
+	}
+
 	f.lastFileStats = nil
 	f.files = nil
+	f.quarantined = nil
 	atomic.StoreInt64(&f.stats.FileCount, 0)
 	return nil
 }
 
+// quarantine removes the file at path from the set of active files after it
+// failed checksum verification.  The file and its tombstones are renamed with the
+// CorruptTSMFileExtension so they are not loaded again when the shard is reopened.
+// The remaining files stay readable.
+func (f *FileStore) quarantine(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var file TSMFile
+	active := make([]TSMFile, 0, len(f.files))
+	for _, tsm := range f.files {
+		if tsm.Path() == path {
+			file = tsm
+			continue
+		}
+		active = append(active, tsm)
+	}
+
+	// The file may have already been quarantined by another reader.
+	if file == nil {
+		return nil
+	}
+
+	f.logger.Info(fmt.Sprintf("quarantining corrupt TSM file %s", path))
+
+	for _, t := range file.TombstoneFiles() {
+		if err := os.Rename(t.Path, t.Path+"."+CorruptTSMFileExtension); err != nil {
+			return err
+		}
+	}
+
+	if err := file.Rename(path + "." + CorruptTSMFileExtension); err != nil {
+		return err
+	}
+
+	if file.InUse() {
+		f.quarantined = append(f.quarantined, file)
+	} else {
+		if f.dereferencer != nil {
+			file.deref(f.dereferencer)
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	atomic.AddInt64(&f.stats.DiskBytes, -int64(file.Size()))
+	atomic.AddInt64(&f.stats.QuarantinedFiles, 1)
+
+	f.lastFileStats = nil
+	f.files = active
+	atomic.StoreInt64(&f.stats.FileCount, int64(len(f.files)))
+	return nil
+}
+
+// checkBlockError records a block checksum error and quarantines the file it
+// occurred in.  err is returned unchanged.
+func (f *FileStore) checkBlockError(err error) error {
+	if err, ok := err.(*BlockChecksumError); ok {
+		atomic.AddInt64(&f.stats.ChecksumErrors, 1)
+		if qerr := f.quarantine(err.Path); qerr != nil {
+			f.logger.Info(fmt.Sprintf("error quarantining TSM file %s: %v", err.Path, qerr))
+		}
+	}
+	return err
+}
+
 // Read returns the slice of values for the given key and the given timestamp,
 // if any file matches those constraints.
 func (f *FileStore) Read(key string, t int64) ([]Value, error) {
+	values, err := f.read(key, t)
+	if err != nil {
+		// The read lock is released first since quarantining a file takes the write lock.
+		return nil, f.checkBlockError(err)
+	}
+	return values, nil
+}
+
+// read returns the values for key and t from the first file holding any.
+func (f *FileStore) read(key string, t int64) ([]Value, error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
@@ -569,7 +693,7 @@ func (f *FileStore) Replace(oldFiles, newFiles []string) error {
 			}
 		}
 
-		tsm, err := NewTSMReader(fd)
+		tsm, err := newTSMReader(fd, f.verifyChecksums, f.keys)
 		if err != nil {
 			return err
 		}
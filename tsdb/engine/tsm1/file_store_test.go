@@ -2003,6 +2003,139 @@ func TestFileStore_Open(t *testing.T) {
 	}
 }
 
+func TestFileStore_Open_QuarantineCorrupt(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// Create 2 TSM files...
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"mem", []tsm1.Value{tsm1.NewValue(0, 2.0)}},
+	}
+
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		fatal(t, "creating test files", err)
+	}
+
+	// Flip a byte in the first block of the first file, after the 5 byte
+	// header and 4 byte checksum.
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		fatal(t, "reading test file", err)
+	}
+	b[10] ^= 0xff
+	if err := ioutil.WriteFile(files[0], b, 0666); err != nil {
+		fatal(t, "corrupting test file", err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	fs.EnableChecksumVerification(true)
+	if err := fs.Open(); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+
+	buf := make([]tsm1.FloatValue, 1000)
+	c := fs.KeyCursor("cpu", 0, true)
+	if _, err := c.ReadFloatBlock(&buf); err == nil {
+		t.Fatalf("expected checksum error")
+	} else if _, ok := err.(*tsm1.BlockChecksumError); !ok {
+		t.Fatalf("unexpected error reading values: %v", err)
+	}
+	c.Close()
+
+	if got, exp := fs.Count(), 1; got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+
+	if _, err := os.Stat(files[0] + "." + tsm1.CorruptTSMFileExtension); err != nil {
+		t.Fatalf("expected quarantined file: %v", err)
+	}
+
+	// The remaining file is still readable.
+	c = fs.KeyCursor("mem", 0, true)
+	values, err := c.ReadFloatBlock(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	} else if got, exp := len(values), 1; got != exp {
+		t.Fatalf("value length mismatch: got %v, exp %v", got, exp)
+	}
+	c.Close()
+
+	stats := fs.Statistics(nil)[0].Values
+	if got, exp := stats["checksumErrors"], int64(1); got != exp {
+		t.Fatalf("checksum errors mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := stats["numQuarantinedFiles"], int64(1); got != exp {
+		t.Fatalf("quarantined files mismatch: got %v, exp %v", got, exp)
+	}
+
+	// The quarantined file is not loaded on reopen and its generation is not reused.
+	fs2 := tsm1.NewFileStore(dir)
+	if err := fs2.Open(); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs2.Close()
+
+	if got, exp := fs2.Count(), 1; got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := fs2.CurrentGeneration(), 3; got != exp {
+		t.Fatalf("current ID mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+// Ensure a corrupt block read by Read quarantines its file.
+func TestFileStore_Read_QuarantineCorrupt(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"mem", []tsm1.Value{tsm1.NewValue(0, 2.0)}},
+	}
+
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		fatal(t, "creating test files", err)
+	}
+
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		fatal(t, "reading test file", err)
+	}
+	b[10] ^= 0xff
+	if err := ioutil.WriteFile(files[0], b, 0666); err != nil {
+		fatal(t, "corrupting test file", err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	fs.EnableChecksumVerification(true)
+	if err := fs.Open(); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+
+	if _, err := fs.Read("cpu", 0); err == nil {
+		t.Fatalf("expected checksum error")
+	} else if _, ok := err.(*tsm1.BlockChecksumError); !ok {
+		t.Fatalf("unexpected error reading values: %v", err)
+	}
+
+	if got, exp := fs.Count(), 1; got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+
+	if _, err := os.Stat(files[0] + "." + tsm1.CorruptTSMFileExtension); err != nil {
+		t.Fatalf("expected quarantined file: %v", err)
+	}
+
+	if values, err := fs.Read("mem", 0); err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	} else if got, exp := len(values), 1; got != exp {
+		t.Fatalf("value length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
 func TestFileStore_Remove(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
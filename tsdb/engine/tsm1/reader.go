@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"math"
 	"os"
@@ -15,6 +16,20 @@ import (
 // ErrFileInUse is returned when attempting to remove or close a TSM file that is still being used.
 var ErrFileInUse = fmt.Errorf("file still in use")
 
+// BlockChecksumError is returned when the checksum stored with a block in a TSM
+// file does not match the block's contents.
+type BlockChecksumError struct {
+	Path     string
+	Offset   int64
+	Checksum uint32
+	Expected uint32
+}
+
+// Error returns a string representation of the checksum mismatch.
+func (e *BlockChecksumError) Error() string {
+	return fmt.Sprintf("%s: block checksum mismatch at offset %d: got %d but expected %d", e.Path, e.Offset, e.Checksum, e.Expected)
+}
+
 // TSMReader is a reader for a TSM file.
 type TSMReader struct {
 	// refs is the count of active references to this reader.
@@ -153,7 +168,7 @@ func (b *BlockIterator) Read() (key string, minTime int64, maxTime int64, checks
 	if b.err != nil {
 		return "", 0, 0, 0, nil, b.err
 	}
-	checksum, buf, err = b.r.readBytes(&b.entries[0], nil)
+	checksum, buf, err = b.r.ReadBytes(&b.entries[0], nil)
 	if err != nil {
 		return "", 0, 0, 0, nil, err
 	}
@@ -179,6 +194,19 @@ type blockAccessor interface {
 
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File) (*TSMReader, error) {
+	return newTSMReader(f, false, nil)
+}
+
+// NewTSMReaderWithKeys returns a new TSMReader from the given file, which
+// decrypts the file with keys if it is encrypted.
+func NewTSMReaderWithKeys(f *os.File, keys *KeyRing) (*TSMReader, error) {
+	return newTSMReader(f, false, keys)
+}
+
+// newTSMReader returns a new TSMReader from the given file.  If verifyChecksums
+// is true, the checksum of every block is verified when it is read.  An
+// encrypted file is decrypted with keys.
+func newTSMReader(f *os.File, verifyChecksums bool, keys *KeyRing) (*TSMReader, error) {
 	t := &TSMReader{}
 
 	stat, err := f.Stat()
@@ -187,9 +215,12 @@ func NewTSMReader(f *os.File) (*TSMReader, error) {
 	}
 	t.size = stat.Size()
 	t.lastModified = stat.ModTime().UnixNano()
-	t.accessor = &mmapAccessor{
-		f: f,
+	accessor := &mmapAccessor{
+		f:               f,
+		verifyChecksums: verifyChecksums,
+		keys:            keys,
 	}
+	t.accessor = accessor
 
 	index, err := t.accessor.init()
 	if err != nil {
@@ -197,7 +228,7 @@ func NewTSMReader(f *os.File) (*TSMReader, error) {
 	}
 
 	t.index = index
-	t.tombstoner = &Tombstoner{Path: t.Path()}
+	t.tombstoner = &Tombstoner{Path: t.Path(), Keys: keys, FileID: accessor.fileID}
 
 	if err := t.applyTombstones(); err != nil {
 		return nil, err
@@ -310,7 +341,10 @@ func (t *TSMReader) ReadAll(key string) ([]Value, error) {
 	return v, err
 }
 
-func (t *TSMReader) readBytes(e *IndexEntry, b []byte) (uint32, []byte, error) {
+// ReadBytes returns the checksum and the data of the block identified by e.
+// The data of an encrypted block is decrypted and its checksum is that of
+// the decrypted data.
+func (t *TSMReader) ReadBytes(e *IndexEntry, b []byte) (uint32, []byte, error) {
 	t.mu.RLock()
 	n, v, err := t.accessor.readBytes(e, b)
 	t.mu.RUnlock()
@@ -972,17 +1006,28 @@ type mmapAccessor struct {
 	f     *os.File
 	b     []byte
 	index *indirectIndex
+
+	// verifyChecksums causes every block to be checked against its checksum
+	// before it is decoded.
+	verifyChecksums bool
+
+	// encrypted is true if the blocks and the index are encrypted with one
+	// of keys.  Encrypted blocks are decrypted from the mapped file as they
+	// are read.
+	encrypted bool
+	keys      *KeyRing
+	fileID    []byte
 }
 
 func (m *mmapAccessor) init() (*indirectIndex, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if err := verifyVersion(m.f); err != nil {
+	version, err := verifyVersion(m.f)
+	if err != nil {
 		return nil, err
 	}
-
-	var err error
+	m.encrypted = version == EncryptedVersion
 
 	if _, err := m.f.Seek(0, 0); err != nil {
 		return nil, err
@@ -1007,8 +1052,22 @@ func (m *mmapAccessor) init() (*indirectIndex, error) {
 		return nil, fmt.Errorf("mmapAccessor: invalid indexStart")
 	}
 
+	index := m.b[indexStart:indexOfsPos]
+	if m.encrypted {
+		// The file is identified by its first block, after the header and
+		// the block checksum.
+		if indexStart < headerSize+4+sealOverhead {
+			return nil, fmt.Errorf("mmapAccessor: invalid indexStart")
+		}
+		m.fileID = sealedFileID(m.b[headerSize+4:])
+
+		if index, err = m.keys.Open(nil, index, positionData(m.fileID, int64(indexStart))); err != nil {
+			return nil, fmt.Errorf("mmapAccessor: index: %v", err)
+		}
+	}
+
 	m.index = NewIndirectIndex()
-	if err := m.index.UnmarshalBinary(m.b[indexStart:indexOfsPos]); err != nil {
+	if err := m.index.UnmarshalBinary(index); err != nil {
 		return nil, err
 	}
 
@@ -1070,9 +1129,17 @@ func (m *mmapAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, er
 	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
 		return nil, ErrTSMClosed
 	}
-	//TODO: Validate checksum
-	var err error
-	values, err = DecodeBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+
+	if err := m.verifyBlock(entry); err != nil {
+		return nil, err
+	}
+
+	b, err := m.blockData(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err = DecodeBlock(b, values)
 	if err != nil {
 		return nil, err
 	}
@@ -1088,7 +1155,18 @@ func (m *mmapAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) (
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeFloatBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	if err := m.verifyBlock(entry); err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeFloatBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -1106,7 +1184,18 @@ func (m *mmapAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValu
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeIntegerBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	if err := m.verifyBlock(entry); err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeIntegerBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -1124,7 +1213,18 @@ func (m *mmapAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue)
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeStringBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	if err := m.verifyBlock(entry); err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeStringBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -1142,7 +1242,18 @@ func (m *mmapAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValu
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeBooleanBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	if err := m.verifyBlock(entry); err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeBooleanBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -1160,6 +1271,20 @@ func (m *mmapAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, e
 		return 0, nil, ErrTSMClosed
 	}
 
+	if err := m.verifyBlock(entry); err != nil {
+		return 0, nil, err
+	}
+
+	// The checksum of a decrypted block is computed, its integrity having
+	// been checked by the decryption.
+	if m.encrypted {
+		b, err := m.blockData(entry)
+		if err != nil {
+			return 0, nil, err
+		}
+		return crc32.ChecksumIEEE(b), b, nil
+	}
+
 	// return the bytes after the 4 byte checksum
 	return binary.BigEndian.Uint32(m.b[entry.Offset : entry.Offset+4]), m.b[entry.Offset+4 : entry.Offset+int64(entry.Size)], nil
 }
@@ -1177,7 +1302,6 @@ func (m *mmapAccessor) readAll(key string) ([]Value, error) {
 	defer m.mu.RUnlock()
 
 	var temp []Value
-	var err error
 	var values []Value
 	for _, block := range blocks {
 		var skip bool
@@ -1192,10 +1316,18 @@ func (m *mmapAccessor) readAll(key string) ([]Value, error) {
 		if skip {
 			continue
 		}
-		//TODO: Validate checksum
+
+		if err := m.verifyBlock(&block); err != nil {
+			return nil, err
+		}
+
+		b, err := m.blockData(&block)
+		if err != nil {
+			return nil, err
+		}
+
 		temp = temp[:0]
-		// The +4 is the 4 byte checksum length
-		temp, err = DecodeBlock(m.b[block.Offset+4:block.Offset+int64(block.Size)], temp)
+		temp, err = DecodeBlock(b, temp)
 		if err != nil {
 			return nil, err
 		}
@@ -1211,6 +1343,43 @@ func (m *mmapAccessor) readAll(key string) ([]Value, error) {
 	return values, nil
 }
 
+// verifyBlock returns a *BlockChecksumError if checksum verification is enabled and
+// the block identified by entry does not match its checksum.  The caller must hold
+// the read lock and have checked that the block is within the mapped file.
+func (m *mmapAccessor) verifyBlock(entry *IndexEntry) error {
+	if !m.verifyChecksums {
+		return nil
+	}
+
+	b := m.b[entry.Offset : entry.Offset+int64(entry.Size)]
+	if len(b) < 4 {
+		return &BlockChecksumError{Path: m.f.Name(), Offset: entry.Offset}
+	}
+
+	checksum := binary.BigEndian.Uint32(b[:4])
+	if expected := crc32.ChecksumIEEE(b[4:]); checksum != expected {
+		return &BlockChecksumError{Path: m.f.Name(), Offset: entry.Offset, Checksum: checksum, Expected: expected}
+	}
+	return nil
+}
+
+// blockData returns the data of the block identified by entry, after its 4 byte
+// checksum, decrypting it if the file is encrypted.  The caller must hold the
+// read lock and have checked that the block is within the mapped file.
+func (m *mmapAccessor) blockData(entry *IndexEntry) ([]byte, error) {
+	b := m.b[entry.Offset+4 : entry.Offset+int64(entry.Size)]
+	if !m.encrypted {
+		return b, nil
+	}
+
+	// The first block is sealed before the file is identified.
+	var fileID []byte
+	if entry.Offset != headerSize {
+		fileID = m.fileID
+	}
+	return m.keys.Open(nil, b, positionData(fileID, entry.Offset))
+}
+
 func (m *mmapAccessor) path() string {
 	m.mu.RLock()
 	path := m.f.Name()
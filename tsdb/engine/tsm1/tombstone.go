@@ -2,7 +2,9 @@ package tsm1
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
+	"io"
 	"io/ioutil"
 	"math"
 	"os"
@@ -14,6 +16,10 @@ import (
 const (
 	v2header     = 0x1502
 	v2headerSize = 4
+
+	// v3header starts tombstone files holding the v2 format sealed with an
+	// encryption key.
+	v3header = 0x1503
 )
 
 // Tombstoner records tombstones when entries are deleted.
@@ -24,6 +30,11 @@ type Tombstoner struct {
 	// full path to a TSM file.
 	Path string
 
+	// Keys encrypts the tombstones, if set, as they hold series keys.  They
+	// are bound to the TSM file identified by FileID.
+	Keys   *KeyRing
+	FileID []byte
+
 	// cache of the stats for this tombstone
 	fileStats []FileStat
 	// indicates that the stats may be out of sync with what is on disk and they
@@ -157,8 +168,11 @@ func (t *Tombstoner) Walk(fn func(t Tombstone) error) error {
 		return err
 	}
 
-	if binary.BigEndian.Uint32(b[:]) == v2header {
+	switch binary.BigEndian.Uint32(b[:]) {
+	case v2header:
 		return t.readTombstoneV2(f, fn)
+	case v3header:
+		return t.readTombstoneV3(f, fn)
 	}
 	return t.readTombstoneV1(f, fn)
 }
@@ -172,26 +186,46 @@ func (t *Tombstoner) writeTombstone(tombstones []Tombstone) error {
 
 	var b [8]byte
 
+	// Encrypted tombstones are written to a buffer and sealed.
+	var w io.Writer = tmp
+	var buf bytes.Buffer
+	if t.Keys != nil {
+		w = &buf
+	}
+
 	binary.BigEndian.PutUint32(b[:4], v2header)
+	if t.Keys != nil {
+		binary.BigEndian.PutUint32(b[:4], v3header)
+	}
 	if _, err := tmp.Write(b[:4]); err != nil {
 		return err
 	}
 
 	for _, t := range tombstones {
 		binary.BigEndian.PutUint32(b[:4], uint32(len(t.Key)))
-		if _, err := tmp.Write(b[:4]); err != nil {
+		if _, err := w.Write(b[:4]); err != nil {
 			return err
 		}
-		if _, err := tmp.Write([]byte(t.Key)); err != nil {
+		if _, err := w.Write([]byte(t.Key)); err != nil {
 			return err
 		}
 		binary.BigEndian.PutUint64(b[:], uint64(t.Min))
-		if _, err := tmp.Write(b[:]); err != nil {
+		if _, err := w.Write(b[:]); err != nil {
 			return err
 		}
 
 		binary.BigEndian.PutUint64(b[:], uint64(t.Max))
-		if _, err := tmp.Write(b[:]); err != nil {
+		if _, err := w.Write(b[:]); err != nil {
+			return err
+		}
+	}
+
+	if t.Keys != nil {
+		sealed, err := t.Keys.Seal(nil, buf.Bytes(), positionData(t.FileID, v2headerSize))
+		if err != nil {
+			return err
+		}
+		if _, err := tmp.Write(sealed); err != nil {
 			return err
 		}
 	}
@@ -310,6 +344,40 @@ func (t *Tombstoner) readTombstoneV2(f *os.File, fn func(t Tombstone) error) err
 	}
 }
 
+// readTombstoneV3 reads tombstone files holding the second version of the
+// format sealed with an encryption key.
+func (t *Tombstoner) readTombstoneV3(f *os.File, fn func(t Tombstone) error) error {
+	sealed, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	b, err := t.Keys.Open(nil, sealed[v2headerSize:], positionData(t.FileID, v2headerSize))
+	if err != nil {
+		return err
+	}
+
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return io.ErrUnexpectedEOF
+		}
+		keyLen := int(binary.BigEndian.Uint32(b[:4]))
+		if len(b) < 4+keyLen+16 {
+			return io.ErrUnexpectedEOF
+		}
+		b = b[4:]
+
+		if err := fn(Tombstone{
+			Key: string(b[:keyLen]),
+			Min: int64(binary.BigEndian.Uint64(b[keyLen : keyLen+8])),
+			Max: int64(binary.BigEndian.Uint64(b[keyLen+8 : keyLen+16])),
+		}); err != nil {
+			return err
+		}
+		b = b[keyLen+16:]
+	}
+	return nil
+}
+
 func (t *Tombstoner) tombstonePath() string {
 	if strings.HasSuffix(t.Path, "tombstone") {
 		return t.Path
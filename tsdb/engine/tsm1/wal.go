@@ -61,6 +61,10 @@ const (
 
 	// DeleteRangeWALEntryType indicates a delete range entry.
 	DeleteRangeWALEntryType WalEntryType = 0x03
+
+	// encryptedWALEntryFlag is set in the type of an entry whose compressed
+	// data is encrypted.
+	encryptedWALEntryFlag = 0x80
 )
 
 var (
@@ -75,10 +79,13 @@ var (
 
 // Statistics gathered by the WAL.
 const (
-	statWALOldBytes     = "oldSegmentsDiskBytes"
-	statWALCurrentBytes = "currentSegmentDiskBytes"
-	statWriteOk         = "writeOk"
-	statWriteErr        = "writeErr"
+	statWALOldBytes      = "oldSegmentsDiskBytes"
+	statWALCurrentBytes  = "currentSegmentDiskBytes"
+	statWriteOk          = "writeOk"
+	statWriteErr         = "writeErr"
+	statWALFsyncCount    = "fsyncCount"
+	statWALFsyncDuration = "fsyncDuration"
+	statWALFsyncEntries  = "fsyncEntries"
 )
 
 // WAL represents the write-ahead log used for writing TSM files.
@@ -103,6 +110,27 @@ type WAL struct {
 	// SegmentSize is the file size at which a segment file will be rotated
 	SegmentSize int
 
+	// SyncDelay is the amount of time to wait for other writes before the
+	// current segment is fsynced.  Writes that arrive within the delay, or
+	// while an fsync is running, share a single fsync.
+	SyncDelay time.Duration
+
+	// Async makes writes return once their entry has been written to the
+	// segment file, without waiting for it to be fsynced.
+	Async bool
+
+	// Keys encrypts the entries with its active key, if set.
+	Keys *KeyRing
+
+	// syncScheduled is true when a goroutine is waiting to fsync the current segment.
+	syncScheduled bool
+
+	// unsynced is the number of entries written since the last fsync.
+	unsynced int
+
+	// syncWaiters are notified with the result of the next fsync.
+	syncWaiters []chan error
+
 	// statistics for the WAL
 	stats   *WALStatistics
 	limiter limiter.Fixed
@@ -143,10 +171,13 @@ func (l *WAL) WithLogger(log zap.Logger) {
 
 // WALStatistics maintains statistics about the WAL.
 type WALStatistics struct {
-	OldBytes     int64
-	CurrentBytes int64
-	WriteOK      int64
-	WriteErr     int64
+	OldBytes      int64
+	CurrentBytes  int64
+	WriteOK       int64
+	WriteErr      int64
+	FsyncCount    int64
+	FsyncDuration int64
+	FsyncEntries  int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -155,10 +186,13 @@ func (l *WAL) Statistics(tags map[string]string) []models.Statistic {
 		Name: "tsm1_wal",
 		Tags: tags,
 		Values: map[string]interface{}{
-			statWALOldBytes:     atomic.LoadInt64(&l.stats.OldBytes),
-			statWALCurrentBytes: atomic.LoadInt64(&l.stats.CurrentBytes),
-			statWriteOk:         atomic.LoadInt64(&l.stats.WriteOK),
-			statWriteErr:        atomic.LoadInt64(&l.stats.WriteErr),
+			statWALOldBytes:      atomic.LoadInt64(&l.stats.OldBytes),
+			statWALCurrentBytes:  atomic.LoadInt64(&l.stats.CurrentBytes),
+			statWriteOk:          atomic.LoadInt64(&l.stats.WriteOK),
+			statWriteErr:         atomic.LoadInt64(&l.stats.WriteErr),
+			statWALFsyncCount:    atomic.LoadInt64(&l.stats.FsyncCount),
+			statWALFsyncDuration: atomic.LoadInt64(&l.stats.FsyncDuration),
+			statWALFsyncEntries:  atomic.LoadInt64(&l.stats.FsyncEntries),
 		},
 	}}
 }
@@ -315,6 +349,18 @@ func (l *WAL) LastWriteTime() time.Time {
 }
 
 func (l *WAL) writeToLog(entry WALEntry) (int, error) {
+	id, syncErr, err := l.writeEntry(entry)
+	if err != nil || syncErr == nil {
+		return id, err
+	}
+
+	// Wait for the fsync covering this entry to complete.
+	return id, <-syncErr
+}
+
+// writeEntry writes entry to the current segment and schedules an fsync.  Unless
+// the WAL is async, the returned channel receives the result of the fsync.
+func (l *WAL) writeEntry(entry WALEntry) (int, chan error, error) {
 	// limit how many concurrent encodings can be in flight.  Since we can only
 	// write one at a time to disk, a slow disk can cause the allocations below
 	// to increase quickly.  If we're backed up, wait until others have completed.
@@ -327,7 +373,7 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 
 	b, err := entry.Encode(bytes)
 	if err != nil {
-		return -1, err
+		return -1, nil, err
 	}
 
 	encBuf := getBuf(snappy.MaxEncodedLen(len(b)))
@@ -340,18 +386,18 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 	// Make sure the log has not been closed
 	select {
 	case <-l.closing:
-		return -1, ErrWALClosed
+		return -1, nil, ErrWALClosed
 	default:
 	}
 
 	// roll the segment file if needed
 	if err := l.rollSegment(); err != nil {
-		return -1, fmt.Errorf("error rolling WAL segment: %v", err)
+		return -1, nil, fmt.Errorf("error rolling WAL segment: %v", err)
 	}
 
-	// write and sync
+	// write the entry, the fsync happens in the background
 	if err := l.currentSegmentWriter.Write(entry.Type(), compressed); err != nil {
-		return -1, fmt.Errorf("error writing WAL entry: %v", err)
+		return -1, nil, fmt.Errorf("error writing WAL entry: %v", err)
 	}
 
 	// Update stats for current segment size
@@ -359,7 +405,65 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 
 	l.lastWriteTime = time.Now()
 
-	return l.currentSegmentID, l.currentSegmentWriter.sync()
+	var syncErr chan error
+	if !l.Async {
+		syncErr = make(chan error, 1)
+		l.syncWaiters = append(l.syncWaiters, syncErr)
+	}
+	l.unsynced++
+	l.scheduleSync()
+
+	return l.currentSegmentID, syncErr, nil
+}
+
+// scheduleSync starts a goroutine that fsyncs the current segment after SyncDelay,
+// unless one is already waiting to do so.  It must be called with the lock held.
+func (l *WAL) scheduleSync() {
+	if l.syncScheduled {
+		return
+	}
+	l.syncScheduled = true
+
+	closing := l.closing
+	go func() {
+		if l.SyncDelay > 0 {
+			t := time.NewTimer(l.SyncDelay)
+			select {
+			case <-t.C:
+			case <-closing:
+				t.Stop()
+			}
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.syncScheduled = false
+		l.syncLocked()
+	}()
+}
+
+// syncLocked fsyncs the current segment if there are unsynced entries and notifies
+// any writers waiting on the fsync.  It must be called with the lock held.
+func (l *WAL) syncLocked() {
+	if l.unsynced == 0 {
+		return
+	}
+
+	var err error
+	if l.currentSegmentWriter != nil {
+		start := time.Now()
+		err = l.currentSegmentWriter.sync()
+
+		atomic.AddInt64(&l.stats.FsyncCount, 1)
+		atomic.AddInt64(&l.stats.FsyncDuration, time.Since(start).Nanoseconds())
+		atomic.AddInt64(&l.stats.FsyncEntries, int64(l.unsynced))
+	}
+
+	for _, c := range l.syncWaiters {
+		c <- err
+	}
+	l.syncWaiters = nil
+	l.unsynced = 0
 }
 
 // rollSegment checks if the current segment is due to roll over to a new segment;
@@ -436,6 +540,9 @@ func (l *WAL) Close() error {
 	// Close, but don't set to nil so future goroutines can still be signaled
 	close(l.closing)
 
+	// Flush any entries still waiting on an fsync.
+	l.syncLocked()
+
 	if l.currentSegmentWriter != nil {
 		l.currentSegmentWriter.close()
 		l.currentSegmentWriter = nil
@@ -456,6 +563,9 @@ func segmentFileNames(dir string) ([]string, error) {
 
 // newSegmentFile will close the current segment file and open a new one, updating bookkeeping info on the log.
 func (l *WAL) newSegmentFile() error {
+	// Entries in the current segment must be on disk before it is closed.
+	l.syncLocked()
+
 	l.currentSegmentID++
 	if l.currentSegmentWriter != nil {
 		if err := l.currentSegmentWriter.close(); err != nil {
@@ -469,7 +579,7 @@ func (l *WAL) newSegmentFile() error {
 	if err != nil {
 		return err
 	}
-	l.currentSegmentWriter = NewWALSegmentWriter(fd)
+	l.currentSegmentWriter = NewWALSegmentWriterWithKeys(fd, l.Keys)
 
 	if stat, err := fd.Stat(); err == nil {
 		l.lastWriteTime = stat.ModTime()
@@ -884,12 +994,26 @@ func (w *DeleteRangeWALEntry) Type() WalEntryType {
 type WALSegmentWriter struct {
 	w    io.WriteCloser
 	size int
+
+	// keys encrypts the entries, if set.  Each entry is bound to its
+	// position in the segment, identified by its first entry.
+	keys   *KeyRing
+	fileID []byte
+	buf    []byte
 }
 
 // NewWALSegmentWriter returns a new WALSegmentWriter writing to w.
 func NewWALSegmentWriter(w io.WriteCloser) *WALSegmentWriter {
+	return NewWALSegmentWriterWithKeys(w, nil)
+}
+
+// NewWALSegmentWriterWithKeys returns a new WALSegmentWriter writing to w,
+// which encrypts the entries with the active key of keys.  The entries
+// aren't encrypted if keys is nil.
+func NewWALSegmentWriterWithKeys(w io.WriteCloser, keys *KeyRing) *WALSegmentWriter {
 	return &WALSegmentWriter{
-		w: w,
+		w:    w,
+		keys: keys,
 	}
 }
 
@@ -904,6 +1028,18 @@ func (w *WALSegmentWriter) path() string {
 func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) error {
 	var buf [5]byte
 	buf[0] = byte(entryType)
+	if w.keys != nil {
+		var err error
+		if w.buf, err = w.keys.Seal(w.buf[:0], compressed, positionData(w.fileID, int64(w.size))); err != nil {
+			return err
+		}
+		compressed = w.buf
+		buf[0] |= encryptedWALEntryFlag
+
+		if w.fileID == nil {
+			w.fileID = sealedFileID(compressed)
+		}
+	}
 	binary.BigEndian.PutUint32(buf[1:5], uint32(len(compressed)))
 
 	if _, err := w.w.Write(buf[:]); err != nil {
@@ -938,12 +1074,23 @@ type WALSegmentReader struct {
 	entry WALEntry
 	n     int64
 	err   error
+
+	// keys decrypts the encrypted entries.
+	keys   *KeyRing
+	fileID []byte
 }
 
 // NewWALSegmentReader returns a new WALSegmentReader reading from r.
 func NewWALSegmentReader(r io.ReadCloser) *WALSegmentReader {
+	return NewWALSegmentReaderWithKeys(r, nil)
+}
+
+// NewWALSegmentReaderWithKeys returns a new WALSegmentReader reading from r,
+// which decrypts the encrypted entries with keys.
+func NewWALSegmentReaderWithKeys(r io.ReadCloser, keys *KeyRing) *WALSegmentReader {
 	return &WALSegmentReader{
-		r: r,
+		r:    r,
+		keys: keys,
 	}
 }
 
@@ -982,7 +1129,22 @@ func (r *WALSegmentReader) Next() bool {
 	}
 	nReadOK += n
 
-	decLen, err := snappy.DecodedLen(b[:length])
+	compressed := b[:length]
+	if entryType&encryptedWALEntryFlag != 0 {
+		entryType &^= encryptedWALEntryFlag
+
+		// The first entry identifies the segment.
+		sealed := compressed
+		if compressed, err = r.keys.Open(nil, sealed, positionData(r.fileID, r.n)); err != nil {
+			r.err = err
+			return true
+		}
+		if r.fileID == nil {
+			r.fileID = sealedFileID(sealed)
+		}
+	}
+
+	decLen, err := snappy.DecodedLen(compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -990,7 +1152,7 @@ func (r *WALSegmentReader) Next() bool {
 	decBuf := getBuf(decLen)
 	defer putBuf(decBuf)
 
-	data, err := snappy.Decode(decBuf, b[:length])
+	data, err := snappy.Decode(decBuf, compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -3,7 +3,9 @@ package tsm1_test
 import (
 	"fmt"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/darshanman40/influxdb/tsdb/engine/tsm1"
 
@@ -513,6 +515,82 @@ func TestWAL_Delete(t *testing.T) {
 	}
 }
 
+// Ensure concurrent writes share fsyncs when a sync delay is set.
+func TestWAL_WritePoints_GroupCommit(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := tsm1.NewWAL(dir)
+	w.SyncDelay = 100 * time.Millisecond
+	if err := w.Open(); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	const n = 8
+	var wg sync.WaitGroup
+	errC := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values := map[string][]tsm1.Value{
+				"cpu,host=A#!~#value": []tsm1.Value{tsm1.NewValue(int64(i), 1.0)},
+			}
+			_, err := w.WritePoints(values)
+			errC <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errC)
+
+	for err := range errC {
+		if err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	}
+
+	stats := w.Statistics(nil)[0].Values
+	if got, exp := stats["fsyncEntries"], int64(n); got != exp {
+		t.Fatalf("fsync entries mismatch: got %v, exp %v", got, exp)
+	}
+	if got := stats["fsyncCount"].(int64); got < 1 || got >= n {
+		t.Fatalf("expected writes to share fsyncs, got %d fsyncs for %d writes", got, n)
+	}
+}
+
+// Ensure async writes return before the fsync and are flushed on close.
+func TestWAL_WritePoints_Async(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := tsm1.NewWAL(dir)
+	w.SyncDelay = time.Hour
+	w.Async = true
+	if err := w.Open(); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+
+	values := map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{tsm1.NewValue(1, 1.0)},
+	}
+	if _, err := w.WritePoints(values); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+
+	if got, exp := w.Statistics(nil)[0].Values["fsyncCount"], int64(0); got != exp {
+		t.Fatalf("fsync count mismatch: got %v, exp %v", got, exp)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing wal: %v", err)
+	}
+
+	if got, exp := w.Statistics(nil)[0].Values["fsyncEntries"], int64(1); got != exp {
+		t.Fatalf("fsync entries mismatch: got %v, exp %v", got, exp)
+	}
+}
+
 func TestWALWriter_Corrupt(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -60,6 +60,13 @@ The last section is the footer that stores the offset of the start of the index.
 │Index Ofs│
 │ 8 bytes │
 └─────────┘
+
+Files written with a KeyRing have the EncryptedVersion in their header.  The
+data of each of their blocks and their index are sealed with an encryption key,
+as described in encryption.go, and the CRC32 of a block is computed over its
+sealed data.  The sealed data is bound to the offset of its block, or of the
+index, and to the file, identified by the nonce of its first block.  The index entries record the offsets and sizes of the sealed
+blocks and the footer the offset of the sealed index.
 */
 
 import (
@@ -83,6 +90,13 @@ const (
 	// Version indicates the version of the TSM file format.
 	Version byte = 1
 
+	// EncryptedVersion indicates the version of the TSM file format whose
+	// blocks and index are encrypted.
+	EncryptedVersion byte = 2
+
+	// Size in bytes of the header, after which the first block starts
+	headerSize = 5
+
 	// Size in bytes of an index entry
 	indexEntrySize = 28
 
@@ -426,21 +440,35 @@ type tsmWriter struct {
 	w       *bufio.Writer
 	index   IndexWriter
 	n       int64
+
+	// keys encrypts the blocks and the index, if set.
+	keys   *KeyRing
+	fileID []byte
+	buf    []byte
 }
 
 // NewTSMWriter returns a new TSMWriter writing to w.
 func NewTSMWriter(w io.Writer) (TSMWriter, error) {
+	return NewTSMWriterWithKeys(w, nil)
+}
+
+// NewTSMWriterWithKeys returns a new TSMWriter writing to w, which encrypts
+// the file with the active key of keys.  The file isn't encrypted if keys is nil.
+func NewTSMWriterWithKeys(w io.Writer, keys *KeyRing) (TSMWriter, error) {
 	index := &directIndex{
 		blocks: map[string]*indexEntries{},
 	}
 
-	return &tsmWriter{wrapped: w, w: bufio.NewWriterSize(w, 4*1024*1024), index: index}, nil
+	return &tsmWriter{wrapped: w, w: bufio.NewWriterSize(w, 4*1024*1024), index: index, keys: keys}, nil
 }
 
 func (t *tsmWriter) writeHeader() error {
-	var buf [5]byte
+	var buf [headerSize]byte
 	binary.BigEndian.PutUint32(buf[0:4], MagicNumber)
 	buf[4] = Version
+	if t.keys != nil {
+		buf[4] = EncryptedVersion
+	}
 
 	n, err := t.w.Write(buf[:])
 	if err != nil {
@@ -478,19 +506,10 @@ func (t *tsmWriter) Write(key string, values Values) error {
 		return err
 	}
 
-	var checksum [crc32.Size]byte
-	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
-
-	_, err = t.w.Write(checksum[:])
-	if err != nil {
-		return err
-	}
-
-	n, err := t.w.Write(block)
+	n, err := t.writeBlock(block)
 	if err != nil {
 		return err
 	}
-	n += len(checksum)
 
 	// Record this block in index
 	t.index.Add(key, blockType, values[0].UnixNano(), values[len(values)-1].UnixNano(), t.n, uint32(n))
@@ -521,19 +540,10 @@ func (t *tsmWriter) WriteBlock(key string, minTime, maxTime int64, block []byte)
 		}
 	}
 
-	var checksum [crc32.Size]byte
-	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
-
-	_, err = t.w.Write(checksum[:])
-	if err != nil {
-		return err
-	}
-
-	n, err := t.w.Write(block)
+	n, err := t.writeBlock(block)
 	if err != nil {
 		return err
 	}
-	n += len(checksum)
 
 	// Record this block in index
 	t.index.Add(key, blockType, minTime, maxTime, t.n, uint32(n))
@@ -548,6 +558,37 @@ func (t *tsmWriter) WriteBlock(key string, minTime, maxTime int64, block []byte)
 	return nil
 }
 
+// writeBlock writes the checksum and the data of a block, encrypting it if
+// the writer has keys, and returns the number of bytes written.
+func (t *tsmWriter) writeBlock(block []byte) (int, error) {
+	if t.keys != nil {
+		var err error
+		if t.buf, err = t.keys.Seal(t.buf[:0], block, positionData(t.fileID, t.n)); err != nil {
+			return 0, err
+		}
+		block = t.buf
+
+		// The first block identifies the file.
+		if t.fileID == nil {
+			t.fileID = sealedFileID(block)
+		}
+	}
+
+	var checksum [crc32.Size]byte
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
+
+	_, err := t.w.Write(checksum[:])
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := t.w.Write(block)
+	if err != nil {
+		return 0, err
+	}
+	return n + len(checksum), nil
+}
+
 // WriteIndex writes the index section of the file.  If there are no index entries to write,
 // this returns ErrNoValues.
 func (t *tsmWriter) WriteIndex() error {
@@ -558,7 +599,18 @@ func (t *tsmWriter) WriteIndex() error {
 	}
 
 	// Write the index
-	if _, err := t.index.WriteTo(t.w); err != nil {
+	if t.keys != nil {
+		b, err := t.index.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if b, err = t.keys.Seal(nil, b, positionData(t.fileID, indexPos)); err != nil {
+			return err
+		}
+		if _, err := t.w.Write(b); err != nil {
+			return err
+		}
+	} else if _, err := t.index.WriteTo(t.w); err != nil {
 		return err
 	}
 
@@ -592,27 +644,28 @@ func (t *tsmWriter) Size() uint32 {
 }
 
 // verifyVersion verifies that the reader's bytes are a TSM byte
-// stream of the correct version (1)
-func verifyVersion(r io.ReadSeeker) error {
+// stream of the correct version (1) or encrypted version (2), and
+// returns the version.
+func verifyVersion(r io.ReadSeeker) (byte, error) {
 	_, err := r.Seek(0, 0)
 	if err != nil {
-		return fmt.Errorf("init: failed to seek: %v", err)
+		return 0, fmt.Errorf("init: failed to seek: %v", err)
 	}
 	var b [4]byte
 	_, err = io.ReadFull(r, b[:])
 	if err != nil {
-		return fmt.Errorf("init: error reading magic number of file: %v", err)
+		return 0, fmt.Errorf("init: error reading magic number of file: %v", err)
 	}
 	if binary.BigEndian.Uint32(b[:]) != MagicNumber {
-		return fmt.Errorf("can only read from tsm file")
+		return 0, fmt.Errorf("can only read from tsm file")
 	}
 	_, err = io.ReadFull(r, b[:1])
 	if err != nil {
-		return fmt.Errorf("init: error reading version: %v", err)
+		return 0, fmt.Errorf("init: error reading version: %v", err)
 	}
-	if b[0] != Version {
-		return fmt.Errorf("init: file is version %b. expected %b", b[0], Version)
+	if b[0] != Version && b[0] != EncryptedVersion {
+		return 0, fmt.Errorf("init: file is version %b. expected %b", b[0], Version)
 	}
 
-	return nil
+	return b[0], nil
 }
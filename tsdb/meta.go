@@ -1976,6 +1976,13 @@ func (m *Measurement) SetFieldName(name string) {
 	m.mu.Unlock()
 }
 
+// DeleteFieldName removes the field name from the measurement.
+func (m *Measurement) DeleteFieldName(name string) {
+	m.mu.Lock()
+	delete(m.fieldNames, name)
+	m.mu.Unlock()
+}
+
 // FieldNames returns a list of the measurement's field names, in an arbitrary order.
 func (m *Measurement) FieldNames() []string {
 	m.mu.RLock()
@@ -0,0 +1,167 @@
+package tsdb
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/darshanman40/influxdb/models"
+)
+
+// Quotas of databases.
+const (
+	// QuotaSeries limits the number of series of a database.
+	QuotaSeries = "series"
+
+	// QuotaDisk limits the size of the shards of a database on disk.
+	QuotaDisk = "disk"
+
+	// QuotaWriteRate limits the number of points written per second to a
+	// database.
+	QuotaWriteRate = "write rate"
+
+	// QuotaQueries limits the number of queries running at the same time
+	// against a database.
+	QuotaQueries = "queries"
+)
+
+// QuotaExceededError is returned when an operation would exceed a quota of
+// a database.
+type QuotaExceededError struct {
+	Database string
+	Quota    string
+	Usage    int64
+	Limit    int64
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota exceeded: db=%s (%d/%d)", e.Quota, e.Database, e.Usage, e.Limit)
+}
+
+// Temporary returns true if the quota limits a rate or the concurrent
+// queries, so that the operation may succeed later.
+func (e QuotaExceededError) Temporary() bool {
+	return e.Quota == QuotaWriteRate || e.Quota == QuotaQueries
+}
+
+// seriesReservations holds the series that writes in progress may create in
+// each database, so that concurrent writes can't together exceed a series
+// quota that each of them fits in.
+type seriesReservations struct {
+	mu   sync.Mutex
+	keys map[string]map[string]int // number of writes reserving each series key
+}
+
+// reserveSeries reserves the series that writing points to the database
+// would create, returning an error if they would exceed its series quota.
+// The returned series keys must be released by releaseSeries once the
+// points are written.
+func (s *Store) reserveSeries(database string, points []models.Point) ([]string, error) {
+	if s.MaxSeriesN == nil {
+		return nil, nil
+	}
+	max := s.MaxSeriesN(database)
+	if max <= 0 {
+		return nil, nil
+	}
+
+	index := s.DatabaseIndex(database)
+	if index == nil {
+		return nil, nil
+	}
+
+	// Collect the distinct series the points would create.
+	created := make(map[string]struct{})
+	for _, p := range points {
+		if index.SeriesBytes(p.Key()) == nil {
+			created[string(p.Key())] = struct{}{}
+		}
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+
+	r := &s.seriesReserved
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Series reserved by other writes count against the quota until they're
+	// in the index.
+	reserved := r.keys[database]
+	var n int64
+	for key := range reserved {
+		if index.Series(key) == nil {
+			n++
+		}
+	}
+	n += int64(index.SeriesN())
+
+	var newN int64
+	for key := range created {
+		if _, ok := reserved[key]; !ok {
+			newN++
+		}
+	}
+	if n+newN > max {
+		return nil, QuotaExceededError{Database: database, Quota: QuotaSeries, Usage: n, Limit: max}
+	}
+
+	if reserved == nil {
+		if r.keys == nil {
+			r.keys = make(map[string]map[string]int)
+		}
+		reserved = make(map[string]int)
+		r.keys[database] = reserved
+	}
+	keys := make([]string, 0, len(created))
+	for key := range created {
+		reserved[key]++
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// releaseSeries releases the series keys reserved by reserveSeries.
+func (s *Store) releaseSeries(database string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	r := &s.seriesReserved
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reserved := r.keys[database]
+	for _, key := range keys {
+		if reserved[key]--; reserved[key] <= 0 {
+			delete(reserved, key)
+		}
+	}
+	if len(reserved) == 0 {
+		delete(r.keys, database)
+	}
+}
+
+// DatabaseDiskBytes returns the size of the shards of a database on disk, as
+// of their last measurement.
+func (s *Store) DatabaseDiskBytes(database string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var size int64
+	for _, sh := range s.shards {
+		if sh.database == database {
+			size += atomic.LoadInt64(&sh.stats.DiskBytes)
+		}
+	}
+	return size
+}
+
+// DatabaseSeriesN returns the number of series of a database.
+func (s *Store) DatabaseSeriesN(database string) int64 {
+	index := s.DatabaseIndex(database)
+	if index == nil {
+		return 0
+	}
+	return int64(index.SeriesN())
+}
@@ -91,12 +91,25 @@ func (e ShardError) Error() string {
 type PartialWriteError struct {
 	Reason  string
 	Dropped int
+
+	// DroppedPoints lists the dropped points along with why each was dropped.
+	DroppedPoints []DroppedPoint
 }
 
 func (e PartialWriteError) Error() string {
 	return fmt.Sprintf("%s dropped=%d", e.Reason, e.Dropped)
 }
 
+// DroppedPoint is a point that was not written and the reason why.
+type DroppedPoint struct {
+	Point  models.Point
+	Reason string
+
+	// Index is the position of the point in the points of the write request.
+	// It is set by the points writer.
+	Index int
+}
+
 // Shard represents a self-contained time series database. An inverted index of
 // the measurement and tag data is kept along with the raw time series data.
 // Data can be split across many shards. The query engine in TSDB is responsible
@@ -117,6 +130,12 @@ type Shard struct {
 	closing chan struct{}
 	enabled bool
 
+	// remote lists the shard's files while they are held by objectStore.
+	// fetchMu serializes downloading them.
+	fetchMu     sync.Mutex
+	remote      *shardManifest
+	objectStore ObjectStore
+
 	// expvar-based stats.
 	stats       *ShardStatistics
 	defaultTags models.StatisticTags
@@ -298,6 +317,9 @@ func (s *Shard) Close() error {
 
 func (s *Shard) close() error {
 	if s.engine == nil {
+		if s.remote != nil {
+			s.UnloadIndex()
+		}
 		return nil
 	}
 
@@ -386,7 +408,7 @@ type SeriesCreate struct {
 
 // WritePoints will write the raw data points and any new metadata to the index in the shard.
 func (s *Shard) WritePoints(points []models.Point) error {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return err
 	}
 
@@ -429,7 +451,7 @@ func (s *Shard) WritePoints(points []models.Point) error {
 // returned map contains all the provided keys that are in the shard, and the
 // value for each key will be true if the shard has values for that key.
 func (s *Shard) ContainsSeries(seriesKeys []string) (map[string]bool, error) {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return nil, err
 	}
 
@@ -438,7 +460,7 @@ func (s *Shard) ContainsSeries(seriesKeys []string) (map[string]bool, error) {
 
 // DeleteSeries deletes a list of series.
 func (s *Shard) DeleteSeries(seriesKeys []string) error {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return err
 	}
 	if err := s.engine.DeleteSeries(seriesKeys); err != nil {
@@ -449,7 +471,7 @@ func (s *Shard) DeleteSeries(seriesKeys []string) error {
 
 // DeleteSeriesRange deletes all values from seriesKeys with timestamps between min and max (inclusive).
 func (s *Shard) DeleteSeriesRange(seriesKeys []string, min, max int64) error {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return err
 	}
 
@@ -462,7 +484,7 @@ func (s *Shard) DeleteSeriesRange(seriesKeys []string, min, max int64) error {
 
 // DeleteMeasurement deletes a measurement and all underlying series.
 func (s *Shard) DeleteMeasurement(name string, seriesKeys []string) error {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return err
 	}
 
@@ -473,6 +495,30 @@ func (s *Shard) DeleteMeasurement(name string, seriesKeys []string) error {
 	return nil
 }
 
+// DeleteField deletes all values of a field across the given series of a measurement.
+func (s *Shard) DeleteField(measurement, field string, seriesKeys []string) error {
+	if err := s.readyLocal(); err != nil {
+		return err
+	}
+
+	return s.engine.DeleteField(measurement, field, seriesKeys)
+}
+
+// AlterField deletes all values of a field across the given series of a measurement
+// and recreates the field with the new type.  Fields already of the new type are
+// left untouched.
+func (s *Shard) AlterField(measurement, field string, typ influxql.DataType, seriesKeys []string) error {
+	if err := s.readyLocal(); err != nil {
+		return err
+	}
+
+	if f := s.engine.MeasurementFields(measurement).Field(field); f != nil && f.Type == typ {
+		return nil
+	}
+
+	return s.engine.AlterField(measurement, field, typ, seriesKeys)
+}
+
 func (s *Shard) createFieldsAndMeasurements(fieldsToCreate []*FieldCreate) error {
 	if len(fieldsToCreate) == 0 {
 		return nil
@@ -505,6 +551,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		err            error
 		dropped, n     int
 		reason         string
+		droppedPoints  []DroppedPoint
 	)
 	if s.options.Config.MaxValuesPerTag > 0 {
 		// Validate that all the new points would not exceed any limits, if so, we drop them
@@ -533,6 +580,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 				if dropPoint {
 					atomic.AddInt64(&s.stats.WritePointsDropped, 1)
 					dropped++
+					droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: reason})
 
 					// This causes n below to not be increment allowing the point to be dropped
 					continue
@@ -582,6 +630,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 				dropped++
 				reason = fmt.Sprintf("max-series-per-database limit exceeded: db=%s (%d/%d)",
 					s.database, s.index.SeriesN(), s.options.Config.MaxSeriesPerDatabase)
+				droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: reason})
 				continue
 			}
 
@@ -650,7 +699,9 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 			}
 		}
 
-		if !skip {
+		if skip {
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: reason})
+		} else {
 			points[n] = points[i]
 			n++
 		}
@@ -658,7 +709,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	points = points[:n]
 
 	if dropped > 0 {
-		err = PartialWriteError{Reason: reason, Dropped: dropped}
+		err = PartialWriteError{Reason: reason, Dropped: dropped, DroppedPoints: droppedPoints}
 	}
 
 	return points, fieldsToCreate, err
@@ -674,7 +725,7 @@ func (s *Shard) SeriesCount() (int, error) {
 
 // WriteTo writes the shard's data to w.
 func (s *Shard) WriteTo(w io.Writer) (int64, error) {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return 0, err
 	}
 	n, err := s.engine.WriteTo(w)
@@ -684,7 +735,7 @@ func (s *Shard) WriteTo(w io.Writer) (int64, error) {
 
 // CreateIterator returns an iterator for the data in the shard.
 func (s *Shard) CreateIterator(measurement string, opt influxql.IteratorOptions) (influxql.Iterator, error) {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return nil, err
 	}
 
@@ -710,7 +761,7 @@ func (s *Shard) createSystemIterator(measurement string, opt influxql.IteratorOp
 
 // FieldDimensions returns unique sets of fields and dimensions across a list of sources.
 func (s *Shard) FieldDimensions(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
-	if err := s.ready(); err != nil {
+	if err := s.readyLocal(); err != nil {
 		return nil, nil, err
 	}
 
@@ -796,11 +847,19 @@ func (s *Shard) MapType(measurement, field string) influxql.DataType {
 		return influxql.Unknown
 	}
 
-	mf := s.engine.MeasurementFields(measurement)
-	if mf != nil {
-		f := mf.Field(field)
-		if f != nil {
-			return f.Type
+	if typ, ok := s.remoteFieldType(measurement, field); ok {
+		return typ
+	}
+
+	s.mu.RLock()
+	engine := s.engine
+	s.mu.RUnlock()
+
+	if engine != nil {
+		if mf := engine.MeasurementFields(measurement); mf != nil {
+			if f := mf.Field(field); f != nil {
+				return f.Type
+			}
 		}
 	}
 
@@ -860,6 +919,10 @@ func (s *Shard) ExpandSources(sources influxql.Sources) (influxql.Sources, error
 // Restore restores data to the underlying engine for the shard.
 // The shard is reopened after restore.
 func (s *Shard) Restore(r io.Reader, basePath string) error {
+	if err := s.fetch(); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 
 	// Restore to engine.
@@ -882,6 +945,10 @@ func (s *Shard) Restore(r io.Reader, basePath string) error {
 // CreateSnapshot will return a path to a temp directory
 // containing hard links to the underlying shard files.
 func (s *Shard) CreateSnapshot() (string, error) {
+	if err := s.fetch(); err != nil {
+		return "", err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.engine.CreateSnapshot()
@@ -1139,6 +1206,13 @@ func (m *MeasurementFields) CreateFieldIfNotExists(name string, typ influxql.Dat
 	return nil
 }
 
+// DeleteField removes the field with name from the measurement, if it exists.
+func (m *MeasurementFields) DeleteField(name string) {
+	m.mu.Lock()
+	delete(m.fields, name)
+	m.mu.Unlock()
+}
+
 // Field returns the field for name, or nil if there is no field for name.
 func (m *MeasurementFields) Field(name string) *Field {
 	m.mu.RLock()
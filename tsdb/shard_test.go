@@ -205,6 +205,48 @@ func TestShard_MaxTagValuesLimit(t *testing.T) {
 	sh.Close()
 }
 
+// Ensure a partial write lists the points that were dropped.
+func TestShard_WritePoints_DroppedPoints(t *testing.T) {
+	tmpDir, _ := ioutil.TempDir("", "shard_test")
+	defer os.RemoveAll(tmpDir)
+	tmpShard := path.Join(tmpDir, "db", "rp", "1")
+	tmpWal := path.Join(tmpDir, "wal")
+
+	index := tsdb.NewDatabaseIndex("db")
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = filepath.Join(tmpDir, "wal")
+
+	sh := tsdb.NewShard(1, index, tmpShard, tmpWal, opts)
+	if err := sh.Open(); err != nil {
+		t.Fatalf("error opening shard: %s", err.Error())
+	}
+	defer sh.Close()
+
+	if err := sh.WritePoints([]models.Point{
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 2.0}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", nil, map[string]interface{}{"value": "x"}, time.Unix(3, 0)),
+	}
+	conflict := points[1]
+
+	err := sh.WritePoints(points)
+	werr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(werr.DroppedPoints) != 1 {
+		t.Fatalf("unexpected dropped points: %v", werr.DroppedPoints)
+	} else if werr.DroppedPoints[0].Point != conflict {
+		t.Fatalf("unexpected dropped point: %v", werr.DroppedPoints[0].Point)
+	} else if exp, got := `field type conflict: input field "value" on measurement "cpu" is type string, already exists as type float`, werr.DroppedPoints[0].Reason; exp != got {
+		t.Fatalf("unexpected reason:\n\texp = %s\n\tgot = %s", exp, got)
+	}
+}
+
 func TestWriteTimeTag(t *testing.T) {
 	tmpDir, _ := ioutil.TempDir("", "shard_test")
 	defer os.RemoveAll(tmpDir)
@@ -41,6 +41,23 @@ type Store struct {
 	baseLogger    zap.Logger
 	Logger        zap.Logger
 
+	// ColdPath is the directory that shards are moved to by TierShard.
+	// If ObjectStore is set, only a manifest of each cold shard is kept
+	// there and its files are uploaded to the object store.
+	ColdPath    string
+	ObjectStore ObjectStore
+
+	// ManifestKeys encrypts the manifests of the shards held by the object
+	// store, if set, as they list the series keys of the shards.
+	ManifestKeys Sealer
+
+	// MaxSeriesN returns the series quota of a database, or zero if it has
+	// none. Writes creating series beyond it are rejected.
+	MaxSeriesN func(database string) int64
+
+	// Series reserved by writes in progress against their series quota.
+	seriesReserved seriesReservations
+
 	closing chan struct{}
 	wg      sync.WaitGroup
 	opened  bool
@@ -109,6 +126,15 @@ func (s *Store) Open() error {
 	if err := os.MkdirAll(s.path, 0777); err != nil {
 		return err
 	}
+	if s.ColdPath != "" {
+		if err := os.MkdirAll(s.ColdPath, 0777); err != nil {
+			return err
+		}
+	}
+
+	if err := s.reconcileColdShards(); err != nil {
+		return err
+	}
 
 	// TODO: Start AE for Node
 	if err := s.loadIndexes(); err != nil {
@@ -124,17 +150,30 @@ func (s *Store) Open() error {
 	return nil
 }
 
-func (s *Store) loadIndexes() error {
-	dbs, err := ioutil.ReadDir(s.path)
-	if err != nil {
-		return err
+// roots returns the directories holding shards: the store path and, if
+// set, the cold path.
+func (s *Store) roots() []string {
+	if s.ColdPath == "" {
+		return []string{s.path}
 	}
-	for _, db := range dbs {
-		if !db.IsDir() {
-			s.Logger.Info(fmt.Sprintf("Skipping database dir: %s. Not a directory", db.Name()))
-			continue
+	return []string{s.path, s.ColdPath}
+}
+
+func (s *Store) loadIndexes() error {
+	for _, root := range s.roots() {
+		dbs, err := ioutil.ReadDir(root)
+		if err != nil {
+			return err
+		}
+		for _, db := range dbs {
+			if !db.IsDir() {
+				s.Logger.Info(fmt.Sprintf("Skipping database dir: %s. Not a directory", db.Name()))
+				continue
+			}
+			if _, ok := s.databaseIndexes[db.Name()]; !ok {
+				s.databaseIndexes[db.Name()] = NewDatabaseIndex(db.Name())
+			}
 		}
-		s.databaseIndexes[db.Name()] = NewDatabaseIndex(db.Name())
 	}
 	return nil
 }
@@ -152,52 +191,73 @@ func (s *Store) loadShards() error {
 	var n int
 
 	// loop through the current database indexes
-	for db := range s.databaseIndexes {
-		rps, err := ioutil.ReadDir(filepath.Join(s.path, db))
-		if err != nil {
-			return err
-		}
-
-		for _, rp := range rps {
-			// retention policies should be directories.  Skip anything that is not a dir.
-			if !rp.IsDir() {
-				s.Logger.Info(fmt.Sprintf("Skipping retention policy dir: %s. Not a directory", rp.Name()))
+	for _, root := range s.roots() {
+		for db := range s.databaseIndexes {
+			rps, err := ioutil.ReadDir(filepath.Join(root, db))
+			if os.IsNotExist(err) {
 				continue
-			}
-
-			shards, err := ioutil.ReadDir(filepath.Join(s.path, db, rp.Name()))
-			if err != nil {
+			} else if err != nil {
 				return err
 			}
-			for _, sh := range shards {
-				n++
-				go func(index *DatabaseIndex, db, rp, sh string) {
-					t.Take()
-					defer t.Release()
-
-					start := time.Now()
-					path := filepath.Join(s.path, db, rp, sh)
-					walPath := filepath.Join(s.EngineOptions.Config.WALDir, db, rp, sh)
-
-					// Shard file names are numeric shardIDs
-					shardID, err := strconv.ParseUint(sh, 10, 64)
-					if err != nil {
-						resC <- &res{err: fmt.Errorf("%s is not a valid ID. Skipping shard.", sh)}
-						return
-					}
-
-					shard := NewShard(shardID, s.databaseIndexes[db], path, walPath, s.EngineOptions)
-					shard.WithLogger(s.baseLogger)
-
-					err = shard.Open()
-					if err != nil {
-						resC <- &res{err: fmt.Errorf("Failed to open shard: %d: %s", shardID, err)}
-						return
-					}
-
-					resC <- &res{s: shard}
-					s.Logger.Info(fmt.Sprintf("%s opened in %s", path, time.Since(start)))
-				}(s.databaseIndexes[db], db, rp.Name(), sh.Name())
+
+			for _, rp := range rps {
+				// retention policies should be directories.  Skip anything that is not a dir.
+				if !rp.IsDir() {
+					s.Logger.Info(fmt.Sprintf("Skipping retention policy dir: %s. Not a directory", rp.Name()))
+					continue
+				}
+
+				shards, err := ioutil.ReadDir(filepath.Join(root, db, rp.Name()))
+				if err != nil {
+					return err
+				}
+				for _, sh := range shards {
+					n++
+					go func(root string, index *DatabaseIndex, db, rp, sh string) {
+						t.Take()
+						defer t.Release()
+
+						start := time.Now()
+						path := filepath.Join(root, db, rp, sh)
+						walPath := filepath.Join(s.EngineOptions.Config.WALDir, db, rp, sh)
+
+						// Shard file names are numeric shardIDs
+						shardID, err := strconv.ParseUint(sh, 10, 64)
+						if err != nil {
+							resC <- &res{err: fmt.Errorf("%s is not a valid ID. Skipping shard.", sh)}
+							return
+						}
+
+						shard := NewShard(shardID, s.databaseIndexes[db], path, walPath, s.EngineOptions)
+						shard.WithLogger(s.baseLogger)
+
+						// Shards whose files are held by the object store are
+						// indexed from their manifest and fetched when accessed.
+						m, err := s.readShardManifest(path, remoteKey(db, rp, shardID, remoteManifestName))
+						if err != nil {
+							resC <- &res{err: fmt.Errorf("Failed to open shard: %d: %s", shardID, err)}
+							return
+						} else if m != nil && !m.fetched(path) {
+							if s.ObjectStore == nil {
+								resC <- &res{err: fmt.Errorf("Failed to open shard: %d: no object store configured", shardID)}
+								return
+							}
+							shard.setRemote(m, s.ObjectStore)
+							resC <- &res{s: shard}
+							s.Logger.Info(fmt.Sprintf("%s indexed in %s", path, time.Since(start)))
+							return
+						}
+
+						err = shard.Open()
+						if err != nil {
+							resC <- &res{err: fmt.Errorf("Failed to open shard: %d: %s", shardID, err)}
+							return
+						}
+
+						resC <- &res{s: shard}
+						s.Logger.Info(fmt.Sprintf("%s opened in %s", path, time.Since(start)))
+					}(root, s.databaseIndexes[db], db, rp.Name(), sh.Name())
+				}
 			}
 		}
 	}
@@ -369,6 +429,10 @@ func (s *Store) DeleteShard(shardID uint64) error {
 		return err
 	}
 
+	if err := s.deleteRemoteFiles(sh); err != nil {
+		return err
+	}
+
 	if err := os.RemoveAll(sh.path); err != nil {
 		return err
 	}
@@ -402,7 +466,10 @@ func (s *Store) DeleteDatabase(name string) error {
 			return nil
 		}
 
-		return sh.Close()
+		if err := sh.Close(); err != nil {
+			return err
+		}
+		return s.deleteRemoteFiles(sh)
 	}); err != nil {
 		return err
 	}
@@ -418,6 +485,11 @@ func (s *Store) DeleteDatabase(name string) error {
 	if err := os.RemoveAll(dbPath); err != nil {
 		return err
 	}
+	if s.ColdPath != "" {
+		if err := os.RemoveAll(filepath.Join(s.ColdPath, filepath.Base(dbPath))); err != nil {
+			return err
+		}
+	}
 	if err := os.RemoveAll(filepath.Join(s.EngineOptions.Config.WALDir, name)); err != nil {
 		return err
 	}
@@ -454,7 +526,10 @@ func (s *Store) DeleteRetentionPolicy(database, name string) error {
 			return nil
 		}
 
-		return sh.Close()
+		if err := sh.Close(); err != nil {
+			return err
+		}
+		return s.deleteRemoteFiles(sh)
 	}); err != nil {
 		return err
 	}
@@ -470,6 +545,11 @@ func (s *Store) DeleteRetentionPolicy(database, name string) error {
 	if err := os.RemoveAll(filepath.Join(s.path, database, name)); err != nil {
 		return err
 	}
+	if s.ColdPath != "" {
+		if err := os.RemoveAll(filepath.Join(s.ColdPath, database, name)); err != nil {
+			return err
+		}
+	}
 
 	// Remove the retention policy folder from the the WAL.
 	if err := os.RemoveAll(filepath.Join(s.EngineOptions.Config.WALDir, database, name)); err != nil {
@@ -523,6 +603,68 @@ func (s *Store) DeleteMeasurement(database, name string) error {
 	return nil
 }
 
+// DeleteField removes a field and all of its values from a measurement.
+func (s *Store) DeleteField(database, measurement, field string) error {
+	db, m, err := s.measurementWithField(database, measurement, field)
+	if err != nil || db == nil {
+		return err
+	}
+
+	seriesKeys := m.SeriesKeys()
+	if err := s.walkShards(s.databaseShards(database), func(sh *Shard) error {
+		return sh.DeleteField(m.Name, field, seriesKeys)
+	}); err != nil {
+		return err
+	}
+
+	// Remove field from index.
+	m.DeleteFieldName(field)
+
+	return nil
+}
+
+// AlterField changes the type of a field. All values written with the
+// previous type are removed.
+func (s *Store) AlterField(database, measurement, field string, typ influxql.DataType) error {
+	db, m, err := s.measurementWithField(database, measurement, field)
+	if err != nil || db == nil {
+		return err
+	}
+
+	seriesKeys := m.SeriesKeys()
+	return s.walkShards(s.databaseShards(database), func(sh *Shard) error {
+		return sh.AlterField(m.Name, field, typ, seriesKeys)
+	})
+}
+
+// measurementWithField returns the index and measurement for a field. A nil
+// index is returned if the database does not exist.
+func (s *Store) measurementWithField(database, measurement, field string) (*DatabaseIndex, *Measurement, error) {
+	s.mu.RLock()
+	db := s.databaseIndexes[database]
+	s.mu.RUnlock()
+	if db == nil {
+		return nil, nil, nil
+	}
+
+	m := db.Measurement(measurement)
+	if m == nil {
+		return nil, nil, influxql.ErrMeasurementNotFound(measurement)
+	} else if !m.HasField(field) {
+		return nil, nil, ErrFieldNotFound
+	}
+	return db, m, nil
+}
+
+// databaseShards returns the shards belonging to database.
+func (s *Store) databaseShards(database string) []*Shard {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.filterShards(func(sh *Shard) bool {
+		return sh.database == database
+	})
+}
+
 // filterShards returns a slice of shards where fn returns true
 // for the shard.
 func (s *Store) filterShards(fn func(sh *Shard) bool) []*Shard {
@@ -677,6 +819,9 @@ func (s *Store) ShardRelativePath(id uint64) (string, error) {
 	if shard == nil {
 		return "", fmt.Errorf("shard %d doesn't exist on this server", id)
 	}
+	if s.isCold(shard.path) {
+		return relativePath(s.ColdPath, shard.path)
+	}
 	return relativePath(s.path, shard.path)
 }
 
@@ -813,6 +958,12 @@ func (s *Store) WriteToShard(shardID uint64, points []models.Point) error {
 	}
 	s.mu.RUnlock()
 
+	keys, err := s.reserveSeries(sh.database, points)
+	if err != nil {
+		return err
+	}
+	defer s.releaseSeries(sh.database, keys)
+
 	return sh.WritePoints(points)
 }
 
@@ -2,12 +2,16 @@ package tsdb_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -15,6 +19,7 @@ import (
 	"github.com/darshanman40/influxdb/models"
 	"github.com/darshanman40/influxdb/pkg/deep"
 	"github.com/darshanman40/influxdb/tsdb"
+	"github.com/darshanman40/influxdb/tsdb/engine/tsm1"
 )
 
 // Ensure the store can delete a retention policy and all shards under
@@ -220,6 +225,447 @@ func TestStore_Open_InvalidShard(t *testing.T) {
 	}
 }
 
+// Ensure the store can drop a field from a measurement.
+func TestStore_DeleteField(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1,idle=2 0`,
+		`cpu,host=serverB value=3,idle=4 10`,
+	)
+
+	if err := s.DeleteField("db0", "cpu", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := s.DatabaseIndex("db0").Measurement("cpu"); m.HasField("value") {
+		t.Fatal("expected field to be removed from index")
+	}
+
+	fields, _, err := s.Shard(0).FieldDimensions([]string{"cpu"})
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := map[string]influxql.DataType{"idle": influxql.Float}; !deep.Equal(fields, exp) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	// Dropping a field that does not exist returns an error.
+	if err := s.DeleteField("db0", "cpu", "value"); err != tsdb.ErrFieldNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the store can change the type of a field.
+func TestStore_AlterField(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.MustCreateShardWithData("db0", "rp0", 0, `cpu,host=serverA value=1 0`)
+
+	if err := s.AlterField("db0", "cpu", "value", influxql.String); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new type is kept when the store is reopened.
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields, _, err := s.Shard(0).FieldDimensions([]string{"cpu"})
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := map[string]influxql.DataType{"value": influxql.String}; !deep.Equal(fields, exp) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	// Values of the new type can now be written.
+	s.MustWriteToShardString(0, `cpu,host=serverA value="foo" 10`)
+
+	fields, _, err = s.Shard(0).FieldDimensions([]string{"cpu"})
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := map[string]influxql.DataType{"value": influxql.String}; !deep.Equal(fields, exp) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
+
+// Ensure altering a field to its current type keeps its values.
+func TestStore_AlterField_SameType(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverA value=2 10`,
+	)
+
+	if err := s.AlterField("db0", "cpu", "value", influxql.Float); err != nil {
+		t.Fatal(err)
+	}
+
+	if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+// Ensure the store can move a shard to the cold path and keep serving it.
+func TestStore_TierShard(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.ColdPath = MustTempDir()
+	defer os.RemoveAll(s.ColdPath)
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverA value=2 10`,
+	)
+	path := s.Shard(0).Path()
+
+	if err := s.TierShard(0); err != nil {
+		t.Fatal(err)
+	} else if dirExists(path) {
+		t.Fatal("expected shard to be removed from the data directory")
+	} else if exp := filepath.Join(s.ColdPath, "db0", "rp0", "0"); s.Shard(0).Path() != exp {
+		t.Fatalf("unexpected shard path: %s", s.Shard(0).Path())
+	}
+
+	if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	// The shard is loaded from the cold path after a restart.
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values after reopen: %v", values)
+	}
+}
+
+// Ensure a move to the cold path interrupted by a restart is completed when
+// the store is reopened.
+func TestStore_TierShard_Interrupted(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.ColdPath = MustTempDir()
+	defer os.RemoveAll(s.ColdPath)
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverA value=2 10`,
+	)
+	path := s.Shard(0).Path()
+
+	if err := s.TierShard(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leave the shard in both directories, as if the server stopped before
+	// removing it from the data directory, along with an incomplete copy.
+	if err := os.MkdirAll(path, 0777); err != nil {
+		t.Fatal(err)
+	}
+	fis, err := ioutil.ReadDir(s.Shard(0).Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range fis {
+		buf, err := ioutil.ReadFile(filepath.Join(s.Shard(0).Path(), fi.Name()))
+		if err != nil {
+			t.Fatal(err)
+		} else if err := ioutil.WriteFile(filepath.Join(path, fi.Name()), buf, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmp := filepath.Join(s.ColdPath, "db0", "rp0", "1.tmp")
+	if err := os.MkdirAll(tmp, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if dirExists(path) {
+		t.Fatal("expected shard to be removed from the data directory")
+	} else if dirExists(tmp) {
+		t.Fatal("expected incomplete copy to be removed")
+	} else if exp := filepath.Join(s.ColdPath, "db0", "rp0", "0"); s.Shard(0).Path() != exp {
+		t.Fatalf("unexpected shard path: %s", s.Shard(0).Path())
+	} else if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values after reopen: %v", values)
+	}
+}
+
+// Ensure the store can upload a shard to an object store and fetch it when queried.
+func TestStore_TierShard_ObjectStore(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	objects := NewObjectStore()
+	s.ColdPath = MustTempDir()
+	s.ObjectStore = objects
+	defer os.RemoveAll(s.ColdPath)
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverB value=2 10`,
+	)
+
+	if err := s.TierShard(0); err != nil {
+		t.Fatal(err)
+	} else if len(objects.m) == 0 {
+		t.Fatal("expected shard files to be uploaded")
+	}
+
+	// Only the manifest is kept locally after a restart.
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	path := s.Shard(0).Path()
+	if files, _ := filepath.Glob(filepath.Join(path, "*.tsm")); len(files) != 0 {
+		t.Fatalf("unexpected local files: %v", files)
+	}
+
+	// The index and field types are available without fetching the shard.
+	if n := s.DatabaseIndex("db0").SeriesShardN(0); n != 2 {
+		t.Fatalf("unexpected series count: %d", n)
+	} else if typ := s.Shard(0).MapType("cpu", "value"); typ != influxql.Float {
+		t.Fatalf("unexpected field type: %s", typ)
+	} else if files, _ := filepath.Glob(filepath.Join(path, "*.tsm")); len(files) != 0 {
+		t.Fatalf("unexpected local files: %v", files)
+	}
+
+	// Querying the shard fetches its files.
+	if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values: %v", values)
+	} else if files, _ := filepath.Glob(filepath.Join(path, "*.tsm")); len(files) == 0 {
+		t.Fatal("expected shard files to be fetched")
+	}
+
+	// Deleting the shard removes the uploaded files.
+	if err := s.DeleteShard(0); err != nil {
+		t.Fatal(err)
+	} else if len(objects.m) != 0 {
+		t.Fatalf("unexpected objects: %d", len(objects.m))
+	}
+}
+
+// Ensure no partial file is left in a cold shard when fetching one of its
+// files fails.
+func TestStore_TierShard_ObjectStore_FetchError(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	objects := NewObjectStore()
+	s.ColdPath = MustTempDir()
+	s.ObjectStore = objects
+	defer os.RemoveAll(s.ColdPath)
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverB value=2 10`,
+	)
+
+	if err := s.TierShard(0); err != nil {
+		t.Fatal(err)
+	} else if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	objects.GetFn = func(key string, r io.Reader) io.Reader {
+		return io.MultiReader(io.LimitReader(r, 8), iotest.ErrReader(errors.New("connection reset")))
+	}
+
+	path := s.Shard(0).Path()
+	if _, err := s.Shard(0).CreateIterator("cpu", influxql.IteratorOptions{
+		Expr:      influxql.MustParseExpr(`value`),
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+	}); err == nil {
+		t.Fatal("expected error")
+	} else if files, _ := filepath.Glob(filepath.Join(path, "*.tmp")); len(files) != 0 {
+		t.Fatalf("unexpected temporary files: %v", files)
+	}
+
+	// The files are fetched once the object store is readable again.
+	objects.GetFn = nil
+	if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+// Ensure the manifest of a shard uploaded to an object store is encrypted
+// with the manifest keys of the store.
+func TestStore_TierShard_ObjectStore_Encrypted(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	keys := tsm1.NewKeyRing()
+	if err := keys.Add(1, bytes.Repeat([]byte{1}, 16)); err != nil {
+		t.Fatal(err)
+	}
+
+	s.ColdPath = MustTempDir()
+	s.ObjectStore = NewObjectStore()
+	s.ManifestKeys = keys
+	defer os.RemoveAll(s.ColdPath)
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverB value=2 10`,
+	)
+
+	if err := s.TierShard(0); err != nil {
+		t.Fatal(err)
+	} else if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := s.Shard(0).Path()
+	if b, err := ioutil.ReadFile(filepath.Join(path, "remote.json")); err != nil {
+		t.Fatal(err)
+	} else if bytes.Contains(b, []byte("serverA")) {
+		t.Fatal("manifest contains plaintext series keys")
+	}
+
+	if n := s.DatabaseIndex("db0").SeriesShardN(0); n != 2 {
+		t.Fatalf("unexpected series count: %d", n)
+	}
+
+	// The shard can't be opened without the keys.
+	s.ManifestKeys = nil
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if s.Shard(0) != nil {
+		t.Fatal("expected shard not to be opened")
+	}
+}
+
+// Ensure a shard keeps serving queries and writes while its files are
+// uploaded to an object store, and keeps the points written meanwhile.
+func TestStore_TierShard_ObjectStore_Serving(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	objects := NewObjectStore()
+	s.ColdPath = MustTempDir()
+	s.ObjectStore = objects
+	defer os.RemoveAll(s.ColdPath)
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverA value=2 10`,
+	)
+
+	var uploading bool
+	objects.PutFn = func(key string) {
+		if uploading {
+			return
+		}
+		uploading = true
+
+		if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2}) {
+			t.Errorf("unexpected values during upload: %v", values)
+		}
+		s.MustWriteToShardString(0, `cpu,host=serverB value=3 20`)
+	}
+
+	if err := s.TierShard(0); err != nil {
+		t.Fatal(err)
+	} else if !uploading {
+		t.Fatal("expected shard files to be uploaded")
+	}
+
+	// The series written during the upload is in the manifest and its point is
+	// replayed from the WAL once the shard is fetched.
+	if n := s.DatabaseIndex("db0").SeriesShardN(0); n != 2 {
+		t.Fatalf("unexpected series count: %d", n)
+	} else if values := MustReadFloats(s.Shard(0), "cpu"); !deep.Equal(values, []float64{1, 2, 3}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+// Ensure writes creating series beyond the series quota of a database are
+// rejected.
+func TestStore_WriteToShard_SeriesQuota(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.MaxSeriesN = func(database string) int64 {
+		if database == "db0" {
+			return 2
+		}
+		return 0
+	}
+
+	s.MustCreateShardWithData("db0", "rp0", 0,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverB value=1 0`,
+	)
+
+	// Existing series may still be written.
+	s.MustWriteToShardString(0, `cpu,host=serverA value=2 10`)
+
+	// New series may not.
+	points, err := models.ParsePointsWithPrecision([]byte(`cpu,host=serverA value=3 20
+cpu,host=serverC value=3 20`), time.Now().UTC(), "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteToShard(0, points); err == nil {
+		t.Fatal("expected error")
+	} else if qerr, ok := err.(tsdb.QuotaExceededError); !ok || qerr.Quota != tsdb.QuotaSeries || qerr.Usage != 2 || qerr.Limit != 2 {
+		t.Fatalf("unexpected error: %#v", err)
+	} else if qerr.Temporary() {
+		t.Fatal("expected permanent error")
+	}
+	if n := s.DatabaseSeriesN("db0"); n != 2 {
+		t.Fatalf("unexpected series: %d", n)
+	}
+
+	// Other databases aren't limited.
+	s.MustCreateShardWithData("db1", "rp0", 1,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverB value=1 0`,
+		`cpu,host=serverC value=1 0`,
+	)
+}
+
+// Ensure concurrent writes can't together exceed the series quota of a
+// database.
+func TestStore_WriteToShard_SeriesQuota_Concurrent(t *testing.T) {
+	s := MustOpenStore()
+	defer s.Close()
+
+	s.MaxSeriesN = func(database string) int64 { return 10 }
+	s.MustCreateShardWithData("db0", "rp0", 0, `cpu,host=server0 value=1 0`)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			points, err := models.ParsePointsString(fmt.Sprintf("cpu,host=server%d value=1 0", i))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if err := s.WriteToShard(0, points); err != nil {
+				if _, ok := err.(tsdb.QuotaExceededError); !ok {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := s.DatabaseSeriesN("db0"); n > 10 {
+		t.Fatalf("unexpected series: %d", n)
+	}
+}
+
 // Ensure shards can create iterators.
 func TestShards_CreateIterator(t *testing.T) {
 	s := MustOpenStore()
@@ -439,8 +885,10 @@ func (s *Store) Reopen() error {
 	if err := s.Store.Close(); err != nil {
 		return err
 	}
+	coldPath, objectStore, keys := s.ColdPath, s.ObjectStore, s.ManifestKeys
 	s.Store = tsdb.NewStore(s.Path())
 	s.EngineOptions.Config.WALDir = filepath.Join(s.Path(), "wal")
+	s.ColdPath, s.ObjectStore, s.ManifestKeys = coldPath, objectStore, keys
 	return s.Open()
 }
 
@@ -499,6 +947,103 @@ func (s *Store) BatchWrite(shardID int, points []models.Point) error {
 	return nil
 }
 
+// MustTempDir returns a new temporary directory. Panic on error.
+func MustTempDir() string {
+	path, err := ioutil.TempDir("", "influxdb-tsdb-")
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// MustReadFloats returns the values of the "value" field of a measurement in
+// a shard, ordered by series and time. Panic on error.
+func MustReadFloats(sh *tsdb.Shard, measurement string) []float64 {
+	itr, err := sh.CreateIterator(measurement, influxql.IteratorOptions{
+		Expr:       influxql.MustParseExpr(`value`),
+		Dimensions: []string{"host"},
+		Ascending:  true,
+		StartTime:  influxql.MinTime,
+		EndTime:    influxql.MaxTime,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer itr.Close()
+
+	var values []float64
+	fitr := itr.(influxql.FloatIterator)
+	for {
+		p, err := fitr.Next()
+		if err != nil {
+			panic(err)
+		} else if p == nil {
+			return values
+		}
+		values = append(values, p.Value)
+	}
+}
+
+// ObjectStore is an in-memory implementation of tsdb.ObjectStore.
+type ObjectStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+
+	// PutFn is called, if set, before an object is stored.
+	PutFn func(key string)
+
+	// GetFn is called, if set, to wrap the reader of an object.
+	GetFn func(key string, r io.Reader) io.Reader
+}
+
+// NewObjectStore returns a new instance of ObjectStore.
+func NewObjectStore() *ObjectStore {
+	return &ObjectStore{m: make(map[string][]byte)}
+}
+
+// Put stores the contents of r under key.
+func (s *ObjectStore) Put(key string, r io.Reader, size int64) error {
+	if s.PutFn != nil {
+		s.PutFn(key)
+	}
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	} else if int64(len(buf)) != size {
+		return fmt.Errorf("short object: %d != %d", len(buf), size)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = buf
+	return nil
+}
+
+// Get returns a reader for the object stored under key.
+func (s *ObjectStore) Get(key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf, ok := s.m[key]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", key)
+	}
+
+	var r io.Reader = bytes.NewReader(buf)
+	if s.GetFn != nil {
+		r = s.GetFn(key, r)
+	}
+	return ioutil.NopCloser(r), nil
+}
+
+// Delete removes the object stored under key.
+func (s *ObjectStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+	return nil
+}
+
 // ParseTags returns an instance of Tags for a comma-delimited list of key/values.
 func ParseTags(s string) influxql.Tags {
 	m := make(map[string]string)
@@ -0,0 +1,589 @@
+package tsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darshanman40/influxdb/influxql"
+	"github.com/darshanman40/influxdb/models"
+)
+
+// ErrTieringNotConfigured is returned when a shard is moved to the cold tier
+// but the store has no cold path.
+var ErrTieringNotConfigured = errors.New("tiered storage is not configured")
+
+// remoteManifestName is the name of the file, within a cold shard directory,
+// that lists the shard's files held by the object store.
+const remoteManifestName = "remote.json"
+
+// tierTempExtension is appended to the cold shard directory a shard is copied
+// into. The directory is renamed once complete, so a shard directory in the
+// cold path always holds all of the shard's files.
+const tierTempExtension = "tmp"
+
+// ObjectStore represents a store of read-only shard files, such as an
+// S3-compatible bucket.
+type ObjectStore interface {
+	// Put stores size bytes read from r under key.
+	Put(key string, r io.Reader, size int64) error
+
+	// Get returns a reader for the object stored under key.
+	Get(key string) (io.ReadCloser, error)
+
+	// Delete removes the object stored under key.
+	Delete(key string) error
+}
+
+// Sealer encrypts and authenticates data, along with additional data that
+// isn't encrypted.
+type Sealer interface {
+	// Seal appends the encryption of b to dst.
+	Seal(dst, b, ad []byte) ([]byte, error)
+
+	// Open appends the decryption of the sealed data b to dst. The
+	// additional data must be that given to Seal.
+	Open(dst, b, ad []byte) ([]byte, error)
+}
+
+// shardManifest describes a shard whose files were uploaded to an object store.
+// It carries enough of the shard's metadata to index the shard without
+// downloading its files.
+type shardManifest struct {
+	Files  []string                                `json:"files"`
+	Series []string                                `json:"series"`
+	Fields map[string]map[string]influxql.DataType `json:"fields"`
+}
+
+// sealedManifest holds a manifest sealed with the store's ManifestKeys, as it
+// lists series keys.
+type sealedManifest struct {
+	Sealed []byte `json:"sealed"`
+}
+
+// readShardManifest returns the manifest in the shard directory at path, or
+// nil if the shard's files were never uploaded. A sealed manifest is bound to
+// the shard's remote key.
+func (s *Store) readShardManifest(path, key string) (*shardManifest, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(path, remoteManifestName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var sm sealedManifest
+	if err := json.Unmarshal(buf, &sm); err != nil {
+		return nil, fmt.Errorf("read manifest %s: %s", path, err)
+	} else if sm.Sealed != nil {
+		if s.ManifestKeys == nil {
+			return nil, fmt.Errorf("read manifest %s: manifest is encrypted but no encryption keys are configured", path)
+		}
+		if buf, err = s.ManifestKeys.Open(nil, sm.Sealed, []byte(key)); err != nil {
+			return nil, fmt.Errorf("read manifest %s: %s", path, err)
+		}
+	}
+
+	var m shardManifest
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf("read manifest %s: %s", path, err)
+	}
+	return &m, nil
+}
+
+// writeShardManifest atomically writes m to the shard directory at path,
+// sealed if the store has ManifestKeys.
+func (s *Store) writeShardManifest(path, key string, m *shardManifest) error {
+	buf, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if s.ManifestKeys != nil {
+		sealed, err := s.ManifestKeys.Seal(nil, buf, []byte(key))
+		if err != nil {
+			return err
+		}
+		if buf, err = json.Marshal(sealedManifest{Sealed: sealed}); err != nil {
+			return err
+		}
+	}
+
+	tmp := filepath.Join(path, remoteManifestName+".tmp")
+	if err := ioutil.WriteFile(tmp, buf, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(path, remoteManifestName))
+}
+
+// fetched returns true if every file listed in the manifest is present in the
+// shard directory at path.
+func (m *shardManifest) fetched(path string) bool {
+	for _, name := range m.Files {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// remoteKey returns the object store key of a file belonging to a shard.
+func remoteKey(database, retentionPolicy string, id uint64, name string) string {
+	return path.Join(database, retentionPolicy, strconv.FormatUint(id, 10), name)
+}
+
+// TierShard moves a shard to the cold tier. With no object store configured,
+// the shard's files are moved to the store's cold path. Otherwise they are
+// uploaded to the object store and only a manifest is kept in the cold path;
+// the files are fetched back the first time the shard's data is accessed.
+// Shards already in the cold tier are left untouched.
+//
+// The shard keeps serving writes and queries while its files are copied, with
+// its compactions disabled so that its TSM files don't change. It is only
+// closed once the copy replaces it. Points written in the meantime are kept in
+// the WAL, which the copy shares.
+func (s *Store) TierShard(id uint64) error {
+	if s.ColdPath == "" {
+		return ErrTieringNotConfigured
+	}
+
+	sh := s.Shard(id)
+	if sh == nil {
+		return ErrShardNotFound
+	} else if s.isCold(sh.path) {
+		return nil
+	}
+
+	start := time.Now()
+
+	// Flush the cache so that all of the shard's data is held in its TSM files.
+	if err := sh.writeSnapshot(); err != nil {
+		return err
+	}
+
+	sh.setCompactionsEnabled(false)
+	defer sh.setCompactionsEnabled(true)
+
+	// The files are copied into a temporary directory that is renamed once
+	// complete. If the server stops before the shard is removed from the data
+	// directory, the copy left there is removed when the store is reopened.
+	dst := filepath.Join(s.ColdPath, sh.database, sh.retentionPolicy, strconv.FormatUint(id, 10))
+	tmp := dst + "." + tierTempExtension
+	files, err := s.copyShardFiles(sh, tmp)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+
+	s.mu.Lock()
+	shard, err := s.swapColdShard(sh, files, tmp, dst)
+	s.mu.Unlock()
+	if shard == nil {
+		os.RemoveAll(tmp)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(sh.path); err != nil {
+		s.Logger.Info(fmt.Sprintf("failed to remove %s: %s", sh.path, err))
+	}
+
+	s.Logger.Info(fmt.Sprintf("%s moved to %s in %s", sh.path, dst, time.Since(start)))
+	return nil
+}
+
+// swapColdShard replaces the open shard sh with its copy in tmp, renamed to
+// dst, and returns the shard opened from the copy. The copy is discarded and
+// nil is returned if the shard was removed or its files changed while they
+// were copied. When the files were uploaded to the object store, the manifest
+// is taken from the open shard, so that it lists the series written during
+// the upload. The store lock must be held.
+func (s *Store) swapColdShard(sh *Shard, files []os.FileInfo, tmp, dst string) (*Shard, error) {
+	if s.shards[sh.id] != sh {
+		return nil, ErrShardNotFound
+	}
+	if changed, err := shardFilesChanged(sh.path, files); err != nil {
+		return nil, err
+	} else if changed {
+		return nil, fmt.Errorf("shard %d changed while being moved to the cold tier", sh.id)
+	}
+
+	var m *shardManifest
+	if s.ObjectStore != nil {
+		var err error
+		if m, err = sh.remoteManifest(); err != nil {
+			return nil, err
+		}
+		for _, fi := range files {
+			m.Files = append(m.Files, fi.Name())
+		}
+
+		if err := s.writeShardManifest(tmp, remoteKey(sh.database, sh.retentionPolicy, sh.id, remoteManifestName), m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		return nil, err
+	}
+	if err := sh.Close(); err != nil {
+		os.RemoveAll(dst)
+		return nil, err
+	}
+
+	// The cold copy now holds the shard, so it replaces the closed shard even
+	// if it fails to open.
+	shard := NewShard(sh.id, s.databaseIndexes[sh.database], dst, sh.walPath, s.EngineOptions)
+	shard.WithLogger(s.baseLogger)
+	shard.EnableOnOpen = sh.EnableOnOpen
+	s.shards[sh.id] = shard
+
+	if m != nil {
+		shard.setRemote(m, s.ObjectStore)
+		return shard, nil
+	}
+	return shard, shard.Open()
+}
+
+// copyShardFiles copies the files of a shard into tmp or, if the store has an
+// object store, uploads them to it. It returns the files copied, which must
+// not change until the copy replaces the shard.
+func (s *Store) copyShardFiles(sh *Shard, tmp string) ([]os.FileInfo, error) {
+	if err := os.RemoveAll(tmp); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(tmp, 0700); err != nil {
+		return nil, err
+	}
+
+	fis, err := shardFiles(sh.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fi := range fis {
+		src := filepath.Join(sh.path, fi.Name())
+
+		if s.ObjectStore == nil {
+			if err := copyFile(src, filepath.Join(tmp, fi.Name())); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := putObject(s.ObjectStore, remoteKey(sh.database, sh.retentionPolicy, sh.id, fi.Name()), src, fi.Size()); err != nil {
+			return nil, err
+		}
+	}
+	return fis, nil
+}
+
+// shardFiles returns the regular files of the shard directory at path.
+func shardFiles(path string) ([]os.FileInfo, error) {
+	fis, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a := fis[:0]
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() {
+			a = append(a, fi)
+		}
+	}
+	return a, nil
+}
+
+// shardFilesChanged returns true if the regular files of the shard directory
+// at path differ in name, size or modification time from files, such as when
+// a tombstone was written.
+func shardFilesChanged(path string, files []os.FileInfo) (bool, error) {
+	fis, err := shardFiles(path)
+	if err != nil {
+		return false, err
+	} else if len(fis) != len(files) {
+		return true, nil
+	}
+
+	for i, fi := range fis {
+		if fi.Name() != files[i].Name() || fi.Size() != files[i].Size() || !fi.ModTime().Equal(files[i].ModTime()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcileColdShards completes the moves to the cold tier interrupted by a
+// restart. Temporary cold directories are removed, as are the shard directories
+// in the data directory that were already copied to the cold path.
+func (s *Store) reconcileColdShards() error {
+	if s.ColdPath == "" {
+		return nil
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(s.ColdPath, "*", "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		if strings.HasSuffix(dir, "."+tierTempExtension) {
+			s.Logger.Info(fmt.Sprintf("removing incomplete cold shard %s", dir))
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rel, err := filepath.Rel(s.ColdPath, dir)
+		if err != nil {
+			return err
+		}
+		hot := filepath.Join(s.path, rel)
+		if _, err := os.Stat(hot); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		s.Logger.Info(fmt.Sprintf("removing %s moved to %s", hot, dir))
+		if err := os.RemoveAll(hot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCold returns true if the shard path is within the store's cold path.
+func (s *Store) isCold(shardPath string) bool {
+	if s.ColdPath == "" {
+		return false
+	}
+	return filepath.Clean(s.ColdPath) == filepath.Dir(filepath.Dir(filepath.Dir(filepath.Clean(shardPath))))
+}
+
+// deleteRemoteFiles removes any files uploaded for the shard from the object store.
+func (s *Store) deleteRemoteFiles(sh *Shard) error {
+	if s.ObjectStore == nil || !s.isCold(sh.path) {
+		return nil
+	}
+
+	m, err := s.readShardManifest(sh.path, remoteKey(sh.database, sh.retentionPolicy, sh.id, remoteManifestName))
+	if err != nil || m == nil {
+		return err
+	}
+
+	for _, name := range m.Files {
+		if err := s.ObjectStore.Delete(remoteKey(sh.database, sh.retentionPolicy, sh.id, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setRemote marks an unopened shard as held by the object store and adds the
+// series and fields listed in m to the index.
+func (s *Shard) setRemote(m *shardManifest, store ObjectStore) {
+	s.mu.Lock()
+	s.remote = m
+	s.objectStore = store
+	s.mu.Unlock()
+
+	for _, key := range m.Series {
+		_, tags, _ := models.ParseKey([]byte(key))
+		ss := s.index.CreateSeriesIndexIfNotExists(MeasurementFromSeriesKey(key), NewSeries(key, tags))
+		ss.AssignShard(s.id)
+	}
+
+	for name, fields := range m.Fields {
+		mm := s.index.CreateMeasurementIndexIfNotExists(name)
+		for field := range fields {
+			mm.SetFieldName(field)
+		}
+	}
+}
+
+// writeSnapshot flushes the cache of the shard to TSM files.
+func (s *Shard) writeSnapshot() error {
+	if err := s.readyLocal(); err != nil && err != ErrShardDisabled {
+		return err
+	}
+	return s.engine.WriteSnapshot()
+}
+
+// setCompactionsEnabled enables or disables the compactions of an open shard.
+// Compactions are only enabled if the shard is.
+func (s *Shard) setCompactionsEnabled(enabled bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine != nil {
+		s.engine.SetEnabled(enabled && s.enabled)
+	}
+}
+
+// remoteManifest returns a manifest of the series and fields of an open shard.
+func (s *Shard) remoteManifest() (*shardManifest, error) {
+	if err := s.ready(); err != nil && err != ErrShardDisabled {
+		return nil, err
+	}
+
+	m := &shardManifest{Fields: make(map[string]map[string]influxql.DataType)}
+	for _, mm := range s.index.Measurements() {
+		var n int
+		for _, key := range mm.SeriesKeys() {
+			if ss := s.index.Series(key); ss != nil && ss.Assigned(s.id) {
+				m.Series = append(m.Series, key)
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+
+		if mf := s.engine.MeasurementFields(mm.Name); mf != nil {
+			if fields := mf.FieldSet(); len(fields) > 0 {
+				m.Fields[mm.Name] = fields
+			}
+		}
+	}
+	return m, nil
+}
+
+// readyLocal fetches the shard's files if they are held by the object store
+// and then determines if the Shard is ready for queries or writes.
+func (s *Shard) readyLocal() error {
+	if err := s.fetch(); err != nil {
+		return err
+	}
+	return s.ready()
+}
+
+// fetch downloads the files of a shard held by the object store and opens it.
+// It is a no-op for shards whose files are local.
+func (s *Shard) fetch() error {
+	s.mu.RLock()
+	m := s.remote
+	s.mu.RUnlock()
+	if m == nil {
+		return nil
+	}
+
+	// Only one reader of the shard downloads its files. The others wait and
+	// find them fetched.
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	s.mu.RLock()
+	m, store := s.remote, s.objectStore
+	s.mu.RUnlock()
+	if m == nil {
+		return nil
+	}
+
+	start := time.Now()
+	for _, name := range m.Files {
+		if err := getObject(store, remoteKey(s.database, s.retentionPolicy, s.id, name), filepath.Join(s.path, name)); err != nil {
+			return NewShardError(s.id, err)
+		}
+	}
+
+	if err := s.Open(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.remote = nil
+	s.mu.Unlock()
+
+	s.logger.Info(fmt.Sprintf("%s fetched in %s", s.path, time.Since(start)))
+	return nil
+}
+
+// remoteFieldType returns the type of a field of a shard held by the object store.
+func (s *Shard) remoteFieldType(measurement, field string) (influxql.DataType, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.remote == nil {
+		return influxql.Unknown, false
+	}
+	typ, ok := s.remote.Fields[measurement][field]
+	return typ, ok
+}
+
+// putObject uploads the file at path to the object store.
+func putObject(store ObjectStore, key, path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return store.Put(key, f, size)
+}
+
+// getObject downloads an object to path unless the file already exists.
+func getObject(store ObjectStore, key, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	rc, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return writeFileFrom(rc, path)
+}
+
+// copyFile copies the file at src to dst.
+func copyFile(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writeFileFrom(f, dst)
+}
+
+// writeFileFrom writes the contents of r to a temporary file and renames it
+// to path once synced.
+func writeFileFrom(r io.Reader, path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}